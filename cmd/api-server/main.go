@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,15 +11,21 @@ import (
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/controller"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
 	"github.com/Tenoywil/CaribEx-backend/internal/repository/postgres"
 	"github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
 	"github.com/Tenoywil/CaribEx-backend/internal/routes"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
 	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
 	"github.com/Tenoywil/CaribEx-backend/pkg/config"
+	"github.com/Tenoywil/CaribEx-backend/pkg/jwtauth"
+	"github.com/Tenoywil/CaribEx-backend/pkg/lifecycle"
 	"github.com/Tenoywil/CaribEx-backend/pkg/logger"
 	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
+	"github.com/Tenoywil/CaribEx-backend/pkg/oracle"
 	"github.com/Tenoywil/CaribEx-backend/pkg/storage"
+	"github.com/Tenoywil/CaribEx-backend/pkg/webhook"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -28,6 +34,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	redisclient "github.com/redis/go-redis/v9"
+	zlog "github.com/rs/zerolog/log"
 )
 
 func main() {
@@ -41,30 +48,81 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// SIGINT and SIGTERM start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, appLogger); err != nil {
+		appLogger.Error(err, "Server stopped with an error")
+		stop()
+		os.Exit(1)
+	}
+	appLogger.Info("Server exited")
+}
+
+// run starts the server and blocks until ctx is cancelled or the server
+// fails. Before returning it waits for in-flight requests and background
+// workers, then closes Redis, the database and the blockchain clients.
+func run(ctx context.Context, cfg *config.Config, appLogger *logger.Logger) error {
+	shutdownTimeout, err := time.ParseDuration(cfg.ServerShutdownTimeout)
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	// Connections are closed here if startup fails part way
+	res := &resources{}
+	defer res.close(appLogger, shutdownTimeout)
+
 	// Initialize database connection pool
 	dbURL := cfg.DBConnectionString
 
 	dbConfig, err := pgxpool.ParseConfig(dbURL)
 	if err != nil {
-		appLogger.Error(err, "Failed to parse database config")
-		os.Exit(1)
+		return fmt.Errorf("failed to parse database config: %w", err)
 	}
 
 	dbConfig.MaxConns = int32(cfg.DBMaxConnections)
+	dbConfig.AfterConnect = postgres.RegisterUTCTimestamps
 
 	db, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
 	if err != nil {
-		appLogger.Error(err, "Failed to connect to database")
-		os.Exit(1)
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer db.Close()
+	res.db = db
 
 	appLogger.Info("Database connection established")
 
 	// Test database connection
 	if err := db.Ping(context.Background()); err != nil {
-		appLogger.Error(err, "Failed to ping database")
-		os.Exit(1)
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// Optional read replica for heavy product listing; reads fall back to the
+	// primary when it is not configured or unreachable
+	var readDB *pgxpool.Pool
+	if cfg.DBReadConnectionString != "" {
+		readConfig, err := pgxpool.ParseConfig(cfg.DBReadConnectionString)
+		if err != nil {
+			appLogger.Error(err, "Failed to parse read replica config - using primary for reads")
+		} else {
+			readConfig.MaxConns = int32(cfg.DBMaxConnections)
+			readConfig.AfterConnect = postgres.RegisterUTCTimestamps
+
+			readDB, err = pgxpool.NewWithConfig(context.Background(), readConfig)
+			if err == nil {
+				err = readDB.Ping(context.Background())
+			}
+			if err != nil {
+				appLogger.Error(err, "Failed to connect to read replica - using primary for reads")
+				if readDB != nil {
+					readDB.Close()
+				}
+				readDB = nil
+			} else {
+				res.readDB = readDB
+				appLogger.Info("Read replica connection established")
+			}
+		}
 	}
 
 	// Initialize Redis client
@@ -73,47 +131,134 @@ func main() {
 		Password: cfg.RedisPassword,
 		Username: "default",
 	})
-	defer redisClient.Close()
 
 	appLogger.Info("Redis connection established")
 
 	// Test Redis connection
 	if err := redisClient.Ping(context.Background()).Err(); err != nil {
-		appLogger.Error(err, "Failed to ping Redis")
-		os.Exit(1)
+		redisClient.Close()
+		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
-	// Initialize blockchain RPC client (optional - only if RPC_URL is configured)
+	// Monitor Redis connectivity and reconnect with backoff if it drops
+	redisMonitor := redis.NewMonitor(redisClient, 10*time.Second)
+	res.redis = redisMonitor
+
+	// Background workers are stopped and awaited during graceful shutdown
+	workers := lifecycle.New(context.Background())
+	res.workers = workers
+	workers.Go("redis-monitor", redisMonitor.Run)
+
+	// Initialize blockchain RPC clients (optional - only for chains with an RPC URL)
+	chainRegistry, err := blockchain.LoadRegistry(cfg.Chains)
+	if err != nil {
+		return fmt.Errorf("failed to load chain configuration: %w", err)
+	}
+	if n := cfg.RPCMinConfirmations; n != nil {
+		if *n < 0 {
+			return fmt.Errorf("RPC_MIN_CONFIRMATIONS must not be negative, got %d", *n)
+		}
+		blockchain.SetMinConfirmations(uint64(*n))
+	}
+	if err := blockchain.InitRPC(chainRegistry); err != nil {
+		// Don't exit - those chains will be unavailable but the app can still run
+		appLogger.Error(err, "Failed to initialize some blockchain RPC clients")
+	}
 	if cfg.RPCURL != "" {
-		if err := blockchain.InitRPC(cfg.RPCURL); err != nil {
+		if _, err := blockchain.ConnectRPC(cfg.RPCURL); err != nil {
 			appLogger.Error(err, "Failed to initialize blockchain RPC client")
-			// Don't exit - blockchain features will be unavailable but app can still run
-		} else {
-			defer blockchain.Close()
-			appLogger.Info("Blockchain RPC client initialized")
 		}
+	}
+	// Chains that failed to connect are retried on use
+	rpcReady := len(blockchain.Pool().Configured()) > 0
+	if rpcReady {
+		appLogger.Info(fmt.Sprintf("Blockchain RPC clients initialized for chains %v", blockchain.ConnectedChains()))
 	} else {
-		appLogger.Info("Blockchain RPC URL not configured - blockchain features disabled")
+		appLogger.Info("No blockchain RPC URL configured - blockchain features disabled")
 	}
 
 	// Initialize repositories
-	sessionRepo := redis.NewSessionRepository(redisClient)
+	sessionRepo := redis.NewMonitoredSessionRepository(redisMonitor)
 	userRepo := postgres.NewUserRepository(db)
-	productRepo := postgres.NewProductRepository(db)
+	productRepo := postgres.NewProductRepository(db, readDB)
+	reservationRepo := postgres.NewReservationRepository(db)
+	adjustmentRepo := postgres.NewAdjustmentRepository(db)
 	walletRepo := postgres.NewWalletRepository(db)
 	cartRepo := postgres.NewCartRepository(db)
 	orderRepo := postgres.NewOrderRepository(db)
+	payoutRepo := postgres.NewPayoutRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	checkoutRepo := postgres.NewCheckoutRepository(db)
+
+	// Per-user order rate limit; ORDER_RATE_LIMIT=0 disables it
+	var orderRateLimiter order.RateLimiter
+	if cfg.OrderRateLimit > 0 {
+		orderRateWindow, err := time.ParseDuration(cfg.OrderRateWindow)
+		if err != nil || orderRateWindow <= 0 {
+			orderRateWindow = time.Minute
+		}
+		orderRateLimiter = redis.NewOrderRateLimiter(redisMonitor, cfg.OrderRateLimit, orderRateWindow)
+		appLogger.Info(fmt.Sprintf("Order rate limit: %d per %s", cfg.OrderRateLimit, orderRateWindow))
+	}
+
+	// Idempotency-Key support for order creation; an unfinished claim lapses
+	// after a minute so a crashed request does not block retries for a day
+	orderIdempotency := redis.NewIdempotencyStore(redisMonitor, time.Minute, 24*time.Hour)
+
+	// Product view counting, debounced per viewer in Redis and flushed to
+	// Postgres in the background; PRODUCT_VIEW_WINDOW=0 disables it
+	var productViewUseCase *usecase.ProductViewUseCase
+	if viewWindow, err := time.ParseDuration(cfg.ProductViewWindow); err == nil && viewWindow > 0 {
+		flushInterval, err := time.ParseDuration(cfg.ProductViewFlushInterval)
+		if err != nil || flushInterval <= 0 {
+			flushInterval = time.Minute
+		}
+		productViewUseCase = usecase.NewProductViewUseCase(
+			redis.NewProductViewCounter(redisMonitor, viewWindow),
+			postgres.NewViewRepository(db),
+		)
+		workers.Go("product-view-flusher", func(ctx context.Context) {
+			productViewUseCase.RunViewFlusher(ctx, flushInterval)
+		})
+	} else {
+		appLogger.Info("PRODUCT_VIEW_WINDOW not configured - product view tracking disabled")
+	}
+
+	// Exchange rates for balance display and deposit conversion; USDC is
+	// pegged to USD
+	var priceOracle wallet.PriceOracle
+	if cfg.FXJAMPerUSD > 0 {
+		priceOracle = oracle.NewCachedOracle(oracle.NewStaticOracle(map[wallet.Currency]float64{
+			wallet.CurrencyUSDC: 1,
+			wallet.CurrencyJAM:  cfg.FXJAMPerUSD,
+		}), 5*time.Minute)
+	} else {
+		appLogger.Info("FX_JAM_PER_USD not configured - wallet currency conversion disabled")
+	}
 
 	// Initialize storage service
+	thumbnailWidths, err := storage.ParseThumbnailWidths(cfg.StorageThumbnailWidths)
+	if err != nil {
+		return fmt.Errorf("failed to parse STORAGE_THUMBNAIL_WIDTHS: %w", err)
+	}
 	storageService, err := storage.NewSupabaseStorage(storage.Config{
-		URL:         cfg.SupabaseURL,
-		Key:         cfg.SupabaseKey,
-		Bucket:      cfg.SupabaseBucket,
-		MaxFileSize: cfg.StorageMaxFileSize,
+		URL:           cfg.SupabaseURL,
+		Key:           cfg.SupabaseKey,
+		Bucket:        cfg.SupabaseBucket,
+		MaxFileSize:   cfg.StorageMaxFileSize,
+		ConvertToWebP: cfg.StorageConvertWebP,
+		KeepOriginal:  cfg.StorageKeepOriginal,
+		Dimensions: storage.DimensionLimits{
+			MaxWidth:       cfg.StorageMaxImageWidth,
+			MaxHeight:      cfg.StorageMaxImageHeight,
+			MinWidth:       cfg.StorageMinImageWidth,
+			MinHeight:      cfg.StorageMinImageHeight,
+			MaxAspectRatio: cfg.StorageMaxAspectRatio,
+		},
+		ThumbnailWidths: thumbnailWidths,
 	})
 	if err != nil {
-		appLogger.Error(err, "Failed to initialize storage service")
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize storage service: %w", err)
 	}
 	appLogger.Info("Storage service initialized")
 
@@ -129,8 +274,7 @@ func main() {
 			S3ForcePathStyle: aws.Bool(true),
 		})
 		if err != nil {
-			appLogger.Error(err, "Failed to create S3 session")
-			os.Exit(1)
+			return fmt.Errorf("failed to create S3 session: %w", err)
 		}
 
 		s3Uploader := s3manager.NewUploader(sess)
@@ -142,40 +286,175 @@ func main() {
 		appLogger.Info("S3 credentials not configured - file uploads will use basic storage service")
 	}
 
-	// S3Service is now available for use in controllers
-	_ = s3Service // TODO: Pass to controllers that need file upload functionality
+	// Products use S3 for batched image cleanup, private documents and
+	// presigned image URLs when it is configured. The interface stays nil
+	// otherwise so controllers can tell it is missing.
+	var s3Storage storage.Uploader
+	if s3Service != nil {
+		s3Storage = s3Service
+	}
 
 	// Initialize use cases
 	userUseCase := usecase.NewUserUseCase(userRepo)
-	authUseCase := usecase.NewAuthUseCase(sessionRepo, userUseCase, cfg.SIWEDomain)
-	productUseCase := usecase.NewProductUseCase(productRepo)
-	walletUseCase := usecase.NewWalletUseCase(walletRepo)
-	cartUseCase := usecase.NewCartUseCase(cartRepo)
-	orderUseCase := usecase.NewOrderUseCase(orderRepo)
-	blockchainUseCase := usecase.NewBlockchainUseCase(walletRepo)
+	// Access tokens are issued at sign-in only when a JWT secret is set
+	var tokenSigner *jwtauth.Signer
+	if cfg.JWTSecret != "" {
+		jwtExpiration, _ := time.ParseDuration(cfg.JWTExpiration)
+		tokenSigner, err = jwtauth.NewSigner(cfg.JWTSecret, jwtExpiration)
+		if err != nil {
+			return fmt.Errorf("failed to configure JWT signing: %w", err)
+		}
+	}
+
+	sessionDuration, err := time.ParseDuration(cfg.SessionDuration)
+	if cfg.SessionDuration != "" && (err != nil || sessionDuration <= 0) {
+		appLogger.Warn(fmt.Sprintf("Invalid SESSION_DURATION %q - sessions last %s", cfg.SessionDuration, usecase.DefaultSessionDuration))
+		sessionDuration = 0
+	}
+	// Invalid or empty durations disable renewal and use the default cap
+	sessionRenewWindow, _ := time.ParseDuration(cfg.SessionRenewWindow)
+	sessionMaxLifetime, _ := time.ParseDuration(cfg.SessionMaxLifetime)
+	// An invalid or empty maximum message age uses siwe.DefaultMaxAge
+	siweMaxAge, _ := time.ParseDuration(cfg.SIWEMaxAge)
+	siweChainIDs, err := blockchain.ParseChainIDs(cfg.SIWEChainIDs)
+	if err != nil {
+		return fmt.Errorf("failed to parse SIWE chain IDs: %w", err)
+	}
+	for _, id := range siweChainIDs {
+		if !blockchain.ValidateChainID(id) {
+			return fmt.Errorf("SIWE chain %d is not a supported chain", id)
+		}
+	}
+	walletCurrency := wallet.DefaultCurrency
+	if cfg.WalletDefaultCurrency != "" {
+		walletCurrency, err = wallet.ParseCurrency(cfg.WalletDefaultCurrency)
+		if err != nil {
+			return fmt.Errorf("invalid WALLET_DEFAULT_CURRENCY %q: %w", cfg.WalletDefaultCurrency, err)
+		}
+	}
+	walletUseCase := usecase.NewWalletUseCase(walletRepo, priceOracle)
+	authUseCase := usecase.NewAuthUseCase(sessionRepo, userUseCase, usecase.AuthConfig{
+		Domains:            cfg.SIWEDomainsSlice,
+		URIs:               cfg.SIWEURIsSlice,
+		BindNonce:          cfg.SIWEBindNonce,
+		SessionDuration:    sessionDuration,
+		SessionRenewWindow: sessionRenewWindow,
+		SessionMaxLifetime: sessionMaxLifetime,
+		MessageMaxAge:      siweMaxAge,
+		ChainIDs:           siweChainIDs,
+		Tokens:             tokenSigner,
+		Wallets:            walletUseCase,
+		WalletCurrency:     walletCurrency,
+	})
+	productUseCase := usecase.NewProductUseCase(productRepo, reservationRepo, adjustmentRepo, usecase.ProductConfig{
+		RegenerateSlugOnTitleChange: cfg.ProductSlugRegenerate,
+	})
+	cartUseCase := usecase.NewCartUseCase(cartRepo, productRepo, reservationRepo)
+	payoutUseCase := usecase.NewPayoutUseCase(payoutRepo, orderRepo, productRepo, cfg.PlatformFeePercent)
+	// Outbound webhooks are delivered in the background, with every attempt
+	// recorded; plain HTTP URLs and private hosts are only accepted outside
+	// production
+	allowLocalWebhooks := cfg.AppEnv != "production"
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, orderRepo, productRepo,
+		webhook.NewHTTPDispatcher(webhook.DispatcherConfig{
+			AllowPrivate: allowLocalWebhooks,
+			MaxAttempts:  cfg.WebhookMaxAttempts,
+			OnAttempt:    usecase.RecordDeliveries(notificationRepo),
+		}), allowLocalWebhooks)
+	workers.Go("webhook-dispatcher", notificationUseCase.RunDispatcher)
+	checkoutUseCase := usecase.NewCheckoutUseCase(checkoutRepo, productRepo)
+	orderUseCase := usecase.NewOrderUseCase(orderRepo, checkoutUseCase, productRepo, payoutUseCase, orderRateLimiter, orderIdempotency, notificationUseCase)
+	depositAddresses, err := blockchain.ParseDepositAddresses(cfg.PlatformDepositAddresses)
+	if err != nil {
+		return fmt.Errorf("failed to parse platform deposit addresses: %w", err)
+	}
+	if rpcReady && len(depositAddresses) == 0 {
+		appLogger.Info("No platform deposit addresses configured - on-chain deposits will be rejected")
+	}
+	blockchainUseCase := usecase.NewBlockchainUseCase(walletRepo, userRepo, depositAddresses, priceOracle)
+
+	trustedProxies, err := middleware.NewTrustedProxies(cfg.TrustedProxiesSlice)
+	if err != nil {
+		return fmt.Errorf("failed to parse trusted proxies: %w", err)
+	}
+
+	cookieSameSite, err := controller.ParseSameSite(cfg.CookieSameSite)
+	if err != nil {
+		return fmt.Errorf("failed to parse COOKIE_SAMESITE: %w", err)
+	}
 
 	// Initialize controllers
-	authController := controller.NewAuthController(authUseCase)
-	userController := controller.NewUserController(userUseCase)
-	productController := controller.NewProductController(productUseCase, storageService)
+	authController := controller.NewAuthController(authUseCase, controller.CookieConfig{
+		Secure:         cfg.AppEnv == "production",
+		TrustedProxies: trustedProxies,
+		Domain:         cfg.CookieDomain,
+		SameSite:       cookieSameSite,
+	})
+	userController := controller.NewUserController(userUseCase, authUseCase)
+	productController := controller.NewProductController(productUseCase, userUseCase, storageService, s3Storage, productViewUseCase)
 	walletController := controller.NewWalletController(walletUseCase)
 	cartController := controller.NewCartController(cartUseCase)
-	orderController := controller.NewOrderController(orderUseCase)
-	blockchainController := controller.NewBlockchainController(blockchainUseCase)
+	orderController := controller.NewOrderController(orderUseCase, checkoutUseCase, userUseCase)
+	var blockchainController *controller.BlockchainController
+	if rpcReady {
+		blockchainController = controller.NewBlockchainController(blockchainUseCase)
+	}
+	payoutController := controller.NewPayoutController(payoutUseCase)
+	if cfg.PaymentWebhookSecret == "" {
+		appLogger.Warn("PAYMENT_WEBHOOK_SECRET not set - payment callbacks will be refused")
+	}
+	webhookController := controller.NewWebhookController(orderUseCase, notificationUseCase, cfg.PaymentWebhookSecret)
+	healthController := controller.NewHealthController(db, controller.PingerFunc(func(ctx context.Context) error {
+		return redisMonitor.Client().Ping(ctx).Err()
+	}), controller.PingerFunc(func(ctx context.Context) error {
+		err := blockchain.HealthCheck(ctx)
+		if errors.Is(err, blockchain.ErrRPCDisabled) {
+			return controller.ErrDependencyDisabled
+		}
+		return err
+	}))
 
 	// Set Gin mode
 	if os.Getenv("ENV") == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Initialize Gin router
-	router := gin.Default()
+	// Initialize Gin router. RequestLogger replaces gin.Default's logger with
+	// a structured access log at DEBUG; slow requests are flagged at WARN.
+	router := gin.New()
+	// Only listed proxies may set the client IP through X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.TrustedProxiesSlice); err != nil {
+		return fmt.Errorf("failed to set trusted proxies: %w", err)
+	}
+	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
+	slowRequestThreshold, err := time.ParseDuration(cfg.SlowRequestThreshold)
+	if err != nil {
+		slowRequestThreshold = middleware.DefaultSlowRequestThreshold
+	}
+	router.Use(middleware.RequestLogger(appLogger, slowRequestThreshold))
 
 	// Setup CORS
-	router.Use(middleware.SetupCORS(cfg.AllowedOriginsSlice))
+	router.Use(middleware.SetupCORS(middleware.CORSConfig{
+		AllowedOrigins:      cfg.AllowedOriginsSlice,
+		AllowSchemeMismatch: cfg.CORSAllowSchemeMismatch,
+		Debug:               cfg.CORSDebug || cfg.AppEnv != "production",
+		Logger:              zlog.Logger,
+	}))
+
+	// Per-client limit on the auth routes; AUTH_RATE_LIMIT=0 disables it
+	var authRateLimit gin.HandlerFunc
+	if cfg.AuthRateLimit > 0 {
+		authRateWindow, err := time.ParseDuration(cfg.AuthRateWindow)
+		if err != nil || authRateWindow <= 0 {
+			authRateWindow = time.Minute
+		}
+		authRateLimit = middleware.RateLimit(redisMonitor, cfg.AuthRateLimit, authRateWindow)
+		appLogger.Info(fmt.Sprintf("Auth rate limit: %d per %s", cfg.AuthRateLimit, authRateWindow))
+	}
 
 	// Setup routes
-	routes.SetupRoutes(router, authController, authUseCase, userController, productController, walletController, cartController, orderController, blockchainController)
+	routes.SetupRoutes(router, authController, authUseCase, userUseCase, userController, productController, walletController, cartController, orderController, blockchainController, payoutController, webhookController, healthController, authRateLimit)
 
 	// Start server
 	addr := fmt.Sprintf("%s:%s", cfg.ServerHost, cfg.ServerPort)
@@ -193,26 +472,8 @@ func main() {
 		WriteTimeout: writeTimeout,
 	}
 
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	appLogger.Info("Shutting down server...")
-
-	shutdownTimeout, _ := time.ParseDuration(cfg.ServerShutdownTimeout)
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		appLogger.Error(err, "Server forced to shutdown")
-	}
-
-	appLogger.Info("Server exited")
+	err = serve(ctx, srv, shutdownTimeout)
+	appLogger.Info("Server stopped, closing connections...")
+	res.close(appLogger, shutdownTimeout)
+	return err
 }
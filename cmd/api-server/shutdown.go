@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
+	"github.com/Tenoywil/CaribEx-backend/pkg/lifecycle"
+	"github.com/Tenoywil/CaribEx-backend/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultShutdownTimeout is used when SERVER_SHUTDOWN_TIMEOUT is unset or
+// invalid
+const defaultShutdownTimeout = 30 * time.Second
+
+// resources are the long-lived connections opened by run. They are recorded
+// as they are opened so that a failure part way through startup releases
+// whatever was already acquired.
+type resources struct {
+	workers *lifecycle.Manager
+	redis   *redis.Monitor
+	db      *pgxpool.Pool
+	readDB  *pgxpool.Pool
+
+	once sync.Once
+}
+
+// close stops the background workers, then closes Redis, the database pools
+// and the blockchain RPC clients in that order, logging each. Only the first
+// call has any effect.
+func (r *resources) close(appLogger *logger.Logger, timeout time.Duration) {
+	r.once.Do(func() {
+		if r.workers != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := r.workers.Shutdown(ctx); err != nil {
+				appLogger.Error(err, "Background workers forced to shutdown")
+			} else {
+				appLogger.Info("Background workers stopped")
+			}
+		}
+		if r.redis != nil {
+			if err := r.redis.Close(); err != nil {
+				appLogger.Error(err, "Failed to close Redis connection")
+			} else {
+				appLogger.Info("Redis connection closed")
+			}
+		}
+		if r.readDB != nil {
+			r.readDB.Close()
+			appLogger.Info("Read replica connection pool closed")
+		}
+		if r.db != nil {
+			r.db.Close()
+			appLogger.Info("Database connection pool closed")
+		}
+		blockchain.Close()
+		appLogger.Info("Blockchain RPC clients closed")
+	})
+}
+
+// serve runs srv until ctx is cancelled, then shuts it down gracefully,
+// waiting up to timeout for in-flight requests. If the server fails to start
+// or stops on its own, that error is returned instead.
+func serve(ctx context.Context, srv *http.Server, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server forced to shutdown: %w", err)
+	}
+	return nil
+}
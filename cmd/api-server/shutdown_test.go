@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServe_ShutsDownWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, srv, time.Second) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() did not return after cancellation")
+	}
+}
+
+func TestServe_ReturnsListenError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	defer taken.Close()
+
+	srv := &http.Server{Addr: taken.Addr().String(), Handler: http.NotFoundHandler()}
+	done := make(chan error, 1)
+	go func() { done <- serve(context.Background(), srv, time.Second) }()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("serve() error = nil, want the listen error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() did not return when the address was taken")
+	}
+}
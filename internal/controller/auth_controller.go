@@ -1,9 +1,15 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -11,17 +17,68 @@ import (
 // AuthController handles authentication HTTP requests
 type AuthController struct {
 	authUseCase *usecase.AuthUseCase
+	cookies     CookieConfig
+}
+
+// CookieConfig controls the attributes of the auth cookies
+type CookieConfig struct {
+	// Secure marks cookies Secure on every request, e.g. in production
+	Secure bool
+	// TrustedProxies may mark a plain-HTTP request as HTTPS with
+	// X-Forwarded-Proto, so cookies stay Secure behind a TLS-terminating proxy
+	TrustedProxies *middleware.TrustedProxies
+	// Domain is the cookies' Domain attribute; empty scopes them to the
+	// request host
+	Domain string
+	// SameSite is the cookies' SameSite mode; zero means Lax. SameSite=None
+	// cookies are always marked Secure, as browsers reject them otherwise.
+	SameSite http.SameSite
+}
+
+// ParseSameSite reads a SameSite mode of "lax", "strict" or "none", in any
+// case. An empty string yields zero, the default.
+func ParseSameSite(mode string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		return 0, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("invalid SameSite mode %q: want lax, strict or none", mode)
+	}
 }
 
 // NewAuthController creates a new auth controller
-func NewAuthController(authUseCase *usecase.AuthUseCase) *AuthController {
-	return &AuthController{authUseCase: authUseCase}
+func NewAuthController(authUseCase *usecase.AuthUseCase, cookies CookieConfig) *AuthController {
+	return &AuthController{authUseCase: authUseCase, cookies: cookies}
+}
+
+// setCookie sets an HTTP-only auth cookie with the configured attributes,
+// marking it Secure when configured or when the client connected over HTTPS.
+// Clearing a cookie goes through here too, as browsers only clear a cookie
+// whose attributes match.
+func (c *AuthController) setCookie(ctx *gin.Context, name, value string, maxAge int) {
+	sameSite := c.cookies.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+	secure := c.cookies.Secure || sameSite == http.SameSiteNoneMode || c.cookies.TrustedProxies.IsHTTPS(ctx)
+	ctx.SetSameSite(sameSite)
+	ctx.SetCookie(name, value, maxAge, "/", c.cookies.Domain, secure, true)
 }
 
-// NonceResponse represents the nonce response
+// nonceTokenCookie holds the client token bound to an issued nonce
+const nonceTokenCookie = "siwe_nonce_token"
+
+// NonceResponse represents the nonce response. Timestamps are serialized as
+// RFC3339 in UTC, matching the domain models.
 type NonceResponse struct {
-	Nonce     string `json:"nonce"`
-	ExpiresAt string `json:"expires_at"`
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // GetNonce handles GET /auth/nonce
@@ -32,9 +89,14 @@ func (c *AuthController) GetNonce(ctx *gin.Context) {
 		return
 	}
 
+	// Bind the nonce to this browser with a short-lived cookie
+	if nonce.Token != "" {
+		c.setCookie(ctx, nonceTokenCookie, nonce.Token, int(time.Until(nonce.ExpiresAt).Seconds()))
+	}
+
 	ctx.JSON(http.StatusOK, NonceResponse{
 		Nonce:     nonce.Value,
-		ExpiresAt: nonce.ExpiresAt.Format(http.TimeFormat),
+		ExpiresAt: nonce.ExpiresAt.UTC(),
 	})
 }
 
@@ -52,7 +114,10 @@ type SIWEResponse struct {
 		WalletAddress string `json:"wallet_address"`
 		Role          string `json:"role"`
 	} `json:"user"`
-	SessionID string `json:"session_id"`
+	SessionID string    `json:"session_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// AccessToken is only issued when JWT signing is configured
+	AccessToken *auth.AccessToken `json:"access_token,omitempty"`
 }
 
 // AuthenticateSIWE handles POST /auth/siwe
@@ -63,31 +128,32 @@ func (c *AuthController) AuthenticateSIWE(ctx *gin.Context) {
 		return
 	}
 
-	session, user, err := c.authUseCase.VerifySIWE(
+	nonceToken, _ := ctx.Cookie(nonceTokenCookie)
+
+	session, user, accessToken, err := c.authUseCase.VerifySIWE(
 		ctx.Request.Context(),
 		req.Message,
 		req.Signature,
+		nonceToken,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("SIWE verification failed")
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed: " + err.Error()})
+		middleware.AbortUnauthenticated(ctx, "authentication failed: "+err.Error())
 		return
 	}
 
-	// Set session cookie
-	ctx.SetCookie(
-		"session_id",
-		session.ID,
-		int(session.ExpiresAt.Sub(session.CreatedAt).Seconds()),
-		"/",
-		"",
-		false, // Set to true in production with HTTPS
-		true,  // HTTP only
-	)
+	// The nonce is consumed, so its binding cookie is no longer needed
+	if nonceToken != "" {
+		c.setCookie(ctx, nonceTokenCookie, "", -1)
+	}
+
+	c.setSessionCookie(ctx, session)
 
 	// Return response
 	response := SIWEResponse{
-		SessionID: session.ID,
+		SessionID:   session.ID,
+		ExpiresAt:   session.ExpiresAt.UTC(),
+		AccessToken: accessToken,
 	}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
@@ -97,12 +163,45 @@ func (c *AuthController) AuthenticateSIWE(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// setSessionCookie sets the session cookie to last until the session's
+// lifetime cap, since the session may be renewed up to then
+func (c *AuthController) setSessionCookie(ctx *gin.Context, session *auth.Session) {
+	maxAge := int(time.Until(c.authUseCase.SessionDeadline(session)).Seconds())
+	c.setCookie(ctx, "session_id", session.ID, maxAge)
+}
+
+// RefreshResponse represents the refresh response
+type RefreshResponse struct {
+	SessionID string    `json:"session_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshSession handles POST /auth/refresh, extending the current session
+func (c *AuthController) RefreshSession(ctx *gin.Context) {
+	session, err := c.authUseCase.RefreshSession(ctx.Request.Context(), ctx.GetString("session_id"))
+	if errors.Is(err, auth.ErrStoreUnavailable) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "authentication temporarily unavailable"})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("session refresh failed")
+		middleware.AbortUnauthenticated(ctx, "invalid or expired session")
+		return
+	}
+
+	c.setSessionCookie(ctx, session)
+	ctx.JSON(http.StatusOK, RefreshResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt.UTC(),
+	})
+}
+
 // GetMe handles GET /auth/me
 func (c *AuthController) GetMe(ctx *gin.Context) {
 	// Get user from context (set by auth middleware)
 	userID, exists := ctx.Get("user_id")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		middleware.AbortUnauthenticated(ctx, "not authenticated")
 		return
 	}
 
@@ -116,8 +215,8 @@ func (c *AuthController) GetMe(ctx *gin.Context) {
 
 // Logout handles POST /auth/logout
 func (c *AuthController) Logout(ctx *gin.Context) {
-	sessionID, err := ctx.Cookie("session_id")
-	if err != nil {
+	sessionID, ok := middleware.SessionToken(ctx)
+	if !ok {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "no session found"})
 		return
 	}
@@ -128,15 +227,7 @@ func (c *AuthController) Logout(ctx *gin.Context) {
 	}
 
 	// Clear cookie
-	ctx.SetCookie(
-		"session_id",
-		"",
-		-1,
-		"/",
-		"",
-		false,
-		true,
-	)
+	c.setCookie(ctx, "session_id", "", -1)
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
 }
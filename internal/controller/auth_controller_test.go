@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	redisrepo "github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestAuthController(t *testing.T, cookies CookieConfig) *AuthController {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	authUseCase := usecase.NewAuthUseCase(
+		redisrepo.NewSessionRepository(client),
+		usecase.NewUserUseCase(nil),
+		usecase.AuthConfig{Domains: []string{"localhost:3000"}},
+	)
+	return NewAuthController(authUseCase, cookies)
+}
+
+func TestGetNonce_ExpiresAtIsRFC3339(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/auth/nonce", newTestAuthController(t, CookieConfig{}).GetNonce)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/auth/nonce", nil)
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	expiresAt := body["expires_at"]
+	if _, err := time.Parse(http.TimeFormat, expiresAt); err == nil {
+		t.Errorf("expires_at %q uses HTTP date format", expiresAt)
+	}
+	parsed, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		t.Fatalf("expires_at %q is not RFC3339: %v", expiresAt, err)
+	}
+	if _, offset := parsed.Zone(); offset != 0 {
+		t.Errorf("expires_at %q is not UTC", expiresAt)
+	}
+}
+
+func TestLogout_SecureCookieBehindTrustedProxy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	proxies, err := middleware.NewTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("NewTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		cookies    CookieConfig
+		remoteAddr string
+		proto      string
+		wantSecure bool
+	}{
+		{"trusted proxy forwarding https", CookieConfig{TrustedProxies: proxies}, "10.1.2.3:4000", "https", true},
+		{"trusted proxy forwarding http", CookieConfig{TrustedProxies: proxies}, "10.1.2.3:4000", "http", false},
+		{"untrusted client forging https", CookieConfig{TrustedProxies: proxies}, "203.0.113.7:4000", "https", false},
+		{"no trusted proxies configured", CookieConfig{}, "10.1.2.3:4000", "https", false},
+		{"secure by environment", CookieConfig{Secure: true}, "203.0.113.7:4000", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/auth/logout", newTestAuthController(t, tt.cookies).Logout)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-1"})
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			cookies := rec.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != "session_id" {
+				t.Fatalf("cookies = %v, want session_id cleared", cookies)
+			}
+			if cookies[0].Secure != tt.wantSecure {
+				t.Errorf("Secure = %v, want %v", cookies[0].Secure, tt.wantSecure)
+			}
+		})
+	}
+}
+
+func TestLogout_CookieAttributesFollowConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		cookies      CookieConfig
+		wantSecure   bool
+		wantDomain   string
+		wantSameSite http.SameSite
+	}{
+		{"development", CookieConfig{}, false, "", http.SameSiteLaxMode},
+		{"production", CookieConfig{Secure: true, Domain: "caribex.com", SameSite: http.SameSiteStrictMode}, true, "caribex.com", http.SameSiteStrictMode},
+		{"cross-site", CookieConfig{SameSite: http.SameSiteNoneMode}, true, "", http.SameSiteNoneMode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/auth/logout", newTestAuthController(t, tt.cookies).Logout)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: "session-1"})
+			router.ServeHTTP(rec, req)
+
+			cookies := rec.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != "session_id" {
+				t.Fatalf("cookies = %v, want session_id cleared", cookies)
+			}
+			c := cookies[0]
+			if c.Secure != tt.wantSecure || c.Domain != tt.wantDomain || c.SameSite != tt.wantSameSite || !c.HttpOnly {
+				t.Errorf("cookie = Secure %v, Domain %q, SameSite %v, HttpOnly %v; want Secure %v, Domain %q, SameSite %v, HttpOnly",
+					c.Secure, c.Domain, c.SameSite, c.HttpOnly, tt.wantSecure, tt.wantDomain, tt.wantSameSite)
+			}
+		})
+	}
+}
+
+func TestParseSameSite(t *testing.T) {
+	for mode, want := range map[string]http.SameSite{
+		"":       0,
+		"lax":    http.SameSiteLaxMode,
+		"Strict": http.SameSiteStrictMode,
+		"NONE":   http.SameSiteNoneMode,
+	} {
+		if got, err := ParseSameSite(mode); err != nil || got != want {
+			t.Errorf("ParseSameSite(%q) = %v, %v; want %v", mode, got, err, want)
+		}
+	}
+	if _, err := ParseSameSite("sometimes"); err == nil {
+		t.Error("ParseSameSite(\"sometimes\") error = nil, want error")
+	}
+}
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	redisrepo "github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// stubUserRepo is a map-backed user.Repository
+type stubUserRepo map[string]*user.User
+
+func (r stubUserRepo) Create(u *user.User) error { r[u.ID] = u; return nil }
+func (r stubUserRepo) GetByID(id string) (*user.User, error) {
+	u, ok := r[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+func (r stubUserRepo) GetByWalletAddress(string) (*user.User, error) {
+	return nil, errors.New("user not found")
+}
+func (r stubUserRepo) Update(u *user.User) error { r[u.ID] = u; return nil }
+func (r stubUserRepo) Delete(id string) error    { delete(r, id); return nil }
+
+func TestProtectedEndpoint_UnauthenticatedVsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	sessions := redisrepo.NewSessionRepository(client)
+
+	users := stubUserRepo{
+		"user-a":  {ID: "user-a", Role: user.RoleCustomer},
+		"user-b":  {ID: "user-b", Role: user.RoleCustomer},
+		"admin-1": {ID: "admin-1", Role: user.RoleAdmin},
+	}
+	userUseCase := usecase.NewUserUseCase(users)
+	authUseCase := usecase.NewAuthUseCase(sessions, userUseCase, usecase.AuthConfig{Domains: []string{"localhost:3000"}})
+
+	sessionFor := func(userID string) string {
+		s := auth.NewSession(userID, "0x0000000000000000000000000000000000000001", users[userID].Role, time.Hour)
+		if err := sessions.SaveSession(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+		return s.ID
+	}
+
+	router := gin.New()
+	userController := NewUserController(userUseCase, authUseCase)
+	protected := router.Group("/users", middleware.AuthMiddleware(authUseCase))
+	protected.PUT("/:id", userController.UpdateUser)
+	protected.DELETE("/:id", userController.DeleteUser)
+
+	const body = `{"username":"a"}`
+	tests := []struct {
+		name       string
+		method     string
+		session    string
+		wantStatus int
+		wantCode   string
+	}{
+		{"No session", http.MethodPut, "", http.StatusUnauthorized, middleware.CodeUnauthenticated},
+		{"Unknown session", http.MethodPut, "not-a-session", http.StatusUnauthorized, middleware.CodeUnauthenticated},
+		{"Another user", http.MethodPut, sessionFor("user-b"), http.StatusForbidden, middleware.CodeForbidden},
+		{"Another user deleting", http.MethodDelete, sessionFor("user-b"), http.StatusForbidden, middleware.CodeForbidden},
+		{"Self", http.MethodPut, sessionFor("user-a"), http.StatusOK, ""},
+		{"Admin", http.MethodDelete, sessionFor("admin-1"), http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users/user-a", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.session != "" {
+				req.AddCookie(&http.Cookie{Name: "session_id", Value: tt.session})
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if resp["code"] != tt.wantCode || resp["error"] == "" {
+				t.Errorf("body = %v, want code %q with an error message", resp, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestUserRole_OnlyAdminsChangeIt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	sessions := redisrepo.NewSessionRepository(client)
+
+	users := stubUserRepo{
+		"user-a":  {ID: "user-a", Role: user.RoleCustomer},
+		"admin-1": {ID: "admin-1", Role: user.RoleAdmin},
+	}
+	userUseCase := usecase.NewUserUseCase(users)
+	authUseCase := usecase.NewAuthUseCase(sessions, userUseCase, usecase.AuthConfig{Domains: []string{"localhost:3000"}})
+	sessionFor := func(userID string) string {
+		s := auth.NewSession(userID, "0x0000000000000000000000000000000000000001", users[userID].Role, time.Hour)
+		if err := sessions.SaveSession(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+		return s.ID
+	}
+
+	router := gin.New()
+	userController := NewUserController(userUseCase, authUseCase)
+	router.POST("/users", middleware.AuthMiddleware(authUseCase),
+		middleware.RequireRole(userUseCase, user.RoleAdmin), userController.CreateUser)
+	router.PUT("/users/:id", middleware.AuthMiddleware(authUseCase), userController.UpdateUser)
+	router.PUT("/admin/users/:id/role", middleware.AuthMiddleware(authUseCase),
+		middleware.RequireRole(userUseCase, user.RoleAdmin), userController.UpdateRole)
+
+	sendMethod := func(method, path, session, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: session})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	send := func(path, session, body string) int {
+		return sendMethod(http.MethodPut, path, session, body)
+	}
+
+	userSession := sessionFor("user-a")
+
+	// Creating a user sets its role, so only admins may do it
+	const newUser = `{"username":"b","wallet_address":"0x0000000000000000000000000000000000000002","role":"admin"}`
+	if code := sendMethod(http.MethodPost, "/users", userSession, newUser); code != http.StatusForbidden {
+		t.Errorf("non-admin user creation = %d, want 403", code)
+	}
+	if len(users) != 2 {
+		t.Fatalf("users = %d, want no user created", len(users))
+	}
+	adminSession := sessionFor("admin-1")
+	if code := sendMethod(http.MethodPost, "/users", adminSession, `{"username":"b","wallet_address":"0x0000000000000000000000000000000000000002","role":"owner"}`); code != http.StatusBadRequest {
+		t.Errorf("user creation with an unknown role = %d, want 400", code)
+	}
+	if code := sendMethod(http.MethodPost, "/users", adminSession, newUser); code != http.StatusCreated {
+		t.Errorf("admin user creation = %d, want 201", code)
+	}
+
+	// The self-update body has no role or wallet address field
+	if code := send("/users/user-a", userSession, `{"username":"a","role":"admin"}`); code != http.StatusBadRequest {
+		t.Errorf("self-update with a role = %d, want 400", code)
+	}
+	if code := send("/users/user-a", userSession, `{"username":"a","wallet_address":"0x0000000000000000000000000000000000000002"}`); code != http.StatusBadRequest {
+		t.Errorf("self-update with a wallet address = %d, want 400", code)
+	}
+	if users["user-a"].WalletAddress != "" {
+		t.Errorf("wallet address = %q, want it unchanged", users["user-a"].WalletAddress)
+	}
+	if code := send("/admin/users/user-a/role", userSession, `{"role":"admin"}`); code != http.StatusForbidden {
+		t.Errorf("non-admin role change = %d, want 403", code)
+	}
+	if users["user-a"].Role != user.RoleCustomer {
+		t.Fatalf("role = %s, want it unchanged", users["user-a"].Role)
+	}
+
+	if code := send("/admin/users/user-a/role", adminSession, `{"role":"seller"}`); code != http.StatusOK {
+		t.Fatalf("admin role change = %d, want 200", code)
+	}
+	if users["user-a"].Role != user.RoleSeller {
+		t.Errorf("role = %s, want seller", users["user-a"].Role)
+	}
+	// The session issued with the old role no longer works
+	if code := send("/users/user-a", userSession, `{"username":"a"}`); code != http.StatusUnauthorized {
+		t.Errorf("request with the old session = %d, want 401", code)
+	}
+}
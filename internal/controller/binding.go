@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Shape limits for guarded request bodies
+const (
+	// maxJSONDepth is deeper than any request struct nests
+	maxJSONDepth = 8
+	// maxProductImages caps the images array on product create and update
+	maxProductImages = 20
+	// maxJSONBodyBytes caps how much of a request body is read before decoding
+	maxJSONBodyBytes = 1 << 20
+)
+
+// bindOptions controls how a request body is decoded
+type bindOptions struct {
+	// Strict rejects fields the target struct does not declare
+	Strict bool
+	// MaxDepth and MaxArrayLength, when positive, are enforced on the raw
+	// JSON before it is decoded so oversized input never reaches the decoder
+	MaxDepth       int
+	MaxArrayLength int
+}
+
+// bindStrictJSON decodes the request body like ShouldBindJSON but rejects
+// fields the target struct does not declare, so client typos surface as a
+// 400 instead of silently zeroed values. Use it on endpoints that opt in.
+func bindStrictJSON(ctx *gin.Context, obj interface{}) error {
+	return bindJSON(ctx, obj, bindOptions{Strict: true})
+}
+
+// bindJSON decodes and validates the request body according to opts
+func bindJSON(ctx *gin.Context, obj interface{}, opts bindOptions) error {
+	if ctx.Request.Body == nil {
+		return errors.New("request body is required")
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxJSONBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("request body exceeds the maximum of %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	// Leave the body readable for any later handler or middleware
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := checkJSONShape(body, opts.MaxDepth, opts.MaxArrayLength); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if opts.Strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(obj); err != nil {
+		// Turn `json: unknown field "x"` into a client-facing message
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s", field)
+		}
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// checkJSONShape walks the JSON tokens without building values and rejects
+// bodies nested deeper than maxDepth or holding an array longer than
+// maxArrayLength. A non-positive limit is not enforced.
+func checkJSONShape(body []byte, maxDepth, maxArrayLength int) error {
+	if maxDepth <= 0 && maxArrayLength <= 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// counts holds, per open container, the element count for arrays or -1 for objects
+	var counts []int
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Malformed input is reported by the real decode
+			return nil
+		}
+
+		// A value directly inside an array counts toward its length
+		if n := len(counts); n > 0 && counts[n-1] >= 0 && tok != json.Delim(']') {
+			counts[n-1]++
+			if maxArrayLength > 0 && counts[n-1] > maxArrayLength {
+				return fmt.Errorf("array exceeds the maximum of %d elements", maxArrayLength)
+			}
+		}
+
+		switch tok {
+		case json.Delim('['), json.Delim('{'):
+			if maxDepth > 0 && len(counts) >= maxDepth {
+				return fmt.Errorf("JSON nesting exceeds the maximum depth of %d", maxDepth)
+			}
+			if tok == json.Delim('[') {
+				counts = append(counts, 0)
+			} else {
+				counts = append(counts, -1)
+			}
+		case json.Delim(']'), json.Delim('}'):
+			counts = counts[:len(counts)-1]
+		}
+	}
+}
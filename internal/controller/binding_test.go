@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindStrictJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"Known fields accepted", `{"product_id": "p1", "quantity": 2}`, ""},
+		{"Unknown field rejected", `{"product_id": "p1", "quantiy": 2}`, `unknown field "quantiy"`},
+		{"Validation still applies", `{"product_id": "p1"}`, "Quantity"},
+		{"Malformed JSON rejected", `{"product_id": `, "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/cart/items", strings.NewReader(tt.body))
+
+			var req AddItemRequest
+			err := bindStrictJSON(ctx, &req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("bindStrictJSON() error = %v", err)
+				}
+				if req.Quantity != 2 {
+					t.Errorf("quantity = %d, want 2", req.Quantity)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("bindStrictJSON() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCartAddItem_StrictModeRejectsUnknownField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/cart/items", NewCartController(nil).AddItem)
+
+	rec := httptest.NewRecorder()
+	body := `{"product_id": "p1", "quantiy": 2, "price": 9.99}`
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/cart/items", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), `unknown field \"quantiy\"`) {
+		t.Errorf("body = %s, want it to name the unexpected field", rec.Body.String())
+	}
+}
+
+func TestBindJSON_RejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"product_id": "` + strings.Repeat("a", maxJSONBodyBytes) + `", "quantity": 2}`
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/cart/items", strings.NewReader(body))
+
+	var req AddItemRequest
+	err := bindJSON(ctx, &req, bindOptions{})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum") {
+		t.Errorf("bindJSON() error = %v, want a body size error", err)
+	}
+}
+
+func TestCheckJSONShape(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"Within limits", `{"images": ["a", "b", "c"], "meta": {"tags": [1, 2]}}`, ""},
+		{"Array at the limit", `{"images": ["a", "b", "c", "d"]}`, ""},
+		{"Array over the limit", `{"images": ["a", "b", "c", "d", "e"]}`, "maximum of 4 elements"},
+		{"Nested arrays count separately", `[["a", "b", "c"], ["d"]]`, ""},
+		{"Too deep", `{"a": {"b": {"c": {"d": 1}}}}`, "maximum depth of 3"},
+		{"Malformed left to the decoder", `{"images": [`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkJSONShape([]byte(tt.body), 3, 4)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkJSONShape() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkJSONShape() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateProduct_RejectsOversizedImagesArray(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// No use case is wired: the request must be rejected before reaching it
+	router.POST("/products", NewProductController(nil, nil, nil, nil, nil).CreateProduct)
+
+	images := make([]string, maxProductImages+1)
+	for i := range images {
+		images[i] = `"https://cdn.example.com/img.png"`
+	}
+	body := `{"title": "Coffee", "price": 10, "quantity": 1, "category_id": "c1", "images": [` + strings.Join(images, ",") + `]}`
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "maximum of 20 elements") {
+		t.Errorf("body = %s, want the array limit named", rec.Body.String())
+	}
+}
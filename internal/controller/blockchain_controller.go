@@ -1,10 +1,15 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,14 +31,19 @@ type VerifyTransactionRequest struct {
 
 // VerifyTransactionResponse represents the response for transaction verification
 type VerifyTransactionResponse struct {
-	Status    string `json:"status"`
-	TxHash    string `json:"txHash"`
-	Message   string `json:"message"`
-	From      string `json:"from,omitempty"`
-	To        string `json:"to,omitempty"`
-	Value     string `json:"value,omitempty"`
-	ChainID   int64  `json:"chainId,omitempty"`
-	IsPending bool   `json:"isPending,omitempty"`
+	Status    string  `json:"status"`
+	TxHash    string  `json:"txHash"`
+	Message   string  `json:"message"`
+	From      string  `json:"from,omitempty"`
+	To        string  `json:"to,omitempty"`
+	Value     string  `json:"value,omitempty"`
+	Amount    float64 `json:"amount,omitempty"`
+	ChainID   int64   `json:"chainId,omitempty"`
+	IsPending bool    `json:"isPending,omitempty"`
+
+	Confirmations         *uint64 `json:"confirmations,omitempty"`
+	RequiredConfirmations uint64  `json:"requiredConfirmations,omitempty"`
+	ExplorerURL           string  `json:"explorerUrl,omitempty"`
 }
 
 // VerifyTransaction handles POST /v1/wallet/verify-transaction
@@ -47,14 +57,18 @@ func (c *BlockchainController) VerifyTransaction(ctx *gin.Context) {
 	// Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 	if userID == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		middleware.AbortUnauthenticated(ctx, "User not authenticated")
 		return
 	}
 
 	// Verify and log the transaction
 	tx, err := c.blockchainUseCase.VerifyAndLogTransaction(userID, req.TxHash, req.ChainID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
+		status := http.StatusBadRequest
+		if errors.Is(err, wallet.ErrDepositClaimed) {
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{
 			"status":  "failed",
 			"txHash":  req.TxHash,
 			"error":   err.Error(),
@@ -66,9 +80,10 @@ func (c *BlockchainController) VerifyTransaction(ctx *gin.Context) {
 	response := VerifyTransactionResponse{
 		Status:  "verified",
 		TxHash:  req.TxHash,
-		Message: "Transaction successfully verified",
+		Message: "Deposit verified and credited",
 		From:    tx.From,
 		To:      tx.To,
+		Amount:  tx.Amount,
 		ChainID: tx.ChainID,
 	}
 
@@ -110,10 +125,16 @@ func (c *BlockchainController) GetTransactionStatus(ctx *gin.Context) {
 		Value:     verification.Value,
 		ChainID:   verification.ChainID,
 		IsPending: verification.IsPending,
+
+		Confirmations:         &verification.Confirmations,
+		RequiredConfirmations: verification.RequiredConfirmations,
+	}
+	if chain, ok := blockchain.LookupChain(verification.ChainID); ok {
+		response.ExplorerURL = chain.TxURL(verification.TxHash)
 	}
 
 	if verification.IsPending {
-		response.Message = "Transaction is pending"
+		response.Message = fmt.Sprintf("Transaction is pending (%d of %d confirmations)", verification.Confirmations, verification.RequiredConfirmations)
 	} else if !verification.Verified {
 		response.Status = "failed"
 		response.Message = "Transaction failed on-chain"
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
@@ -17,14 +20,11 @@ func NewCartController(cartUseCase *usecase.CartUseCase) *CartController {
 	return &CartController{cartUseCase: cartUseCase}
 }
 
-// GetCart handles GET /cart
+// GetCart handles GET /cart, creating an empty cart for users without one
 func (c *CartController) GetCart(ctx *gin.Context) {
-	// TODO: Get user ID from authenticated user context
-	userID := ctx.GetString("user_id")
-
-	cart, err := c.cartUseCase.GetCartByUserID(userID)
+	cart, err := c.cartUseCase.GetOrCreateActiveCart(ctx.GetString("user_id"))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "cart not found"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -40,77 +40,166 @@ func (c *CartController) GetCart(ctx *gin.Context) {
 	})
 }
 
-// AddItemRequest represents the request body for adding an item to cart
+// AddItemRequest represents the request body for adding an item to cart.
+// The item is priced by the product, so a client-sent price is rejected as
+// an unknown field.
 type AddItemRequest struct {
-	ProductID string  `json:"product_id" binding:"required"`
-	Quantity  int     `json:"quantity" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
 // AddItem handles POST /cart/items
 func (c *CartController) AddItem(ctx *gin.Context) {
 	var req AddItemRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := bindStrictJSON(ctx, &req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// TODO: Get cart ID from user context
-	cartID := ctx.GetString("cart_id")
-
-	item, err := c.cartUseCase.AddItemToCart(cartID, req.ProductID, req.Quantity, req.Price)
+	userCart, err := c.cartUseCase.GetOrCreateActiveCart(ctx.GetString("user_id"))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	item, err := c.cartUseCase.AddItemToCart(userCart.ID, req.ProductID, req.Quantity)
+	if err != nil {
+		writeCartItemError(ctx, err)
+		return
+	}
+
 	ctx.JSON(http.StatusCreated, item)
 }
 
+// writeCartItemError maps an error from changing a cart item to a response
+func writeCartItemError(ctx *gin.Context, err error) {
+	if errors.Is(err, cart.ErrItemNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	var unavailable *cart.ProductUnavailableError
+	if errors.As(err, &unavailable) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":      err.Error(),
+			"product_id": unavailable.ProductID,
+		})
+		return
+	}
+	var insufficient *product.InsufficientStockError
+	if errors.As(err, &insufficient) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":      err.Error(),
+			"product_id": insufficient.ProductID,
+			"available":  insufficient.Available,
+		})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
 // UpdateItemRequest represents the request body for updating a cart item
 type UpdateItemRequest struct {
-	Quantity int `json:"quantity" binding:"required"`
+	Quantity int `json:"quantity" binding:"required,min=1"`
 }
 
-// UpdateItem handles PUT /cart/items/:id
+// UpdateItem handles PUT /cart/items/:id, setting the quantity of an item in
+// the user's cart. Items in other users' carts are reported as not found.
 func (c *CartController) UpdateItem(ctx *gin.Context) {
-	itemID := ctx.Param("id")
-
 	var req UpdateItemRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := bindStrictJSON(ctx, &req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// TODO: Get and validate cart item
-	// For now, create a stub item
-	item := &struct {
-		ID        string
-		CartID    string
-		ProductID string
-		Quantity  int
-		Price     float64
-	}{
-		ID:       itemID,
-		Quantity: req.Quantity,
+	item, err := c.cartUseCase.UpdateItemQuantity(ctx.GetString("user_id"), ctx.Param("id"), req.Quantity)
+	if err != nil {
+		writeCartItemError(ctx, err)
+		return
 	}
 
-	// This is a simplified version - in production, you'd fetch the item first
 	ctx.JSON(http.StatusOK, item)
 }
 
-// RemoveItem handles DELETE /cart/items/:id
+// RemoveItem handles DELETE /cart/items/:id. Items in other users' carts
+// are reported as not found and left in place.
 func (c *CartController) RemoveItem(ctx *gin.Context) {
-	itemID := ctx.Param("id")
+	err := c.cartUseCase.RemoveCartItem(ctx.GetString("user_id"), ctx.Param("id"))
+	if errors.Is(err, cart.ErrItemNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	// TODO: Get cart ID from context
-	cartID := ctx.GetString("cart_id")
+	ctx.Status(http.StatusNoContent)
+}
 
-	err := c.cartUseCase.RemoveCartItem(cartID, itemID)
-	if err != nil {
+// ClearCart handles DELETE /cart/items, removing every item from the user's cart
+func (c *CartController) ClearCart(ctx *gin.Context) {
+	if err := c.cartUseCase.ClearCart(ctx.GetString("user_id")); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// maxMergeItems caps the guest cart items accepted by one merge
+const maxMergeItems = 50
+
+// MergeCartItem is one guest cart line in a merge request
+type MergeCartItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// MergeCartRequest represents the request body for merging a guest cart
+type MergeCartRequest struct {
+	Items []MergeCartItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// MergeCart handles POST /cart/merge, adding the items of a cart built before
+// sign-in to the user's active cart. Items that could not be added are listed
+// under "rejected" while the rest are merged.
+func (c *CartController) MergeCart(ctx *gin.Context) {
+	var req MergeCartRequest
+	if err := bindJSON(ctx, &req, bindOptions{Strict: true, MaxDepth: maxJSONDepth, MaxArrayLength: maxMergeItems}); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]usecase.MergeItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, usecase.MergeItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	userCart, rejections, err := c.cartUseCase.MergeItems(ctx.GetString("user_id"), items)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cartItems, err := c.cartUseCase.GetCartItems(userCart.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rejected := make([]gin.H, 0, len(rejections))
+	for _, r := range rejections {
+		entry := gin.H{"product_id": r.ProductID, "error": r.Err.Error()}
+		var insufficient *product.InsufficientStockError
+		if errors.As(r.Err, &insufficient) {
+			entry["available"] = insufficient.Available
+		}
+		rejected = append(rejected, entry)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"cart":     userCart,
+		"items":    cartItems,
+		"rejected": rejected,
+	})
+}
@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// stubCartRepo is a map-backed cart.Repository
+type stubCartRepo struct {
+	carts map[string]*cart.Cart
+	items map[string]*cart.CartItem
+}
+
+func newStubCartRepo(carts ...*cart.Cart) *stubCartRepo {
+	r := &stubCartRepo{carts: make(map[string]*cart.Cart), items: make(map[string]*cart.CartItem)}
+	for _, c := range carts {
+		r.carts[c.ID] = c
+	}
+	return r
+}
+
+func (r *stubCartRepo) GetByUserID(userID string) (*cart.Cart, error) {
+	for _, c := range r.carts {
+		if c.UserID == userID && c.Status == cart.CartStatusActive {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, cart.ErrNotFound
+}
+
+func (r *stubCartRepo) CreateCart(c *cart.Cart) error {
+	if _, err := r.GetByUserID(c.UserID); err == nil {
+		return cart.ErrActiveCartExists
+	}
+	cp := *c
+	r.carts[c.ID] = &cp
+	return nil
+}
+
+func (r *stubCartRepo) GetItems(cartID string) ([]*cart.CartItem, error) {
+	var items []*cart.CartItem
+	for _, item := range r.items {
+		if item.CartID == cartID {
+			cp := *item
+			items = append(items, &cp)
+		}
+	}
+	return items, nil
+}
+
+func (r *stubCartRepo) GetItemByID(itemID string) (*cart.CartItem, error) {
+	item, ok := r.items[itemID]
+	if !ok {
+		return nil, cart.ErrItemNotFound
+	}
+	cp := *item
+	return &cp, nil
+}
+
+func (r *stubCartRepo) AddItem(item *cart.CartItem) error {
+	for _, existing := range r.items {
+		if existing.CartID == item.CartID && existing.ProductID == item.ProductID {
+			existing.Quantity += item.Quantity
+			return nil
+		}
+	}
+	cp := *item
+	r.items[item.ID] = &cp
+	return nil
+}
+
+func (r *stubCartRepo) UpdateItem(item *cart.CartItem) error {
+	cp := *item
+	r.items[item.ID] = &cp
+	return nil
+}
+
+func (r *stubCartRepo) RemoveItem(itemID string) error {
+	delete(r.items, itemID)
+	return nil
+}
+
+func (r *stubCartRepo) ClearItems(cartID string) error {
+	for id, item := range r.items {
+		if item.CartID == cartID {
+			delete(r.items, id)
+		}
+	}
+	return nil
+}
+
+func (r *stubCartRepo) RecalculateTotal(cartID string) (float64, error) {
+	total := 0.0
+	for _, item := range r.items {
+		if item.CartID == cartID {
+			total += item.Price * float64(item.Quantity)
+		}
+	}
+	r.carts[cartID].Total = total
+	return total, nil
+}
+
+func (r *stubCartRepo) SetStatus(cartID string, status cart.CartStatus) error {
+	r.carts[cartID].Status = status
+	return nil
+}
+
+// stubReservationRepo holds reservations without enforcing stock
+type stubReservationRepo struct{}
+
+func (stubReservationRepo) ReservedQuantities(productIDs []string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (stubReservationRepo) Reserve(r *product.Reservation) error { return nil }
+
+func (stubReservationRepo) Release(productID, cartID string) error { return nil }
+
+func (stubReservationRepo) Unreserve(productID, cartID string, quantity int) error { return nil }
+
+// newCartTestController serves carts from cartRepo with two products for sale
+func newCartTestController(cartRepo *stubCartRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	productRepo := &stubProductRepo{products: map[string]*product.Product{
+		"product-1": {ID: "product-1", Price: 10, Quantity: 5, IsActive: true},
+		"product-2": {ID: "product-2", Price: 4, Quantity: 5, IsActive: true},
+	}}
+	c := NewCartController(usecase.NewCartUseCase(cartRepo, productRepo, stubReservationRepo{}))
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", ctx.GetHeader("X-Test-User"))
+	})
+	router.GET("/cart", c.GetCart)
+	router.POST("/cart/items", c.AddItem)
+	router.PUT("/cart/items/:id", c.UpdateItem)
+	router.DELETE("/cart/items", c.ClearCart)
+	router.DELETE("/cart/items/:id", c.RemoveItem)
+	return router
+}
+
+func sendCartRequest(router *gin.Engine, method, target, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", userID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAddItem_CreatesCartOnFirstAdd(t *testing.T) {
+	cartRepo := newStubCartRepo()
+	router := newCartTestController(cartRepo)
+
+	w := sendCartRequest(router, http.MethodPost, "/cart/items", "user-1", `{"product_id":"product-1","quantity":1}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first add status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	var first cart.CartItem
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+	if len(cartRepo.carts) != 1 {
+		t.Fatalf("carts = %d, want 1 created on the first add", len(cartRepo.carts))
+	}
+	userCart, _ := cartRepo.GetByUserID("user-1")
+	if first.CartID != userCart.ID {
+		t.Errorf("item cart_id = %q, want the user's cart %q", first.CartID, userCart.ID)
+	}
+
+	w = sendCartRequest(router, http.MethodPost, "/cart/items", "user-1", `{"product_id":"product-2","quantity":2}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("second add status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	if len(cartRepo.carts) != 1 {
+		t.Errorf("carts = %d, want the first cart reused", len(cartRepo.carts))
+	}
+
+	w = sendCartRequest(router, http.MethodGet, "/cart", "user-1", "")
+	var resp struct {
+		Cart  cart.Cart        `json:"cart"`
+		Items []*cart.CartItem `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cart.ID != userCart.ID || len(resp.Items) != 2 || resp.Cart.Total != 18 {
+		t.Errorf("GET /cart = cart %s with %d items totalling %v, want %s with 2 items totalling 18",
+			resp.Cart.ID, len(resp.Items), resp.Cart.Total, userCart.ID)
+	}
+}
+
+func TestAddItem_UsesEachUsersOwnCart(t *testing.T) {
+	cartRepo := newStubCartRepo(&cart.Cart{ID: "cart-a", UserID: "user-a", Status: cart.CartStatusActive})
+	router := newCartTestController(cartRepo)
+
+	for _, userID := range []string{"user-a", "user-b"} {
+		w := sendCartRequest(router, http.MethodPost, "/cart/items", userID, `{"product_id":"product-1","quantity":1}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s add status = %d, want %d: %s", userID, w.Code, http.StatusCreated, w.Body)
+		}
+	}
+
+	for _, userID := range []string{"user-a", "user-b"} {
+		userCart, err := cartRepo.GetByUserID(userID)
+		if err != nil {
+			t.Fatalf("%s has no cart: %v", userID, err)
+		}
+		items, _ := cartRepo.GetItems(userCart.ID)
+		if len(items) != 1 {
+			t.Errorf("%s cart items = %d, want 1", userID, len(items))
+		}
+	}
+}
+
+func TestGetCart_EmptyForNewUser(t *testing.T) {
+	router := newCartTestController(newStubCartRepo())
+
+	w := sendCartRequest(router, http.MethodGet, "/cart", "user-1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestUpdateItem(t *testing.T) {
+	cartRepo := newStubCartRepo(
+		&cart.Cart{ID: "cart-a", UserID: "user-a", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "cart-b", UserID: "user-b", Status: cart.CartStatusActive},
+	)
+	cartRepo.items["item-a"] = &cart.CartItem{ID: "item-a", CartID: "cart-a", ProductID: "product-1", Quantity: 1, Price: 10}
+	router := newCartTestController(cartRepo)
+
+	tests := []struct {
+		name         string
+		userID       string
+		body         string
+		wantStatus   int
+		wantQuantity int
+	}{
+		{"Owner updates quantity", "user-a", `{"quantity":3}`, http.StatusOK, 3},
+		{"Other user cannot update", "user-b", `{"quantity":5}`, http.StatusNotFound, 3},
+		{"User without a cart cannot update", "user-c", `{"quantity":5}`, http.StatusNotFound, 3},
+		{"Over stock", "user-a", `{"quantity":6}`, http.StatusConflict, 3},
+		{"Zero quantity", "user-a", `{"quantity":0}`, http.StatusBadRequest, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendCartRequest(router, http.MethodPut, "/cart/items/item-a", tt.userID, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := cartRepo.items["item-a"].Quantity; got != tt.wantQuantity {
+				t.Errorf("stored quantity = %d, want %d", got, tt.wantQuantity)
+			}
+		})
+	}
+
+	if got := cartRepo.carts["cart-a"].Total; got != 30 {
+		t.Errorf("cart total = %v, want 30", got)
+	}
+}
+
+func TestRemoveItem(t *testing.T) {
+	cartRepo := newStubCartRepo(
+		&cart.Cart{ID: "cart-a", UserID: "user-a", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "cart-b", UserID: "user-b", Status: cart.CartStatusActive},
+	)
+	cartRepo.items["item-a"] = &cart.CartItem{ID: "item-a", CartID: "cart-a", ProductID: "product-1", Quantity: 1, Price: 10}
+	router := newCartTestController(cartRepo)
+
+	for _, userID := range []string{"user-b", "user-c"} {
+		w := sendCartRequest(router, http.MethodDelete, "/cart/items/item-a", userID, "")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s remove status = %d, want %d", userID, w.Code, http.StatusNotFound)
+		}
+		if _, ok := cartRepo.items["item-a"]; !ok {
+			t.Fatalf("%s removed another user's item", userID)
+		}
+	}
+
+	w := sendCartRequest(router, http.MethodDelete, "/cart/items/item-a", "user-a", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("owner remove status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if _, ok := cartRepo.items["item-a"]; ok {
+		t.Error("owner's item was not removed")
+	}
+}
+
+func TestClearCart(t *testing.T) {
+	cartRepo := newStubCartRepo(
+		&cart.Cart{ID: "cart-a", UserID: "user-a", Status: cart.CartStatusActive, Total: 18},
+		&cart.Cart{ID: "cart-b", UserID: "user-b", Status: cart.CartStatusActive, Total: 4},
+	)
+	cartRepo.items["item-1"] = &cart.CartItem{ID: "item-1", CartID: "cart-a", ProductID: "product-1", Quantity: 1, Price: 10}
+	cartRepo.items["item-2"] = &cart.CartItem{ID: "item-2", CartID: "cart-a", ProductID: "product-2", Quantity: 2, Price: 4}
+	cartRepo.items["item-3"] = &cart.CartItem{ID: "item-3", CartID: "cart-b", ProductID: "product-2", Quantity: 1, Price: 4}
+	router := newCartTestController(cartRepo)
+
+	w := sendCartRequest(router, http.MethodDelete, "/cart/items", "user-a", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusNoContent, w.Body)
+	}
+	if items, _ := cartRepo.GetItems("cart-a"); len(items) != 0 {
+		t.Errorf("cart items = %d, want 0", len(items))
+	}
+	if got := cartRepo.carts["cart-a"].Total; got != 0 {
+		t.Errorf("cart total = %v, want 0", got)
+	}
+	if _, ok := cartRepo.items["item-3"]; !ok {
+		t.Error("another user's item was removed")
+	}
+}
+
+func TestAddItem_PricedByProduct(t *testing.T) {
+	cartRepo := newStubCartRepo()
+	router := newCartTestController(cartRepo)
+
+	w := sendCartRequest(router, http.MethodPost, "/cart/items", "user-1", `{"product_id":"product-1","quantity":2,"price":0}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("client price status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if len(cartRepo.items) != 0 {
+		t.Fatal("item added despite a client-sent price")
+	}
+
+	w = sendCartRequest(router, http.MethodPost, "/cart/items", "user-1", `{"product_id":"product-1","quantity":2}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	var item cart.CartItem
+	if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Price != 10 {
+		t.Errorf("item price = %v, want the product price 10", item.Price)
+	}
+	userCart, _ := cartRepo.GetByUserID("user-1")
+	if userCart.Total != 20 {
+		t.Errorf("cart total = %v, want 20", userCart.Total)
+	}
+}
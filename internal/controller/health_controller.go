@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// readinessTimeout bounds how long each dependency check may take
+const readinessTimeout = 2 * time.Second
+
+// ErrDependencyDisabled is returned by a Pinger for a dependency that is not
+// configured. It is reported as "disabled" and does not fail readiness.
+var ErrDependencyDisabled = errors.New("dependency disabled")
+
+// Pinger checks that a dependency is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc adapts a function to a Pinger
+type PingerFunc func(ctx context.Context) error
+
+// Ping calls f
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// dependency is a readiness check. An optional dependency is reported but
+// does not fail readiness.
+type dependency struct {
+	pinger   Pinger
+	optional bool
+}
+
+// HealthController serves the liveness and readiness probes
+type HealthController struct {
+	dependencies map[string]dependency
+}
+
+// NewHealthController creates a health controller that checks the database
+// and Redis for readiness. The blockchain RPC is reported too, but since only
+// on-chain features depend on it, an outage does not fail readiness.
+func NewHealthController(db, redis, blockchain Pinger) *HealthController {
+	return &HealthController{dependencies: map[string]dependency{
+		"database":   {pinger: db},
+		"redis":      {pinger: redis},
+		"blockchain": {pinger: blockchain, optional: true},
+	}}
+}
+
+// Healthz handles GET /healthz. It only reports that the process is up.
+func (c *HealthController) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz handles GET /readyz, pinging every dependency in parallel. It
+// returns 503 with the status of each dependency when a required one is
+// unreachable; the errors themselves are only logged.
+func (c *HealthController) Readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	checks := make(map[string]string, len(c.dependencies))
+	ready := true
+	for name, dep := range c.dependencies {
+		wg.Add(1)
+		go func(name string, dep dependency) {
+			defer wg.Done()
+			status := "ok"
+			if err := dep.pinger.Ping(checkCtx); errors.Is(err, ErrDependencyDisabled) {
+				status = "disabled"
+			} else if err != nil {
+				log.Warn().Err(err).Str("dependency", name).Msg("readiness check failed")
+				status = "unavailable"
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			checks[name] = status
+			if status == "unavailable" && !dep.optional {
+				ready = false
+			}
+		}(name, dep)
+	}
+	wg.Wait()
+
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubPinger struct {
+	err error
+}
+
+func (p stubPinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+func TestHealthController_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name                  string
+		db, redis, blockchain error
+		wantStatus            int
+		wantChecks            map[string]string
+	}{
+		{
+			name:       "Healthy",
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"database": "ok", "redis": "ok", "blockchain": "ok"},
+		},
+		{
+			name:       "Database down",
+			db:         down,
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"database": "unavailable", "redis": "ok"},
+		},
+		{
+			name:       "Redis down",
+			redis:      down,
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]string{"database": "ok", "redis": "unavailable"},
+		},
+		{
+			name:       "Blockchain RPC unreachable",
+			blockchain: down,
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"database": "ok", "redis": "ok", "blockchain": "unavailable"},
+		},
+		{
+			name:       "Blockchain RPC disabled",
+			blockchain: ErrDependencyDisabled,
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]string{"database": "ok", "redis": "ok", "blockchain": "disabled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHealthController(stubPinger{tt.db}, stubPinger{tt.redis}, stubPinger{tt.blockchain})
+			router := gin.New()
+			router.GET("/readyz", c.Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var body struct {
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			for name, want := range tt.wantChecks {
+				if body.Checks[name] != want {
+					t.Errorf("checks[%s] = %q, want %q", name, body.Checks[name], want)
+				}
+			}
+		})
+	}
+}
@@ -1,34 +1,55 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
 // OrderController handles HTTP requests for orders
 type OrderController struct {
-	orderUseCase *usecase.OrderUseCase
+	orderUseCase    *usecase.OrderUseCase
+	checkoutUseCase *usecase.CheckoutUseCase
+	userUseCase     *usecase.UserUseCase
 }
 
 // NewOrderController creates a new order controller
-func NewOrderController(orderUseCase *usecase.OrderUseCase) *OrderController {
-	return &OrderController{orderUseCase: orderUseCase}
+func NewOrderController(orderUseCase *usecase.OrderUseCase, checkoutUseCase *usecase.CheckoutUseCase, userUseCase *usecase.UserUseCase) *OrderController {
+	return &OrderController{
+		orderUseCase:    orderUseCase,
+		checkoutUseCase: checkoutUseCase,
+		userUseCase:     userUseCase,
+	}
 }
 
+// IdempotencyKeyHeader lets clients retry order creation without creating duplicates
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the client-chosen key stored in Redis
+const maxIdempotencyKeyLength = 255
+
 // CreateOrderRequest represents the request body for creating an order
+// The total and payment reference are set server-side.
 type CreateOrderRequest struct {
-	CartID     string  `json:"cart_id" binding:"required"`
-	PaymentRef string  `json:"payment_ref"`
-	Total      float64 `json:"total" binding:"required"`
+	CartID string `json:"cart_id" binding:"required"`
 }
 
-// CreateOrder handles POST /orders
+// CreateOrder handles POST /orders. With an Idempotency-Key header, a retry
+// returns the order created by the first request with 200 instead of 201.
 func (c *OrderController) CreateOrder(ctx *gin.Context) {
 	var req CreateOrderRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := bindStrictJSON(ctx, &req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -36,67 +57,225 @@ func (c *OrderController) CreateOrder(ctx *gin.Context) {
 	// TODO: Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 
-	order, err := c.orderUseCase.CreateOrder(userID, req.CartID, req.Total, req.PaymentRef)
+	key := ctx.GetHeader(IdempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)})
+		return
+	}
+
+	o, replayed, err := c.orderUseCase.CreateOrderIdempotent(userID, key, req.CartID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var rateErr *order.RateLimitError
+		if errors.As(err, &rateErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": order.ErrRateLimited.Error()})
+			return
+		}
+		if errors.Is(err, order.ErrIdempotencyInProgress) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, order.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		respondCheckoutError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, order)
+	if replayed {
+		ctx.JSON(http.StatusOK, o)
+		return
+	}
+	ctx.JSON(http.StatusCreated, o)
+}
+
+// CheckoutRequest represents the request body for checking out a cart
+type CheckoutRequest struct {
+	CartID string `json:"cart_id" binding:"required"`
 }
 
-// GetOrder handles GET /orders/:id
+// Checkout handles POST /orders/checkout, converting the cart into an order
+func (c *OrderController) Checkout(ctx *gin.Context) {
+	var req CheckoutRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetString("user_id")
+
+	o, items, err := c.checkoutUseCase.Checkout(userID, req.CartID)
+	if err != nil {
+		respondCheckoutError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"order": o,
+		"items": items,
+	})
+}
+
+// respondCheckoutError writes the response for an error from checking out a
+// cart
+func respondCheckoutError(ctx *gin.Context, err error) {
+	var stockErr *product.InsufficientStockError
+	var invalidErr *order.InvalidProductsError
+	switch {
+	case errors.As(err, &stockErr):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":      err.Error(),
+			"product_id": stockErr.ProductID,
+			"available":  stockErr.Available,
+		})
+	case errors.As(err, &invalidErr):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":       order.ErrInvalidProducts.Error(),
+			"product_ids": invalidErr.ProductIDs,
+		})
+	case errors.Is(err, checkout.ErrCartNotFound), errors.Is(err, product.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, checkout.ErrCartNotActive):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, cart.ErrEmptyCart):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetOrder handles GET /orders/:id for the order's buyer, its sellers and admins
 func (c *OrderController) GetOrder(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	order, err := c.orderUseCase.GetOrderByID(id)
+	o, err := c.orderUseCase.GetOrderByID(id)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 		return
 	}
 
-	items, err := c.orderUseCase.GetOrderItems(id)
+	u, err := c.userUseCase.GetUserByID(ctx.GetString("user_id"))
+	if err != nil {
+		middleware.AbortForbidden(ctx, order.ErrNotParticipant.Error())
+		return
+	}
+	allowed, err := c.orderUseCase.CanAccessOrder(o, u.ID, u.Role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
+		middleware.AbortForbidden(ctx, order.ErrNotParticipant.Error())
+		return
+	}
+
+	items, err := c.orderUseCase.GetOrderItemsWithProduct(id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"order": order,
+		"order": o,
 		"items": items,
 	})
 }
 
 // ListOrders handles GET /orders
 func (c *OrderController) ListOrders(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
-	
-	// Ensure page and pageSize are within valid ranges
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 20
-	}
-	if pageSize > 100 {
-		pageSize = 100
-	}
+	params := pagination.FromQuery(ctx)
 
 	// TODO: Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 
-	orders, total, err := c.orderUseCase.GetOrdersByUserID(userID, page, pageSize)
+	orders, total, err := c.orderUseCase.GetOrdersByUserID(userID, params.Page, params.PageSize)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	meta := params.Meta(total)
 	ctx.JSON(http.StatusOK, gin.H{
 		"orders":      orders,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": (total + pageSize - 1) / pageSize,
+		"total":       meta.Total,
+		"page":        meta.Page,
+		"page_size":   meta.PageSize,
+		"total_pages": meta.TotalPages,
+	})
+}
+
+// UpdateOrderStatusRequest represents the request body for changing an order's status
+type UpdateOrderStatusRequest struct {
+	Status order.OrderStatus `json:"status" binding:"required"`
+}
+
+// UpdateStatus handles PATCH /orders/:id/status
+func (c *OrderController) UpdateStatus(ctx *gin.Context) {
+	var req UpdateOrderStatusRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !order.ValidStatus(req.Status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown order status"})
+		return
+	}
+
+	id := ctx.Param("id")
+	if _, err := c.orderUseCase.GetOrderByID(id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	u, err := c.userUseCase.GetUserByID(ctx.GetString("user_id"))
+	if err != nil {
+		middleware.AbortForbidden(ctx, order.ErrNotParticipant.Error())
+		return
+	}
+
+	o, err := c.orderUseCase.TransitionOrderStatus(id, u.ID, u.Role, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, order.ErrNotParticipant):
+			middleware.AbortForbidden(ctx, err.Error())
+		case errors.Is(err, order.ErrInvalidTransition):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, order.ErrStatusConflict):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, o)
+}
+
+// ShipItems handles POST /orders/:id/ship, marking the calling seller's items shipped
+func (c *OrderController) ShipItems(ctx *gin.Context) {
+	id := ctx.Param("id")
+	sellerID := ctx.GetString("user_id")
+
+	if _, err := c.orderUseCase.GetOrderByID(id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+
+	o, items, err := c.orderUseCase.ShipSellerItems(id, sellerID)
+	if err != nil {
+		if errors.Is(err, order.ErrNoSellerItems) || errors.Is(err, order.ErrOrderClosed) ||
+			errors.Is(err, order.ErrOrderNotPaid) || errors.Is(err, order.ErrStatusConflict) ||
+			errors.Is(err, order.ErrInvalidTransition) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"order": o,
+		"items": items,
 	})
 }
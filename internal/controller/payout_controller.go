@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// PayoutController handles HTTP requests for seller earnings and payouts
+type PayoutController struct {
+	payoutUseCase *usecase.PayoutUseCase
+}
+
+// NewPayoutController creates a new payout controller
+func NewPayoutController(payoutUseCase *usecase.PayoutUseCase) *PayoutController {
+	return &PayoutController{
+		payoutUseCase: payoutUseCase,
+	}
+}
+
+// GetMyEarnings handles GET /sellers/me/earnings
+func (c *PayoutController) GetMyEarnings(ctx *gin.Context) {
+	sellerID := ctx.GetString("user_id")
+
+	earnings, err := c.payoutUseCase.GetSellerEarnings(sellerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, earnings)
+}
+
+// MarkPaid handles POST /admin/payouts/:id/mark-paid. The route requires
+// the admin role.
+func (c *PayoutController) MarkPaid(ctx *gin.Context) {
+	p, err := c.payoutUseCase.MarkPaid(ctx.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, payout.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "payout not found"})
+		case errors.Is(err, payout.ErrAlreadyPaid):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, p)
+}
@@ -1,28 +1,60 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/bulk"
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
 	"github.com/Tenoywil/CaribEx-backend/pkg/storage"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
 // ProductController handles HTTP requests for products
 type ProductController struct {
 	productUseCase *usecase.ProductUseCase
+	userUseCase    *usecase.UserUseCase
 	storageService storage.Service
+	// s3Storage stores private documents, reissues URLs for private images
+	// and batches image cleanup; nil when S3 is not configured, in which case
+	// images are deleted through storageService one file at a time
+	s3Storage storage.Uploader
+	// viewUseCase counts product detail views; nil disables view tracking
+	viewUseCase *usecase.ProductViewUseCase
 }
 
 // NewProductController creates a new product controller
-func NewProductController(productUseCase *usecase.ProductUseCase, storageService storage.Service) *ProductController {
+func NewProductController(productUseCase *usecase.ProductUseCase, userUseCase *usecase.UserUseCase, storageService storage.Service, s3Storage storage.Uploader, viewUseCase *usecase.ProductViewUseCase) *ProductController {
 	return &ProductController{
 		productUseCase: productUseCase,
+		userUseCase:    userUseCase,
 		storageService: storageService,
+		s3Storage:      s3Storage,
+		viewUseCase:    viewUseCase,
 	}
 }
 
+// imageURLExpiryMinutes is how long a reissued product image URL stays valid
+const imageURLExpiryMinutes = 60
+
+// documentURLExpiryMinutes is how long the URL returned for an uploaded
+// private document stays valid
+const documentURLExpiryMinutes = 15
+
+// maxDocumentUploads caps the files accepted by one document upload
+const maxDocumentUploads = 10
+
 // CreateProductRequest represents the request body for creating a product
 type CreateProductRequest struct {
 	Title       string   `json:"title" binding:"required"`
@@ -33,10 +65,52 @@ type CreateProductRequest struct {
 	CategoryID  string   `json:"category_id"`
 }
 
+// UpdateProductRequest represents the request body for updating a product.
+// Only the listed fields are editable; omitted ones keep their current value.
+// Activation, deletion and the seller are never taken from the body.
+type UpdateProductRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Price       *float64  `json:"price"`
+	Quantity    *int      `json:"quantity"`
+	Images      *[]string `json:"images"`
+	CategoryID  *string   `json:"category_id"`
+}
+
+// apply copies the fields present in the request onto p
+func (r *UpdateProductRequest) apply(p *product.Product) {
+	if r.Title != nil {
+		p.Title = *r.Title
+	}
+	if r.Description != nil {
+		p.Description = *r.Description
+	}
+	if r.Price != nil {
+		p.Price = *r.Price
+	}
+	if r.Quantity != nil {
+		p.Quantity = *r.Quantity
+	}
+	if r.Images != nil {
+		p.Images = *r.Images
+	}
+	if r.CategoryID != nil {
+		p.CategoryID = *r.CategoryID
+	}
+}
+
+// productBindOptions rejects unknown fields and oversized images arrays on
+// product create and update
+var productBindOptions = bindOptions{
+	Strict:         true,
+	MaxDepth:       maxJSONDepth,
+	MaxArrayLength: maxProductImages,
+}
+
 // CreateProduct handles POST /products
 func (c *ProductController) CreateProduct(ctx *gin.Context) {
 	var req CreateProductRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(ctx, &req, productBindOptions); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -45,6 +119,10 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 	sellerID := ctx.GetString("user_id")
 
 	p, err := c.productUseCase.CreateProduct(sellerID, req.Title, req.Description, req.Price, req.Quantity, req.Images, req.CategoryID)
+	if errors.Is(err, product.ErrNoValidImages) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,35 +131,54 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, p)
 }
 
-// GetProduct handles GET /products/:id
+// GetProduct handles GET /products/:id, where id may be a UUID or a slug
 func (c *ProductController) GetProduct(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	p, err := c.productUseCase.GetProductByIDWithCategory(id)
+	p, err := c.productUseCase.GetProductByIDOrSlugWithCategory(id)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
 		return
 	}
 
+	if c.viewUseCase != nil {
+		c.viewUseCase.RecordView(p.ID, viewer(ctx))
+	}
+
 	ctx.JSON(http.StatusOK, p)
 }
 
-// ListProducts handles GET /products
-func (c *ProductController) ListProducts(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
-	
-	// Ensure page and pageSize are within valid ranges
-	if page < 1 {
-		page = 1
+// viewer identifies who is viewing a product for view debouncing: the
+// signed-in user when the session was validated, otherwise the client IP.
+// Unvalidated cookies are ignored so a client cannot count again by sending
+// a new session ID with each request.
+func viewer(ctx *gin.Context) string {
+	if userID := ctx.GetString("user_id"); userID != "" {
+		return "user:" + userID
 	}
-	if pageSize < 1 {
-		pageSize = 20
+	return "ip:" + ctx.ClientIP()
+}
+
+// GetMyStats handles GET /sellers/me/stats
+func (c *ProductController) GetMyStats(ctx *gin.Context) {
+	if c.viewUseCase == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "product stats are not available"})
+		return
 	}
-	if pageSize > 100 {
-		pageSize = 100
+
+	stats, err := c.viewUseCase.GetSellerStats(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// ListProducts handles GET /products
+func (c *ProductController) ListProducts(ctx *gin.Context) {
+	params := pagination.FromQuery(ctx)
+
 	filters := make(map[string]interface{})
 	if categoryID := ctx.Query("category_id"); categoryID != "" {
 		filters["category_id"] = categoryID
@@ -89,26 +186,142 @@ func (c *ProductController) ListProducts(ctx *gin.Context) {
 	if search := ctx.Query("search"); search != "" {
 		filters["search"] = search
 	}
+	if sellerID := ctx.Query("seller_id"); sellerID != "" {
+		filters["seller_id"] = sellerID
+	}
+	if err := priceRangeFromQuery(ctx, filters); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if inStock := ctx.Query("in_stock"); inStock != "" {
+		v, err := strconv.ParseBool(inStock)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "in_stock must be true or false"})
+			return
+		}
+		if v {
+			filters["in_stock"] = true
+		}
+	}
+	created, err := filter.DateRangeFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !created.IsZero() {
+		filters["created"] = created
+	}
 	
 	// Get sort parameters
-	sortBy := ctx.DefaultQuery("sort_by", "created_at")
+	// Searches rank by relevance unless the client picks another order
+	defaultSort := product.DefaultSortField
+	if _, ok := filters["search"]; ok {
+		defaultSort = product.SortByRelevance
+	}
+	sortBy := ctx.DefaultQuery("sort_by", defaultSort)
 	sortOrder := ctx.DefaultQuery("sort_order", "desc")
 
-	products, total, err := c.productUseCase.ListProductsWithCategory(filters, page, pageSize, sortBy, sortOrder)
+	// A cursor parameter, even an empty one for the first page, switches to
+	// keyset pagination; page is ignored in that mode
+	if cursor, ok := ctx.GetQuery("cursor"); ok {
+		products, next, err := c.productUseCase.ListProductsWithCategoryCursor(filters, cursor, params.PageSize, sortBy, sortOrder)
+		if errors.Is(err, product.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"products":    products,
+			"page_size":   params.PageSize,
+			"next_cursor": next,
+		})
+		return
+	}
+
+	products, total, err := c.productUseCase.ListProductsWithCategory(filters, params.Page, params.PageSize, sortBy, sortOrder)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	meta := params.Meta(total)
+	ctx.JSON(http.StatusOK, gin.H{
+		"products":    products,
+		"total":       meta.Total,
+		"page":        meta.Page,
+		"page_size":   meta.PageSize,
+		"total_pages": meta.TotalPages,
+	})
+}
+
+// priceRangeFromQuery adds the min_price and max_price query parameters to
+// filters, rejecting negative prices and an inverted range
+func priceRangeFromQuery(ctx *gin.Context, filters map[string]interface{}) error {
+	var minPrice, maxPrice float64
+	for _, bound := range []struct {
+		param string
+		dest  *float64
+	}{{"min_price", &minPrice}, {"max_price", &maxPrice}} {
+		v := ctx.Query(bound.param)
+		if v == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("%s must be a non-negative number", bound.param)
+		}
+		*bound.dest = price
+		filters[bound.param] = price
+	}
+
+	_, hasMin := filters["min_price"]
+	_, hasMax := filters["max_price"]
+	if hasMin && hasMax && minPrice > maxPrice {
+		return errors.New("min_price must not exceed max_price")
+	}
+	return nil
+}
+
+// ListMyProducts handles GET /products/mine, listing the caller's own
+// products including inactive ones
+func (c *ProductController) ListMyProducts(ctx *gin.Context) {
+	params := pagination.FromQuery(ctx)
+
+	products, total, err := c.productUseCase.ListSellerProducts(ctx.GetString("user_id"), params.Page, params.PageSize)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	meta := params.Meta(total)
 	ctx.JSON(http.StatusOK, gin.H{
 		"products":    products,
-		"total":       total,
-		"page":        page,
-		"page_size":   pageSize,
-		"total_pages": (total + pageSize - 1) / pageSize,
+		"total":       meta.Total,
+		"page":        meta.Page,
+		"page_size":   meta.PageSize,
+		"total_pages": meta.TotalPages,
 	})
 }
 
+// actor returns the authenticated user's ID and role, taking the role from
+// the session when it is there. The role is empty when the user cannot be
+// loaded, which grants no admin rights.
+func (c *ProductController) actor(ctx *gin.Context) (string, user.Role) {
+	userID := ctx.GetString("user_id")
+	if role, ok := middleware.ContextRole(ctx); ok {
+		return userID, role
+	}
+	u, err := c.userUseCase.GetUserByID(userID)
+	if err != nil {
+		return userID, ""
+	}
+	return userID, u.Role
+}
+
 // UpdateProduct handles PUT /products/:id
 func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 	id := ctx.Param("id")
@@ -120,14 +333,23 @@ func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 		return
 	}
 
-	// Bind updates
-	if err := ctx.ShouldBindJSON(&p); err != nil {
+	var req UpdateProductRequest
+	if err := bindJSON(ctx, &req, productBindOptions); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.apply(p)
 
-	p.ID = id
-	err = c.productUseCase.UpdateProduct(p)
+	actorID, actorRole := c.actor(ctx)
+	err = c.productUseCase.UpdateProduct(actorID, actorRole, p)
+	if errors.Is(err, product.ErrNoValidImages) || errors.Is(err, product.ErrInvalidPrice) || errors.Is(err, product.ErrInvalidQuantity) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, product.ErrNotOwner) {
+		middleware.AbortForbidden(ctx, err.Error())
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -136,19 +358,222 @@ func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, p)
 }
 
-// DeleteProduct handles DELETE /products/:id
+// DeleteProduct handles DELETE /products/:id by soft-deleting the product.
+// Its images stay in storage since order receipts still show them, but their
+// thumbnails and kept originals, which receipts never use, are removed. Only
+// a hard delete removes the images themselves. Either way only images the
+// seller uploaded are touched.
 func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 	id := ctx.Param("id")
+	actorID, actorRole := c.actor(ctx)
+	err := c.productUseCase.DeactivateProduct(actorID, actorRole, id)
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		if errors.Is(err, product.ErrNotOwner) {
+			middleware.AbortForbidden(ctx, err.Error())
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if p, err := c.productUseCase.GetProductByID(id); err == nil {
+		if images, variants, ok := c.sellerImages(p); ok {
+			c.deleteProductImages(ctx.Request.Context(), id, product.VariantURLs(nil, variants), images)
+		}
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// HardDeleteProduct handles DELETE /admin/products/:id, removing the product
+// row and its stored images permanently. The route requires the admin role.
+func (c *ProductController) HardDeleteProduct(ctx *gin.Context) {
+	id := ctx.Param("id")
 
-	err := c.productUseCase.DeleteProduct(id)
+	p, err := c.productUseCase.GetProductByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		return
+	}
+
+	err = c.productUseCase.DeleteProduct(id)
+	if errors.Is(err, product.ErrInUse) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "product is referenced by orders; deactivate it instead"})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if images, variants, ok := c.sellerImages(p); ok {
+		c.deleteProductImages(ctx.Request.Context(), id, product.VariantURLs(images, variants), images)
+	}
+
 	ctx.Status(http.StatusNoContent)
 }
 
+// sellerImages returns the deleted product's images its seller uploaded, and
+// their copies. A lookup failure is logged and deletes nothing, since the
+// product is already gone and other sellers' files must never be removed.
+func (c *ProductController) sellerImages(p *product.Product) ([]string, []*product.ImageVariants, bool) {
+	images, variants, err := c.productUseCase.SellerImages(p)
+	if err != nil {
+		log.Warn().Err(err).Str("product_id", p.ID).Msg("failed to look up product image uploads")
+		return nil, nil, false
+	}
+	return images, variants, true
+}
+
+// deleteProductImages removes the given files of a deleted product from
+// storage, then forgets the copies recorded for its images. Failures are
+// logged rather than returned since the product is already gone.
+func (c *ProductController) deleteProductImages(ctx context.Context, productID string, files, images []string) {
+	c.deleteFiles(ctx, productID, files)
+	if err := c.productUseCase.DeleteImageVariants(images); err != nil {
+		log.Warn().Err(err).Str("product_id", productID).Msg("failed to delete product image variants")
+	}
+}
+
+// deleteFiles removes files from whichever storage holds product images
+func (c *ProductController) deleteFiles(ctx context.Context, productID string, images []string) {
+	if len(images) == 0 {
+		return
+	}
+
+	if c.s3Storage != nil {
+		keys := make([]string, 0, len(images))
+		for _, image := range images {
+			if key, ok := c.s3Storage.KeyFromURL(image); ok {
+				keys = append(keys, key)
+			}
+		}
+		if err := c.s3Storage.DeleteFiles(keys); err != nil {
+			log.Warn().Err(err).Str("product_id", productID).Msg("failed to delete product images")
+		}
+		return
+	}
+
+	for _, image := range images {
+		if err := c.storageService.DeleteFile(ctx, image); err != nil {
+			log.Warn().Err(err).Str("product_id", productID).Str("image", image).Msg("failed to delete product image")
+		}
+	}
+}
+
+// maxBulkPriceProducts caps how many product IDs a bulk price update may name
+const maxBulkPriceProducts = 100
+
+// UpdateQuantityRequest represents the request body for setting a product's stock
+type UpdateQuantityRequest struct {
+	Quantity *int   `json:"quantity" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+// UpdateProductQuantity handles PATCH /products/:id/quantity, recording the
+// change in the product's inventory history
+func (c *ProductController) UpdateProductQuantity(ctx *gin.Context) {
+	var req UpdateQuantityRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID, actorRole := c.actor(ctx)
+	p, err := c.productUseCase.UpdateProductQuantity(actorID, actorRole, ctx.Param("id"), *req.Quantity, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrInvalidQuantity), errors.Is(err, product.ErrReasonTooLong):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, product.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		case errors.Is(err, product.ErrNotOwner):
+			middleware.AbortForbidden(ctx, err.Error())
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, p)
+}
+
+// ListInventoryAdjustments handles GET /products/:id/adjustments, returning
+// the product's stock history to its seller or an admin
+func (c *ProductController) ListInventoryAdjustments(ctx *gin.Context) {
+	params := pagination.FromQuery(ctx)
+
+	actorID, actorRole := c.actor(ctx)
+	adjustments, total, err := c.productUseCase.ListInventoryAdjustments(actorID, actorRole, ctx.Param("id"), params.Page, params.PageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		case errors.Is(err, product.ErrNotOwner):
+			middleware.AbortForbidden(ctx, err.Error())
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	meta := params.Meta(total)
+	ctx.JSON(http.StatusOK, gin.H{
+		"adjustments": adjustments,
+		"total":       meta.Total,
+		"page":        meta.Page,
+		"page_size":   meta.PageSize,
+		"total_pages": meta.TotalPages,
+	})
+}
+
+// BulkPriceUpdateRequest represents the request body for a bulk price update
+type BulkPriceUpdateRequest struct {
+	ProductIDs []string `json:"product_ids"`
+	CategoryID string   `json:"category_id"`
+	Operation  string   `json:"operation" binding:"required"`
+	Value      float64  `json:"value" binding:"required"`
+}
+
+// BulkUpdatePrices handles POST /products/bulk-price
+func (c *ProductController) BulkUpdatePrices(ctx *gin.Context) {
+	var req BulkPriceUpdateRequest
+	err := bindJSON(ctx, &req, bindOptions{MaxDepth: maxJSONDepth, MaxArrayLength: maxBulkPriceProducts})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.ProductIDs) > maxBulkPriceProducts {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "too many product_ids (max 100)"})
+		return
+	}
+
+	sellerID := ctx.GetString("user_id")
+
+	results, err := c.productUseCase.BulkUpdatePrices(sellerID, req.ProductIDs, req.CategoryID, product.PriceOperation(req.Operation), req.Value)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrInvalidPriceSelector),
+			errors.Is(err, product.ErrInvalidPriceOperation),
+			errors.Is(err, product.ErrInvalidPriceAdjustment),
+			errors.Is(err, product.ErrInvalidPrice):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, product.ErrPriceChanged):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	bulk.Respond(ctx, results)
+}
+
 // GetCategories handles GET /categories
 func (c *ProductController) GetCategories(ctx *gin.Context) {
 	categories, err := c.productUseCase.GetCategories()
@@ -164,6 +589,22 @@ func (c *ProductController) GetCategories(ctx *gin.Context) {
 type UploadImageResponse struct {
 	URL      string `json:"url"`
 	Filename string `json:"filename"`
+	// Conversion details, present when the upload was re-encoded as WebP
+	ConvertedURL string `json:"converted_url,omitempty"`
+	OriginalURL  string `json:"original_url,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	OriginalSize int64  `json:"original_size,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	SavedBytes   int64  `json:"saved_bytes,omitempty"`
+	// Thumbnails are resized copies of the image, smallest first
+	Thumbnails []ImageThumbnail `json:"thumbnails,omitempty"`
+}
+
+// ImageThumbnail is a resized copy of an uploaded image
+type ImageThumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
 }
 
 // UploadImage handles POST /products/upload-image for standalone image uploads
@@ -176,17 +617,187 @@ func (c *ProductController) UploadImage(ctx *gin.Context) {
 	}
 	defer file.Close()
 
-	// Upload to storage
-	url, err := c.storageService.UploadFile(ctx.Request.Context(), file, header, "products")
+	resp, err := c.storeImage(ctx.Request.Context(), ctx.GetString("user_id"), file, header)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, UploadImageResponse{
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// storeImage uploads one product image for the seller, reporting any WebP
+// conversion and thumbnails when the storage service can
+func (c *ProductController) storeImage(ctx context.Context, sellerID string, file multipart.File, header *multipart.FileHeader) (UploadImageResponse, error) {
+	if uploader, ok := c.storageService.(storage.ImageUploader); ok {
+		result, err := uploader.UploadImage(ctx, file, header, "products")
+		if err != nil {
+			return UploadImageResponse{}, err
+		}
+		if err := c.productUseCase.RecordImageUploads(sellerID, []string{result.URL}); err != nil {
+			return UploadImageResponse{}, err
+		}
+
+		resp := UploadImageResponse{
+			URL:      result.URL,
+			Filename: header.Filename,
+		}
+		if result.Converted {
+			resp.ConvertedURL = result.URL
+			resp.OriginalURL = result.OriginalURL
+			resp.ContentType = result.ContentType
+			resp.OriginalSize = result.OriginalSize
+			resp.Size = result.Size
+			resp.SavedBytes = result.SavedBytes()
+		}
+		variants := &product.ImageVariants{URL: result.URL, OriginalURL: result.OriginalURL}
+		for _, thumb := range result.Thumbnails {
+			resp.Thumbnails = append(resp.Thumbnails, ImageThumbnail{Width: thumb.Width, Height: thumb.Height, URL: thumb.URL})
+			variants.Thumbnails = append(variants.Thumbnails, product.Thumbnail{Width: thumb.Width, Height: thumb.Height, URL: thumb.URL})
+		}
+		// Record the extra copies so products list them and deleting a
+		// product removes them too
+		if variants.OriginalURL != "" || len(variants.Thumbnails) > 0 {
+			if err := c.productUseCase.SaveImageVariants(variants); err != nil {
+				return UploadImageResponse{}, err
+			}
+		}
+		return resp, nil
+	}
+
+	url, err := c.storageService.UploadFile(ctx, file, header, "products")
+	if err != nil {
+		return UploadImageResponse{}, err
+	}
+	if err := c.productUseCase.RecordImageUploads(sellerID, []string{url}); err != nil {
+		return UploadImageResponse{}, err
+	}
+	return UploadImageResponse{
 		URL:      url,
 		Filename: header.Filename,
-	})
+	}, nil
+}
+
+// UploadImageResult is the outcome of one file in a batch image upload.
+// Error is set, and URL empty, when that file failed.
+type UploadImageResult struct {
+	UploadImageResponse
+	Error string `json:"error,omitempty"`
+}
+
+// UploadImages handles POST /products/upload-images, storing every file sent
+// under images[] and reporting each one's outcome. One bad file does not fail
+// the rest of the batch.
+func (c *ProductController) UploadImages(ctx *gin.Context) {
+	if err := ctx.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB max
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form data"})
+		return
+	}
+	files := ctx.Request.MultipartForm.File["images[]"]
+	if len(files) == 0 {
+		files = ctx.Request.MultipartForm.File["images"]
+	}
+	if len(files) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "at least one image is required"})
+		return
+	}
+	if len(files) > maxProductImages {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d images can be uploaded at once", maxProductImages)})
+		return
+	}
+
+	results := make([]UploadImageResult, 0, len(files))
+	for _, fileHeader := range files {
+		results = append(results, c.storeImageFile(ctx.Request.Context(), ctx.GetString("user_id"), fileHeader))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"images": results})
+}
+
+// storeImageFile opens and uploads one file of a batch, folding any failure
+// into the result
+func (c *ProductController) storeImageFile(ctx context.Context, sellerID string, fileHeader *multipart.FileHeader) UploadImageResult {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return UploadImageResult{UploadImageResponse: UploadImageResponse{Filename: fileHeader.Filename}, Error: "failed to open uploaded file"}
+	}
+	defer file.Close()
+
+	resp, err := c.storeImage(ctx, sellerID, file, fileHeader)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", fileHeader.Filename).Msg("failed to upload image in batch")
+		return UploadImageResult{UploadImageResponse: UploadImageResponse{Filename: fileHeader.Filename}, Error: err.Error()}
+	}
+	return UploadImageResult{UploadImageResponse: resp}
+}
+
+// UploadedDocument describes a privately stored document
+type UploadedDocument struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// UploadDocumentsResponse lists uploaded documents with presigned URLs that
+// expire at ExpiresAt
+type UploadDocumentsResponse struct {
+	Documents []UploadedDocument `json:"documents"`
+	ExpiresAt time.Time          `json:"expires_at"`
+}
+
+// UploadDocuments handles POST /products/documents, storing files from the
+// "documents" form field privately under the seller and returning presigned
+// URLs to read them
+func (c *ProductController) UploadDocuments(ctx *gin.Context) {
+	if c.s3Storage == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "private uploads are not available"})
+		return
+	}
+
+	if err := ctx.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB max
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form data"})
+		return
+	}
+	files := ctx.Request.MultipartForm.File["documents"]
+	if len(files) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "at least one document is required"})
+		return
+	}
+	if len(files) > maxDocumentUploads {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d documents can be uploaded at once", maxDocumentUploads)})
+		return
+	}
+
+	results, err := c.s3Storage.UploadFiles(files, "documents/"+ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The stored location is private, so hand out presigned URLs instead,
+	// downloading under the name the file was uploaded with
+	resp := UploadDocumentsResponse{
+		Documents: make([]UploadedDocument, 0, len(results)),
+		ExpiresAt: time.Now().UTC().Add(documentURLExpiryMinutes * time.Minute),
+	}
+	for i, result := range results {
+		url, err := c.s3Storage.GeneratePresignedDownloadURL(result.Key, files[i].Filename, documentURLExpiryMinutes)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate document URL"})
+			return
+		}
+		resp.Documents = append(resp.Documents, UploadedDocument{
+			Key:         result.Key,
+			URL:         url,
+			Filename:    files[i].Filename,
+			Size:        result.Size,
+			ContentType: result.ContentType,
+		})
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
 }
 
 // CreateProductMultipart handles POST /products with multipart/form-data
@@ -248,9 +859,17 @@ func (c *ProductController) CreateProductMultipart(ctx *gin.Context) {
 
 		imageURLs = append(imageURLs, url)
 	}
+	if err := c.productUseCase.RecordImageUploads(sellerID, imageURLs); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Create product
 	p, err := c.productUseCase.CreateProduct(sellerID, title, description, price, quantity, imageURLs, categoryID)
+	if errors.Is(err, product.ErrNoValidImages) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -258,3 +877,36 @@ func (c *ProductController) CreateProductMultipart(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusCreated, p)
 }
+
+// GetImageURL handles GET /products/:id/images/:key/url, reissuing a
+// presigned URL for one of the product's images, identified by its file name
+func (c *ProductController) GetImageURL(ctx *gin.Context) {
+	if c.s3Storage == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "image URLs are not available"})
+		return
+	}
+
+	key, err := c.productUseCase.ProductImageKey(ctx.Param("id"), ctx.GetString("user_id"), ctx.Param("key"), c.s3Storage.KeyFromURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		case errors.Is(err, product.ErrImageNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	url, err := c.s3Storage.GeneratePresignedURL(key, imageURLExpiryMinutes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate image URL"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_at": time.Now().UTC().Add(imageURLExpiryMinutes * time.Minute),
+	})
+}
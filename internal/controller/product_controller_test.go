@@ -0,0 +1,708 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/storage"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPriceRangeFromQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"No bounds", "", map[string]interface{}{}, false},
+		{"Range", "min_price=10&max_price=50.5", map[string]interface{}{"min_price": 10.0, "max_price": 50.5}, false},
+		{"Equal bounds", "min_price=10&max_price=10", map[string]interface{}{"min_price": 10.0, "max_price": 10.0}, false},
+		{"Max only", "max_price=0", map[string]interface{}{"max_price": 0.0}, false},
+		{"Inverted range", "min_price=50&max_price=10", nil, true},
+		{"Negative", "min_price=-1", nil, true},
+		{"Not a number", "max_price=cheap", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/products?"+tt.query, nil)
+
+			filters := map[string]interface{}{}
+			err := priceRangeFromQuery(ctx, filters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("priceRangeFromQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(filters) != len(tt.want) {
+				t.Fatalf("filters = %v, want %v", filters, tt.want)
+			}
+			for k, v := range tt.want {
+				if filters[k] != v {
+					t.Errorf("filters[%s] = %v, want %v", k, filters[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestListProducts_RejectsInvertedPriceRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := NewProductController(nil, nil, nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/products?min_price=100&max_price=5&in_stock=true", nil)
+	c.ListProducts(ctx)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// fakeUploader is an in-memory storage.Uploader. Presigned URLs embed the
+// key so tests can check which object they point at.
+type fakeUploader struct {
+	objects   map[string][]byte
+	prefixes  []string
+	presigned []string
+}
+
+func newFakeUploader() *fakeUploader {
+	return &fakeUploader{objects: make(map[string][]byte)}
+}
+
+func (f *fakeUploader) UploadFile(fileHeader *multipart.FileHeader, prefix string) (storage.UploadFileResult, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return storage.UploadFileResult{}, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return storage.UploadFileResult{}, err
+	}
+
+	key := prefix + "/" + fileHeader.Filename
+	f.objects[key] = data
+	return storage.UploadFileResult{
+		Key:         key,
+		URL:         "https://s3.example/products/" + key,
+		Size:        int64(len(data)),
+		ContentType: http.DetectContentType(data),
+	}, nil
+}
+
+func (f *fakeUploader) UploadFiles(files []*multipart.FileHeader, prefix string) ([]storage.UploadFileResult, error) {
+	f.prefixes = append(f.prefixes, prefix)
+	var results []storage.UploadFileResult
+	for _, file := range files {
+		result, err := f.UploadFile(file, prefix)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (f *fakeUploader) GeneratePresignedURL(key string, expirationMinutes int) (string, error) {
+	f.presigned = append(f.presigned, key)
+	return "https://s3.example/products/" + key + "?X-Amz-Signature=sig", nil
+}
+
+func (f *fakeUploader) GeneratePresignedDownloadURL(key, filename string, expirationMinutes int) (string, error) {
+	f.presigned = append(f.presigned, key)
+	return "https://s3.example/products/" + key + "?X-Amz-Signature=sig&response-content-disposition=" +
+		url.QueryEscape(storage.ContentDisposition(filename)), nil
+}
+
+func (f *fakeUploader) DeleteFile(key string) error {
+	delete(f.objects, key)
+	return nil
+}
+
+func (f *fakeUploader) DeleteFiles(keys []string) error {
+	for _, key := range keys {
+		delete(f.objects, key)
+	}
+	return nil
+}
+
+func (f *fakeUploader) KeyFromURL(rawURL string) (string, bool) {
+	return strings.CutPrefix(rawURL, "https://s3.example/products/")
+}
+
+// stubProductRepo serves products by ID. The embedded interface leaves the
+// methods these tests don't reach unimplemented.
+type stubProductRepo struct {
+	product.Repository
+	products map[string]*product.Product
+	variants map[string]*product.ImageVariants
+	// uploads maps each recorded image URL to the seller who uploaded it
+	uploads map[string]string
+	// deleteErr, when set, fails Delete
+	deleteErr error
+}
+
+func (r *stubProductRepo) RecordImageUploads(sellerID string, urls []string) error {
+	if r.uploads == nil {
+		r.uploads = make(map[string]string)
+	}
+	for _, url := range urls {
+		if _, ok := r.uploads[url]; !ok {
+			r.uploads[url] = sellerID
+		}
+	}
+	return nil
+}
+
+func (r *stubProductRepo) UploadedImages(sellerID string, urls []string) ([]string, error) {
+	var uploaded []string
+	for _, url := range urls {
+		if r.uploads[url] == sellerID {
+			uploaded = append(uploaded, url)
+		}
+	}
+	return uploaded, nil
+}
+
+func (r *stubProductRepo) SaveImageVariants(v *product.ImageVariants) error {
+	if r.variants == nil {
+		r.variants = make(map[string]*product.ImageVariants)
+	}
+	r.variants[v.URL] = v
+	return nil
+}
+
+func (r *stubProductRepo) DeleteImageVariants(urls []string) error {
+	for _, url := range urls {
+		delete(r.variants, url)
+	}
+	return nil
+}
+
+func (r *stubProductRepo) GetByID(id string) (*product.Product, error) {
+	p, ok := r.products[id]
+	if !ok {
+		return nil, product.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *stubProductRepo) GetByIDs(ids []string) ([]*product.Product, error) {
+	var products []*product.Product
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
+
+func (r *stubProductRepo) Delete(id string) error {
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.products, id)
+	return nil
+}
+
+func (r *stubProductRepo) SoftDelete(id string) error {
+	r.products[id].IsActive = false
+	return nil
+}
+
+// recordingStorage is a storage.Service that records deleted paths. Uploads
+// of file names and deletes of paths listed in failing fail.
+type recordingStorage struct {
+	deleted []string
+	failing map[string]bool
+}
+
+func (s *recordingStorage) UploadFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (string, error) {
+	if s.failing[header.Filename] {
+		return "", errors.New("invalid file type: text/plain. Only images are allowed")
+	}
+	return s.GetPublicURL(folder + "/" + header.Filename), nil
+}
+
+func (s *recordingStorage) DeleteFile(ctx context.Context, path string) error {
+	s.deleted = append(s.deleted, path)
+	if s.failing[path] {
+		return errors.New("storage unavailable")
+	}
+	return nil
+}
+
+func (s *recordingStorage) GetPublicURL(path string) string {
+	return "https://project.supabase.co/storage/v1/object/public/product-images/" + path
+}
+
+func documentsRequest(t *testing.T, filenames ...string) *http.Request {
+	t.Helper()
+	return multipartRequest(t, "/products/documents", "documents", filenames...)
+}
+
+// multipartRequest posts a file per name under field
+func multipartRequest(t *testing.T, target, field string, filenames ...string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, name := range filenames {
+		part, err := writer.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte("%PDF-1.4 test"))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newFakeUploader()
+	c := NewProductController(nil, nil, nil, store, nil)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = documentsRequest(t, "invoice.pdf", "certificate.pdf")
+	ctx.Set("user_id", "seller-1")
+	c.UploadDocuments(ctx)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+	if len(store.prefixes) != 1 || store.prefixes[0] != "documents/seller-1" {
+		t.Errorf("upload prefixes = %v, want [documents/seller-1]", store.prefixes)
+	}
+
+	var resp UploadDocumentsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Documents) != 2 {
+		t.Fatalf("documents = %d, want 2", len(resp.Documents))
+	}
+	for _, doc := range resp.Documents {
+		if !strings.Contains(doc.URL, "X-Amz-Signature") {
+			t.Errorf("document %s URL = %s, want a presigned URL", doc.Filename, doc.URL)
+		}
+		if !strings.Contains(doc.URL, url.QueryEscape(`filename="`+doc.Filename+`"`)) {
+			t.Errorf("document %s URL = %s, want it to download under its uploaded name", doc.Filename, doc.URL)
+		}
+		if doc.Key != "documents/seller-1/"+doc.Filename {
+			t.Errorf("document key = %s", doc.Key)
+		}
+		if _, ok := store.objects[doc.Key]; !ok {
+			t.Errorf("document %s was not stored", doc.Key)
+		}
+	}
+	if resp.ExpiresAt.IsZero() {
+		t.Error("expires_at not set")
+	}
+}
+
+func TestUploadDocuments_Rejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := make([]string, maxDocumentUploads+1)
+	for i := range tooMany {
+		tooMany[i] = "doc.pdf"
+	}
+
+	tests := []struct {
+		name       string
+		store      storage.Uploader
+		files      []string
+		wantStatus int
+	}{
+		{"S3 not configured", nil, []string{"invoice.pdf"}, http.StatusServiceUnavailable},
+		{"No documents", newFakeUploader(), nil, http.StatusBadRequest},
+		{"Too many documents", newFakeUploader(), tooMany, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewProductController(nil, nil, nil, tt.store, nil)
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = documentsRequest(t, tt.files...)
+			c.UploadDocuments(ctx)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGetImageURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &stubProductRepo{
+		products: map[string]*product.Product{
+			"product-1": {ID: "product-1", SellerID: "seller-1", IsActive: true, Images: []string{
+				"https://s3.example/products/products/a1b2.jpg",
+				"https://s3.example/products/products/c3d4.jpg",
+				"https://s3.example/products/documents/seller-2/id.pdf",
+			}},
+		},
+		uploads: map[string]string{
+			"https://s3.example/products/products/a1b2.jpg": "seller-1",
+			"https://s3.example/products/products/c3d4.jpg": "seller-2",
+		},
+	}
+	store := newFakeUploader()
+	c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, nil, store, nil)
+
+	router := gin.New()
+	router.GET("/products/:id/images/:key/url", c.GetImageURL)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"Known image", "/products/product-1/images/a1b2.jpg/url", http.StatusOK},
+		{"Unknown image", "/products/product-1/images/other.jpg/url", http.StatusNotFound},
+		{"Image another seller uploaded", "/products/product-1/images/c3d4.jpg/url", http.StatusNotFound},
+		{"Document listed as an image", "/products/product-1/images/id.pdf/url", http.StatusNotFound},
+		{"Unknown product", "/products/product-2/images/a1b2.jpg/url", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+	if len(store.presigned) != 1 || store.presigned[0] != "products/a1b2.jpg" {
+		t.Errorf("presigned keys = %v, want [products/a1b2.jpg]", store.presigned)
+	}
+}
+
+func TestDeleteProduct_Images(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	images := []string{
+		"https://project.supabase.co/storage/v1/object/public/product-images/products/a.jpg",
+		"https://project.supabase.co/storage/v1/object/public/product-images/products/b.jpg",
+		"https://project.supabase.co/storage/v1/object/public/product-images/products/c.jpg",
+	}
+	variants := []*product.ImageVariants{{
+		URL:         images[0],
+		OriginalURL: "https://project.supabase.co/storage/v1/object/public/product-images/products/a.png",
+		Thumbnails: []product.Thumbnail{
+			{Width: 200, URL: "https://project.supabase.co/storage/v1/object/public/product-images/products/a_w200.jpg"},
+		},
+	}}
+	// Another seller's image, with copies of its own, listed on the product
+	foreign := "https://project.supabase.co/storage/v1/object/public/product-images/products/theirs.jpg"
+	foreignVariants := &product.ImageVariants{
+		URL:        foreign,
+		Thumbnails: []product.Thumbnail{{Width: 200, URL: "https://project.supabase.co/storage/v1/object/public/product-images/products/theirs_w200.jpg"}},
+	}
+	newRepo := func() *stubProductRepo {
+		return &stubProductRepo{
+			products: map[string]*product.Product{
+				"product-1": {
+					ID: "product-1", SellerID: "seller-1", IsActive: true,
+					Images:        append(slices.Clone(images), foreign),
+					ImageVariants: []*product.ImageVariants{variants[0], foreignVariants},
+				},
+			},
+			variants: map[string]*product.ImageVariants{images[0]: variants[0], foreign: foreignVariants},
+			uploads: map[string]string{
+				images[0]: "seller-1",
+				images[1]: "seller-1",
+				images[2]: "seller-1",
+				foreign:   "seller-2",
+			},
+		}
+	}
+	copies := []string{variants[0].OriginalURL, variants[0].Thumbnails[0].URL}
+	send := func(c *ProductController, handler gin.HandlerFunc) int {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodDelete, "/products/product-1", nil)
+		ctx.Params = gin.Params{{Key: "id", Value: "product-1"}}
+		ctx.Set("user_id", "seller-1")
+		ctx.Set("user_role", user.RoleSeller)
+		handler(ctx)
+		return ctx.Writer.Status()
+	}
+
+	t.Run("Hard delete removes every uploaded image and copy despite failures", func(t *testing.T) {
+		store := &recordingStorage{failing: map[string]bool{images[1]: true}}
+		repo := newRepo()
+		c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, store, nil, nil)
+
+		if code := send(c, c.HardDeleteProduct); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", code, http.StatusNoContent)
+		}
+		if _, ok := repo.products["product-1"]; ok {
+			t.Error("product was not deleted")
+		}
+		want := append(slices.Clone(images), copies...)
+		if strings.Join(store.deleted, ",") != strings.Join(want, ",") {
+			t.Errorf("deleted = %v, want %v", store.deleted, want)
+		}
+		if len(repo.variants) != 1 || repo.variants[foreign] == nil {
+			t.Errorf("variants recorded = %v, want only the other seller's", repo.variants)
+		}
+	})
+
+	t.Run("Hard delete of a referenced product conflicts", func(t *testing.T) {
+		store := &recordingStorage{}
+		repo := newRepo()
+		repo.deleteErr = product.ErrInUse
+		c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, store, nil, nil)
+
+		if code := send(c, c.HardDeleteProduct); code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d", code, http.StatusConflict)
+		}
+		if len(store.deleted) != 0 || len(repo.variants) == 0 {
+			t.Errorf("images of a kept product were deleted: %v", store.deleted)
+		}
+	})
+
+	t.Run("Hard delete batches through S3", func(t *testing.T) {
+		uploader := newFakeUploader()
+		uploader.objects["products/x.jpg"] = []byte("x")
+		uploader.objects["products/other.jpg"] = []byte("y")
+		uploader.objects["documents/seller-2/id.pdf"] = []byte("z")
+		repo := newRepo()
+		repo.products["product-1"].Images = []string{
+			"https://s3.example/products/products/x.jpg",
+			"https://s3.example/products/documents/seller-2/id.pdf",
+		}
+		repo.products["product-1"].ImageVariants = nil
+		repo.uploads["https://s3.example/products/products/x.jpg"] = "seller-1"
+		c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, &recordingStorage{}, uploader, nil)
+
+		if code := send(c, c.HardDeleteProduct); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", code, http.StatusNoContent)
+		}
+		if _, ok := uploader.objects["products/x.jpg"]; ok {
+			t.Error("product image was not deleted from S3")
+		}
+		if _, ok := uploader.objects["products/other.jpg"]; !ok {
+			t.Error("unrelated object was deleted")
+		}
+		if _, ok := uploader.objects["documents/seller-2/id.pdf"]; !ok {
+			t.Error("listed file the seller did not upload was deleted")
+		}
+	})
+
+	t.Run("Soft delete keeps images but removes their copies", func(t *testing.T) {
+		store := &recordingStorage{}
+		repo := newRepo()
+		c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, store, nil, nil)
+
+		if code := send(c, c.DeleteProduct); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", code, http.StatusNoContent)
+		}
+		if strings.Join(store.deleted, ",") != strings.Join(copies, ",") {
+			t.Errorf("deleted = %v, want only the copies %v", store.deleted, copies)
+		}
+		if len(repo.variants) != 1 || repo.variants[foreign] == nil {
+			t.Errorf("variants recorded = %v, want only the other seller's", repo.variants)
+		}
+	})
+}
+
+// stubAdjustmentRepo records the products saved through it
+type stubAdjustmentRepo struct {
+	product.AdjustmentRepository
+	saved []product.Product
+}
+
+func (r *stubAdjustmentRepo) UpdateWithAdjustment(p *product.Product, userID, reason string) error {
+	r.saved = append(r.saved, *p)
+	return nil
+}
+
+func TestUpdateProduct_EditableFieldsOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deletedAt := time.Now().Add(-time.Hour)
+	send := func(body string) (int, *stubAdjustmentRepo) {
+		repo := &stubProductRepo{products: map[string]*product.Product{
+			"product-1": {ID: "product-1", SellerID: "seller-1", Title: "Old", Slug: "old", Price: 20, Quantity: 5, IsActive: false, DeletedAt: &deletedAt},
+		}}
+		adjustments := &stubAdjustmentRepo{}
+		c := NewProductController(usecase.NewProductUseCase(repo, nil, adjustments, usecase.ProductConfig{}), nil, nil, nil, nil)
+
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodPut, "/products/product-1", strings.NewReader(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{{Key: "id", Value: "product-1"}}
+		ctx.Set("user_id", "seller-1")
+		ctx.Set("user_role", user.RoleSeller)
+		c.UpdateProduct(ctx)
+		return rec.Code, adjustments
+	}
+
+	for _, body := range []string{
+		`{"is_active":true}`,
+		`{"deleted_at":null}`,
+		`{"seller_id":"seller-2"}`,
+		`{"price":0}`,
+		`{"price":-5}`,
+		`{"quantity":-1}`,
+	} {
+		if code, adjustments := send(body); code != http.StatusBadRequest || len(adjustments.saved) != 0 {
+			t.Errorf("%s: status = %d with %d saves, want 400 and nothing saved", body, code, len(adjustments.saved))
+		}
+	}
+
+	code, adjustments := send(`{"title":"New","price":25}`)
+	if code != http.StatusOK || len(adjustments.saved) != 1 {
+		t.Fatalf("status = %d with %d saves, want 200 and one save", code, len(adjustments.saved))
+	}
+	saved := adjustments.saved[0]
+	if saved.Title != "New" || saved.Price != 25 || saved.Quantity != 5 {
+		t.Errorf("saved = %+v, want the new title and price with the quantity kept", saved)
+	}
+	if saved.IsActive || saved.DeletedAt == nil || saved.SellerID != "seller-1" {
+		t.Errorf("saved = %+v, want activation, deletion and seller unchanged", saved)
+	}
+}
+
+// thumbnailingStorage is a recordingStorage that converts uploads to WebP,
+// keeping the original, and stores one thumbnail
+type thumbnailingStorage struct {
+	recordingStorage
+}
+
+func (s *thumbnailingStorage) UploadImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (*storage.ImageUploadResult, error) {
+	base := folder + "/" + strings.TrimSuffix(header.Filename, ".jpg")
+	return &storage.ImageUploadResult{
+		URL:         s.GetPublicURL(base + ".webp"),
+		OriginalURL: s.GetPublicURL(base + ".jpg"),
+		Converted:   true,
+		Thumbnails:  []storage.Thumbnail{{Width: 200, Height: 150, URL: s.GetPublicURL(base + "_w200.webp")}},
+	}, nil
+}
+
+func TestUploadImage_RecordsVariants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &thumbnailingStorage{}
+	repo := &stubProductRepo{}
+	c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, store, nil, nil)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = multipartRequest(t, "/products/upload-image", "image", "front.jpg")
+	c.UploadImage(ctx)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	url := store.GetPublicURL("products/front.webp")
+	v, ok := repo.variants[url]
+	if !ok {
+		t.Fatalf("variants recorded for %v, want %s", repo.variants, url)
+	}
+	if v.OriginalURL != store.GetPublicURL("products/front.jpg") ||
+		len(v.Thumbnails) != 1 || v.Thumbnails[0].URL != store.GetPublicURL("products/front_w200.webp") {
+		t.Errorf("recorded variants = %+v", v)
+	}
+}
+
+func TestUploadImages_PartialSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingStorage{failing: map[string]bool{"notes.txt": true}}
+	repo := &stubProductRepo{}
+	c := NewProductController(usecase.NewProductUseCase(repo, nil, nil, usecase.ProductConfig{}), nil, store, nil, nil)
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = multipartRequest(t, "/products/upload-images", "images[]", "front.jpg", "notes.txt", "back.jpg")
+	ctx.Set("user_id", "seller-1")
+	c.UploadImages(ctx)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var resp struct {
+		Images []UploadImageResult `json:"images"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		filename string
+		ok       bool
+	}{{"front.jpg", true}, {"notes.txt", false}, {"back.jpg", true}}
+	if len(resp.Images) != len(want) {
+		t.Fatalf("results = %d, want %d", len(resp.Images), len(want))
+	}
+	for i, w := range want {
+		got := resp.Images[i]
+		if got.Filename != w.filename {
+			t.Errorf("result %d filename = %s, want %s", i, got.Filename, w.filename)
+		}
+		if w.ok && (got.URL == "" || got.Error != "") {
+			t.Errorf("%s: url = %q, error = %q, want an uploaded image", w.filename, got.URL, got.Error)
+		}
+		if !w.ok && (got.URL != "" || got.Error == "") {
+			t.Errorf("%s: url = %q, error = %q, want a per-file error", w.filename, got.URL, got.Error)
+		}
+		if w.ok && repo.uploads[got.URL] != "seller-1" {
+			t.Errorf("%s: upload recorded for %q, want seller-1", w.filename, repo.uploads[got.URL])
+		}
+	}
+}
+
+func TestUploadImages_Rejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := make([]string, maxProductImages+1)
+	for i := range tooMany {
+		tooMany[i] = "image.jpg"
+	}
+
+	for name, files := range map[string][]string{
+		"No images":       nil,
+		"Too many images": tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &recordingStorage{}
+			c := NewProductController(nil, nil, store, nil, nil)
+			rec := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(rec)
+			ctx.Request = multipartRequest(t, "/products/upload-images", "images[]", files...)
+			c.UploadImages(ctx)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
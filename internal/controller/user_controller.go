@@ -1,21 +1,25 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
+	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 // UserController handles HTTP requests for users
 type UserController struct {
 	userUseCase *usecase.UserUseCase
+	authUseCase *usecase.AuthUseCase
 }
 
 // NewUserController creates a new user controller
-func NewUserController(userUseCase *usecase.UserUseCase) *UserController {
-	return &UserController{userUseCase: userUseCase}
+func NewUserController(userUseCase *usecase.UserUseCase, authUseCase *usecase.AuthUseCase) *UserController {
+	return &UserController{userUseCase: userUseCase, authUseCase: authUseCase}
 }
 
 // CreateUserRequest represents the request body for creating a user
@@ -25,16 +29,21 @@ type CreateUserRequest struct {
 	Role          user.Role `json:"role" binding:"required"`
 }
 
-// CreateUser handles POST /users
+// CreateUser handles POST /users. The route is limited to admins, since the
+// request sets the new user's role.
 func (c *UserController) CreateUser(ctx *gin.Context) {
 	var req CreateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := bindStrictJSON(ctx, &req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	u, err := c.userUseCase.CreateUser(req.Username, req.WalletAddress, req.Role)
 	if err != nil {
+		if errors.Is(err, blockchain.ErrInvalidAddress) || errors.Is(err, user.ErrInvalidRole) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -61,6 +70,10 @@ func (c *UserController) GetUserByWallet(ctx *gin.Context) {
 
 	u, err := c.userUseCase.GetUserByWalletAddress(address)
 	if err != nil {
+		if errors.Is(err, blockchain.ErrInvalidAddress) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
@@ -68,19 +81,73 @@ func (c *UserController) GetUserByWallet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, u)
 }
 
+// canModifyUser reports whether the caller may change the user with the given
+// ID: users may change themselves, admins anyone
+func (c *UserController) canModifyUser(ctx *gin.Context, id string) bool {
+	actorID := ctx.GetString("user_id")
+	if actorID == id {
+		return true
+	}
+	actor, err := c.userUseCase.GetUserByID(actorID)
+	return err == nil && actor.Role == user.RoleAdmin
+}
+
+// UpdateUserRequest represents the request body for updating a user's
+// profile. The role is changed through UpdateRole, and the wallet address is
+// the one proven by signing in with it and never changes.
+type UpdateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
 // UpdateUser handles PUT /users/:id
 func (c *UserController) UpdateUser(ctx *gin.Context) {
 	id := ctx.Param("id")
+	if !c.canModifyUser(ctx, id) {
+		middleware.AbortForbidden(ctx, "cannot modify another user")
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := c.userUseCase.UpdateProfile(id, req.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, u)
+}
+
+// UpdateRoleRequest represents the request body for changing a user's role
+type UpdateRoleRequest struct {
+	Role user.Role `json:"role" binding:"required"`
+}
 
-	var u user.User
-	if err := ctx.ShouldBindJSON(&u); err != nil {
+// UpdateRole handles PUT /admin/users/:id/role. The user is signed out of
+// every session so the new role applies from their next sign-in.
+func (c *UserController) UpdateRole(ctx *gin.Context) {
+	var req UpdateRoleRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	u.ID = id
-	err := c.userUseCase.UpdateUser(&u)
+	id := ctx.Param("id")
+	if _, err := c.userUseCase.GetUserByID(id); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	u, err := c.authUseCase.ChangeRole(ctx.Request.Context(), id, req.Role)
 	if err != nil {
+		if errors.Is(err, user.ErrInvalidRole) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -91,6 +158,10 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 // DeleteUser handles DELETE /users/:id
 func (c *UserController) DeleteUser(ctx *gin.Context) {
 	id := ctx.Param("id")
+	if !c.canModifyUser(ctx, id) {
+		middleware.AbortForbidden(ctx, "cannot modify another user")
+		return
+	}
 
 	err := c.userUseCase.DeleteUser(id)
 	if err != nil {
@@ -1,10 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,7 +25,6 @@ func NewWalletController(walletUseCase *usecase.WalletUseCase) *WalletController
 
 // GetWallet handles GET /wallet
 func (c *WalletController) GetWallet(ctx *gin.Context) {
-	// TODO: Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 
 	w, err := c.walletUseCase.GetWalletByUserID(userID)
@@ -32,9 +36,40 @@ func (c *WalletController) GetWallet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, w)
 }
 
+// ConvertBalance handles GET /wallet/convert?to=USD, showing the wallet
+// balance in another currency without changing it
+func (c *WalletController) ConvertBalance(ctx *gin.Context) {
+	to, err := wallet.ParseCurrency(ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetString("user_id")
+	if _, err := c.walletUseCase.GetWalletByUserID(userID); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
+		return
+	}
+
+	conversion, err := c.walletUseCase.ConvertBalance(userID, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, wallet.ErrUnsupportedCurrency):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, wallet.ErrRateUnavailable):
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversion)
+}
+
 // SendFundsRequest represents the request body for sending funds
 type SendFundsRequest struct {
-	Amount    float64 `json:"amount" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
 	Reference string  `json:"reference"`
 }
 
@@ -46,11 +81,16 @@ func (c *WalletController) SendFunds(ctx *gin.Context) {
 		return
 	}
 
-	// TODO: Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 
 	tx, err := c.walletUseCase.SendFunds(userID, req.Amount, req.Reference)
 	if err != nil {
+		// A retry with an already used reference gets the original back
+		var dup *wallet.DuplicateTransactionError
+		if errors.As(err, &dup) {
+			ctx.JSON(http.StatusOK, dup.Existing)
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -60,7 +100,7 @@ func (c *WalletController) SendFunds(ctx *gin.Context) {
 
 // ReceiveFundsRequest represents the request body for receiving funds
 type ReceiveFundsRequest struct {
-	Amount    float64 `json:"amount" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
 	Reference string  `json:"reference"`
 }
 
@@ -72,11 +112,16 @@ func (c *WalletController) ReceiveFunds(ctx *gin.Context) {
 		return
 	}
 
-	// TODO: Get user ID from authenticated user context
 	userID := ctx.GetString("user_id")
 
 	tx, err := c.walletUseCase.ReceiveFunds(userID, req.Amount, req.Reference)
 	if err != nil {
+		// A retry with an already used reference gets the original back
+		var dup *wallet.DuplicateTransactionError
+		if errors.As(err, &dup) {
+			ctx.JSON(http.StatusOK, dup.Existing)
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,36 +129,145 @@ func (c *WalletController) ReceiveFunds(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, tx)
 }
 
-// GetTransactions handles GET /wallet/transactions
-func (c *WalletController) GetTransactions(ctx *gin.Context) {
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
-	
-	// Ensure page and pageSize are within valid ranges
-	if page < 1 {
-		page = 1
+// TransferRequest represents the request body for a wallet-to-wallet
+// transfer
+type TransferRequest struct {
+	ToUserID  string  `json:"to_user_id" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Reference string  `json:"reference"`
+}
+
+// Transfer handles POST /wallet/transfer, moving funds from the user's
+// wallet to another user's
+func (c *WalletController) Transfer(ctx *gin.Context) {
+	var req TransferRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if pageSize < 1 {
-		pageSize = 20
+
+	userID := ctx.GetString("user_id")
+
+	tx, err := c.walletUseCase.Transfer(userID, req.ToUserID, req.Amount, req.Reference)
+	if err != nil {
+		// A retry with an already used reference gets the original back
+		var dup *wallet.DuplicateTransactionError
+		switch {
+		case errors.As(err, &dup):
+			ctx.JSON(http.StatusOK, dup.Existing)
+		case errors.Is(err, wallet.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, wallet.ErrSelfTransfer), errors.Is(err, wallet.ErrInvalidAmount),
+			errors.Is(err, wallet.ErrCurrencyMismatch), errors.Is(err, wallet.ErrInsufficientBalance):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
 	}
-	if pageSize > 100 {
-		pageSize = 100
+
+	ctx.JSON(http.StatusOK, tx)
+}
+
+// GetTransactions handles GET /wallet/transactions, optionally filtered by
+// type, status and a from/to date range
+func (c *WalletController) GetTransactions(ctx *gin.Context) {
+	params := pagination.FromQuery(ctx)
+
+	txFilter, err := transactionFilterFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// TODO: Get wallet ID from authenticated user context
-	walletID := ctx.GetString("wallet_id")
+	userID := ctx.GetString("user_id")
 
-	transactions, total, err := c.walletUseCase.GetTransactions(walletID, page, pageSize)
+	transactions, total, err := c.walletUseCase.GetTransactions(userID, txFilter, params.Page, params.PageSize)
 	if err != nil {
+		switch {
+		case errors.Is(err, wallet.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
+			return
+		case errors.Is(err, wallet.ErrInvalidRange):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid range: from must be before to"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	meta := params.Meta(total)
 	ctx.JSON(http.StatusOK, gin.H{
 		"transactions": transactions,
-		"total":        total,
-		"page":         page,
-		"page_size":    pageSize,
-		"total_pages":  (total + pageSize - 1) / pageSize,
+		"total":        meta.Total,
+		"page":         meta.Page,
+		"page_size":    meta.PageSize,
+		"total_pages":  meta.TotalPages,
+	})
+}
+
+// transactionFilterFromQuery reads the type, status, from and to query
+// parameters. Dates are RFC3339 timestamps or YYYY-MM-DD dates in UTC.
+func transactionFilterFromQuery(ctx *gin.Context) (wallet.TransactionFilter, error) {
+	var f wallet.TransactionFilter
+	var err error
+	if v := ctx.Query("type"); v != "" {
+		if f.Type, err = wallet.ParseTransactionType(v); err != nil {
+			return f, err
+		}
+	}
+	if v := ctx.Query("status"); v != "" {
+		if f.Status, err = wallet.ParseTransactionStatus(v); err != nil {
+			return f, err
+		}
+	}
+	if f.Created, err = filter.DateRangeFromParams(ctx, "from", "to"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// defaultTimeseriesDays is the range used when no from parameter is given
+const defaultTimeseriesDays = 30
+
+// GetTransactionTimeseries handles GET /wallet/transactions/timeseries
+func (c *WalletController) GetTransactionTimeseries(ctx *gin.Context) {
+	bucket, err := wallet.ParseBucket(ctx.DefaultQuery("bucket", string(wallet.BucketDay)))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dates, err := filter.DateRangeFromParams(ctx, "from", "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to := time.Now().UTC()
+	if dates.Before != nil {
+		to = *dates.Before
+	}
+	from := to.AddDate(0, 0, -defaultTimeseriesDays)
+	if dates.After != nil {
+		from = *dates.After
+	}
+
+	userID := ctx.GetString("user_id")
+
+	series, err := c.walletUseCase.GetTransactionTimeseries(userID, from, to, bucket)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidRange) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"from":   from,
+		"to":     to,
+		"series": series,
 	})
 }
@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// stubWalletRepo serves wallets by user and transactions by wallet. The
+// embedded interface leaves the methods these tests don't reach unimplemented.
+type stubWalletRepo struct {
+	wallet.Repository
+	wallets      map[string]*wallet.Wallet
+	transactions []*wallet.Transaction
+}
+
+func (r *stubWalletRepo) GetByUserID(userID string) (*wallet.Wallet, error) {
+	w, ok := r.wallets[userID]
+	if !ok {
+		return nil, wallet.ErrNotFound
+	}
+	return w, nil
+}
+
+func (r *stubWalletRepo) CreateTransaction(tx *wallet.Transaction) error {
+	for _, existing := range r.transactions {
+		if existing.WalletID == tx.WalletID && tx.Reference != "" && existing.Reference == tx.Reference {
+			return &wallet.DuplicateTransactionError{Existing: existing}
+		}
+	}
+	r.transactions = append(r.transactions, tx)
+	return nil
+}
+
+func (r *stubWalletRepo) UpdateBalance(walletID string, amount float64) error {
+	for _, w := range r.wallets {
+		if w.ID == walletID {
+			w.Balance += amount
+		}
+	}
+	return nil
+}
+
+func (r *stubWalletRepo) Credit(tx *wallet.Transaction) error {
+	if err := r.CreateTransaction(tx); err != nil {
+		return err
+	}
+	tx.Status = wallet.TransactionStatusSuccess
+	return r.UpdateBalance(tx.WalletID, tx.Amount)
+}
+
+func (r *stubWalletRepo) Transfer(debit, credit *wallet.Transaction) error {
+	for _, w := range r.wallets {
+		if w.ID == debit.WalletID && w.Balance < debit.Amount {
+			return wallet.ErrInsufficientBalance
+		}
+	}
+	r.UpdateBalance(debit.WalletID, -debit.Amount)
+	r.UpdateBalance(credit.WalletID, credit.Amount)
+	r.transactions = append(r.transactions, debit, credit)
+	return nil
+}
+
+func (r *stubWalletRepo) GetTransactions(walletID string, filter wallet.TransactionFilter, page, pageSize int) ([]*wallet.Transaction, int, error) {
+	var txs []*wallet.Transaction
+	for _, tx := range r.transactions {
+		if tx.WalletID == walletID && filter.Matches(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, len(txs), nil
+}
+
+func TestGetTransactions_UsesAuthenticatedUsersWallet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &stubWalletRepo{
+		wallets: map[string]*wallet.Wallet{
+			"user-1": {ID: "wallet-1", UserID: "user-1"},
+			"user-2": {ID: "wallet-2", UserID: "user-2"},
+		},
+		transactions: []*wallet.Transaction{
+			{ID: "tx-1", WalletID: "wallet-1", Type: wallet.TransactionTypeCredit, Amount: 10},
+			{ID: "tx-2", WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Amount: 4},
+			{ID: "tx-3", WalletID: "wallet-2", Type: wallet.TransactionTypeCredit, Amount: 99},
+		},
+	}
+	controller := NewWalletController(usecase.NewWalletUseCase(repo, nil))
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"Own transactions", "user-1", http.StatusOK, []string{"tx-1", "tx-2"}},
+		{"Other user", "user-2", http.StatusOK, []string{"tx-3"}},
+		{"No wallet", "user-3", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/wallet/transactions", func(ctx *gin.Context) {
+				ctx.Set("user_id", tt.userID)
+			}, controller.GetTransactions)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallet/transactions", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Transactions []wallet.Transaction `json:"transactions"`
+				Total        int                  `json:"total"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Total != len(tt.wantIDs) || len(body.Transactions) != len(tt.wantIDs) {
+				t.Fatalf("got %d transactions (total %d), want %v", len(body.Transactions), body.Total, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if body.Transactions[i].ID != id {
+					t.Errorf("transaction %d = %s, want %s", i, body.Transactions[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestReceiveFunds_RetryReturnsExistingTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &stubWalletRepo{
+		wallets: map[string]*wallet.Wallet{"user-1": {ID: "wallet-1", UserID: "user-1"}},
+	}
+	controller := NewWalletController(usecase.NewWalletUseCase(repo, nil))
+	router := gin.New()
+	router.POST("/wallet/receive", func(ctx *gin.Context) {
+		ctx.Set("user_id", "user-1")
+	}, controller.ReceiveFunds)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/wallet/receive", strings.NewReader(`{"amount": 25, "reference": "deposit-abc"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("attempt %d: status = %d, want 200: %s", i+1, rec.Code, rec.Body.String())
+		}
+		var tx wallet.Transaction
+		if err := json.Unmarshal(rec.Body.Bytes(), &tx); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		ids = append(ids, tx.ID)
+	}
+
+	if ids[0] != ids[1] {
+		t.Errorf("retry returned transaction %s, want original %s", ids[1], ids[0])
+	}
+	if balance := repo.wallets["user-1"].Balance; balance != 25 {
+		t.Errorf("balance = %v, want 25", balance)
+	}
+}
+
+func TestTransfer_Status(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"Success", `{"to_user_id": "user-2", "amount": 30}`, http.StatusOK},
+		{"Self-transfer", `{"to_user_id": "user-1", "amount": 30}`, http.StatusBadRequest},
+		{"Insufficient funds", `{"to_user_id": "user-2", "amount": 500}`, http.StatusBadRequest},
+		{"Unknown recipient", `{"to_user_id": "user-9", "amount": 30}`, http.StatusNotFound},
+		{"Missing recipient", `{"amount": 30}`, http.StatusBadRequest},
+		{"Negative amount", `{"to_user_id": "user-2", "amount": -30}`, http.StatusBadRequest},
+		{"Unknown field", `{"to_user_id": "user-2", "amount": 30, "from_user_id": "user-2"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubWalletRepo{
+				wallets: map[string]*wallet.Wallet{
+					"user-1": {ID: "wallet-1", UserID: "user-1", Balance: 100},
+					"user-2": {ID: "wallet-2", UserID: "user-2"},
+				},
+			}
+			controller := NewWalletController(usecase.NewWalletUseCase(repo, nil))
+			router := gin.New()
+			router.POST("/wallet/transfer", func(ctx *gin.Context) {
+				ctx.Set("user_id", "user-1")
+			}, controller.Transfer)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/wallet/transfer", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			wantBalance := 100.0
+			if tt.wantStatus == http.StatusOK {
+				wantBalance = 70
+			}
+			if balance := repo.wallets["user-1"].Balance; balance != wantBalance {
+				t.Errorf("sender balance = %v, want %v", balance, wantBalance)
+			}
+		})
+	}
+}
+
+func TestGetTransactions_Filters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	day := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+	repo := &stubWalletRepo{
+		wallets: map[string]*wallet.Wallet{"user-1": {ID: "wallet-1", UserID: "user-1"}},
+		transactions: []*wallet.Transaction{
+			{ID: "tx-1", WalletID: "wallet-1", Type: wallet.TransactionTypeCredit, Status: wallet.TransactionStatusSuccess, CreatedAt: day},
+			{ID: "tx-2", WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Status: wallet.TransactionStatusFailed, CreatedAt: day.AddDate(0, 0, 1)},
+			{ID: "tx-3", WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Status: wallet.TransactionStatusSuccess, CreatedAt: day.AddDate(0, 0, 2)},
+		},
+	}
+	controller := NewWalletController(usecase.NewWalletUseCase(repo, nil))
+	router := gin.New()
+	router.GET("/wallet/transactions", func(ctx *gin.Context) {
+		ctx.Set("user_id", "user-1")
+	}, controller.GetTransactions)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"Type", "?type=debit", http.StatusOK, []string{"tx-2", "tx-3"}},
+		{"Status", "?status=success", http.StatusOK, []string{"tx-1", "tx-3"}},
+		{"Date range", "?from=2025-10-21&to=2025-10-22", http.StatusOK, []string{"tx-2"}},
+		{"RFC3339 from", "?from=2025-10-21T12:00:00Z", http.StatusOK, []string{"tx-2", "tx-3"}},
+		{"Combined", "?type=debit&status=success&from=2025-10-21", http.StatusOK, []string{"tx-3"}},
+		{"Unknown type", "?type=refund", http.StatusBadRequest, nil},
+		{"Unknown status", "?status=done", http.StatusBadRequest, nil},
+		{"Malformed from", "?from=21/10/2025", http.StatusBadRequest, nil},
+		{"Malformed to", "?to=yesterday", http.StatusBadRequest, nil},
+		{"From after to", "?from=2025-10-22&to=2025-10-21", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallet/transactions"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Transactions []wallet.Transaction `json:"transactions"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var ids []string
+			for _, tx := range body.Transactions {
+				ids = append(ids, tx.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("transactions = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
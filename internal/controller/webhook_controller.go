@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/notification"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// maxWebhookBodyBytes caps the size of a provider callback
+const maxWebhookBodyBytes = 64 << 10
+
+// WebhookController handles callbacks from external providers and users'
+// outbound webhook subscriptions
+type WebhookController struct {
+	orderUseCase        *usecase.OrderUseCase
+	notificationUseCase *usecase.NotificationUseCase
+	paymentSecret       []byte
+}
+
+// NewWebhookController creates a new webhook controller. paymentSecret is the
+// key payment callbacks are signed with; when empty, payment callbacks are
+// refused.
+func NewWebhookController(orderUseCase *usecase.OrderUseCase, notificationUseCase *usecase.NotificationUseCase, paymentSecret string) *WebhookController {
+	return &WebhookController{
+		orderUseCase:        orderUseCase,
+		notificationUseCase: notificationUseCase,
+		paymentSecret:       []byte(paymentSecret),
+	}
+}
+
+// PaymentEventPayload is the body of a payment provider callback
+type PaymentEventPayload struct {
+	ID   string `json:"id" binding:"required"`
+	Type string `json:"type" binding:"required"`
+	Data struct {
+		PaymentRef string  `json:"payment_ref"`
+		Amount     float64 `json:"amount"`
+	} `json:"data"`
+}
+
+// PaymentCallback handles POST /webhooks/payments. The body must be signed
+// with the payment webhook secret in the X-Signature header. A
+// payment.succeeded event marks the order with the event's payment_ref as
+// paid; other event types are acknowledged and ignored.
+func (c *WebhookController) PaymentCallback(ctx *gin.Context) {
+	if len(c.paymentSecret) == 0 {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "payment webhooks are not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+		return
+	}
+	if err := webhook.Verify(c.paymentSecret, body, ctx.GetHeader(webhook.SignatureHeader)); err != nil {
+		log.Warn().Str("ip", ctx.ClientIP()).Msg("rejected payment webhook with an invalid signature")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var payload PaymentEventPayload
+	if err := bindJSON(ctx, &payload, bindOptions{MaxDepth: maxJSONDepth}); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if payload.Type != order.PaymentEventSucceeded {
+		ctx.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+	if payload.Data.PaymentRef == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "data.payment_ref is required"})
+		return
+	}
+
+	o, duplicate, err := c.orderUseCase.ConfirmPayment(payload.ID, payload.Data.PaymentRef, payload.Data.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, order.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no order with this payment_ref"})
+		case errors.Is(err, order.ErrPaymentAmountMismatch):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	status := "processed"
+	if duplicate {
+		status = "duplicate"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":       status,
+		"order_id":     o.ID,
+		"order_status": o.Status,
+	})
+}
+
+// CreateWebhookRequest represents the request body for subscribing a URL to
+// events
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+}
+
+// CreateWebhook handles POST /sellers/me/webhooks. The response includes the
+// signing secret, which is not shown again.
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	var req CreateWebhookRequest
+	if err := bindStrictJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w, err := c.notificationUseCase.CreateWebhook(ctx.GetString("user_id"), req.URL, req.Events)
+	if err != nil {
+		if errors.Is(err, notification.ErrInvalidURL) || errors.Is(err, notification.ErrPrivateURL) ||
+			errors.Is(err, notification.ErrUnknownEvent) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"webhook": w,
+		"secret":  w.Secret,
+	})
+}
+
+// ListWebhooks handles GET /sellers/me/webhooks
+func (c *WebhookController) ListWebhooks(ctx *gin.Context) {
+	webhooks, err := c.notificationUseCase.ListWebhooks(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*notification.Webhook{}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// DeleteWebhook handles DELETE /sellers/me/webhooks/:id
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	if err := c.notificationUseCase.DeleteWebhook(ctx.Param("id"), ctx.GetString("user_id")); err != nil {
+		if errors.Is(err, notification.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "webhook deleted"})
+}
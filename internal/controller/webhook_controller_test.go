@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/Tenoywil/CaribEx-backend/pkg/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+const testPaymentSecret = "whsec_test"
+
+// stubOrderRepo serves orders by payment reference and records payment
+// events. The embedded interface leaves the methods these tests don't reach
+// unimplemented.
+type stubOrderRepo struct {
+	order.Repository
+	orders map[string]*order.Order
+	events map[string]*order.PaymentEvent
+}
+
+func (r *stubOrderRepo) GetByPaymentRef(paymentRef string) (*order.Order, error) {
+	for _, o := range r.orders {
+		if o.PaymentRef == paymentRef {
+			copied := *o
+			return &copied, nil
+		}
+	}
+	return nil, order.ErrNotFound
+}
+
+func (r *stubOrderRepo) ApplyPaymentEvent(e *order.PaymentEvent) (bool, error) {
+	if _, ok := r.events[e.ID]; ok {
+		return false, order.ErrDuplicatePaymentEvent
+	}
+	r.events[e.ID] = e
+	o := r.orders[e.OrderID]
+	if o.Status != order.OrderStatusPending {
+		return false, nil
+	}
+	o.Status = order.OrderStatusPaid
+	return true, nil
+}
+
+func newWebhookRouter(secret string) (*gin.Engine, *stubOrderRepo) {
+	gin.SetMode(gin.TestMode)
+	repo := &stubOrderRepo{
+		orders: map[string]*order.Order{
+			"order-1": {ID: "order-1", Status: order.OrderStatusPending, Total: 25, PaymentRef: "pay_123"},
+		},
+		events: map[string]*order.PaymentEvent{},
+	}
+	ctrl := NewWebhookController(usecase.NewOrderUseCase(repo, nil, nil, nil, nil, nil, nil), nil, secret)
+
+	router := gin.New()
+	router.POST("/webhooks/payments", ctrl.PaymentCallback)
+	return router, repo
+}
+
+func sendPaymentEvent(router *gin.Engine, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(webhook.SignatureHeader, signature)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+const paymentSucceededBody = `{"id":"evt_1","type":"payment.succeeded","data":{"payment_ref":"pay_123","amount":25}}`
+
+func TestPaymentCallback_MarksOrderPaid(t *testing.T) {
+	router, repo := newWebhookRouter(testPaymentSecret)
+
+	w := sendPaymentEvent(router, paymentSucceededBody, webhook.Sign([]byte(testPaymentSecret), []byte(paymentSucceededBody)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp struct {
+		Status      string `json:"status"`
+		OrderID     string `json:"order_id"`
+		OrderStatus string `json:"order_status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "processed" || resp.OrderID != "order-1" || resp.OrderStatus != string(order.OrderStatusPaid) {
+		t.Errorf("response = %+v, want order-1 processed and paid", resp)
+	}
+	if got := repo.orders["order-1"].Status; got != order.OrderStatusPaid {
+		t.Errorf("stored status = %q, want %q", got, order.OrderStatusPaid)
+	}
+}
+
+func TestPaymentCallback_RejectsBadSignatures(t *testing.T) {
+	router, repo := newWebhookRouter(testPaymentSecret)
+
+	for name, signature := range map[string]string{
+		"Missing":        "",
+		"Wrong secret":   webhook.Sign([]byte("other-secret"), []byte(paymentSucceededBody)),
+		"Different body": webhook.Sign([]byte(testPaymentSecret), []byte(`{"id":"evt_1"}`)),
+		"Malformed":      "sha256=not-hex",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if w := sendPaymentEvent(router, paymentSucceededBody, signature); w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+	if got := repo.orders["order-1"].Status; got != order.OrderStatusPending {
+		t.Errorf("stored status = %q, want %q", got, order.OrderStatusPending)
+	}
+}
+
+func TestPaymentCallback_DuplicateEvent(t *testing.T) {
+	router, repo := newWebhookRouter(testPaymentSecret)
+	signature := webhook.Sign([]byte(testPaymentSecret), []byte(paymentSucceededBody))
+
+	if w := sendPaymentEvent(router, paymentSucceededBody, signature); w.Code != http.StatusOK {
+		t.Fatalf("first delivery status = %d, want %d", w.Code, http.StatusOK)
+	}
+	repo.orders["order-1"].Status = order.OrderStatusShipped
+
+	w := sendPaymentEvent(router, paymentSucceededBody, signature)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"duplicate"`) {
+		t.Fatalf("redelivery = %d %s, want 200 duplicate", w.Code, w.Body.String())
+	}
+	if got := repo.orders["order-1"].Status; got != order.OrderStatusShipped {
+		t.Errorf("stored status after redelivery = %q, want %q", got, order.OrderStatusShipped)
+	}
+	if len(repo.events) != 1 {
+		t.Errorf("recorded %d events, want 1", len(repo.events))
+	}
+}
+
+func TestPaymentCallback_Responses(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"Other event type", `{"id":"evt_2","type":"payment.refunded","data":{"payment_ref":"pay_123"}}`, http.StatusOK},
+		{"Unknown payment ref", `{"id":"evt_2","type":"payment.succeeded","data":{"payment_ref":"pay_999","amount":25}}`, http.StatusNotFound},
+		{"Missing payment ref", `{"id":"evt_2","type":"payment.succeeded","data":{"amount":25}}`, http.StatusBadRequest},
+		{"Short amount", `{"id":"evt_2","type":"payment.succeeded","data":{"payment_ref":"pay_123","amount":20}}`, http.StatusUnprocessableEntity},
+		{"Missing event ID", `{"type":"payment.succeeded","data":{"payment_ref":"pay_123","amount":25}}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newWebhookRouter(testPaymentSecret)
+			w := sendPaymentEvent(router, tt.body, webhook.Sign([]byte(testPaymentSecret), []byte(tt.body)))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPaymentCallback_NotConfigured(t *testing.T) {
+	router, _ := newWebhookRouter("")
+
+	w := sendPaymentEvent(router, paymentSucceededBody, webhook.Sign(nil, []byte(paymentSucceededBody)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
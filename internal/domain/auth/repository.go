@@ -1,6 +1,12 @@
 package auth
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrStoreUnavailable is returned while the session store is unreachable
+var ErrStoreUnavailable = errors.New("session store unavailable")
 
 // SessionRepository defines the interface for session storage
 type SessionRepository interface {
@@ -12,6 +18,10 @@ type SessionRepository interface {
 	
 	// DeleteSession removes a session
 	DeleteSession(ctx context.Context, sessionID string) error
+
+	// DeleteUserSessions removes every session of the user, returning how
+	// many were removed
+	DeleteUserSessions(ctx context.Context, userID string) (int, error)
 	
 	// SaveNonce stores a nonce
 	SaveNonce(ctx context.Context, nonce *Nonce) error
@@ -21,4 +31,8 @@ type SessionRepository interface {
 	
 	// DeleteNonce removes a nonce
 	DeleteNonce(ctx context.Context, nonceValue string) error
+
+	// ConsumeNonce retrieves and removes a nonce in one atomic step, so
+	// only one caller can ever use it
+	ConsumeNonce(ctx context.Context, nonceValue string) (*Nonce, error)
 }
@@ -3,6 +3,7 @@ package auth
 import (
 	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
 	"github.com/google/uuid"
 )
 
@@ -14,15 +15,19 @@ type Session struct {
 	Nonce         string    `json:"nonce"`
 	ExpiresAt     time.Time `json:"expires_at"`
 	CreatedAt     time.Time `json:"created_at"`
+	// Role is the user's role at login. Sessions stored before roles were
+	// recorded have none and are treated as customers.
+	Role user.Role `json:"role,omitempty"`
 }
 
 // NewSession creates a new session
-func NewSession(userID, walletAddress string, duration time.Duration) *Session {
-	now := time.Now()
+func NewSession(userID, walletAddress string, role user.Role, duration time.Duration) *Session {
+	now := time.Now().UTC()
 	return &Session{
 		ID:            uuid.New().String(),
 		UserID:        userID,
 		WalletAddress: walletAddress,
+		Role:          role,
 		ExpiresAt:     now.Add(duration),
 		CreatedAt:     now,
 	}
@@ -33,16 +38,26 @@ func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
 
+// AccessToken is a signed token issued alongside a session for services
+// that verify it without the session store
+type AccessToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Nonce represents a SIWE nonce
 type Nonce struct {
 	Value     string    `json:"nonce"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
+	// Token binds the nonce to the client that requested it; when set, the
+	// verify request must present the same token
+	Token string `json:"token,omitempty"`
 }
 
 // NewNonce creates a new nonce with 10 minute expiration
 func NewNonce() *Nonce {
-	now := time.Now()
+	now := time.Now().UTC()
 	return &Nonce{
 		Value:     uuid.New().String(),
 		ExpiresAt: now.Add(10 * time.Minute),
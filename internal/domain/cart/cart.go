@@ -1,6 +1,10 @@
 package cart
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // CartStatus represents the status of a cart
 type CartStatus string
@@ -10,6 +14,35 @@ const (
 	CartStatusCheckedOut CartStatus = "checked_out"
 )
 
+var (
+	// ErrProductUnavailable is returned when a product is deleted, inactive, or out of stock
+	ErrProductUnavailable = errors.New("product is unavailable")
+	// ErrEmptyCart is returned when ordering from a cart without items
+	ErrEmptyCart = errors.New("cart is empty")
+	// ErrNotFound is returned when a user has no active cart
+	ErrNotFound = errors.New("cart not found")
+	// ErrItemNotFound is returned for a cart item that does not exist or
+	// belongs to another user's cart
+	ErrItemNotFound = errors.New("cart item not found")
+	// ErrActiveCartExists is returned when creating a cart for a user who
+	// already has an active one
+	ErrActiveCartExists = errors.New("user already has an active cart")
+)
+
+// ProductUnavailableError identifies the product behind ErrProductUnavailable
+type ProductUnavailableError struct {
+	ProductID string
+}
+
+func (e *ProductUnavailableError) Error() string {
+	return fmt.Sprintf("product %s is unavailable", e.ProductID)
+}
+
+// Unwrap lets errors.Is match ErrProductUnavailable
+func (e *ProductUnavailableError) Unwrap() error {
+	return ErrProductUnavailable
+}
+
 // Cart represents a shopping cart
 type Cart struct {
 	ID        string     `json:"id"`
@@ -29,15 +62,28 @@ type CartItem struct {
 	Price     float64   `json:"price"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Unavailable flags items whose product can no longer be bought so the
+	// client can prompt the user to remove them
+	Unavailable bool `json:"unavailable,omitempty"`
 }
 
 // Repository defines the interface for cart data operations
 type Repository interface {
+	// GetByUserID returns the user's active cart, or ErrNotFound
 	GetByUserID(userID string) (*Cart, error)
+	// CreateCart stores a new cart, returning ErrActiveCartExists when the
+	// user already has an active one
+	CreateCart(c *Cart) error
 	GetItems(cartID string) ([]*CartItem, error)
+	// GetItemByID returns a single cart item, or ErrItemNotFound
+	GetItemByID(itemID string) (*CartItem, error)
 	AddItem(item *CartItem) error
 	UpdateItem(item *CartItem) error
 	RemoveItem(itemID string) error
-	UpdateTotal(cartID string, total float64) error
+	// ClearItems removes every item from the cart
+	ClearItems(cartID string) error
+	// RecalculateTotal recomputes the cart total from its items in a single
+	// statement so concurrent modifications cannot persist a stale sum
+	RecalculateTotal(cartID string) (float64, error)
 	SetStatus(cartID string, status CartStatus) error
 }
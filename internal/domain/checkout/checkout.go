@@ -0,0 +1,38 @@
+package checkout
+
+import (
+	"errors"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+)
+
+var (
+	// ErrCartNotFound is returned when the cart does not exist or belongs to another user
+	ErrCartNotFound = errors.New("cart not found")
+	// ErrCartNotActive is returned when the cart has already been checked out
+	ErrCartNotActive = errors.New("cart is not active")
+)
+
+// Tx is the set of operations a checkout performs inside a single database
+// transaction. Nothing written through it is visible until the transaction commits.
+type Tx interface {
+	// GetCart loads the cart and locks it against concurrent checkouts
+	GetCart(cartID string) (*cart.Cart, error)
+	GetCartItems(cartID string) ([]*cart.CartItem, error)
+	CreateOrder(o *order.Order) error
+	CreateOrderItems(items []*order.OrderItem) error
+	// DecrementStock removes sold units, returning a
+	// *product.InsufficientStockError when fewer than quantity remain
+	DecrementStock(productID string, quantity int) error
+	SetCartStatus(cartID string, status cart.CartStatus) error
+	// ReleaseReservations drops the cart's stock holds once stock is decremented
+	ReleaseReservations(cartID string) error
+}
+
+// Repository runs checkouts transactionally
+type Repository interface {
+	// WithinTx calls fn inside a transaction, committing when it returns nil
+	// and rolling back every change otherwise
+	WithinTx(fn func(tx Tx) error) error
+}
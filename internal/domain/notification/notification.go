@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// EventOrderStatusChanged is sent to the sellers of an order whenever its
+// status changes
+const EventOrderStatusChanged = "order.status_changed"
+
+// Events lists the event types a webhook can subscribe to
+var Events = []string{EventOrderStatusChanged}
+
+var (
+	// ErrNotFound is returned when a webhook does not exist
+	ErrNotFound = errors.New("webhook not found")
+	// ErrInvalidURL is returned for a webhook URL that is not absolute HTTPS
+	ErrInvalidURL = errors.New("webhook url must be an absolute https url")
+	// ErrPrivateURL is returned for a webhook URL whose host is, or resolves
+	// to, an address that is not publicly routable
+	ErrPrivateURL = errors.New("webhook url must resolve to a public address")
+	// ErrUnknownEvent is returned when subscribing to an event that is never sent
+	ErrUnknownEvent = errors.New("unknown webhook event")
+)
+
+// Webhook is a user's subscription of a URL to events. Deliveries are signed
+// with Secret, which is only shown when the webhook is created.
+type Webhook struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook receives events of this type
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery records one attempt to deliver an event to a webhook
+type Delivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ValidateURL checks that a webhook URL is an absolute HTTPS URL. allowHTTP
+// also accepts plain HTTP, for local development.
+func ValidateURL(raw string, allowHTTP bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "https" && !(allowHTTP && u.Scheme == "http") {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// ValidateEvents checks that every event is one that is sent
+func ValidateEvents(events []string) error {
+	for _, e := range events {
+		known := false
+		for _, k := range Events {
+			known = known || e == k
+		}
+		if !known {
+			return fmt.Errorf("%w: %q", ErrUnknownEvent, e)
+		}
+	}
+	return nil
+}
+
+// Repository defines the interface for webhook subscription operations
+type Repository interface {
+	Create(w *Webhook) error
+	// ListByUser returns the user's webhooks, newest first
+	ListByUser(userID string) ([]*Webhook, error)
+	// ListSubscribed returns the active webhooks of the users that subscribe
+	// to event
+	ListSubscribed(userIDs []string, event string) ([]*Webhook, error)
+	// Delete removes the user's webhook, returning ErrNotFound if the user
+	// has no webhook with this ID
+	Delete(id, userID string) error
+	RecordDelivery(d *Delivery) error
+}
@@ -0,0 +1,29 @@
+package order
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIdempotencyInProgress is returned when a request with the same
+// Idempotency-Key is still being processed
+var ErrIdempotencyInProgress = errors.New("a request with this idempotency key is already in progress")
+
+// ErrIdempotencyKeyReused is returned when an Idempotency-Key is sent again
+// with a different request body
+var ErrIdempotencyKeyReused = errors.New("this idempotency key was already used for a different request")
+
+// IdempotencyStore remembers which order each client-supplied idempotency key
+// produced. Keys are scoped per user so different users never collide.
+type IdempotencyStore interface {
+	// Claim reserves the key for a new order made by the request with the
+	// given fingerprint. When the key was already claimed it returns false
+	// along with the order ID recorded for it, which is empty while the
+	// first request is still in flight, or ErrIdempotencyKeyReused when the
+	// key was claimed by a request with another fingerprint.
+	Claim(ctx context.Context, userID, key, fingerprint string) (orderID string, claimed bool, err error)
+	// Complete records the order created for a claimed key
+	Complete(ctx context.Context, userID, key, fingerprint, orderID string) error
+	// Release drops a claim whose order could not be created so the client can retry
+	Release(ctx context.Context, userID, key string) error
+}
@@ -1,6 +1,10 @@
 package order
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 // OrderStatus represents the status of an order
 type OrderStatus string
@@ -11,8 +15,82 @@ const (
 	OrderStatusShipped   OrderStatus = "shipped"
 	OrderStatusCompleted OrderStatus = "completed"
 	OrderStatusCancelled OrderStatus = "cancelled"
+
+	// OrderStatusPartiallyShipped is derived when only some items have shipped
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped"
+)
+
+// ItemStatus represents the fulfillment status of a single order item
+type ItemStatus string
+
+const (
+	ItemStatusPending   ItemStatus = "pending"
+	ItemStatusShipped   ItemStatus = "shipped"
+	ItemStatusCancelled ItemStatus = "cancelled"
+)
+
+var (
+	// ErrNoSellerItems is returned when a seller has no unshipped items in an order
+	ErrNoSellerItems = errors.New("order has no unshipped items from this seller")
+	// ErrOrderClosed is returned when fulfilling an order that is cancelled or completed
+	ErrOrderClosed = errors.New("order is cancelled or completed")
+	// ErrOrderNotPaid is returned when shipping items of an order that has not been paid
+	ErrOrderNotPaid = errors.New("order has not been paid")
+	// ErrInvalidTransition is returned when a status change is not allowed from the current status
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	// ErrStatusConflict is returned when the order's status changed after it was read
+	ErrStatusConflict = errors.New("order status was changed by another request")
+	// ErrNotParticipant is returned when the caller is neither the buyer, a seller in the order, nor an admin
+	ErrNotParticipant = errors.New("not allowed to manage this order")
+	// ErrInvalidProducts is returned when an order references products that
+	// no longer exist or are no longer for sale
+	ErrInvalidProducts = errors.New("order contains unavailable products")
 )
 
+// InvalidProductsError lists the products behind ErrInvalidProducts
+type InvalidProductsError struct {
+	ProductIDs []string
+}
+
+func (e *InvalidProductsError) Error() string {
+	return ErrInvalidProducts.Error() + ": " + strings.Join(e.ProductIDs, ", ")
+}
+
+// Unwrap lets errors.Is match ErrInvalidProducts
+func (e *InvalidProductsError) Unwrap() error {
+	return ErrInvalidProducts
+}
+
+// transitions lists the statuses each status may move to. Completed and
+// cancelled orders are final, and an order cannot be cancelled once any of
+// its items have shipped.
+var transitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:          {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:             {OrderStatusPartiallyShipped, OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusPartiallyShipped: {OrderStatusShipped},
+	OrderStatusShipped:          {OrderStatusCompleted},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidStatus reports whether s is a known order status
+func ValidStatus(s OrderStatus) bool {
+	switch s {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusPartiallyShipped,
+		OrderStatusShipped, OrderStatusCompleted, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
 // Order represents a customer order
 type Order struct {
 	ID         string      `json:"id"`
@@ -27,18 +105,75 @@ type Order struct {
 
 // OrderItem represents an item in an order
 type OrderItem struct {
-	ID        string  `json:"id"`
-	OrderID   string  `json:"order_id"`
-	ProductID string  `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	ID        string     `json:"id"`
+	OrderID   string     `json:"order_id"`
+	ProductID string     `json:"product_id"`
+	Quantity  int        `json:"quantity"`
+	Price     float64    `json:"price"`
+	Status    ItemStatus `json:"status"`
+	// Title is the product title snapshotted when the order was placed
+	Title string `json:"title,omitempty"`
+}
+
+// OrderItemWithProduct is an order item with the product details needed to
+// render a receipt. Price is always the price at purchase; Title is the
+// product's current title, falling back to the snapshot on the item.
+type OrderItemWithProduct struct {
+	OrderItem
+	Images []string `json:"images"`
+	// ProductAvailable is false once the product is deactivated or removed
+	ProductAvailable bool `json:"product_available"`
+}
+
+// DeriveStatus computes an order's fulfillment status from its items.
+// Cancelled items are ignored unless every item is cancelled. It returns
+// false when no item has shipped yet, leaving the order status unchanged.
+func DeriveStatus(items []*OrderItem) (OrderStatus, bool) {
+	var active, shipped int
+	for _, item := range items {
+		switch item.Status {
+		case ItemStatusCancelled:
+			continue
+		case ItemStatusShipped:
+			shipped++
+		}
+		active++
+	}
+
+	switch {
+	case len(items) > 0 && active == 0:
+		return OrderStatusCancelled, true
+	case shipped == 0:
+		return "", false
+	case shipped == active:
+		return OrderStatusShipped, true
+	default:
+		return OrderStatusPartiallyShipped, true
+	}
+}
+
+// StatusNotifier is told when an order's status changes. It must not block,
+// since it is called while serving the request that changed the status.
+type StatusNotifier interface {
+	OrderStatusChanged(o *Order)
 }
 
 // Repository defines the interface for order data operations
 type Repository interface {
 	Create(order *Order) error
 	GetByID(id string) (*Order, error)
+	// GetByPaymentRef returns the order placed with the payment reference,
+	// ErrNotFound, or ErrDuplicatePaymentRef if more than one order has it
+	GetByPaymentRef(paymentRef string) (*Order, error)
 	GetByUserID(userID string, page, pageSize int) ([]*Order, int, error)
 	GetItems(orderID string) ([]*OrderItem, error)
-	UpdateStatus(orderID string, status OrderStatus) error
+	GetItemsWithProduct(orderID string) ([]*OrderItemWithProduct, error)
+	// UpdateStatus moves the order from one status to another, returning
+	// ErrStatusConflict if it no longer has the from status
+	UpdateStatus(orderID string, from, to OrderStatus) error
+	UpdateItemsStatus(orderID string, itemIDs []string, status ItemStatus) error
+	// ApplyPaymentEvent records the event and moves a pending order to paid
+	// in one atomic step, reporting whether the status changed. An event ID
+	// already recorded returns ErrDuplicatePaymentEvent and changes nothing.
+	ApplyPaymentEvent(e *PaymentEvent) (paid bool, err error)
 }
@@ -0,0 +1,33 @@
+package order
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no order matches
+	ErrNotFound = errors.New("order not found")
+	// ErrDuplicatePaymentEvent is returned when a payment provider event has
+	// already been applied
+	ErrDuplicatePaymentEvent = errors.New("payment event already processed")
+	// ErrPaymentAmountMismatch is returned when a payment does not cover the
+	// order total
+	ErrPaymentAmountMismatch = errors.New("payment amount does not cover the order total")
+	// ErrDuplicatePaymentRef is returned when a payment reference is already
+	// used by another order
+	ErrDuplicatePaymentRef = errors.New("payment reference is already used by another order")
+)
+
+// PaymentEventSucceeded is the provider event type reporting a settled payment
+const PaymentEventSucceeded = "payment.succeeded"
+
+// PaymentEvent is a payment provider callback about an order's payment. Its
+// ID is the provider's event ID, so a redelivered event is recognised.
+type PaymentEvent struct {
+	ID         string
+	OrderID    string
+	Type       string
+	Amount     float64
+	ReceivedAt time.Time
+}
@@ -0,0 +1,32 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRateLimited is returned when a user places orders faster than allowed
+var ErrRateLimited = errors.New("too many orders, please try again later")
+
+// RateLimitError carries how long the user must wait before ordering again
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", ErrRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap lets errors.Is match ErrRateLimited
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RateLimiter caps how many orders a single user may create per window
+type RateLimiter interface {
+	// Allow records an order attempt for the user and reports whether it is
+	// within the limit, and if not, how long until the window resets
+	Allow(ctx context.Context, userID string) (bool, time.Duration, error)
+}
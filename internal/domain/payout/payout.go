@@ -0,0 +1,75 @@
+package payout
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/pkg/money"
+)
+
+// Status represents the settlement status of a payout ledger entry
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusPaid    Status = "paid"
+)
+
+var (
+	// ErrAlreadyAccrued is returned when earnings for an order were already recorded
+	ErrAlreadyAccrued = errors.New("earnings already accrued for order")
+	// ErrAlreadyPaid is returned when marking a payout that was already settled
+	ErrAlreadyPaid = errors.New("payout already marked as paid")
+	// ErrNotFound is returned when a payout does not exist
+	ErrNotFound = errors.New("payout not found")
+)
+
+// Payout represents a seller's earnings from a single completed order
+type Payout struct {
+	ID        string     `json:"id"`
+	SellerID  string     `json:"seller_id"`
+	OrderID   string     `json:"order_id"`
+	Gross     float64    `json:"gross"`
+	Fee       float64    `json:"fee"`
+	Net       float64    `json:"net"`
+	Status    Status     `json:"status"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Earnings summarizes a seller's pending and settled balances
+type Earnings struct {
+	SellerID string  `json:"seller_id"`
+	Pending  float64 `json:"pending"`
+	Paid     float64 `json:"paid"`
+	Total    float64 `json:"total"`
+}
+
+// CalculateFees splits a gross amount into the platform fee and the seller's net,
+// rounding the fee to the nearest cent so fee and net always add up to gross
+func CalculateFees(gross, feePercent float64) (fee, net float64) {
+	if feePercent <= 0 {
+		return 0, gross
+	}
+	g := money.FromFloat(gross)
+	f := g.Percent(feePercent)
+	return f.Float64(), g.Sub(f).Float64()
+}
+
+// Repository defines the interface for payout ledger operations
+type Repository interface {
+	// Accrue records all payouts for an order atomically, returning
+	// ErrAlreadyAccrued if the order already has ledger entries
+	Accrue(payouts []*Payout) error
+	// CompleteOrder marks a shipped order completed and records its payouts
+	// in the same transaction, so an order is never completed without its
+	// seller earnings. The payouts are skipped, and accrued is false, when the
+	// order already has ledger entries. An order that is not shipped returns
+	// order.ErrStatusConflict.
+	CompleteOrder(orderID string, payouts []*Payout) (accrued bool, err error)
+	ExistsForOrder(orderID string) (bool, error)
+	GetByID(id string) (*Payout, error)
+	GetEarnings(sellerID string) (*Earnings, error)
+	MarkPaid(id string, paidAt time.Time) error
+}
@@ -0,0 +1,104 @@
+package product
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different sort than the one requested
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// DefaultSortField is used when sort_by is missing or not sortable
+const DefaultSortField = "created_at"
+
+// SortByRelevance orders search results by text rank. It only applies when a
+// search filter is present and is not available for cursor pagination, where
+// it falls back to DefaultSortField.
+const SortByRelevance = "relevance"
+
+// sortFields lists the product fields listings may be sorted by
+var sortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"price":      true,
+	"title":      true,
+}
+
+// NormalizeSort maps free-form sort parameters to a sortable field and
+// direction, defaulting to newest first
+func NormalizeSort(sortBy, sortOrder string) (field string, desc bool) {
+	if !sortFields[sortBy] {
+		sortBy = DefaultSortField
+	}
+	return sortBy, !strings.EqualFold(sortOrder, "asc")
+}
+
+// Cursor marks the last product of a page for keyset pagination: the next
+// page starts strictly after (Value, ID) in the listing's sort order.
+type Cursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// NewCursor builds the cursor positioned at p for the given sort
+func NewCursor(p *ProductWithCategory, sortBy string, desc bool) Cursor {
+	c := Cursor{SortBy: sortBy, Desc: desc, ID: p.ID}
+	switch sortBy {
+	case "updated_at":
+		c.Value = p.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "price":
+		c.Value = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case "title":
+		c.Value = p.Title
+	default:
+		c.Value = p.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+// Encode returns the opaque string handed to clients as next_cursor
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor previously returned by Encode, rejecting it
+// unless it was issued for the same sort field and direction
+func DecodeCursor(s, sortBy string, desc bool) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Desc != desc {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if err := c.validateValue(); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// validateValue checks the sort value parses as the field's type so it can be
+// bound to the query without a database cast error
+func (c Cursor) validateValue() error {
+	switch c.SortBy {
+	case "created_at", "updated_at":
+		_, err := time.Parse(time.RFC3339Nano, c.Value)
+		return err
+	case "price":
+		_, err := strconv.ParseFloat(c.Value, 64)
+		return err
+	}
+	return nil
+}
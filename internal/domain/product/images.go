@@ -0,0 +1,100 @@
+package product
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoValidImages is returned when images were supplied but none are usable
+	ErrNoValidImages = errors.New("images must contain at least one non-empty URL")
+	// ErrImageNotFound is returned when an image key does not belong to the product
+	ErrImageNotFound = errors.New("image not found on product")
+)
+
+// ImageKeyPrefix is the storage folder product images are uploaded to
+const ImageKeyPrefix = "products/"
+
+// DedupeImages trims image URLs and drops blanks and repeats, keeping the
+// first occurrence of each URL in its original position
+func DedupeImages(images []string) []string {
+	if images == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(images))
+	result := make([]string, 0, len(images))
+	for _, url := range images {
+		url = strings.TrimSpace(url)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		result = append(result, url)
+	}
+	return result
+}
+
+// NormalizeImages de-duplicates images, rejecting a non-empty list that
+// contains no usable URL
+func NormalizeImages(images []string) ([]string, error) {
+	result := DedupeImages(images)
+	if len(images) > 0 && len(result) == 0 {
+		return nil, ErrNoValidImages
+	}
+	return result, nil
+}
+
+// FindImageKey returns the URL and storage key of the image matching key,
+// which may be either the full object key or just its file name. keyFromURL
+// translates each stored image URL into its object key, reporting false for
+// URLs outside our storage; those images, and any stored outside
+// ImageKeyPrefix, never match.
+func FindImageKey(images []string, key string, keyFromURL func(string) (string, bool)) (image, imageKey string, ok bool) {
+	if key == "" {
+		return "", "", false
+	}
+	for _, image := range images {
+		k, ok := keyFromURL(image)
+		if !ok || !strings.HasPrefix(k, ImageKeyPrefix) {
+			continue
+		}
+		if k == key || path.Base(k) == key {
+			return image, k, true
+		}
+	}
+	return "", "", false
+}
+
+// Thumbnail is a resized copy of a product image
+type Thumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// ImageVariants are the copies stored alongside a product image: the
+// original upload when the image was converted, and its thumbnails, smallest
+// first
+type ImageVariants struct {
+	URL         string      `json:"url"`
+	OriginalURL string      `json:"original_url,omitempty"`
+	Thumbnails  []Thumbnail `json:"thumbnails"`
+}
+
+// VariantURLs returns the URLs of every stored copy of the images: the images
+// themselves followed by their originals and thumbnails
+func VariantURLs(images []string, variants []*ImageVariants) []string {
+	urls := slices.Clone(images)
+	for _, v := range variants {
+		if v.OriginalURL != "" {
+			urls = append(urls, v.OriginalURL)
+		}
+		for _, thumb := range v.Thumbnails {
+			urls = append(urls, thumb.URL)
+		}
+	}
+	return urls
+}
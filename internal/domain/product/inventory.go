@@ -0,0 +1,38 @@
+package product
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidQuantity is returned when stock is set below zero
+var ErrInvalidQuantity = errors.New("quantity must not be negative")
+
+// ErrReasonTooLong is returned when an adjustment reason exceeds
+// MaxAdjustmentReasonLength
+var ErrReasonTooLong = errors.New("reason must be at most 255 characters")
+
+// MaxAdjustmentReasonLength caps the free-text reason stored with a stock change
+const MaxAdjustmentReasonLength = 255
+
+// InventoryAdjustment records a change to a product's stock quantity, who
+// made it and why. UserID is nil once that user has been deleted.
+type InventoryAdjustment struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	UserID    *string   `json:"user_id"`
+	Delta     int       `json:"delta"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdjustmentRepository defines the interface for the inventory audit trail
+type AdjustmentRepository interface {
+	// UpdateWithAdjustment saves p and, in the same transaction, records any
+	// change from the stored quantity, which stays locked until commit, as a
+	// stock change made by userID. It returns ErrNotFound for an unknown
+	// product.
+	UpdateWithAdjustment(p *Product, userID, reason string) error
+	// ListAdjustments returns the product's stock changes, newest first
+	ListAdjustments(productID string, page, pageSize int) ([]*InventoryAdjustment, int, error)
+}
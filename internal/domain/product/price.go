@@ -0,0 +1,94 @@
+package product
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// MaxPrice is the highest listing price accepted for a product
+const MaxPrice = 1000000.00
+
+// PriceOperation describes how a bulk price update adjusts each product
+type PriceOperation string
+
+const (
+	PriceOpSet         PriceOperation = "set"
+	PriceOpIncreasePct PriceOperation = "increase-pct"
+	PriceOpDecreasePct PriceOperation = "decrease-pct"
+)
+
+var (
+	// ErrInvalidPrice is returned when a price is not positive or exceeds MaxPrice
+	ErrInvalidPrice = errors.New("price must be greater than 0 and at most 1000000")
+	// ErrInvalidPriceOperation is returned for an unknown bulk price operation
+	ErrInvalidPriceOperation = errors.New("operation must be one of set, increase-pct, decrease-pct")
+	// ErrInvalidPriceAdjustment is returned when a percentage adjustment is out of range
+	ErrInvalidPriceAdjustment = errors.New("percentage must be greater than 0, and below 100 for decreases")
+	// ErrInvalidPriceSelector is returned unless exactly one of product IDs or category is given
+	ErrInvalidPriceSelector = errors.New("specify either product_ids or category_id")
+	// ErrPriceChanged is returned when a product's price changed after a bulk
+	// update read it, so the update would be based on a stale price
+	ErrPriceChanged = errors.New("a product's price changed during the update; please retry")
+)
+
+// PriceChange records a product price movement for audit
+type PriceChange struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	ChangedBy string    `json:"changed_by"`
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PriceUpdateResult is a product whose price a bulk update changed
+type PriceUpdateResult struct {
+	ProductID string  `json:"product_id"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+// ValidatePrice checks that a price is within the accepted listing range
+func ValidatePrice(price float64) error {
+	if math.IsNaN(price) || price <= 0 || price > MaxPrice {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+// Validate checks the operation and its value before any product is touched
+func (op PriceOperation) Validate(value float64) error {
+	switch op {
+	case PriceOpSet:
+		return ValidatePrice(value)
+	case PriceOpIncreasePct:
+		if math.IsNaN(value) || value <= 0 {
+			return ErrInvalidPriceAdjustment
+		}
+	case PriceOpDecreasePct:
+		if math.IsNaN(value) || value <= 0 || value >= 100 {
+			return ErrInvalidPriceAdjustment
+		}
+	default:
+		return ErrInvalidPriceOperation
+	}
+	return nil
+}
+
+// Apply computes the new price for the operation, rounded to cents
+func (op PriceOperation) Apply(current, value float64) (float64, error) {
+	var price float64
+	switch op {
+	case PriceOpSet:
+		price = value
+	case PriceOpIncreasePct:
+		price = current * (1 + value/100)
+	case PriceOpDecreasePct:
+		price = current * (1 - value/100)
+	default:
+		return 0, ErrInvalidPriceOperation
+	}
+	return math.Round(price*100) / 100, nil
+}
@@ -1,36 +1,58 @@
 package product
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
-// Product represents a marketplace product listing
+// ErrNotFound is returned when no product matches a lookup
+var ErrNotFound = errors.New("product not found")
+
+// ErrNotOwner is returned when a seller changes a product they did not list
+var ErrNotOwner = errors.New("product belongs to another seller")
+
+// ErrInUse is returned when a product cannot be deleted because other
+// records, such as order items, still reference it
+var ErrInUse = errors.New("product is still referenced and cannot be deleted")
+
+// Product represents a marketplace product listing. DeletedAt is set once the
+// product is soft-deleted; the row is kept so cart and order items still resolve.
+// ImageVariants lists the stored copies of the images that have any.
 type Product struct {
-	ID          string    `json:"id"`
-	SellerID    string    `json:"seller_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Quantity    int       `json:"quantity"`
-	Images      []string  `json:"images"`
-	CategoryID  string    `json:"category_id"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            string           `json:"id"`
+	SellerID      string           `json:"seller_id"`
+	Title         string           `json:"title"`
+	Slug          string           `json:"slug"`
+	Description   string           `json:"description"`
+	Price         float64          `json:"price"`
+	Quantity      int              `json:"quantity"`
+	Images        []string         `json:"images"`
+	ImageVariants []*ImageVariants `json:"image_variants,omitempty"`
+	CategoryID    string           `json:"category_id"`
+	IsActive      bool             `json:"is_active"`
+	DeletedAt     *time.Time       `json:"deleted_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
 }
 
 // ProductWithCategory represents a product with its category details
 type ProductWithCategory struct {
-	ID          string    `json:"id"`
-	SellerID    string    `json:"seller_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Quantity    int       `json:"quantity"`
-	Images      []string  `json:"images"`
-	CategoryID  string    `json:"category_id"`
-	Category    *Category `json:"category,omitempty"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                string           `json:"id"`
+	SellerID          string           `json:"seller_id"`
+	Title             string           `json:"title"`
+	Slug              string           `json:"slug"`
+	Description       string           `json:"description"`
+	Price             float64          `json:"price"`
+	Quantity          int              `json:"quantity"`
+	AvailableQuantity int              `json:"available_quantity"`
+	Images            []string         `json:"images"`
+	ImageVariants     []*ImageVariants `json:"image_variants,omitempty"`
+	CategoryID        string           `json:"category_id"`
+	Category          *Category        `json:"category,omitempty"`
+	IsActive          bool             `json:"is_active"`
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
 }
 
 // Category represents a product category
@@ -44,9 +66,33 @@ type Repository interface {
 	Create(product *Product) error
 	GetByID(id string) (*Product, error)
 	GetByIDWithCategory(id string) (*ProductWithCategory, error)
+	GetBySlug(slug string) (*Product, error)
+	// SlugExists reports whether another product than excludeID uses the slug
+	SlugExists(slug, excludeID string) (bool, error)
 	List(filters map[string]interface{}, page, pageSize int) ([]*Product, int, error)
 	ListWithCategory(filters map[string]interface{}, page, pageSize int, sortBy, sortOrder string) ([]*ProductWithCategory, int, error)
+	ListWithCategoryCursor(filters map[string]interface{}, cursor string, limit int, sortBy, sortOrder string) ([]*ProductWithCategory, string, error)
 	Update(product *Product) error
+	// SoftDelete deactivates the product and stamps deleted_at, keeping the row
+	SoftDelete(id string) error
+	// Delete removes the product row, returning ErrInUse while other records
+	// still reference it
 	Delete(id string) error
 	GetCategories() ([]*Category, error)
+	GetByIDs(ids []string) ([]*Product, error)
+	ListBySellerAndCategory(sellerID, categoryID string) ([]*Product, error)
+	ListBySeller(sellerID string, page, pageSize int) ([]*ProductWithCategory, int, error)
+	// UpdatePrices applies and records the changes in one transaction,
+	// returning ErrPriceChanged if a product's price is no longer OldPrice
+	UpdatePrices(changes []*PriceChange) error
+	// SaveImageVariants records the copies stored alongside an uploaded image,
+	// replacing any recorded for the same URL
+	SaveImageVariants(variants *ImageVariants) error
+	// DeleteImageVariants forgets the copies recorded for the image URLs
+	DeleteImageVariants(urls []string) error
+	// RecordImageUploads records the seller as the uploader of the image URLs
+	RecordImageUploads(sellerID string, urls []string) error
+	// UploadedImages returns those of the image URLs the seller uploaded, in
+	// their original order
+	UploadedImages(sellerID string, urls []string) ([]string, error)
 }
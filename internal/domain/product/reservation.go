@@ -0,0 +1,61 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInsufficientStock is returned when a reservation asks for more units
+// than remain unreserved
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// InsufficientStockError identifies the product behind ErrInsufficientStock
+// and how many units could still be reserved
+type InsufficientStockError struct {
+	ProductID string
+	Available int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %s: %d available", e.ProductID, e.Available)
+}
+
+// Unwrap lets errors.Is match ErrInsufficientStock
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// Reservation holds units of a product for a cart until it expires
+type Reservation struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	CartID    string    `json:"cart_id"`
+	Quantity  int       `json:"quantity"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReservationRepository defines the interface for inventory reservation data
+type ReservationRepository interface {
+	// ReservedQuantities returns the units held by unexpired reservations,
+	// keyed by product ID. Products without reservations are omitted.
+	ReservedQuantities(productIDs []string) (map[string]int, error)
+	// Reserve atomically adds r.Quantity to the cart's hold on the product and
+	// extends it to r.ExpiresAt, returning an *InsufficientStockError when the
+	// unreserved stock cannot cover it
+	Reserve(r *Reservation) error
+	// Release drops the cart's hold on the product
+	Release(productID, cartID string) error
+	// Unreserve takes quantity units back off the cart's hold on the
+	// product, dropping the hold when none remain
+	Unreserve(productID, cartID string, quantity int) error
+}
+
+// AvailableQuantity returns the sellable stock once reserved units are held back
+func AvailableQuantity(quantity, reserved int) int {
+	if reserved >= quantity {
+		return 0
+	}
+	return quantity - reserved
+}
@@ -0,0 +1,67 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrSlugTaken is returned when saving a product whose slug another product
+// claimed after it was checked
+var ErrSlugTaken = errors.New("slug is already used by another product")
+
+// maxSlugLength bounds the base slug so collision suffixes still fit the column
+const maxSlugLength = 80
+
+// Slugify converts a title into a URL-friendly slug of lowercase ASCII
+// letters, digits and single hyphens. Accents are stripped; titles with no
+// usable characters fall back to "product".
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFKD.String(title) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+			hyphen = false
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks left by decomposing accented letters
+		default:
+			if b.Len() > 0 && !hyphen {
+				b.WriteByte('-')
+				hyphen = true
+			}
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return "product"
+	}
+	return slug
+}
+
+// UniqueSlug returns base, or base with the lowest numeric suffix (-2, -3, ...)
+// for which taken reports false
+func UniqueSlug(base string, taken func(slug string) (bool, error)) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		exists, err := taken(slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
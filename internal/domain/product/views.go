@@ -0,0 +1,37 @@
+package product
+
+import "context"
+
+// ViewCounter buffers product detail views, counting each viewer at most
+// once per debounce window
+type ViewCounter interface {
+	// RecordView counts a view of the product unless the same viewer already
+	// viewed it within the window, and reports whether it was counted
+	RecordView(ctx context.Context, productID, viewer string) (bool, error)
+	// DrainViews returns the views counted since the last drain and resets them
+	DrainViews(ctx context.Context) (map[string]int64, error)
+	// RestoreViews puts drained counts back, for when they could not be stored
+	RestoreViews(ctx context.Context, counts map[string]int64) error
+}
+
+// ViewRepository stores flushed view totals
+type ViewRepository interface {
+	// AddViews adds the counts to each product's stored total
+	AddViews(counts map[string]int64) error
+	// GetSellerViews returns the view totals of the seller's products, most
+	// viewed first
+	GetSellerViews(sellerID string) ([]*ProductViews, error)
+}
+
+// ProductViews is the number of detail views of one product
+type ProductViews struct {
+	ProductID string `json:"product_id"`
+	Title     string `json:"title"`
+	Views     int64  `json:"views"`
+}
+
+// SellerStats summarises how often a seller's products are viewed
+type SellerStats struct {
+	TotalViews int64           `json:"total_views"`
+	Products   []*ProductViews `json:"products"`
+}
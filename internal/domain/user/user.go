@@ -1,6 +1,9 @@
 package user
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // Role represents user roles in the system
 type Role string
@@ -11,6 +14,18 @@ const (
 	RoleAdmin    Role = "admin"
 )
 
+// ErrInvalidRole is returned for a role the system does not know
+var ErrInvalidRole = errors.New("unknown role")
+
+// ValidRole reports whether r is a known role
+func ValidRole(r Role) bool {
+	switch r {
+	case RoleCustomer, RoleSeller, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
 	ID            string    `json:"id"`
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidBucket is returned for an unsupported aggregation bucket
+	ErrInvalidBucket = errors.New("bucket must be one of day, week, month")
+	// ErrInvalidRange is returned when a time series range is empty or too long
+	ErrInvalidRange = errors.New("invalid time range")
+)
+
+// MaxSeriesPoints caps how many periods a single time series may span
+const MaxSeriesPoints = 366
+
+// Bucket is the period transaction aggregates are grouped by
+type Bucket string
+
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// ParseBucket validates a bucket name
+func ParseBucket(s string) (Bucket, error) {
+	switch b := Bucket(s); b {
+	case BucketDay, BucketWeek, BucketMonth:
+		return b, nil
+	}
+	return "", ErrInvalidBucket
+}
+
+// Truncate returns the start of the UTC period containing t. Weeks start on
+// Monday, matching PostgreSQL's date_trunc.
+func (b Bucket) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch b {
+	case BucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the period after the one beginning at start
+func (b Bucket) Next(start time.Time) time.Time {
+	switch b {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// TransactionAggregate sums a wallet's inflow and outflow over one period
+type TransactionAggregate struct {
+	PeriodStart time.Time `json:"period_start"`
+	Credit      float64   `json:"credit"`
+	Debit       float64   `json:"debit"`
+	Count       int       `json:"count"`
+}
@@ -0,0 +1,38 @@
+package wallet
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsupportedCurrency is returned for currencies the marketplace cannot price
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// ErrRateUnavailable is returned when no exchange rate source is configured
+var ErrRateUnavailable = errors.New("exchange rates unavailable")
+
+// PriceOracle quotes exchange rates between supported currencies
+type PriceOracle interface {
+	// Rate returns how many units of to one unit of from is worth
+	Rate(from, to Currency) (float64, error)
+}
+
+// Conversion is a wallet balance expressed in another currency for display;
+// the stored balance is never changed
+type Conversion struct {
+	From      Currency `json:"from"`
+	To        Currency `json:"to"`
+	Balance   float64  `json:"balance"`
+	Rate      float64  `json:"rate"`
+	Converted float64  `json:"converted"`
+}
+
+// ParseCurrency validates a currency code, ignoring case
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	switch c {
+	case CurrencyJAM, CurrencyUSD, CurrencyUSDC:
+		return c, nil
+	}
+	return "", ErrUnsupportedCurrency
+}
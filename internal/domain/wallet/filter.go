@@ -0,0 +1,63 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+)
+
+var (
+	// ErrInvalidTransactionType is returned for an unknown transaction type
+	ErrInvalidTransactionType = errors.New("type must be one of credit, debit")
+	// ErrInvalidTransactionStatus is returned for an unknown transaction status
+	ErrInvalidTransactionStatus = errors.New("status must be one of pending, success, failed")
+)
+
+// ParseTransactionType validates a transaction type
+func ParseTransactionType(s string) (TransactionType, error) {
+	switch t := TransactionType(s); t {
+	case TransactionTypeCredit, TransactionTypeDebit:
+		return t, nil
+	}
+	return "", ErrInvalidTransactionType
+}
+
+// ParseTransactionStatus validates a transaction status
+func ParseTransactionStatus(s string) (TransactionStatus, error) {
+	switch st := TransactionStatus(s); st {
+	case TransactionStatusPending, TransactionStatusSuccess, TransactionStatusFailed:
+		return st, nil
+	}
+	return "", ErrInvalidTransactionStatus
+}
+
+// TransactionFilter narrows a wallet's transaction history. Zero fields are
+// not applied.
+type TransactionFilter struct {
+	Type    TransactionType
+	Status  TransactionStatus
+	Created filter.DateRangeFilter
+}
+
+// Validate rejects a range whose start is not before its end
+func (f TransactionFilter) Validate() error {
+	if f.Created.Validate() != nil {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// Matches reports whether tx passes the filter
+func (f TransactionFilter) Matches(tx *Transaction) bool {
+	switch {
+	case f.Type != "" && tx.Type != f.Type:
+		return false
+	case f.Status != "" && tx.Status != f.Status:
+		return false
+	case f.Created.After != nil && tx.CreatedAt.Before(*f.Created.After):
+		return false
+	case f.Created.Before != nil && !tx.CreatedAt.Before(*f.Created.Before):
+		return false
+	}
+	return true
+}
@@ -1,6 +1,53 @@
 package wallet
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when the user has no wallet
+var ErrNotFound = errors.New("wallet not found")
+
+// ErrWalletExists is returned when creating a wallet for a user who already
+// has one
+var ErrWalletExists = errors.New("wallet already exists")
+
+// ErrInsufficientBalance is returned when a debit exceeds the wallet balance
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ErrSelfTransfer is returned for a transfer to the sender's own wallet
+var ErrSelfTransfer = errors.New("cannot transfer to your own wallet")
+
+// ErrCurrencyMismatch is returned for a transfer between wallets holding
+// different currencies
+var ErrCurrencyMismatch = errors.New("wallets hold different currencies")
+
+// ErrInvalidAmount is returned for a send, receive or transfer of zero or a
+// negative amount
+var ErrInvalidAmount = errors.New("amount must be greater than zero")
+
+// ErrDuplicateTransaction is returned when the wallet already has a
+// transaction with the same reference
+var ErrDuplicateTransaction = errors.New("duplicate transaction reference")
+
+// ErrDepositClaimed is returned when an on-chain transaction has already been
+// credited to another wallet
+var ErrDepositClaimed = errors.New("deposit already claimed by another wallet")
+
+// DuplicateTransactionError carries the transaction already recorded under
+// a reference, so a retried request can be answered with the original
+type DuplicateTransactionError struct {
+	Existing *Transaction
+}
+
+func (e *DuplicateTransactionError) Error() string {
+	return fmt.Sprintf("%s: %q", ErrDuplicateTransaction, e.Existing.Reference)
+}
+
+func (e *DuplicateTransactionError) Unwrap() error {
+	return ErrDuplicateTransaction
+}
 
 // Currency represents supported currencies
 type Currency string
@@ -11,6 +58,9 @@ const (
 	CurrencyUSDC Currency = "USDC"
 )
 
+// DefaultCurrency is the currency new wallets hold when none is configured
+const DefaultCurrency = CurrencyJAM
+
 // Wallet represents a user's wallet
 type Wallet struct {
 	ID        string    `json:"id"`
@@ -46,6 +96,9 @@ type Transaction struct {
 	Reference string            `json:"reference"`
 	Status    TransactionStatus `json:"status"`
 	CreatedAt time.Time         `json:"created_at"`
+	// TransferID is shared by the debit and credit of a wallet-to-wallet
+	// transfer
+	TransferID string `json:"transfer_id,omitempty"`
 	// Blockchain specific fields
 	TxHash  string `json:"tx_hash,omitempty"`
 	ChainID int64  `json:"chain_id,omitempty"`
@@ -56,7 +109,35 @@ type Transaction struct {
 // Repository defines the interface for wallet data operations
 type Repository interface {
 	GetByUserID(userID string) (*Wallet, error)
+	// Create stores a new wallet, returning ErrWalletExists if the user
+	// already has one
+	Create(w *Wallet) error
+	// CreateTransaction records tx, returning a *DuplicateTransactionError if
+	// the wallet already has a transaction with the same non-empty reference
 	CreateTransaction(tx *Transaction) error
-	GetTransactions(walletID string, page, pageSize int) ([]*Transaction, int, error)
+	// GetTransactions returns a page of the wallet's transactions passing
+	// filter, newest first, with the total number that pass it
+	GetTransactions(walletID string, filter TransactionFilter, page, pageSize int) ([]*Transaction, int, error)
+	// GetTransactionAggregates sums non-failed transactions created in
+	// [from, to) per bucket, returning only periods that have transactions
+	GetTransactionAggregates(walletID string, from, to time.Time, bucket Bucket) ([]*TransactionAggregate, error)
 	UpdateBalance(walletID string, amount float64) error
+	// Debit subtracts tx.Amount from tx.WalletID and records tx in one
+	// atomic step, returning ErrInsufficientBalance if the balance does not
+	// cover it. tx.Status is set to success once the debit has committed.
+	// A reference already used by the wallet leaves the balance untouched and
+	// returns a *DuplicateTransactionError.
+	Debit(tx *Transaction) error
+	// Credit records tx and adds tx.Amount to tx.WalletID in one atomic
+	// step, setting tx.Status to success once it has committed. A reference
+	// already used by the wallet leaves the balance untouched and returns a
+	// *DuplicateTransactionError; an on-chain transaction already credited to
+	// another wallet returns ErrDepositClaimed.
+	Credit(tx *Transaction) error
+	// Transfer moves debit.Amount from debit.WalletID to credit.WalletID and
+	// records both transactions in one atomic step. It fails like Debit, with
+	// ErrInsufficientBalance or a *DuplicateTransactionError for the debit's
+	// reference, and moves nothing when it does. Both statuses are set to
+	// success once the transfer has committed.
+	Transfer(debit, credit *Transaction) error
 }
@@ -2,9 +2,11 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,12 +28,34 @@ func (r *cartRepository) GetByUserID(userID string) (*cart.Cart, error) {
 	var c cart.Cart
 	err := r.db.QueryRow(context.Background(), query, userID).Scan(
 		&c.ID, &c.UserID, &c.Status, &c.Total, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, cart.ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cart by user id: %w", err)
 	}
 	return &c, nil
 }
 
+func (r *cartRepository) CreateCart(c *cart.Cart) error {
+	// The partial unique index on active carts makes a concurrent create for
+	// the same user a no-op rather than a second active cart
+	query := `
+		INSERT INTO carts (id, user_id, status, total, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) WHERE status = 'active' DO NOTHING
+	`
+	tag, err := r.db.Exec(context.Background(), query,
+		c.ID, c.UserID, c.Status, c.Total, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cart: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return cart.ErrActiveCartExists
+	}
+	return nil
+}
+
 func (r *cartRepository) GetItems(cartID string) ([]*cart.CartItem, error) {
 	query := `
 		SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
@@ -56,6 +80,23 @@ func (r *cartRepository) GetItems(cartID string) ([]*cart.CartItem, error) {
 	return items, nil
 }
 
+func (r *cartRepository) GetItemByID(itemID string) (*cart.CartItem, error) {
+	query := `
+		SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
+		FROM cart_items WHERE id = $1
+	`
+	var item cart.CartItem
+	err := r.db.QueryRow(context.Background(), query, itemID).Scan(
+		&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, cart.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart item: %w", err)
+	}
+	return &item, nil
+}
+
 func (r *cartRepository) AddItem(item *cart.CartItem) error {
 	query := `
 		INSERT INTO cart_items (id, cart_id, product_id, quantity, price, created_at, updated_at)
@@ -85,14 +126,26 @@ func (r *cartRepository) RemoveItem(itemID string) error {
 	return err
 }
 
-func (r *cartRepository) UpdateTotal(cartID string, total float64) error {
+func (r *cartRepository) ClearItems(cartID string) error {
+	query := `DELETE FROM cart_items WHERE cart_id = $1`
+	_, err := r.db.Exec(context.Background(), query, cartID)
+	return err
+}
+
+func (r *cartRepository) RecalculateTotal(cartID string) (float64, error) {
 	query := `
-		UPDATE carts 
-		SET total = $1, updated_at = NOW()
-		WHERE id = $2
+		UPDATE carts
+		SET total = COALESCE((SELECT SUM(price * quantity) FROM cart_items WHERE cart_id = $1), 0),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING total
 	`
-	_, err := r.db.Exec(context.Background(), query, total, cartID)
-	return err
+	var total float64
+	err := r.db.QueryRow(context.Background(), query, cartID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recalculate cart total: %w", err)
+	}
+	return total, nil
 }
 
 func (r *cartRepository) SetStatus(cartID string, status cart.CartStatus) error {
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type checkoutRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewCheckoutRepository creates a new checkout repository
+func NewCheckoutRepository(db *pgxpool.Pool) checkout.Repository {
+	return &checkoutRepository{db: db}
+}
+
+func (r *checkoutRepository) WithinTx(fn func(tx checkout.Tx) error) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&checkoutTx{ctx: ctx, tx: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit checkout: %w", err)
+	}
+	return nil
+}
+
+// checkoutTx implements checkout.Tx on top of an open pgx transaction
+type checkoutTx struct {
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+func (t *checkoutTx) GetCart(cartID string) (*cart.Cart, error) {
+	query := `
+		SELECT id, user_id, status, total, created_at, updated_at
+		FROM carts WHERE id = $1
+		FOR UPDATE
+	`
+	var c cart.Cart
+	err := t.tx.QueryRow(t.ctx, query, cartID).Scan(
+		&c.ID, &c.UserID, &c.Status, &c.Total, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, checkout.ErrCartNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cart: %w", err)
+	}
+	return &c, nil
+}
+
+func (t *checkoutTx) GetCartItems(cartID string) ([]*cart.CartItem, error) {
+	query := `
+		SELECT id, cart_id, product_id, quantity, price, created_at, updated_at
+		FROM cart_items WHERE cart_id = $1
+	`
+	rows, err := t.tx.Query(t.ctx, query, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cart items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*cart.CartItem
+	for rows.Next() {
+		var item cart.CartItem
+		err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+func (t *checkoutTx) CreateOrder(o *order.Order) error {
+	query := `
+		INSERT INTO orders (id, user_id, cart_id, status, total, payment_ref, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := t.tx.Exec(t.ctx, query,
+		o.ID, o.UserID, o.CartID, o.Status, o.Total, o.PaymentRef, o.CreatedAt, o.UpdatedAt)
+	if violatesConstraint(err, orderPaymentRefIndex) {
+		return order.ErrDuplicatePaymentRef
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	return nil
+}
+
+func (t *checkoutTx) CreateOrderItems(items []*order.OrderItem) error {
+	// Snapshot the product title so receipts survive the product going away
+	query := `
+		INSERT INTO order_items (id, order_id, product_id, quantity, price, status, title)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			COALESCE(NULLIF($7, ''), (SELECT title FROM products WHERE id = $3), ''))
+	`
+	for _, item := range items {
+		_, err := t.tx.Exec(t.ctx, query,
+			item.ID, item.OrderID, item.ProductID, item.Quantity, item.Price, item.Status, item.Title)
+		if err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *checkoutTx) DecrementStock(productID string, quantity int) error {
+	query := `
+		UPDATE products
+		SET quantity = quantity - $1, updated_at = NOW()
+		WHERE id = $2 AND quantity >= $1
+		RETURNING quantity
+	`
+	var remaining int
+	err := t.tx.QueryRow(t.ctx, query, quantity, productID).Scan(&remaining)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var available int
+		if err := t.tx.QueryRow(t.ctx, `SELECT quantity FROM products WHERE id = $1`, productID).Scan(&available); err != nil {
+			return product.ErrNotFound
+		}
+		return &product.InsufficientStockError{ProductID: productID, Available: available}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decrement stock: %w", err)
+	}
+	return nil
+}
+
+func (t *checkoutTx) SetCartStatus(cartID string, status cart.CartStatus) error {
+	query := `UPDATE carts SET status = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := t.tx.Exec(t.ctx, query, status, cartID); err != nil {
+		return fmt.Errorf("failed to update cart status: %w", err)
+	}
+	return nil
+}
+
+func (t *checkoutTx) ReleaseReservations(cartID string) error {
+	query := `DELETE FROM product_reservations WHERE cart_id = $1`
+	if _, err := t.tx.Exec(t.ctx, query, cartID); err != nil {
+		return fmt.Errorf("failed to release reservations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type adjustmentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAdjustmentRepository creates a new inventory adjustment repository
+func NewAdjustmentRepository(db *pgxpool.Pool) product.AdjustmentRepository {
+	return &adjustmentRepository{db: db}
+}
+
+func (r *adjustmentRepository) UpdateWithAdjustment(p *product.Product, userID, reason string) error {
+	ctx := context.Background()
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	// Locking the row means concurrent updates are audited against the
+	// quantity they actually replace
+	var previous int
+	err = dbTx.QueryRow(ctx, `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`, p.ID).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return product.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	if _, err := dbTx.Exec(ctx, updateProductQuery, updateProductArgs(p)...); err != nil {
+		if violatesConstraint(err, productSlugIndex) {
+			return product.ErrSlugTaken
+		}
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if delta := p.Quantity - previous; delta != 0 {
+		_, err = dbTx.Exec(ctx, `
+			INSERT INTO inventory_adjustments (id, product_id, user_id, delta, reason, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, uuid.New().String(), p.ID, userID, delta, reason, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to log inventory adjustment: %w", err)
+		}
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit inventory adjustment: %w", err)
+	}
+	return nil
+}
+
+func (r *adjustmentRepository) ListAdjustments(productID string, page, pageSize int) ([]*product.InventoryAdjustment, int, error) {
+	ctx := context.Background()
+
+	var total int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM inventory_adjustments WHERE product_id = $1`, productID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count inventory adjustments: %w", err)
+	}
+
+	query := `
+		SELECT id, product_id, user_id, delta, reason, created_at
+		FROM inventory_adjustments
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, productID, pageSize, pagination.Params{Page: page, PageSize: pageSize}.Offset())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query inventory adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adjustments := []*product.InventoryAdjustment{}
+	for rows.Next() {
+		var a product.InventoryAdjustment
+		if err := rows.Scan(&a.ID, &a.ProductID, &a.UserID, &a.Delta, &a.Reason, &a.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan inventory adjustment: %w", err)
+		}
+		adjustments = append(adjustments, &a)
+	}
+
+	return adjustments, total, rows.Err()
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/notification"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type notificationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationRepository creates a new webhook subscription repository
+func NewNotificationRepository(db *pgxpool.Pool) notification.Repository {
+	return &notificationRepository{db: db}
+}
+
+const webhookColumns = `id, user_id, url, events, secret, active, created_at, updated_at`
+
+func (r *notificationRepository) Create(w *notification.Webhook) error {
+	query := `
+		INSERT INTO webhooks (` + webhookColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(context.Background(), query,
+		w.ID, w.UserID, w.URL, w.Events, w.Secret, w.Active, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) ListByUser(userID string) ([]*notification.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return scanWebhooks(rows)
+}
+
+func (r *notificationRepository) ListSubscribed(userIDs []string, event string) ([]*notification.Webhook, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT ` + webhookColumns + ` FROM webhooks
+		WHERE user_id = ANY($1::uuid[]) AND active AND $2 = ANY(events)
+	`
+	rows, err := r.db.Query(context.Background(), query, userIDs, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscribed webhooks: %w", err)
+	}
+	return scanWebhooks(rows)
+}
+
+func (r *notificationRepository) Delete(id, userID string) error {
+	tag, err := r.db.Exec(context.Background(), `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return notification.ErrNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) RecordDelivery(d *notification.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, attempt, status_code, error, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, ''), $8, $9)
+	`
+	_, err := r.db.Exec(context.Background(), query,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.Attempt, d.StatusCode, d.Error, d.DurationMS, d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func scanWebhooks(rows pgx.Rows) ([]*notification.Webhook, error) {
+	defer rows.Close()
+
+	var webhooks []*notification.Webhook
+	for rows.Next() {
+		var w notification.Webhook
+		if err := rows.Scan(&w.ID, &w.UserID, &w.URL, &w.Events, &w.Secret, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, &w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks: %w", err)
+	}
+	return webhooks, nil
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// orderPaymentRefIndex is the unique index on orders.payment_ref
+const orderPaymentRefIndex = "idx_orders_payment_ref"
+
 type orderRepository struct {
 	db *pgxpool.Pool
 }
@@ -24,6 +28,9 @@ func (r *orderRepository) Create(o *order.Order) error {
 	`
 	_, err := r.db.Exec(context.Background(), query,
 		o.ID, o.UserID, o.CartID, o.Status, o.Total, o.PaymentRef, o.CreatedAt, o.UpdatedAt)
+	if violatesConstraint(err, orderPaymentRefIndex) {
+		return order.ErrDuplicatePaymentRef
+	}
 	return err
 }
 
@@ -41,8 +48,44 @@ func (r *orderRepository) GetByID(id string) (*order.Order, error) {
 	return &o, nil
 }
 
+func (r *orderRepository) GetByPaymentRef(paymentRef string) (*order.Order, error) {
+	query := `
+		SELECT id, user_id, cart_id, status, total, payment_ref, created_at, updated_at
+		FROM orders WHERE payment_ref = $1
+		LIMIT 2
+	`
+	rows, err := r.db.Query(context.Background(), query, paymentRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order by payment ref: %w", err)
+	}
+	defer rows.Close()
+
+	var found []*order.Order
+	for rows.Next() {
+		var o order.Order
+		err := rows.Scan(&o.ID, &o.UserID, &o.CartID, &o.Status, &o.Total, &o.PaymentRef, &o.CreatedAt, &o.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		found = append(found, &o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get order by payment ref: %w", err)
+	}
+
+	// The unique index rules this out; refuse to guess if it is ever missing
+	switch len(found) {
+	case 0:
+		return nil, order.ErrNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return nil, order.ErrDuplicatePaymentRef
+	}
+}
+
 func (r *orderRepository) GetByUserID(userID string, page, pageSize int) ([]*order.Order, int, error) {
-	offset := (page - 1) * pageSize
+	offset := pagination.Params{Page: page, PageSize: pageSize}.Offset()
 
 	// Get total count
 	var total int
@@ -81,7 +124,7 @@ func (r *orderRepository) GetByUserID(userID string, page, pageSize int) ([]*ord
 
 func (r *orderRepository) GetItems(orderID string) ([]*order.OrderItem, error) {
 	query := `
-		SELECT id, order_id, product_id, quantity, price
+		SELECT id, order_id, product_id, quantity, price, status, title
 		FROM order_items WHERE order_id = $1
 	`
 	rows, err := r.db.Query(context.Background(), query, orderID)
@@ -93,7 +136,7 @@ func (r *orderRepository) GetItems(orderID string) ([]*order.OrderItem, error) {
 	var items []*order.OrderItem
 	for rows.Next() {
 		var item order.OrderItem
-		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price)
+		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.Status, &item.Title)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
 		}
@@ -103,12 +146,99 @@ func (r *orderRepository) GetItems(orderID string) ([]*order.OrderItem, error) {
 	return items, nil
 }
 
-func (r *orderRepository) UpdateStatus(orderID string, status order.OrderStatus) error {
+func (r *orderRepository) GetItemsWithProduct(orderID string) ([]*order.OrderItemWithProduct, error) {
+	// Price comes from the order item so receipts keep the price at purchase
+	query := `
+		SELECT oi.id, oi.order_id, oi.product_id, oi.quantity, oi.price, oi.status,
+		       COALESCE(NULLIF(p.title, ''), oi.title),
+		       COALESCE(p.images, ARRAY[]::TEXT[]),
+		       COALESCE(p.is_active AND p.deleted_at IS NULL, false)
+		FROM order_items oi
+		LEFT JOIN products p ON p.id = oi.product_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.created_at, oi.id
+	`
+	rows, err := r.db.Query(context.Background(), query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*order.OrderItemWithProduct
+	for rows.Next() {
+		var item order.OrderItemWithProduct
+		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.Status,
+			&item.Title, &item.Images, &item.ProductAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *orderRepository) UpdateStatus(orderID string, from, to order.OrderStatus) error {
 	query := `
 		UPDATE orders 
 		SET status = $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND status = $3
 	`
-	_, err := r.db.Exec(context.Background(), query, status, orderID)
-	return err
+	tag, err := r.db.Exec(context.Background(), query, to, orderID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return order.ErrStatusConflict
+	}
+	return nil
+}
+
+func (r *orderRepository) UpdateItemsStatus(orderID string, itemIDs []string, status order.ItemStatus) error {
+	query := `
+		UPDATE order_items
+		SET status = $1
+		WHERE order_id = $2 AND id = ANY($3)
+	`
+	_, err := r.db.Exec(context.Background(), query, status, orderID, itemIDs)
+	if err != nil {
+		return fmt.Errorf("failed to update order item status: %w", err)
+	}
+	return nil
+}
+
+func (r *orderRepository) ApplyPaymentEvent(e *order.PaymentEvent) (bool, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A redelivered event conflicts on its ID and leaves the order alone
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO payment_events (id, order_id, type, amount, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO NOTHING
+	`, e.ID, e.OrderID, e.Type, e.Amount, e.ReceivedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record payment event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, order.ErrDuplicatePaymentEvent
+	}
+
+	tag, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`, order.OrderStatusPaid, e.OrderID, order.OrderStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark order paid: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit payment event: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
 }
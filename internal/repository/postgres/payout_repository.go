@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type payoutRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPayoutRepository creates a new payout repository
+func NewPayoutRepository(db *pgxpool.Pool) payout.Repository {
+	return &payoutRepository{db: db}
+}
+
+func (r *payoutRepository) Accrue(payouts []*payout.Payout) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertPayouts(ctx, tx, payouts); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *payoutRepository) CompleteOrder(orderID string, payouts []*payout.Payout) (bool, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Updating the order locks its row, so concurrent completions of the same
+	// order see each other's payouts below. Only a shipped order completes.
+	tag, err := tx.Exec(ctx, `
+		UPDATE orders
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`, order.OrderStatusCompleted, orderID, order.OrderStatusShipped)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, order.ErrStatusConflict
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM payouts WHERE order_id = $1)`, orderID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check payouts for order: %w", err)
+	}
+	if !exists {
+		if err := insertPayouts(ctx, tx, payouts); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return !exists, nil
+}
+
+// insertPayouts writes payouts within tx, returning payout.ErrAlreadyAccrued
+// if a seller already has an entry for the order
+func insertPayouts(ctx context.Context, tx pgx.Tx, payouts []*payout.Payout) error {
+	query := `
+		INSERT INTO payouts (id, seller_id, order_id, gross, fee, net, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for _, p := range payouts {
+		_, err := tx.Exec(ctx, query,
+			p.ID, p.SellerID, p.OrderID, p.Gross, p.Fee, p.Net, p.Status, p.CreatedAt, p.UpdatedAt)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return payout.ErrAlreadyAccrued
+			}
+			return fmt.Errorf("failed to insert payout: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *payoutRepository) ExistsForOrder(orderID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM payouts WHERE order_id = $1)`
+	var exists bool
+	err := r.db.QueryRow(context.Background(), query, orderID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check payouts for order: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *payoutRepository) GetByID(id string) (*payout.Payout, error) {
+	query := `
+		SELECT id, seller_id, order_id, gross, fee, net, status, paid_at, created_at, updated_at
+		FROM payouts WHERE id = $1
+	`
+	var p payout.Payout
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&p.ID, &p.SellerID, &p.OrderID, &p.Gross, &p.Fee, &p.Net, &p.Status, &p.PaidAt, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, payout.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout by id: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *payoutRepository) GetEarnings(sellerID string) (*payout.Earnings, error) {
+	query := `
+		SELECT COALESCE(SUM(net) FILTER (WHERE status = 'pending'), 0),
+		       COALESCE(SUM(net) FILTER (WHERE status = 'paid'), 0)
+		FROM payouts WHERE seller_id = $1
+	`
+	e := payout.Earnings{SellerID: sellerID}
+	err := r.db.QueryRow(context.Background(), query, sellerID).Scan(&e.Pending, &e.Paid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller earnings: %w", err)
+	}
+	e.Total = e.Pending + e.Paid
+	return &e, nil
+}
+
+func (r *payoutRepository) MarkPaid(id string, paidAt time.Time) error {
+	query := `
+		UPDATE payouts
+		SET status = 'paid', paid_at = $1, updated_at = $1
+		WHERE id = $2 AND status = 'pending'
+	`
+	tag, err := r.db.Exec(context.Background(), query, paidAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark payout paid: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return payout.ErrAlreadyPaid
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a Postgres foreign key
+// violation, e.g. deleting a row that is still referenced
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
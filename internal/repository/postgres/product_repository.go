@@ -2,61 +2,114 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type productRepository struct {
 	db *pgxpool.Pool
+	// readDB serves listing, search and public lookups; it is db when no
+	// read replica is configured
+	readDB *pgxpool.Pool
 }
 
-// NewProductRepository creates a new product repository
-func NewProductRepository(db *pgxpool.Pool) product.Repository {
-	return &productRepository{db: db}
+// NewProductRepository creates a new product repository. Read-only listing
+// and lookup queries go to readDB when it is non-nil; everything else, and
+// every read that feeds a write, stays on the primary db.
+func NewProductRepository(db, readDB *pgxpool.Pool) product.Repository {
+	if readDB == nil {
+		readDB = db
+	}
+	return &productRepository{db: db, readDB: readDB}
 }
 
 func (r *productRepository) Create(p *product.Product) error {
 	query := `
-		INSERT INTO products (id, seller_id, title, description, price, quantity, images, category_id, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO products (id, seller_id, title, slug, description, price, quantity, images, category_id, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	_, err := r.db.Exec(context.Background(), query,
-		p.ID, p.SellerID, p.Title, p.Description, p.Price, p.Quantity, p.Images, p.CategoryID, p.IsActive, p.CreatedAt, p.UpdatedAt)
+		p.ID, p.SellerID, p.Title, p.Slug, p.Description, p.Price, p.Quantity, p.Images, p.CategoryID, p.IsActive, p.CreatedAt, p.UpdatedAt)
+	if violatesConstraint(err, productSlugIndex) {
+		return product.ErrSlugTaken
+	}
 	return err
 }
 
+// productSlugIndex keeps product slugs unique
+const productSlugIndex = "idx_products_slug"
+
+// GetByID reads from the primary: updates, ownership checks, stock
+// reservations and checkout all load the product through it before writing.
 func (r *productRepository) GetByID(id string) (*product.Product, error) {
-	query := `
-		SELECT id, seller_id, title, description, price, quantity, images, category_id, is_active, created_at, updated_at
-		FROM products WHERE id = $1
-	`
+	query := fmt.Sprintf(`
+		SELECT p.id, p.seller_id, p.title, p.slug, p.description, p.price, p.quantity, p.images, p.category_id, p.is_active, p.deleted_at, p.created_at, p.updated_at,
+		       %s
+		FROM products p WHERE p.id = $1
+	`, imageVariantsColumn)
 	var p product.Product
 	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&p.ID, &p.SellerID, &p.Title, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+		&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt,
+		&p.ImageVariants)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, product.ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product by id: %w", err)
 	}
 	return &p, nil
 }
 
-func (r *productRepository) GetByIDWithCategory(id string) (*product.ProductWithCategory, error) {
+func (r *productRepository) GetBySlug(slug string) (*product.Product, error) {
 	query := `
-		SELECT p.id, p.seller_id, p.title, p.description, p.price, p.quantity, p.images, 
-		       p.category_id, p.is_active, p.created_at, p.updated_at,
-		       c.id, c.name
+		SELECT id, seller_id, title, slug, description, price, quantity, images, category_id, is_active, deleted_at, created_at, updated_at
+		FROM products WHERE slug = $1
+	`
+	var p product.Product
+	err := r.readDB.QueryRow(context.Background(), query, slug).Scan(
+		&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, product.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by slug: %w", err)
+	}
+	return &p, nil
+}
+
+// SlugExists reads from the primary, since a lagging replica would let a new
+// product pick a slug that was just taken
+func (r *productRepository) SlugExists(slug, excludeID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE slug = $1 AND id::text <> $2)`
+	var exists bool
+	err := r.db.QueryRow(context.Background(), query, slug, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product slug: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *productRepository) GetByIDWithCategory(id string) (*product.ProductWithCategory, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
 		WHERE p.id = $1
-	`
+	`, productWithCategoryColumns)
 	var p product.ProductWithCategory
 	var categoryID, categoryName *string
 	
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&p.ID, &p.SellerID, &p.Title, &p.Description, &p.Price, &p.Quantity, &p.Images, 
-		&p.CategoryID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
-		&categoryID, &categoryName)
+	err := r.readDB.QueryRow(context.Background(), query, id).Scan(
+		&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images, 
+		&p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt,
+		&categoryID, &categoryName, &p.ImageVariants)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product by id: %w", err)
 	}
@@ -73,10 +126,10 @@ func (r *productRepository) GetByIDWithCategory(id string) (*product.ProductWith
 }
 
 func (r *productRepository) List(filters map[string]interface{}, page, pageSize int) ([]*product.Product, int, error) {
-	offset := (page - 1) * pageSize
+	offset := pagination.Params{Page: page, PageSize: pageSize}.Offset()
 
 	// Build query with filters
-	whereClause := "WHERE is_active = true"
+	whereClause := "WHERE is_active = true AND deleted_at IS NULL"
 	args := []interface{}{}
 	argCount := 1
 
@@ -93,17 +146,24 @@ func (r *productRepository) List(filters map[string]interface{}, page, pageSize
 		argCount++
 	}
 
+	if created, ok := filters["created"].(filter.DateRangeFilter); ok {
+		clause, rangeArgs := created.SQL("created_at", argCount)
+		whereClause += clause
+		args = append(args, rangeArgs...)
+		argCount += len(rangeArgs)
+	}
+
 	// Get total count
 	var total int
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereClause)
-	err := r.db.QueryRow(context.Background(), countQuery, args...).Scan(&total)
+	err := r.readDB.QueryRow(context.Background(), countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count products: %w", err)
 	}
 
 	// Get products
 	query := fmt.Sprintf(`
-		SELECT id, seller_id, title, description, price, quantity, images, category_id, is_active, created_at, updated_at
+		SELECT id, seller_id, title, slug, description, price, quantity, images, category_id, is_active, deleted_at, created_at, updated_at
 		FROM products
 		%s
 		ORDER BY created_at DESC
@@ -111,7 +171,7 @@ func (r *productRepository) List(filters map[string]interface{}, page, pageSize
 	`, whereClause, argCount, argCount+1)
 	args = append(args, pageSize, offset)
 
-	rows, err := r.db.Query(context.Background(), query, args...)
+	rows, err := r.readDB.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query products: %w", err)
 	}
@@ -120,7 +180,7 @@ func (r *productRepository) List(filters map[string]interface{}, page, pageSize
 	var products []*product.Product
 	for rows.Next() {
 		var p product.Product
-		err := rows.Scan(&p.ID, &p.SellerID, &p.Title, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
 		}
@@ -131,69 +191,184 @@ func (r *productRepository) List(filters map[string]interface{}, page, pageSize
 }
 
 func (r *productRepository) ListWithCategory(filters map[string]interface{}, page, pageSize int, sortBy, sortOrder string) ([]*product.ProductWithCategory, int, error) {
-	offset := (page - 1) * pageSize
+	offset := pagination.Params{Page: page, PageSize: pageSize}.Offset()
 
 	// Build query with filters
-	whereClause := "WHERE p.is_active = true"
-	args := []interface{}{}
-	argCount := 1
-
-	if categoryID, ok := filters["category_id"]; ok {
-		whereClause += fmt.Sprintf(" AND p.category_id = $%d", argCount)
-		args = append(args, categoryID)
-		argCount++
-	}
-
-	if search, ok := filters["search"]; ok {
-		whereClause += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.description ILIKE $%d)", argCount, argCount)
-		searchPattern := fmt.Sprintf("%%%s%%", search)
-		args = append(args, searchPattern)
-		argCount++
-	}
+	whereClause, args, tsQueryArg := listWithCategoryWhere(filters)
+	argCount := len(args) + 1
 
 	// Get total count
 	var total int
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products p %s", whereClause)
-	err := r.db.QueryRow(context.Background(), countQuery, args...).Scan(&total)
+	err := r.readDB.QueryRow(context.Background(), countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count products: %w", err)
 	}
 
 	// Build ORDER BY clause
-	orderByClause := "ORDER BY p.created_at DESC"
-	validSortFields := map[string]string{
-		"created_at": "p.created_at",
-		"updated_at": "p.updated_at",
-		"price":      "p.price",
-		"title":      "p.title",
-	}
-	
-	if sortBy != "" {
-		if dbField, ok := validSortFields[sortBy]; ok {
-			order := "DESC"
-			if sortOrder == "asc" || sortOrder == "ASC" {
-				order = "ASC"
-			}
-			orderByClause = fmt.Sprintf("ORDER BY %s %s", dbField, order)
-		}
+	field, desc := product.NormalizeSort(sortBy, sortOrder)
+	orderByClause := fmt.Sprintf("ORDER BY %s %s", sortColumns[field].column, sortDirection(desc))
+	if sortBy == product.SortByRelevance && tsQueryArg > 0 {
+		orderByClause = fmt.Sprintf("ORDER BY ts_rank(p.search_vector, to_tsquery('%s', $%d)) DESC, p.created_at DESC", searchConfig, tsQueryArg)
 	}
 
 	// Get products with category
 	query := fmt.Sprintf(`
-		SELECT p.id, p.seller_id, p.title, p.description, p.price, p.quantity, p.images, 
-		       p.category_id, p.is_active, p.created_at, p.updated_at,
-		       c.id, c.name
+		SELECT %s
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id
 		%s
 		%s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, orderByClause, argCount, argCount+1)
+	`, productWithCategoryColumns, whereClause, orderByClause, argCount, argCount+1)
 	args = append(args, pageSize, offset)
 
-	rows, err := r.db.Query(context.Background(), query, args...)
+	products, err := r.queryProductsWithCategory(r.readDB, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query products: %w", err)
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// sortColumns maps each sortable field to its column and the cast applied to
+// cursor values compared against it
+var sortColumns = map[string]struct{ column, cast string }{
+	"created_at": {"p.created_at", "timestamptz"},
+	"updated_at": {"p.updated_at", "timestamptz"},
+	"price":      {"p.price", "numeric"},
+	"title":      {"p.title", "text"},
+}
+
+func sortDirection(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// ListWithCategoryCursor pages through active products by keyset rather than
+// OFFSET, so pages stay stable while products are inserted. Rows are ordered
+// by the sort field with id as tie-breaker; the returned cursor is empty on
+// the last page.
+func (r *productRepository) ListWithCategoryCursor(filters map[string]interface{}, cursor string, limit int, sortBy, sortOrder string) ([]*product.ProductWithCategory, string, error) {
+	field, desc := product.NormalizeSort(sortBy, sortOrder)
+	sortCol := sortColumns[field]
+
+	whereClause, args, _ := listWithCategoryWhere(filters)
+	argCount := len(args) + 1
+
+	if cursor != "" {
+		c, err := product.DecodeCursor(cursor, field, desc)
+		if err != nil {
+			return nil, "", err
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		whereClause += fmt.Sprintf(" AND (%s, p.id) %s ($%d::%s, $%d::uuid)", sortCol.column, cmp, argCount, sortCol.cast, argCount+1)
+		args = append(args, c.Value, c.ID)
+		argCount += 2
+	}
+
+	dir := sortDirection(desc)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		%s
+		ORDER BY %s %s, p.id %s
+		LIMIT $%d
+	`, productWithCategoryColumns, whereClause, sortCol.column, dir, dir, argCount)
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, limit+1)
+
+	products, err := r.queryProductsWithCategory(r.readDB, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(products) <= limit {
+		return products, "", nil
+	}
+	products = products[:limit]
+	return products, product.NewCursor(products[limit-1], field, desc).Encode(), nil
+}
+
+// listWithCategoryWhere builds the WHERE clause shared by the category-joined
+// listings, returning it with its positional arguments. Searches run against
+// the full-text index; tsQueryArg is the position of the tsquery argument for
+// ranking, or 0 when the search fell back to ILIKE or there is none.
+func listWithCategoryWhere(filters map[string]interface{}) (whereClause string, args []interface{}, tsQueryArg int) {
+	whereClause = "WHERE p.is_active = true AND p.deleted_at IS NULL"
+	args = []interface{}{}
+	argCount := 1
+
+	if categoryID, ok := filters["category_id"]; ok {
+		whereClause += fmt.Sprintf(" AND p.category_id = $%d", argCount)
+		args = append(args, categoryID)
+		argCount++
+	}
+
+	if search, ok := filters["search"]; ok {
+		if query, ok := prefixTSQuery(fmt.Sprint(search)); ok {
+			whereClause += fmt.Sprintf(" AND p.search_vector @@ to_tsquery('%s', $%d)", searchConfig, argCount)
+			args = append(args, query)
+			tsQueryArg = argCount
+		} else {
+			whereClause += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.description ILIKE $%d)", argCount, argCount)
+			searchPattern := fmt.Sprintf("%%%s%%", search)
+			args = append(args, searchPattern)
+		}
+		argCount++
+	}
+
+	if sellerID, ok := filters["seller_id"]; ok {
+		whereClause += fmt.Sprintf(" AND p.seller_id = $%d", argCount)
+		args = append(args, sellerID)
+		argCount++
+	}
+
+	if minPrice, ok := filters["min_price"]; ok {
+		whereClause += fmt.Sprintf(" AND p.price >= $%d", argCount)
+		args = append(args, minPrice)
+		argCount++
+	}
+
+	if maxPrice, ok := filters["max_price"]; ok {
+		whereClause += fmt.Sprintf(" AND p.price <= $%d", argCount)
+		args = append(args, maxPrice)
+		argCount++
+	}
+
+	if inStock, _ := filters["in_stock"].(bool); inStock {
+		whereClause += " AND p.quantity > 0"
+	}
+
+	if created, ok := filters["created"].(filter.DateRangeFilter); ok {
+		clause, rangeArgs := created.SQL("p.created_at", argCount)
+		whereClause += clause
+		args = append(args, rangeArgs...)
+	}
+
+	return whereClause, args, tsQueryArg
+}
+
+// imageVariantsColumn selects the recorded variants of the images of the
+// product aliased p, in the order the images are listed
+const imageVariantsColumn = `COALESCE((
+		           SELECT jsonb_agg(jsonb_build_object('url', v.url, 'original_url', v.original_url, 'thumbnails', v.thumbnails)
+		                            ORDER BY array_position(p.images, v.url))
+		           FROM image_variants v WHERE v.url = ANY(p.images)), '[]'::jsonb)`
+
+const productWithCategoryColumns = `p.id, p.seller_id, p.title, p.slug, p.description, p.price, p.quantity, p.images,
+		       p.category_id, p.is_active, p.deleted_at, p.created_at, p.updated_at,
+		       c.id, c.name, ` + imageVariantsColumn
+
+func (r *productRepository) queryProductsWithCategory(pool *pgxpool.Pool, query string, args ...interface{}) ([]*product.ProductWithCategory, error) {
+	rows, err := pool.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
 	}
 	defer rows.Close()
 
@@ -201,15 +376,15 @@ func (r *productRepository) ListWithCategory(filters map[string]interface{}, pag
 	for rows.Next() {
 		var p product.ProductWithCategory
 		var categoryID, categoryName *string
-		
+
 		err := rows.Scan(
-			&p.ID, &p.SellerID, &p.Title, &p.Description, &p.Price, &p.Quantity, &p.Images, 
-			&p.CategoryID, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
-			&categoryID, &categoryName)
+			&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images,
+			&p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt,
+			&categoryID, &categoryName, &p.ImageVariants)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
+			return nil, fmt.Errorf("failed to scan product: %w", err)
 		}
-		
+
 		// Set category if it exists
 		if categoryID != nil && categoryName != nil {
 			p.Category = &product.Category{
@@ -217,33 +392,135 @@ func (r *productRepository) ListWithCategory(filters map[string]interface{}, pag
 				Name: *categoryName,
 			}
 		}
-		
+
 		products = append(products, &p)
 	}
 
-	return products, total, nil
+	return products, nil
+}
+
+const updateProductQuery = `
+	UPDATE products
+	SET title = $1, slug = $2, description = $3, price = $4, quantity = $5, images = $6, category_id = $7, is_active = $8, updated_at = $9
+	WHERE id = $10
+`
+
+// updateProductArgs returns the arguments for updateProductQuery
+func updateProductArgs(p *product.Product) []interface{} {
+	return []interface{}{p.Title, p.Slug, p.Description, p.Price, p.Quantity, p.Images, p.CategoryID, p.IsActive, p.UpdatedAt, p.ID}
 }
 
 func (r *productRepository) Update(p *product.Product) error {
+	_, err := r.db.Exec(context.Background(), updateProductQuery, updateProductArgs(p)...)
+	if violatesConstraint(err, productSlugIndex) {
+		return product.ErrSlugTaken
+	}
+	return err
+}
+
+func (r *productRepository) SoftDelete(id string) error {
 	query := `
-		UPDATE products 
-		SET title = $1, description = $2, price = $3, quantity = $4, images = $5, category_id = $6, is_active = $7, updated_at = $8
-		WHERE id = $9
+		UPDATE products
+		SET is_active = false, deleted_at = COALESCE(deleted_at, $1), updated_at = $1
+		WHERE id = $2
 	`
-	_, err := r.db.Exec(context.Background(), query,
-		p.Title, p.Description, p.Price, p.Quantity, p.Images, p.CategoryID, p.IsActive, p.UpdatedAt, p.ID)
-	return err
+	tag, err := r.db.Exec(context.Background(), query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete product: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return product.ErrNotFound
+	}
+	return nil
 }
 
 func (r *productRepository) Delete(id string) error {
 	query := `DELETE FROM products WHERE id = $1`
 	_, err := r.db.Exec(context.Background(), query, id)
+	if isForeignKeyViolation(err) {
+		return product.ErrInUse
+	}
 	return err
 }
 
+func (r *productRepository) SaveImageVariants(v *product.ImageVariants) error {
+	thumbnails := v.Thumbnails
+	if thumbnails == nil {
+		thumbnails = []product.Thumbnail{}
+	}
+	query := `
+		INSERT INTO image_variants (url, original_url, thumbnails)
+		VALUES ($1, NULLIF($2, ''), $3)
+		ON CONFLICT (url) DO UPDATE SET original_url = EXCLUDED.original_url, thumbnails = EXCLUDED.thumbnails
+	`
+	if _, err := r.db.Exec(context.Background(), query, v.URL, v.OriginalURL, thumbnails); err != nil {
+		return fmt.Errorf("failed to save image variants: %w", err)
+	}
+	return nil
+}
+
+func (r *productRepository) DeleteImageVariants(urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	if _, err := r.db.Exec(context.Background(), `DELETE FROM image_variants WHERE url = ANY($1)`, urls); err != nil {
+		return fmt.Errorf("failed to delete image variants: %w", err)
+	}
+	return nil
+}
+
+func (r *productRepository) RecordImageUploads(sellerID string, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO product_image_uploads (url, seller_id)
+		SELECT url, $1 FROM unnest($2::text[]) AS url
+		ON CONFLICT (url) DO NOTHING
+	`
+	if _, err := r.db.Exec(context.Background(), query, sellerID, urls); err != nil {
+		return fmt.Errorf("failed to record image uploads: %w", err)
+	}
+	return nil
+}
+
+// UploadedImages reads from the primary: it decides which files are deleted,
+// and an image recorded moments ago must already count
+func (r *productRepository) UploadedImages(sellerID string, urls []string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	query := `SELECT url FROM product_image_uploads WHERE seller_id = $1 AND url = ANY($2)`
+	rows, err := r.db.Query(context.Background(), query, sellerID, urls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query image uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploaded := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("failed to scan image upload: %w", err)
+		}
+		uploaded[url] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, url := range urls {
+		if uploaded[url] {
+			result = append(result, url)
+		}
+	}
+	return result, nil
+}
+
 func (r *productRepository) GetCategories() ([]*product.Category, error) {
 	query := `SELECT id, name FROM categories ORDER BY name`
-	rows, err := r.db.Query(context.Background(), query)
+	rows, err := r.readDB.Query(context.Background(), query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query categories: %w", err)
 	}
@@ -261,3 +538,112 @@ func (r *productRepository) GetCategories() ([]*product.Category, error) {
 
 	return categories, nil
 }
+
+// GetByIDs reads from the primary through queryProducts, since order pricing
+// and bulk price updates write from what it returns
+func (r *productRepository) GetByIDs(ids []string) ([]*product.Product, error) {
+	query := `
+		SELECT id, seller_id, title, slug, description, price, quantity, images, category_id, is_active, deleted_at, created_at, updated_at
+		FROM products WHERE id = ANY($1)
+	`
+	return r.queryProducts(query, ids)
+}
+
+// ListBySellerAndCategory reads from the primary since bulk price updates
+// write from what it returns
+func (r *productRepository) ListBySellerAndCategory(sellerID, categoryID string) ([]*product.Product, error) {
+	query := `
+		SELECT id, seller_id, title, slug, description, price, quantity, images, category_id, is_active, deleted_at, created_at, updated_at
+		FROM products WHERE seller_id = $1 AND category_id = $2
+		ORDER BY created_at DESC
+	`
+	return r.queryProducts(query, sellerID, categoryID)
+}
+
+// ListBySeller returns all of a seller's products that are not soft-deleted,
+// including inactive ones. It reads from the primary so sellers see their
+// own edits immediately.
+func (r *productRepository) ListBySeller(sellerID string, page, pageSize int) ([]*product.ProductWithCategory, int, error) {
+	var total int
+	err := r.db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM products p WHERE p.seller_id = $1 AND p.deleted_at IS NULL`, sellerID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.seller_id = $1 AND p.deleted_at IS NULL
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	`, productWithCategoryColumns)
+
+	products, err := r.queryProductsWithCategory(r.db, query, sellerID, pageSize, pagination.Params{Page: page, PageSize: pageSize}.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (r *productRepository) queryProducts(query string, args ...interface{}) ([]*product.Product, error) {
+	rows, err := r.db.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*product.Product
+	for rows.Next() {
+		var p product.Product
+		err := rows.Scan(&p.ID, &p.SellerID, &p.Title, &p.Slug, &p.Description, &p.Price, &p.Quantity, &p.Images, &p.CategoryID, &p.IsActive, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, &p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *productRepository) UpdatePrices(changes []*product.PriceChange) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `SELECT price FROM products WHERE id = $1 FOR UPDATE`
+	updateQuery := `UPDATE products SET price = $1, updated_at = $2 WHERE id = $3`
+	auditQuery := `
+		INSERT INTO product_price_changes (id, product_id, changed_by, old_price, new_price, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, c := range changes {
+		// Locking the row keeps the recorded old price current until commit;
+		// a price that moved since it was read would make NewPrice stale
+		var current float64
+		if err := tx.QueryRow(ctx, lockQuery, c.ProductID).Scan(&current); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return product.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock product price: %w", err)
+		}
+		if current != c.OldPrice {
+			return product.ErrPriceChanged
+		}
+
+		if _, err := tx.Exec(ctx, updateQuery, c.NewPrice, c.CreatedAt, c.ProductID); err != nil {
+			return fmt.Errorf("failed to update product price: %w", err)
+		}
+		_, err := tx.Exec(ctx, auditQuery,
+			c.ID, c.ProductID, c.ChangedBy, c.OldPrice, c.NewPrice, c.Reason, c.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record price change: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
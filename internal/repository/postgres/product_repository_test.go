@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errConnectRefused = errors.New("connect refused by test")
+
+// recordingPool returns a pool whose connection attempts are recorded under
+// name and then refused, so tests can see which pool a query was sent to
+// without a running database.
+func recordingPool(t *testing.T, name string, mu *sync.Mutex, used *[]string) *pgxpool.Pool {
+	t.Helper()
+	cfg, err := pgxpool.ParseConfig("postgres://test@127.0.0.1:1/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		mu.Lock()
+		*used = append(*used, name)
+		mu.Unlock()
+		return errConnectRefused
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestProductRepository_ReadReplicaRouting(t *testing.T) {
+	var mu sync.Mutex
+	var used []string
+	primary := recordingPool(t, "primary", &mu, &used)
+	replica := recordingPool(t, "replica", &mu, &used)
+
+	tests := []struct {
+		name   string
+		readDB *pgxpool.Pool
+		query  func(r *productRepository) error
+		want   string
+	}{
+		{"List uses replica", replica, func(r *productRepository) error {
+			_, _, err := r.List(nil, 1, 20)
+			return err
+		}, "replica"},
+		{"ListWithCategory uses replica", replica, func(r *productRepository) error {
+			_, _, err := r.ListWithCategory(nil, 1, 20, "", "")
+			return err
+		}, "replica"},
+		{"ListWithCategoryCursor uses replica", replica, func(r *productRepository) error {
+			_, _, err := r.ListWithCategoryCursor(nil, "", 20, "", "")
+			return err
+		}, "replica"},
+		{"GetByIDWithCategory uses replica", replica, func(r *productRepository) error {
+			_, err := r.GetByIDWithCategory("p1")
+			return err
+		}, "replica"},
+		{"GetBySlug uses replica", replica, func(r *productRepository) error {
+			_, err := r.GetBySlug("blue-mountain-coffee")
+			return err
+		}, "replica"},
+		{"GetCategories uses replica", replica, func(r *productRepository) error {
+			_, err := r.GetCategories()
+			return err
+		}, "replica"},
+		// Reads that feed a write, or must see the caller's own edits, stay
+		// on the primary
+		{"GetByID stays on primary", replica, func(r *productRepository) error {
+			_, err := r.GetByID("p1")
+			return err
+		}, "primary"},
+		{"GetByIDs stays on primary", replica, func(r *productRepository) error {
+			_, err := r.GetByIDs([]string{"p1"})
+			return err
+		}, "primary"},
+		{"SlugExists stays on primary", replica, func(r *productRepository) error {
+			_, err := r.SlugExists("blue-mountain-coffee", "")
+			return err
+		}, "primary"},
+		{"ListBySeller stays on primary", replica, func(r *productRepository) error {
+			_, _, err := r.ListBySeller("seller-1", 1, 20)
+			return err
+		}, "primary"},
+		{"ListBySellerAndCategory stays on primary", replica, func(r *productRepository) error {
+			_, err := r.ListBySellerAndCategory("seller-1", "cat-1")
+			return err
+		}, "primary"},
+		{"UploadedImages stays on primary", replica, func(r *productRepository) error {
+			_, err := r.UploadedImages("seller-1", []string{"https://example.com/products/a.jpg"})
+			return err
+		}, "primary"},
+		{"Writes stay on primary", replica, func(r *productRepository) error {
+			return r.SoftDelete("p1")
+		}, "primary"},
+		{"Reads fall back to primary", nil, func(r *productRepository) error {
+			_, _, err := r.List(nil, 1, 20)
+			return err
+		}, "primary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			used = nil
+			mu.Unlock()
+
+			repo := NewProductRepository(primary, tt.readDB).(*productRepository)
+			if err := tt.query(repo); !errors.Is(err, errConnectRefused) {
+				t.Fatalf("query error = %v, want the refused connection", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(used) == 0 || used[0] != tt.want {
+				t.Errorf("query went to %v, want %s", used, tt.want)
+			}
+		})
+	}
+}
+
+func TestListWithCategoryWhere_Filters(t *testing.T) {
+	tests := []struct {
+		name      string
+		filters   map[string]interface{}
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "Price range with in stock",
+			filters:   map[string]interface{}{"min_price": 10.0, "max_price": 50.0, "in_stock": true},
+			wantWhere: " AND p.price >= $1 AND p.price <= $2 AND p.quantity > 0",
+			wantArgs:  []interface{}{10.0, 50.0},
+		},
+		{
+			name:      "Category, seller and min price",
+			filters:   map[string]interface{}{"category_id": "cat-1", "seller_id": "seller-1", "min_price": 5.0},
+			wantWhere: " AND p.category_id = $1 AND p.seller_id = $2 AND p.price >= $3",
+			wantArgs:  []interface{}{"cat-1", "seller-1", 5.0},
+		},
+		{
+			name:      "Search then max price",
+			filters:   map[string]interface{}{"search": "shoe", "max_price": 20.0},
+			wantWhere: " AND p.search_vector @@ to_tsquery('english', $1) AND p.price <= $2",
+			wantArgs:  []interface{}{"shoe:*", 20.0},
+		},
+		{
+			name:      "In stock false is ignored",
+			filters:   map[string]interface{}{"in_stock": false},
+			wantWhere: "",
+			wantArgs:  []interface{}{},
+		},
+	}
+
+	const base = "WHERE p.is_active = true AND p.deleted_at IS NULL"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args, _ := listWithCategoryWhere(tt.filters)
+			if where != base+tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, base+tt.wantWhere)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type viewRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewViewRepository creates a new product view repository
+func NewViewRepository(db *pgxpool.Pool) product.ViewRepository {
+	return &viewRepository{db: db}
+}
+
+func (r *viewRepository) AddViews(counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(counts))
+	views := make([]int64, 0, len(counts))
+	for id, n := range counts {
+		ids = append(ids, id)
+		views = append(views, n)
+	}
+
+	// Joining on products skips counts for products deleted since they were
+	// viewed instead of failing the whole batch
+	query := `
+		INSERT INTO product_views (product_id, views, updated_at)
+		SELECT v.product_id, v.views, NOW()
+		FROM unnest($1::uuid[], $2::bigint[]) AS v(product_id, views)
+		JOIN products p ON p.id = v.product_id
+		ON CONFLICT (product_id) DO UPDATE
+		SET views = product_views.views + EXCLUDED.views, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.db.Exec(context.Background(), query, ids, views); err != nil {
+		return fmt.Errorf("failed to add product views: %w", err)
+	}
+	return nil
+}
+
+func (r *viewRepository) GetSellerViews(sellerID string) ([]*product.ProductViews, error) {
+	query := `
+		SELECT p.id, p.title, COALESCE(v.views, 0) AS views
+		FROM products p
+		LEFT JOIN product_views v ON v.product_id = p.id
+		WHERE p.seller_id = $1 AND p.deleted_at IS NULL
+		ORDER BY views DESC, p.created_at DESC
+	`
+	rows, err := r.db.Query(context.Background(), query, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product views: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*product.ProductViews{}
+	for rows.Next() {
+		var v product.ProductViews
+		if err := rows.Scan(&v.ProductID, &v.Title, &v.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan product views: %w", err)
+		}
+		products = append(products, &v)
+	}
+	return products, rows.Err()
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reservationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *pgxpool.Pool) product.ReservationRepository {
+	return &reservationRepository{db: db}
+}
+
+func (r *reservationRepository) ReservedQuantities(productIDs []string) (map[string]int, error) {
+	reserved := make(map[string]int)
+	if len(productIDs) == 0 {
+		return reserved, nil
+	}
+
+	query := `
+		SELECT product_id, SUM(quantity)
+		FROM product_reservations
+		WHERE product_id = ANY($1) AND expires_at > NOW()
+		GROUP BY product_id
+	`
+	rows, err := r.db.Query(context.Background(), query, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reservations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		reserved[productID] = quantity
+	}
+
+	return reserved, rows.Err()
+}
+
+func (r *reservationRepository) Reserve(res *product.Reservation) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the product row serializes concurrent reservations for it, so
+	// two carts cannot both claim the last unit
+	var stock int
+	err = tx.QueryRow(ctx,
+		`SELECT quantity FROM products WHERE id = $1 AND is_active = true AND deleted_at IS NULL FOR UPDATE`,
+		res.ProductID).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return product.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	var reserved int
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(quantity), 0) FROM product_reservations WHERE product_id = $1 AND expires_at > NOW()`,
+		res.ProductID).Scan(&reserved)
+	if err != nil {
+		return fmt.Errorf("failed to sum reservations: %w", err)
+	}
+
+	available := product.AvailableQuantity(stock, reserved)
+	if res.Quantity > available {
+		return &product.InsufficientStockError{ProductID: res.ProductID, Available: available}
+	}
+
+	// An expired hold is replaced rather than topped up
+	query := `
+		INSERT INTO product_reservations (id, product_id, cart_id, quantity, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (product_id, cart_id) DO UPDATE SET
+			quantity = CASE
+				WHEN product_reservations.expires_at > NOW() THEN product_reservations.quantity + EXCLUDED.quantity
+				ELSE EXCLUDED.quantity
+			END,
+			expires_at = EXCLUDED.expires_at
+	`
+	_, err = tx.Exec(ctx, query,
+		res.ID, res.ProductID, res.CartID, res.Quantity, res.ExpiresAt, res.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save reservation: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *reservationRepository) Release(productID, cartID string) error {
+	query := `DELETE FROM product_reservations WHERE product_id = $1 AND cart_id = $2`
+	if _, err := r.db.Exec(context.Background(), query, productID, cartID); err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	return nil
+}
+
+func (r *reservationRepository) Unreserve(productID, cartID string, quantity int) error {
+	ctx := context.Background()
+
+	// Holds must stay positive, so one that would reach zero is deleted
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM product_reservations WHERE product_id = $1 AND cart_id = $2 AND quantity <= $3`,
+		productID, cartID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	_, err = r.db.Exec(ctx,
+		`UPDATE product_reservations SET quantity = quantity - $3 WHERE product_id = $1 AND cart_id = $2`,
+		productID, cartID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to reduce reservation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchConfig is the text search configuration products.search_vector is
+// built with; queries must use the same one to stem words alike
+const searchConfig = "english"
+
+// prefixTSQuery turns free-text search input into a to_tsquery expression
+// that requires every word, each as a prefix. Postgres stems each word before
+// applying the prefix, so "running shoe" becomes 'run':* & 'shoe':* and
+// matches a product titled "shoes for runners".
+//
+// ok is false when a word contains characters to_tsquery would parse as
+// operators or reject; callers fall back to ILIKE for those searches.
+func prefixTSQuery(search string) (query string, ok bool) {
+	words := strings.Fields(search)
+	if len(words) == 0 {
+		return "", false
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		for _, r := range w {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return "", false
+			}
+		}
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & "), true
+}
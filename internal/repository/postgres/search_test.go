@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+// With the english configuration, to_tsquery('english', 'running:* & shoe:*')
+// stems to 'run':* & 'shoe':*, which matches the vector for "shoes for
+// runners" ('runner':2 'shoe':1). A plain websearch_to_tsquery would look for
+// 'run' and miss it.
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+		wantOK bool
+	}{
+		{"running shoe", "running:* & shoe:*", true},
+		{"  Shoes ", "Shoes:*", true},
+		{"café 2024", "café:* & 2024:*", true},
+		{"", "", false},
+		{"shoe & sock", "", false},
+		{"t-shirt", "", false},
+		{"50%", "", false},
+		{"don't", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := prefixTSQuery(tt.search)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("prefixTSQuery(%q) = %q, %v; want %q, %v", tt.search, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestListWithCategoryWhere_Search(t *testing.T) {
+	where, args, tsQueryArg := listWithCategoryWhere(map[string]interface{}{
+		"category_id": "cat-1",
+		"search":      "running shoe",
+	})
+	if !strings.Contains(where, "p.search_vector @@ to_tsquery('english', $2)") {
+		t.Errorf("where = %q, want full-text match on $2", where)
+	}
+	if tsQueryArg != 2 || len(args) != 2 || args[1] != "running:* & shoe:*" {
+		t.Errorf("tsQueryArg = %d, args = %v; want 2 and the prefix query", tsQueryArg, args)
+	}
+
+	where, args, tsQueryArg = listWithCategoryWhere(map[string]interface{}{"search": "t-shirt"})
+	if !strings.Contains(where, "p.title ILIKE $1") || tsQueryArg != 0 || args[0] != "%t-shirt%" {
+		t.Errorf("where = %q, args = %v, tsQueryArg = %d; want ILIKE fallback", where, args, tsQueryArg)
+	}
+}
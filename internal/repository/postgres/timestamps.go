@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RegisterUTCTimestamps makes the connection scan timestamptz columns in UTC so
+// every timestamp the API returns serializes as RFC3339 with a Z suffix,
+// regardless of the server's local time zone. Use it as a pool AfterConnect hook.
+func RegisterUTCTimestamps(_ context.Context, conn *pgx.Conn) error {
+	conn.TypeMap().RegisterType(&pgtype.Type{
+		Name:  "timestamptz",
+		OID:   pgtype.TimestamptzOID,
+		Codec: &pgtype.TimestamptzCodec{ScanLocation: time.UTC},
+	})
+	return nil
+}
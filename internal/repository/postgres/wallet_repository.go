@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -25,42 +30,265 @@ func (r *walletRepository) GetByUserID(userID string) (*wallet.Wallet, error) {
 	var w wallet.Wallet
 	err := r.db.QueryRow(context.Background(), query, userID).Scan(
 		&w.ID, &w.UserID, &w.Balance, &w.Currency, &w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, wallet.ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wallet by user id: %w", err)
 	}
 	return &w, nil
 }
 
-func (r *walletRepository) CreateTransaction(tx *wallet.Transaction) error {
+func (r *walletRepository) Create(w *wallet.Wallet) error {
 	query := `
-		INSERT INTO transactions (id, wallet_id, type, amount, reference, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO wallets (id, user_id, balance, currency, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 	`
 	_, err := r.db.Exec(context.Background(), query,
-		tx.ID, tx.WalletID, tx.Type, tx.Amount, tx.Reference, tx.Status, tx.CreatedAt)
+		w.ID, w.UserID, w.Balance, w.Currency, w.UpdatedAt)
+	if isUniqueViolation(err) {
+		return wallet.ErrWalletExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create wallet: %w", err)
+	}
+	return nil
+}
+
+// insertTransactionQuery records a transaction from transactionArgs. Unset
+// transfer and blockchain fields are stored as NULL.
+const insertTransactionQuery = `
+	INSERT INTO transactions (
+		id, wallet_id, type, amount, reference, status, created_at,
+		transfer_id, tx_hash, chain_id, from_address, to_address
+	)
+	VALUES (
+		$1, $2, $3, $4, $5, $6, $7,
+		NULLIF($8, '')::uuid, NULLIF($9, ''), NULLIF($10::bigint, 0), NULLIF($11, ''), NULLIF($12, '')
+	)
+`
+
+// transactionArgs returns the arguments for insertTransactionQuery, recording
+// tx with the given status
+func transactionArgs(tx *wallet.Transaction, status wallet.TransactionStatus) []interface{} {
+	return []interface{}{
+		tx.ID, tx.WalletID, tx.Type, tx.Amount, tx.Reference, status, tx.CreatedAt,
+		tx.TransferID, tx.TxHash, tx.ChainID, tx.From, tx.To,
+	}
+}
+
+// transactionColumns selects a transaction in the order scanTransaction
+// reads it
+const transactionColumns = `
+	id, wallet_id, type, amount, COALESCE(reference, ''), status, created_at,
+	COALESCE(transfer_id::text, ''),
+	COALESCE(tx_hash, ''), COALESCE(chain_id, 0), COALESCE(from_address, ''), COALESCE(to_address, '')
+`
+
+// scanTransaction reads a row selected with transactionColumns
+func scanTransaction(row pgx.Row) (*wallet.Transaction, error) {
+	var tx wallet.Transaction
+	err := row.Scan(&tx.ID, &tx.WalletID, &tx.Type, &tx.Amount, &tx.Reference, &tx.Status, &tx.CreatedAt,
+		&tx.TransferID,
+		&tx.TxHash, &tx.ChainID, &tx.From, &tx.To)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (r *walletRepository) CreateTransaction(tx *wallet.Transaction) error {
+	_, err := r.db.Exec(context.Background(), insertTransactionQuery, transactionArgs(tx, tx.Status)...)
+	if isUniqueViolation(err) {
+		return r.duplicateTransaction(tx.WalletID, tx.Reference)
+	}
 	return err
 }
 
-func (r *walletRepository) GetTransactions(walletID string, page, pageSize int) ([]*wallet.Transaction, int, error) {
-	offset := (page - 1) * pageSize
+// duplicateTransaction builds the error for a reference the wallet has
+// already used, carrying the transaction recorded under it
+func (r *walletRepository) duplicateTransaction(walletID, reference string) error {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE wallet_id = $1 AND reference = $2
+	`
+	tx, err := scanTransaction(r.db.QueryRow(context.Background(), query, walletID, reference))
+	if err != nil {
+		return fmt.Errorf("%w: failed to load existing transaction: %v", wallet.ErrDuplicateTransaction, err)
+	}
+	return &wallet.DuplicateTransactionError{Existing: tx}
+}
+
+func (r *walletRepository) Debit(t *wallet.Transaction) error {
+	ctx := context.Background()
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	// Checking and debiting in one statement means two concurrent debits
+	// cannot both pass the balance check
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE wallets SET balance = balance - $1, updated_at = NOW() WHERE id = $2 AND balance >= $1`,
+		t.Amount, t.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to debit wallet: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return wallet.ErrInsufficientBalance
+	}
+
+	_, err = dbTx.Exec(ctx, insertTransactionQuery, transactionArgs(t, wallet.TransactionStatusSuccess)...)
+	if isUniqueViolation(err) {
+		// Roll back the debit before looking up the original outside it
+		dbTx.Rollback(ctx)
+		return r.duplicateTransaction(t.WalletID, t.Reference)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit debit: %w", err)
+	}
+	t.Status = wallet.TransactionStatusSuccess
+	return nil
+}
+
+// chainTxHashIndex keeps each on-chain transaction credited at most once
+const chainTxHashIndex = "idx_transactions_chain_tx_hash"
+
+// violatesConstraint reports whether err was raised by the named constraint
+// or unique index
+func violatesConstraint(err error, name string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == name
+}
+
+func (r *walletRepository) Credit(t *wallet.Transaction) error {
+	ctx := context.Background()
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	// Recording first means a reused reference fails before any balance
+	// changes
+	_, err = dbTx.Exec(ctx, insertTransactionQuery, transactionArgs(t, wallet.TransactionStatusSuccess)...)
+	if isUniqueViolation(err) {
+		dbTx.Rollback(ctx)
+		dupErr := r.duplicateTransaction(t.WalletID, t.Reference)
+		// A hash this wallet never recorded was credited to someone else
+		var dup *wallet.DuplicateTransactionError
+		if !errors.As(dupErr, &dup) && violatesConstraint(err, chainTxHashIndex) {
+			return wallet.ErrDepositClaimed
+		}
+		return dupErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE wallets SET balance = balance + $1, updated_at = NOW() WHERE id = $2`,
+		t.Amount, t.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to credit wallet: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return wallet.ErrNotFound
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit credit: %w", err)
+	}
+	t.Status = wallet.TransactionStatusSuccess
+	return nil
+}
+
+func (r *walletRepository) Transfer(debit, credit *wallet.Transaction) error {
+	ctx := context.Background()
+	dbTx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	// Lock both wallets in a fixed order so opposing transfers between the
+	// same pair cannot deadlock
+	if _, err := dbTx.Exec(ctx,
+		`SELECT id FROM wallets WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`,
+		debit.WalletID, credit.WalletID); err != nil {
+		return fmt.Errorf("failed to lock wallets: %w", err)
+	}
+
+	tag, err := dbTx.Exec(ctx,
+		`UPDATE wallets SET balance = balance - $1, updated_at = NOW() WHERE id = $2 AND balance >= $1`,
+		debit.Amount, debit.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to debit wallet: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return wallet.ErrInsufficientBalance
+	}
+
+	_, err = dbTx.Exec(ctx, insertTransactionQuery, transactionArgs(debit, wallet.TransactionStatusSuccess)...)
+	if isUniqueViolation(err) {
+		// Roll back the debit before looking up the original outside it
+		dbTx.Rollback(ctx)
+		return r.duplicateTransaction(debit.WalletID, debit.Reference)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record debit: %w", err)
+	}
+
+	tag, err = dbTx.Exec(ctx,
+		`UPDATE wallets SET balance = balance + $1, updated_at = NOW() WHERE id = $2`,
+		credit.Amount, credit.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to credit wallet: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return wallet.ErrNotFound
+	}
+	_, err = dbTx.Exec(ctx, insertTransactionQuery, transactionArgs(credit, wallet.TransactionStatusSuccess)...)
+	if err != nil {
+		return fmt.Errorf("failed to record credit: %w", err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+	debit.Status = wallet.TransactionStatusSuccess
+	credit.Status = wallet.TransactionStatusSuccess
+	return nil
+}
+
+func (r *walletRepository) GetTransactions(walletID string, filter wallet.TransactionFilter, page, pageSize int) ([]*wallet.Transaction, int, error) {
+	offset := pagination.Params{Page: page, PageSize: pageSize}.Offset()
+	whereClause, args := transactionWhere(walletID, filter)
+	argCount := len(args) + 1
 
 	// Get total count
 	var total int
-	countQuery := `SELECT COUNT(*) FROM transactions WHERE wallet_id = $1`
-	err := r.db.QueryRow(context.Background(), countQuery, walletID).Scan(&total)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM transactions %s", whereClause)
+	err := r.db.QueryRow(context.Background(), countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
 	}
 
 	// Get transactions
-	query := `
-		SELECT id, wallet_id, type, amount, reference, status, created_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM transactions
-		WHERE wallet_id = $1
+		%s
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.db.Query(context.Background(), query, walletID, pageSize, offset)
+		LIMIT $%d OFFSET $%d
+	`, transactionColumns, whereClause, argCount, argCount+1)
+	args = append(args, pageSize, offset)
+	rows, err := r.db.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -68,17 +296,64 @@ func (r *walletRepository) GetTransactions(walletID string, page, pageSize int)
 
 	var transactions []*wallet.Transaction
 	for rows.Next() {
-		var tx wallet.Transaction
-		err := rows.Scan(&tx.ID, &tx.WalletID, &tx.Type, &tx.Amount, &tx.Reference, &tx.Status, &tx.CreatedAt)
+		tx, err := scanTransaction(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan transaction: %w", err)
 		}
-		transactions = append(transactions, &tx)
+		transactions = append(transactions, tx)
 	}
 
 	return transactions, total, nil
 }
 
+// transactionWhere builds the WHERE clause selecting the wallet's
+// transactions that pass filter, with its args in placeholder order
+func transactionWhere(walletID string, f wallet.TransactionFilter) (string, []interface{}) {
+	whereClause := "WHERE wallet_id = $1"
+	args := []interface{}{walletID}
+
+	if f.Type != "" {
+		args = append(args, f.Type)
+		whereClause += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		whereClause += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	createdSQL, createdArgs := f.Created.SQL("created_at", len(args)+1)
+	return whereClause + createdSQL, append(args, createdArgs...)
+}
+
+func (r *walletRepository) GetTransactionAggregates(walletID string, from, to time.Time, bucket wallet.Bucket) ([]*wallet.TransactionAggregate, error) {
+	// Truncate in UTC so buckets line up with wallet.Bucket.Truncate
+	query := `
+		SELECT date_trunc($4::text, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS period_start,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'credit'), 0) AS credit,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'debit'), 0) AS debit,
+			COUNT(*)
+		FROM transactions
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3 AND status <> 'failed'
+		GROUP BY period_start
+		ORDER BY period_start
+	`
+	rows, err := r.db.Query(context.Background(), query, walletID, from, to, string(bucket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []*wallet.TransactionAggregate
+	for rows.Next() {
+		var a wallet.TransactionAggregate
+		if err := rows.Scan(&a.PeriodStart, &a.Credit, &a.Debit, &a.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction aggregate: %w", err)
+		}
+		aggregates = append(aggregates, &a)
+	}
+
+	return aggregates, rows.Err()
+}
+
 func (r *walletRepository) UpdateBalance(walletID string, amount float64) error {
 	query := `
 		UPDATE wallets 
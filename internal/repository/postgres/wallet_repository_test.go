@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+)
+
+func TestTransactionWhere(t *testing.T) {
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    wallet.TransactionFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{"No filter", wallet.TransactionFilter{},
+			"WHERE wallet_id = $1", []interface{}{"wallet-1"}},
+		{"Type", wallet.TransactionFilter{Type: wallet.TransactionTypeCredit},
+			"WHERE wallet_id = $1 AND type = $2", []interface{}{"wallet-1", wallet.TransactionTypeCredit}},
+		{"Status", wallet.TransactionFilter{Status: wallet.TransactionStatusFailed},
+			"WHERE wallet_id = $1 AND status = $2", []interface{}{"wallet-1", wallet.TransactionStatusFailed}},
+		{"From", wallet.TransactionFilter{Created: filter.DateRangeFilter{After: &from}},
+			"WHERE wallet_id = $1 AND created_at >= $2", []interface{}{"wallet-1", from}},
+		{"To", wallet.TransactionFilter{Created: filter.DateRangeFilter{Before: &to}},
+			"WHERE wallet_id = $1 AND created_at < $2", []interface{}{"wallet-1", to}},
+		{"Status and range", wallet.TransactionFilter{Status: wallet.TransactionStatusSuccess, Created: filter.DateRangeFilter{After: &from, Before: &to}},
+			"WHERE wallet_id = $1 AND status = $2 AND created_at >= $3 AND created_at < $4",
+			[]interface{}{"wallet-1", wallet.TransactionStatusSuccess, from, to}},
+		{"Everything", wallet.TransactionFilter{Type: wallet.TransactionTypeDebit, Status: wallet.TransactionStatusPending, Created: filter.DateRangeFilter{After: &from, Before: &to}},
+			"WHERE wallet_id = $1 AND type = $2 AND status = $3 AND created_at >= $4 AND created_at < $5",
+			[]interface{}{"wallet-1", wallet.TransactionTypeDebit, wallet.TransactionStatusPending, from, to}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := transactionWhere("wallet-1", tt.filter)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// valuesRow is a pgx.Row returning fixed values in column order
+type valuesRow []interface{}
+
+func (r valuesRow) Scan(dest ...interface{}) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("scanned %d columns, row has %d", len(dest), len(r))
+	}
+	for i, v := range r {
+		target := reflect.ValueOf(dest[i]).Elem()
+		value := reflect.ValueOf(v)
+		if !value.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("column %d: cannot scan %T into %s", i, v, target.Type())
+		}
+		target.Set(value.Convert(target.Type()))
+	}
+	return nil
+}
+
+// The insert and select list the same columns in the same order, so a
+// transaction read back from its own insert arguments must come out unchanged
+func TestTransactionColumns_RoundTrip(t *testing.T) {
+	verified := &wallet.Transaction{
+		ID:        "tx-1",
+		WalletID:  "wallet-1",
+		Type:      wallet.TransactionTypeCredit,
+		Amount:    0.25,
+		Reference: "deposit:0xabc",
+		Status:    wallet.TransactionStatusPending,
+		CreatedAt: time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC),
+		TxHash:    "0xabc",
+		ChainID:   8453,
+		From:      "0x1111111111111111111111111111111111111111",
+		To:        "0x2222222222222222222222222222222222222222",
+	}
+
+	got, err := scanTransaction(valuesRow(transactionArgs(verified, wallet.TransactionStatusSuccess)))
+	if err != nil {
+		t.Fatalf("scanTransaction() error = %v", err)
+	}
+
+	want := *verified
+	want.Status = wallet.TransactionStatusSuccess
+	if *got != want {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", *got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyStore implements order.IdempotencyStore in Redis. A claimed key
+// holds the request fingerprint with an empty order ID until the order is
+// recorded. Claims expire after the in-flight TTL so a crashed request does
+// not block retries for long; completed keys are kept for the full TTL.
+type IdempotencyStore struct {
+	monitor     *Monitor
+	inFlightTTL time.Duration
+	ttl         time.Duration
+}
+
+// idempotencyRecord is the value stored under an idempotency key
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	OrderID     string `json:"order_id,omitempty"`
+}
+
+// NewIdempotencyStore holds unfinished claims for inFlightTTL and keeps each
+// completed idempotency key for ttl
+func NewIdempotencyStore(monitor *Monitor, inFlightTTL, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		monitor:     monitor,
+		inFlightTTL: inFlightTTL,
+		ttl:         ttl,
+	}
+}
+
+func idempotencyKey(userID, key string) string {
+	return fmt.Sprintf("idempotency:orders:%s:%s", userID, key)
+}
+
+// Claim reserves the key or returns the order ID already recorded for it
+func (s *IdempotencyStore) Claim(ctx context.Context, userID, key, fingerprint string) (string, bool, error) {
+	if !s.monitor.Healthy() {
+		return "", false, auth.ErrStoreUnavailable
+	}
+	client := s.monitor.Client()
+	k := idempotencyKey(userID, key)
+
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	claimed, err := client.SetNX(ctx, k, data, s.inFlightTTL).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return "", true, nil
+	}
+
+	stored, err := client.Get(ctx, k).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Expired between SETNX and GET; claim it again
+		return s.Claim(ctx, userID, key, fingerprint)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(stored, &record); err != nil {
+		return "", false, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+	if record.Fingerprint != fingerprint {
+		return "", false, order.ErrIdempotencyKeyReused
+	}
+	return record.OrderID, false, nil
+}
+
+// Complete records the order created for the key, restarting its TTL
+func (s *IdempotencyStore) Complete(ctx context.Context, userID, key, fingerprint, orderID string) error {
+	if !s.monitor.Healthy() {
+		return auth.ErrStoreUnavailable
+	}
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, OrderID: orderID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	if err := s.monitor.Client().Set(ctx, idempotencyKey(userID, key), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release deletes the claim
+func (s *IdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	if !s.monitor.Healthy() {
+		return auth.ErrStoreUnavailable
+	}
+	if err := s.monitor.Client().Del(ctx, idempotencyKey(userID, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := NewIdempotencyStore(NewMonitor(client, time.Minute), time.Minute, 24*time.Hour)
+	ctx := context.Background()
+
+	// First request claims the key
+	orderID, claimed, err := store.Claim(ctx, "buyer", "key-1", "fp-1")
+	if err != nil || !claimed || orderID != "" {
+		t.Fatalf("Claim() = %q, %v, %v; want a fresh claim", orderID, claimed, err)
+	}
+
+	// A concurrent duplicate sees the claim without an order yet
+	orderID, claimed, err = store.Claim(ctx, "buyer", "key-1", "fp-1")
+	if err != nil || claimed || orderID != "" {
+		t.Fatalf("Claim() in flight = %q, %v, %v; want an unfinished claim", orderID, claimed, err)
+	}
+
+	// The key cannot be reused for a different request
+	if _, _, err := store.Claim(ctx, "buyer", "key-1", "fp-2"); !errors.Is(err, order.ErrIdempotencyKeyReused) {
+		t.Fatalf("Claim() with another fingerprint error = %v, want %v", err, order.ErrIdempotencyKeyReused)
+	}
+
+	if err := store.Complete(ctx, "buyer", "key-1", "fp-1", "order-1"); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	// A retry gets the recorded order
+	orderID, claimed, err = store.Claim(ctx, "buyer", "key-1", "fp-1")
+	if err != nil || claimed || orderID != "order-1" {
+		t.Fatalf("Claim() after completion = %q, %v, %v; want order-1", orderID, claimed, err)
+	}
+
+	if _, _, err := store.Claim(ctx, "buyer", "key-1", "fp-2"); !errors.Is(err, order.ErrIdempotencyKeyReused) {
+		t.Errorf("Claim() after completion with another fingerprint error = %v, want %v", err, order.ErrIdempotencyKeyReused)
+	}
+
+	// The same key from another user is independent
+	if _, claimed, _ := store.Claim(ctx, "someone-else", "key-1", "fp-1"); !claimed {
+		t.Error("Claim() for another user collided with the first user's key")
+	}
+
+	// Expired keys can be claimed again
+	server.FastForward(24 * time.Hour)
+	if _, claimed, _ := store.Claim(ctx, "buyer", "key-1", "fp-1"); !claimed {
+		t.Error("Claim() after expiry was not a fresh claim")
+	}
+
+	// Released keys can be claimed again
+	if err := store.Release(ctx, "buyer", "key-1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, claimed, _ := store.Claim(ctx, "buyer", "key-1", "fp-1"); !claimed {
+		t.Error("Claim() after release was not a fresh claim")
+	}
+
+	// An unfinished claim lapses after the in-flight TTL
+	server.FastForward(time.Minute)
+	if _, claimed, _ := store.Claim(ctx, "buyer", "key-1", "fp-1"); !claimed {
+		t.Error("Claim() after the in-flight TTL was not a fresh claim")
+	}
+}
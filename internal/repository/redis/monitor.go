@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// Monitor periodically pings Redis and, when the connection is lost,
+// rebuilds the client with exponential backoff until it recovers
+type Monitor struct {
+	mu      sync.RWMutex
+	client  *redis.Client
+	healthy atomic.Bool
+
+	interval    time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	pingTimeout time.Duration
+}
+
+// NewMonitor creates a health monitor for the given client
+func NewMonitor(client *redis.Client, interval time.Duration) *Monitor {
+	m := &Monitor{
+		client:      client,
+		interval:    interval,
+		minBackoff:  500 * time.Millisecond,
+		maxBackoff:  30 * time.Second,
+		pingTimeout: 2 * time.Second,
+	}
+	m.healthy.Store(true)
+	return m
+}
+
+// Client returns the current Redis client
+func (m *Monitor) Client() *redis.Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.client
+}
+
+// Healthy reports whether the last health check succeeded
+func (m *Monitor) Healthy() bool {
+	return m.healthy.Load()
+}
+
+// Close closes the current Redis client
+func (m *Monitor) Close() error {
+	return m.Client().Close()
+}
+
+// Run checks connectivity every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.ping(ctx, m.Client()); err != nil {
+				log.Error().Err(err).Msg("redis connection lost, entering degraded mode")
+				m.healthy.Store(false)
+				m.reconnect(ctx)
+			}
+		}
+	}
+}
+
+// reconnect replaces the client with a fresh one, backing off exponentially
+// between attempts until a ping succeeds or ctx is cancelled
+func (m *Monitor) reconnect(ctx context.Context) {
+	backoff := m.minBackoff
+
+	for attempt := 1; ; attempt++ {
+		old := m.Client()
+		candidate := redis.NewClient(old.Options())
+
+		err := m.ping(ctx, candidate)
+		if err == nil {
+			m.mu.Lock()
+			m.client = candidate
+			m.mu.Unlock()
+			old.Close()
+
+			m.healthy.Store(true)
+			log.Info().Int("attempts", attempt).Msg("redis connection re-established")
+			return
+		}
+
+		candidate.Close()
+		log.Warn().Err(err).Int("attempt", attempt).Dur("retry_in", backoff).Msg("redis reconnect failed")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+func (m *Monitor) ping(ctx context.Context, client *redis.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, m.pingTimeout)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestMonitor(t *testing.T) (*Monitor, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr:       server.Addr(),
+		MaxRetries: -1,
+	})
+	t.Cleanup(func() { client.Close() })
+
+	m := NewMonitor(client, 10*time.Millisecond)
+	m.minBackoff = 5 * time.Millisecond
+	m.maxBackoff = 20 * time.Millisecond
+	m.pingTimeout = 50 * time.Millisecond
+	return m, server
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+func TestMonitor_ReconnectsAfterConnectionLoss(t *testing.T) {
+	m, server := newTestMonitor(t)
+	original := m.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	// Drop the connection: pings fail and the monitor enters degraded mode
+	server.Close()
+	waitFor(t, func() bool { return !m.Healthy() }, "monitor did not detect connection loss")
+
+	repo := NewMonitoredSessionRepository(m)
+	if _, err := repo.GetSession(context.Background(), "any"); !errors.Is(err, auth.ErrStoreUnavailable) {
+		t.Errorf("GetSession() while degraded error = %v, want ErrStoreUnavailable", err)
+	}
+
+	// Recover: the monitor re-establishes a working client
+	if err := server.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m.Healthy, "monitor did not recover after Redis came back")
+
+	if m.Client() == original {
+		t.Error("monitor did not replace the dropped client")
+	}
+
+	nonce := auth.NewNonce()
+	if err := repo.SaveNonce(context.Background(), nonce); err != nil {
+		t.Fatalf("SaveNonce() after recovery error = %v", err)
+	}
+	if _, err := repo.GetNonce(context.Background(), nonce.Value); err != nil {
+		t.Errorf("GetNonce() after recovery error = %v", err)
+	}
+}
+
+func TestMonitor_StopsOnContextCancel(t *testing.T) {
+	m, _ := newTestMonitor(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after context cancellation")
+	}
+}
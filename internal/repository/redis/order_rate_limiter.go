@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+// OrderRateLimiter implements order.RateLimiter with a fixed-window counter
+// per user in Redis
+type OrderRateLimiter struct {
+	monitor *Monitor
+	limit   int
+	window  time.Duration
+}
+
+// NewOrderRateLimiter allows each user limit orders per window
+func NewOrderRateLimiter(monitor *Monitor, limit int, window time.Duration) *OrderRateLimiter {
+	return &OrderRateLimiter{
+		monitor: monitor,
+		limit:   limit,
+		window:  window,
+	}
+}
+
+// Allow increments the user's counter for the current window and reports
+// whether the attempt is within the limit
+func (l *OrderRateLimiter) Allow(ctx context.Context, userID string) (bool, time.Duration, error) {
+	if !l.monitor.Healthy() {
+		return false, 0, auth.ErrStoreUnavailable
+	}
+	client := l.monitor.Client()
+
+	key := fmt.Sprintf("ratelimit:orders:%s", userID)
+
+	var count *redis.IntCmd
+	var ttl *redis.DurationCmd
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		// Only the first attempt in a window starts the clock
+		pipe.ExpireNX(ctx, key, l.window)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check order rate limit: %w", err)
+	}
+
+	if count.Val() > int64(l.limit) {
+		return false, ttl.Val(), nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestOrderRateLimiter_ThrottlesAndResets(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := NewOrderRateLimiter(NewMonitor(client, time.Minute), 3, time.Minute)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		allowed, _, err := limiter.Allow(ctx, "buyer")
+		if err != nil {
+			t.Fatalf("Allow() attempt %d error = %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("Allow() attempt %d was throttled, want allowed", i)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "buyer")
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if allowed {
+		t.Fatal("Allow() over the limit was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("retryAfter = %v, want within the window", retryAfter)
+	}
+
+	// Other users have their own budget
+	if allowed, _, _ := limiter.Allow(ctx, "someone-else"); !allowed {
+		t.Error("Allow() for another user was throttled")
+	}
+
+	server.FastForward(time.Minute)
+	if allowed, _, _ := limiter.Allow(ctx, "buyer"); !allowed {
+		t.Error("Allow() after the window elapsed was throttled")
+	}
+}
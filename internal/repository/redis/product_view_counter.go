@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+// pendingViewsKey holds the views counted since the last flush, by product
+const pendingViewsKey = "views:products:pending"
+
+// drainViewsScript reads and clears the pending counts in one step so views
+// recorded meanwhile land in the next drain
+var drainViewsScript = redis.NewScript(`
+local counts = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return counts
+`)
+
+// ProductViewCounter implements product.ViewCounter in Redis. Each viewer
+// holds a short-lived marker per product; only views that set a new marker
+// are counted.
+type ProductViewCounter struct {
+	monitor *Monitor
+	window  time.Duration
+}
+
+// NewProductViewCounter counts each viewer at most once per window
+func NewProductViewCounter(monitor *Monitor, window time.Duration) *ProductViewCounter {
+	return &ProductViewCounter{
+		monitor: monitor,
+		window:  window,
+	}
+}
+
+// viewMarkerKey hashes the viewer so user IDs and client IPs are not stored
+// as keys
+func viewMarkerKey(productID, viewer string) string {
+	sum := sha256.Sum256([]byte(viewer))
+	return fmt.Sprintf("views:products:seen:%s:%s", productID, hex.EncodeToString(sum[:16]))
+}
+
+// RecordView counts the view unless the viewer's marker is still set
+func (c *ProductViewCounter) RecordView(ctx context.Context, productID, viewer string) (bool, error) {
+	if !c.monitor.Healthy() {
+		return false, auth.ErrStoreUnavailable
+	}
+	client := c.monitor.Client()
+
+	first, err := client.SetNX(ctx, viewMarkerKey(productID, viewer), 1, c.window).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to debounce product view: %w", err)
+	}
+	if !first {
+		return false, nil
+	}
+
+	if err := client.HIncrBy(ctx, pendingViewsKey, productID, 1).Err(); err != nil {
+		return false, fmt.Errorf("failed to count product view: %w", err)
+	}
+	return true, nil
+}
+
+// DrainViews returns and clears the pending counts
+func (c *ProductViewCounter) DrainViews(ctx context.Context) (map[string]int64, error) {
+	if !c.monitor.Healthy() {
+		return nil, auth.ErrStoreUnavailable
+	}
+
+	fields, err := drainViewsScript.Run(ctx, c.monitor.Client(), []string{pendingViewsKey}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to drain product views: %w", err)
+	}
+
+	counts := make(map[string]int64, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		n, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[fields[i]] = n
+	}
+	return counts, nil
+}
+
+// RestoreViews adds counts back to the pending totals
+func (c *ProductViewCounter) RestoreViews(ctx context.Context, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	if !c.monitor.Healthy() {
+		return auth.ErrStoreUnavailable
+	}
+
+	_, err := c.monitor.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for productID, n := range counts {
+			pipe.HIncrBy(ctx, pendingViewsKey, productID, n)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore product views: %w", err)
+	}
+	return nil
+}
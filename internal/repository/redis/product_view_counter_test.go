@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestProductViewCounter_DebouncesRepeatViews(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	counter := NewProductViewCounter(NewMonitor(client, time.Minute), 30*time.Minute)
+	ctx := context.Background()
+
+	// The same session viewing repeatedly within the window counts once
+	for i := 0; i < 3; i++ {
+		counted, err := counter.RecordView(ctx, "product-1", "session-a")
+		if err != nil {
+			t.Fatalf("RecordView() error = %v", err)
+		}
+		if counted != (i == 0) {
+			t.Errorf("RecordView() view %d counted = %v, want %v", i+1, counted, i == 0)
+		}
+	}
+	// Other viewers and other products count separately
+	counter.RecordView(ctx, "product-1", "session-b")
+	counter.RecordView(ctx, "product-2", "session-a")
+
+	counts, err := counter.DrainViews(ctx)
+	if err != nil {
+		t.Fatalf("DrainViews() error = %v", err)
+	}
+	if counts["product-1"] != 2 || counts["product-2"] != 1 || len(counts) != 2 {
+		t.Errorf("DrainViews() = %v, want product-1: 2, product-2: 1", counts)
+	}
+
+	// Draining resets the pending counts
+	if counts, _ := counter.DrainViews(ctx); len(counts) != 0 {
+		t.Errorf("second DrainViews() = %v, want empty", counts)
+	}
+
+	// Once the window has passed the viewer counts again
+	server.FastForward(30 * time.Minute)
+	if counted, _ := counter.RecordView(ctx, "product-1", "session-a"); !counted {
+		t.Error("RecordView() after the window was not counted")
+	}
+}
+
+func TestProductViewCounter_RestoreViews(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	counter := NewProductViewCounter(NewMonitor(client, time.Minute), time.Minute)
+	ctx := context.Background()
+
+	counter.RecordView(ctx, "product-1", "session-a")
+	if err := counter.RestoreViews(ctx, map[string]int64{"product-1": 4, "product-2": 2}); err != nil {
+		t.Fatalf("RestoreViews() error = %v", err)
+	}
+
+	counts, err := counter.DrainViews(ctx)
+	if err != nil {
+		t.Fatalf("DrainViews() error = %v", err)
+	}
+	if counts["product-1"] != 5 || counts["product-2"] != 2 {
+		t.Errorf("DrainViews() = %v, want product-1: 5, product-2: 2", counts)
+	}
+}
@@ -10,9 +10,16 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// userSessionsKey is the set of session IDs issued to a user. It lives as
+// long as the user's longest session and may name sessions already gone.
+func userSessionsKey(userID string) string {
+	return fmt.Sprintf("user_sessions:%s", userID)
+}
+
 // SessionRepository implements auth.SessionRepository using Redis
 type SessionRepository struct {
-	client *redis.Client
+	client  *redis.Client
+	monitor *Monitor
 }
 
 // NewSessionRepository creates a new Redis session repository
@@ -20,8 +27,30 @@ func NewSessionRepository(client *redis.Client) *SessionRepository {
 	return &SessionRepository{client: client}
 }
 
+// NewMonitoredSessionRepository creates a session repository that follows the
+// monitor's current client and fails fast while Redis is unreachable
+func NewMonitoredSessionRepository(monitor *Monitor) *SessionRepository {
+	return &SessionRepository{monitor: monitor}
+}
+
+// conn returns the client to use, or auth.ErrStoreUnavailable while degraded
+func (r *SessionRepository) conn() (*redis.Client, error) {
+	if r.monitor == nil {
+		return r.client, nil
+	}
+	if !r.monitor.Healthy() {
+		return nil, auth.ErrStoreUnavailable
+	}
+	return r.monitor.Client(), nil
+}
+
 // SaveSession stores a session in Redis
 func (r *SessionRepository) SaveSession(ctx context.Context, session *auth.Session) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+
 	data, err := json.Marshal(session)
 	if err != nil {
 		return fmt.Errorf("failed to marshal session: %w", err)
@@ -29,8 +58,17 @@ func (r *SessionRepository) SaveSession(ctx context.Context, session *auth.Sessi
 
 	key := fmt.Sprintf("session:%s", session.ID)
 	ttl := time.Until(session.ExpiresAt)
-	
-	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	userKey := userSessionsKey(session.UserID)
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, data, ttl)
+		pipe.SAdd(ctx, userKey, session.ID)
+		// Give a new set this session's TTL, or extend an existing one
+		pipe.ExpireNX(ctx, userKey, ttl)
+		pipe.ExpireGT(ctx, userKey, ttl)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
@@ -39,9 +77,14 @@ func (r *SessionRepository) SaveSession(ctx context.Context, session *auth.Sessi
 
 // GetSession retrieves a session from Redis
 func (r *SessionRepository) GetSession(ctx context.Context, sessionID string) (*auth.Session, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+
 	key := fmt.Sprintf("session:%s", sessionID)
 	
-	data, err := r.client.Get(ctx, key).Bytes()
+	data, err := client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
 		return nil, fmt.Errorf("session not found")
 	}
@@ -65,17 +108,60 @@ func (r *SessionRepository) GetSession(ctx context.Context, sessionID string) (*
 
 // DeleteSession removes a session from Redis
 func (r *SessionRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+
 	key := fmt.Sprintf("session:%s", sessionID)
 	
-	if err := r.client.Del(ctx, key).Err(); err != nil {
+	if err := client.Del(ctx, key).Err(); err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
 	return nil
 }
 
+// DeleteUserSessions removes every session issued to the user
+func (r *SessionRepository) DeleteUserSessions(ctx context.Context, userID string) (int, error) {
+	client, err := r.conn()
+	if err != nil {
+		return 0, err
+	}
+
+	userKey := userSessionsKey(userID)
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, fmt.Sprintf("session:%s", id))
+	}
+	var deleted *redis.IntCmd
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, userKey, ids)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+
+	return int(deleted.Val()), nil
+}
+
 // SaveNonce stores a nonce in Redis
 func (r *SessionRepository) SaveNonce(ctx context.Context, nonce *auth.Nonce) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+
 	data, err := json.Marshal(nonce)
 	if err != nil {
 		return fmt.Errorf("failed to marshal nonce: %w", err)
@@ -84,7 +170,7 @@ func (r *SessionRepository) SaveNonce(ctx context.Context, nonce *auth.Nonce) er
 	key := fmt.Sprintf("nonce:%s", nonce.Value)
 	ttl := time.Until(nonce.ExpiresAt)
 	
-	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	if err := client.Set(ctx, key, data, ttl).Err(); err != nil {
 		return fmt.Errorf("failed to save nonce: %w", err)
 	}
 
@@ -93,9 +179,14 @@ func (r *SessionRepository) SaveNonce(ctx context.Context, nonce *auth.Nonce) er
 
 // GetNonce retrieves a nonce from Redis
 func (r *SessionRepository) GetNonce(ctx context.Context, nonceValue string) (*auth.Nonce, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+
 	key := fmt.Sprintf("nonce:%s", nonceValue)
 	
-	data, err := r.client.Get(ctx, key).Bytes()
+	data, err := client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
 		return nil, fmt.Errorf("nonce not found")
 	}
@@ -119,11 +210,46 @@ func (r *SessionRepository) GetNonce(ctx context.Context, nonceValue string) (*a
 
 // DeleteNonce removes a nonce from Redis
 func (r *SessionRepository) DeleteNonce(ctx context.Context, nonceValue string) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+
 	key := fmt.Sprintf("nonce:%s", nonceValue)
 	
-	if err := r.client.Del(ctx, key).Err(); err != nil {
+	if err := client.Del(ctx, key).Err(); err != nil {
 		return fmt.Errorf("failed to delete nonce: %w", err)
 	}
 
 	return nil
 }
+
+// ConsumeNonce retrieves and deletes a nonce with GETDEL, so concurrent
+// callers presenting the same nonce cannot both receive it
+func (r *SessionRepository) ConsumeNonce(ctx context.Context, nonceValue string) (*auth.Nonce, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("nonce:%s", nonceValue)
+
+	data, err := client.GetDel(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("nonce not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume nonce: %w", err)
+	}
+
+	var nonce auth.Nonce
+	if err := json.Unmarshal(data, &nonce); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal nonce: %w", err)
+	}
+
+	if nonce.IsExpired() {
+		return nil, fmt.Errorf("nonce expired")
+	}
+
+	return &nonce, nil
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestDeleteUserSessions(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := NewSessionRepository(client)
+	ctx := context.Background()
+
+	short := auth.NewSession("user-1", "0xabc", "customer", time.Minute)
+	long := auth.NewSession("user-1", "0xabc", "customer", time.Hour)
+	other := auth.NewSession("user-2", "0xdef", "customer", time.Hour)
+	for _, s := range []*auth.Session{long, short, other} {
+		if err := repo.SaveSession(ctx, s); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+	}
+	// The index outlives the shorter session
+	if ttl := server.TTL(userSessionsKey("user-1")); ttl < 59*time.Minute {
+		t.Errorf("index TTL = %v, want the longest session's", ttl)
+	}
+
+	deleted, err := repo.DeleteUserSessions(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("DeleteUserSessions() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteUserSessions() = %d, want 2", deleted)
+	}
+	for _, s := range []*auth.Session{short, long} {
+		if _, err := repo.GetSession(ctx, s.ID); err == nil {
+			t.Errorf("session %s survived", s.ID)
+		}
+	}
+	if _, err := repo.GetSession(ctx, other.ID); err != nil {
+		t.Errorf("another user's session was deleted: %v", err)
+	}
+}
+
+func TestConsumeNonce_SingleUse(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := NewSessionRepository(client)
+	ctx := context.Background()
+
+	nonce := auth.NewNonce()
+	if err := repo.SaveNonce(ctx, nonce); err != nil {
+		t.Fatalf("SaveNonce() error = %v", err)
+	}
+
+	got, err := repo.ConsumeNonce(ctx, nonce.Value)
+	if err != nil {
+		t.Fatalf("ConsumeNonce() error = %v", err)
+	}
+	if got.Value != nonce.Value {
+		t.Errorf("ConsumeNonce() = %q, want %q", got.Value, nonce.Value)
+	}
+	if _, err := repo.ConsumeNonce(ctx, nonce.Value); err == nil {
+		t.Error("second ConsumeNonce() succeeded, want error")
+	}
+	if server.Exists("nonce:" + nonce.Value) {
+		t.Error("consumed nonce was left in Redis")
+	}
+}
@@ -2,49 +2,58 @@ package routes
 
 import (
 	"github.com/Tenoywil/CaribEx-backend/internal/controller"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
 	"github.com/Tenoywil/CaribEx-backend/pkg/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRoutes configures all application routes
+// SetupRoutes configures all application routes. blockchainController may be
+// nil when no RPC endpoint is available, in which case its routes are omitted.
+// authRateLimit, when not nil, is applied to every auth route.
 func SetupRoutes(
 	router *gin.Engine,
 	authController *controller.AuthController,
 	authUseCase *usecase.AuthUseCase,
+	userUseCase *usecase.UserUseCase,
 	userController *controller.UserController,
 	productController *controller.ProductController,
 	walletController *controller.WalletController,
 	cartController *controller.CartController,
 	orderController *controller.OrderController,
 	blockchainController *controller.BlockchainController,
+	payoutController *controller.PayoutController,
+	webhookController *controller.WebhookController,
+	healthController *controller.HealthController,
+	authRateLimit gin.HandlerFunc,
 ) {
-	// Health check
-	router.GET("/healthz", func(ctx *gin.Context) {
-		ctx.JSON(200, gin.H{"status": "ok"})
-	})
+	// Unknown routes and wrong methods return the JSON error envelope
+	middleware.RegisterFallbackHandlers(router)
 
-	router.GET("/readyz", func(ctx *gin.Context) {
-		// TODO: Check database and redis connectivity
-		ctx.JSON(200, gin.H{"status": "ready"})
-	})
+	// Health checks
+	router.GET("/healthz", healthController.Healthz)
+	router.GET("/readyz", healthController.Readyz)
 
 	// API v1 routes
 	v1 := router.Group("/v1")
 	{
 		// Auth routes (public)
 		auth := v1.Group("/auth")
+		if authRateLimit != nil {
+			auth.Use(authRateLimit)
+		}
 		{
 			auth.GET("/nonce", authController.GetNonce)
 			auth.POST("/siwe", authController.AuthenticateSIWE)
 			auth.GET("/me", middleware.AuthMiddleware(authUseCase), authController.GetMe)
+			auth.POST("/refresh", middleware.AuthMiddleware(authUseCase), authController.RefreshSession)
 			auth.POST("/logout", middleware.AuthMiddleware(authUseCase), authController.Logout)
 		}
 
 		// User routes (protected)
 		users := v1.Group("/users", middleware.AuthMiddleware(authUseCase))
 		{
-			users.POST("", userController.CreateUser)
+			users.POST("", middleware.RequireRole(userUseCase, user.RoleAdmin), userController.CreateUser)
 			users.GET("/:id", userController.GetUser)
 			users.GET("/wallet/:address", userController.GetUserByWallet)
 			users.PUT("/:id", userController.UpdateUser)
@@ -55,16 +64,26 @@ func SetupRoutes(
 		products := v1.Group("/products")
 		{
 			products.GET("", productController.ListProducts)
-			products.GET("/:id", productController.GetProduct)
+			products.GET("/:id", middleware.OptionalAuthMiddleware(authUseCase), productController.GetProduct)
+			products.GET("/:id/images/:key/url", middleware.OptionalAuthMiddleware(authUseCase), productController.GetImageURL)
 			
 			// Protected product routes
 			productsProtected := products.Group("", middleware.AuthMiddleware(authUseCase))
 			{
-				productsProtected.POST("", productController.CreateProduct)
-				productsProtected.POST("/multipart", productController.CreateProductMultipart)
-				productsProtected.POST("/upload-image", productController.UploadImage)
-				productsProtected.PUT("/:id", productController.UpdateProduct)
-				productsProtected.DELETE("/:id", productController.DeleteProduct)
+				productsProtected.GET("/mine", productController.ListMyProducts)
+				productsProtected.GET("/:id/adjustments", productController.ListInventoryAdjustments)
+
+				// Only sellers and admins may change listings
+				productsWrite := productsProtected.Group("", middleware.RequireRole(userUseCase, user.RoleSeller, user.RoleAdmin))
+				productsWrite.POST("", productController.CreateProduct)
+				productsWrite.POST("/multipart", productController.CreateProductMultipart)
+				productsWrite.POST("/upload-image", productController.UploadImage)
+				productsWrite.POST("/upload-images", productController.UploadImages)
+				productsWrite.POST("/documents", productController.UploadDocuments)
+				productsWrite.POST("/bulk-price", productController.BulkUpdatePrices)
+				productsWrite.PUT("/:id", productController.UpdateProduct)
+				productsWrite.PATCH("/:id/quantity", productController.UpdateProductQuantity)
+				productsWrite.DELETE("/:id", productController.DeleteProduct)
 			}
 		}
 
@@ -75,11 +94,19 @@ func SetupRoutes(
 		wallet := v1.Group("/wallet", middleware.AuthMiddleware(authUseCase))
 		{
 			wallet.GET("", walletController.GetWallet)
+			wallet.GET("/convert", walletController.ConvertBalance)
 			wallet.POST("/send", walletController.SendFunds)
 			wallet.POST("/receive", walletController.ReceiveFunds)
+			wallet.POST("/transfer", walletController.Transfer)
 			wallet.GET("/transactions", walletController.GetTransactions)
-			wallet.POST("/verify-transaction", blockchainController.VerifyTransaction)
-			wallet.GET("/transaction-status", blockchainController.GetTransactionStatus)
+			wallet.GET("/transactions/timeseries", walletController.GetTransactionTimeseries)
+
+			// On-chain verification is only routed when the RPC client is
+			// configured; main passes a nil controller otherwise
+			if blockchainController != nil {
+				wallet.POST("/verify-transaction", blockchainController.VerifyTransaction)
+				wallet.GET("/transaction-status", blockchainController.GetTransactionStatus)
+			}
 		}
 
 		// Cart routes (protected)
@@ -88,15 +115,44 @@ func SetupRoutes(
 			cart.GET("", cartController.GetCart)
 			cart.POST("/items", cartController.AddItem)
 			cart.PUT("/items/:id", cartController.UpdateItem)
+			cart.DELETE("/items", cartController.ClearCart)
 			cart.DELETE("/items/:id", cartController.RemoveItem)
+			cart.POST("/merge", cartController.MergeCart)
 		}
 
 		// Order routes (protected)
 		orders := v1.Group("/orders", middleware.AuthMiddleware(authUseCase))
 		{
 			orders.POST("", orderController.CreateOrder)
+			orders.POST("/checkout", orderController.Checkout)
 			orders.GET("", orderController.ListOrders)
 			orders.GET("/:id", orderController.GetOrder)
+			orders.POST("/:id/ship", orderController.ShipItems)
+			orders.PATCH("/:id/status", orderController.UpdateStatus)
+		}
+
+		// Provider callbacks (public, authenticated by payload signature)
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("/payments", webhookController.PaymentCallback)
+		}
+
+		// Seller routes (protected)
+		sellers := v1.Group("/sellers", middleware.AuthMiddleware(authUseCase))
+		{
+			sellers.GET("/me/earnings", payoutController.GetMyEarnings)
+			sellers.GET("/me/stats", productController.GetMyStats)
+			sellers.GET("/me/webhooks", webhookController.ListWebhooks)
+			sellers.POST("/me/webhooks", webhookController.CreateWebhook)
+			sellers.DELETE("/me/webhooks/:id", webhookController.DeleteWebhook)
+		}
+
+		// Admin routes (protected, admin role required)
+		admin := v1.Group("/admin", middleware.AuthMiddleware(authUseCase), middleware.RequireRole(userUseCase, user.RoleAdmin))
+		{
+			admin.POST("/payouts/:id/mark-paid", payoutController.MarkPaid)
+			admin.DELETE("/products/:id", productController.HardDeleteProduct)
+			admin.PUT("/users/:id/role", userController.UpdateRole)
 		}
 	}
 }
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/controller"
+	"github.com/gin-gonic/gin"
+)
+
+func registeredRoutes(blockchainController *controller.BlockchainController) map[string]bool {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router, nil, nil, nil, nil, nil, nil, nil, nil, blockchainController, nil, nil, nil, nil)
+
+	routes := make(map[string]bool)
+	for _, r := range router.Routes() {
+		routes[r.Method+" "+r.Path] = true
+	}
+	return routes
+}
+
+func TestSetupRoutes_BlockchainRoutesConditional(t *testing.T) {
+	blockchainRoutes := []string{
+		http.MethodPost + " /v1/wallet/verify-transaction",
+		http.MethodGet + " /v1/wallet/transaction-status",
+	}
+
+	t.Run("RPC disabled", func(t *testing.T) {
+		routes := registeredRoutes(nil)
+		for _, route := range blockchainRoutes {
+			if routes[route] {
+				t.Errorf("%s registered without an RPC client", route)
+			}
+		}
+		if !routes[http.MethodGet+" /v1/wallet/transactions"] {
+			t.Error("non-blockchain wallet routes missing")
+		}
+	})
+
+	t.Run("RPC enabled", func(t *testing.T) {
+		routes := registeredRoutes(controller.NewBlockchainController(nil))
+		for _, route := range blockchainRoutes {
+			if !routes[route] {
+				t.Errorf("%s not registered with an RPC client", route)
+			}
+		}
+	})
+}
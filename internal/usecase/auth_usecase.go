@@ -2,33 +2,83 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
+	"github.com/Tenoywil/CaribEx-backend/pkg/jwtauth"
 	"github.com/Tenoywil/CaribEx-backend/pkg/siwe"
 	"github.com/rs/zerolog/log"
 )
 
+// AuthConfig holds the settings for SIWE authentication
+type AuthConfig struct {
+	// Domains are the SIWE domains messages may be issued for; a
+	// single-entry list restricts sign-in to one frontend
+	Domains []string
+	// URIs are the hosts or URI prefixes a message's URI and resources may
+	// point to; empty allows the hosts in Domains
+	URIs []string
+	// MessageMaxAge is how long after its Issued At time a SIWE message is
+	// accepted; zero means siwe.DefaultMaxAge
+	MessageMaxAge time.Duration
+	// ChainIDs are the chains users may sign in on; empty allows every
+	// supported chain
+	ChainIDs []int64
+	// BindNonce issues a client token with each nonce that the verify
+	// request must present, tying both requests to the same browser
+	BindNonce bool
+	// SessionDuration is how long a session lasts from login or renewal;
+	// zero means DefaultSessionDuration
+	SessionDuration time.Duration
+	// SessionRenewWindow renews a session when it is used within this long
+	// of expiring; zero disables sliding expiration
+	SessionRenewWindow time.Duration
+	// SessionMaxLifetime caps how long after login a session can be renewed
+	// to; zero means DefaultSessionMaxLifetime
+	SessionMaxLifetime time.Duration
+	// Tokens signs an access token at each sign-in; nil issues none
+	Tokens *jwtauth.Signer
+	// Wallets opens a wallet for each user who signs in without one; nil
+	// opens none
+	Wallets *WalletUseCase
+	// WalletCurrency is the currency new users' wallets hold; empty means
+	// wallet.DefaultCurrency
+	WalletCurrency wallet.Currency
+}
+
+// DefaultSessionDuration is used when no session duration is configured
+const DefaultSessionDuration = 24 * time.Hour
+
+// DefaultSessionMaxLifetime is used when no session lifetime cap is
+// configured
+const DefaultSessionMaxLifetime = 7 * 24 * time.Hour
+
 // AuthUseCase handles authentication business logic
 type AuthUseCase struct {
 	sessionRepo auth.SessionRepository
 	userUseCase *UserUseCase
-	domain      string
+	config      AuthConfig
 }
 
 // NewAuthUseCase creates a new auth use case
 func NewAuthUseCase(
 	sessionRepo auth.SessionRepository,
 	userUseCase *UserUseCase,
-	domain string,
+	config AuthConfig,
 ) *AuthUseCase {
 	return &AuthUseCase{
 		sessionRepo: sessionRepo,
 		userUseCase: userUseCase,
-		domain:      domain,
+		config:      config,
 	}
 }
 
@@ -36,6 +86,14 @@ func NewAuthUseCase(
 func (uc *AuthUseCase) GenerateNonce(ctx context.Context) (*auth.Nonce, error) {
 	nonce := auth.NewNonce()
 
+	if uc.config.BindNonce {
+		token, err := generateToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate nonce token: %w", err)
+		}
+		nonce.Token = token
+	}
+
 	if err := uc.sessionRepo.SaveNonce(ctx, nonce); err != nil {
 		log.Error().Err(err).Msg("failed to save nonce")
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
@@ -44,32 +102,45 @@ func (uc *AuthUseCase) GenerateNonce(ctx context.Context) (*auth.Nonce, error) {
 	return nonce, nil
 }
 
-// VerifySIWE verifies a SIWE message and signature
+// VerifySIWE verifies a SIWE message and signature and signs the user in.
+// nonceToken is the client token issued with the nonce and is only checked
+// when nonce binding is enabled. An access token is returned with the
+// session when token signing is configured, and is nil otherwise.
 func (uc *AuthUseCase) VerifySIWE(
 	ctx context.Context,
-	message, signature string,
-) (*auth.Session, *user.User, error) {
+	message, signature, nonceToken string,
+) (*auth.Session, *user.User, *auth.AccessToken, error) {
 	// Use our custom SIWE verification
-	siweMessage, err := siwe.VerifySIWE(message, signature, uc.domain)
+	siweMessage, err := siwe.VerifySIWE(message, signature, siwe.Options{
+		Domains:  uc.config.Domains,
+		URIs:     uc.config.URIs,
+		MaxAge:   uc.config.MessageMaxAge,
+		ChainIDs: uc.config.ChainIDs,
+	})
 	if err != nil {
 		log.Error().Err(err).Msg("SIWE verification failed")
-		return nil, nil, fmt.Errorf("SIWE verification failed: %w", err)
+		return nil, nil, nil, fmt.Errorf("SIWE verification failed: %w", err)
 	}
 
-	// Verify the nonce exists and is valid
-	nonce, err := uc.sessionRepo.GetNonce(ctx, siweMessage.Nonce)
+	// Consume the nonce atomically so a replayed message racing this one
+	// finds it gone
+	nonce, err := uc.sessionRepo.ConsumeNonce(ctx, siweMessage.Nonce)
 	if err != nil {
 		log.Error().Err(err).Str("nonce", siweMessage.Nonce).Msg("nonce not found or expired")
-		return nil, nil, fmt.Errorf("invalid or expired nonce")
+		return nil, nil, nil, fmt.Errorf("invalid or expired nonce")
 	}
 
-	// Get the wallet address from the message (already verified by signature check)
-	walletAddress := strings.ToLower(siweMessage.Address)
+	if uc.config.BindNonce && !nonceTokenMatches(nonce.Token, nonceToken) {
+		log.Warn().Str("nonce", nonce.Value).Msg("nonce token mismatch")
+		return nil, nil, nil, fmt.Errorf("nonce was not issued to this client")
+	}
 
-	// Delete the used nonce
-	if err := uc.sessionRepo.DeleteNonce(ctx, nonce.Value); err != nil {
-		log.Warn().Err(err).Msg("failed to delete used nonce")
+	// Get the wallet address from the message (already verified by signature check)
+	address, err := blockchain.ParseAddress(siweMessage.Address)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid wallet address: %w", err)
 	}
+	walletAddress := address.Key()
 
 	// Get or create user
 	u, err := uc.userUseCase.GetUserByWalletAddress(walletAddress)
@@ -83,27 +154,72 @@ func (uc *AuthUseCase) VerifySIWE(
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to create user")
-			return nil, nil, fmt.Errorf("failed to create user: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to create user: %w", err)
 		}
 	}
 
+	// Checked on every sign-in, not just the first, so a user whose wallet
+	// was never opened (a failed first sign-in, or an account created another
+	// way) gets one now
+	if err := uc.ensureWallet(u.ID); err != nil {
+		log.Error().Err(err).Str("user_id", u.ID).Msg("failed to create wallet")
+		return nil, nil, nil, fmt.Errorf("failed to create wallet: %w", err)
+	}
+
 	// Create session
-	session := auth.NewSession(u.ID, walletAddress, 24*time.Hour)
+	session := auth.NewSession(u.ID, walletAddress, u.Role, uc.sessionDuration())
 	if err := uc.sessionRepo.SaveSession(ctx, session); err != nil {
 		log.Error().Err(err).Msg("failed to save session")
-		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	log.Info().
 		Str("user_id", u.ID).
 		Str("wallet", walletAddress).
-		Str("session_id", session.ID).
+		Str("session", sessionLogID(session.ID)).
 		Msg("user authenticated via SIWE")
 
-	return session, u, nil
+	var accessToken *auth.AccessToken
+	if uc.config.Tokens != nil {
+		token, expiresAt, err := uc.config.Tokens.Sign(u.ID, walletAddress, string(u.Role))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to sign access token")
+			return nil, nil, nil, fmt.Errorf("failed to issue access token: %w", err)
+		}
+		accessToken = &auth.AccessToken{Token: token, ExpiresAt: expiresAt}
+	}
+
+	return session, u, accessToken, nil
+}
+
+// ensureWallet opens a wallet for the user when wallets are configured and
+// the user has none. A wallet that already exists, including one opened by a
+// concurrent sign-in, is left as it is.
+func (uc *AuthUseCase) ensureWallet(userID string) error {
+	if uc.config.Wallets == nil {
+		return nil
+	}
+	_, err := uc.config.Wallets.GetWalletByUserID(userID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, wallet.ErrNotFound) {
+		return err
+	}
+
+	currency := uc.config.WalletCurrency
+	if currency == "" {
+		currency = wallet.DefaultCurrency
+	}
+	_, err = uc.config.Wallets.CreateWallet(userID, currency)
+	if errors.Is(err, wallet.ErrWalletExists) {
+		return nil
+	}
+	return err
 }
 
-// ValidateSession checks if a session is valid
+// ValidateSession checks if a session is valid. A session used within the
+// renewal window of expiring is extended.
 func (uc *AuthUseCase) ValidateSession(ctx context.Context, sessionID string) (*auth.Session, error) {
 	session, err := uc.sessionRepo.GetSession(ctx, sessionID)
 	if err != nil {
@@ -114,17 +230,130 @@ func (uc *AuthUseCase) ValidateSession(ctx context.Context, sessionID string) (*
 		uc.sessionRepo.DeleteSession(ctx, sessionID)
 		return nil, fmt.Errorf("session expired")
 	}
+	if session.Role == "" {
+		session.Role = user.RoleCustomer
+	}
+
+	window := uc.config.SessionRenewWindow
+	if window > 0 && time.Until(session.ExpiresAt) <= window {
+		// The session is still valid, so a failed renewal only means it
+		// expires on schedule
+		if err := uc.extendSession(ctx, session); err != nil {
+			log.Warn().Err(err).Str("user_id", session.UserID).Msg("failed to renew session")
+		}
+	}
 
 	return session, nil
 }
 
+// RefreshSession extends a valid session to a full session duration from
+// now, up to its lifetime cap
+func (uc *AuthUseCase) RefreshSession(ctx context.Context, sessionID string) (*auth.Session, error) {
+	session, err := uc.ValidateSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.extendSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	return session, nil
+}
+
+// sessionDuration is how long a session lasts from login or renewal
+func (uc *AuthUseCase) sessionDuration() time.Duration {
+	if uc.config.SessionDuration <= 0 {
+		return DefaultSessionDuration
+	}
+	return uc.config.SessionDuration
+}
+
+// SessionDeadline is the latest a session can be renewed to
+func (uc *AuthUseCase) SessionDeadline(session *auth.Session) time.Time {
+	maxLifetime := uc.config.SessionMaxLifetime
+	if maxLifetime <= 0 {
+		maxLifetime = DefaultSessionMaxLifetime
+	}
+	return session.CreatedAt.Add(maxLifetime)
+}
+
+// extendSession moves the session's expiry to a full duration from now,
+// capped at its deadline, and saves it so the store's TTL follows
+func (uc *AuthUseCase) extendSession(ctx context.Context, session *auth.Session) error {
+	expiresAt := time.Now().UTC().Add(uc.sessionDuration())
+	if deadline := uc.SessionDeadline(session); expiresAt.After(deadline) {
+		expiresAt = deadline
+	}
+	if !expiresAt.After(session.ExpiresAt) {
+		return nil
+	}
+
+	renewed := *session
+	renewed.ExpiresAt = expiresAt
+	if err := uc.sessionRepo.SaveSession(ctx, &renewed); err != nil {
+		return err
+	}
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+// ChangeRole gives the user a new role and signs them out everywhere, since
+// sessions carry the role they were issued with. Access tokens already issued
+// cannot be revoked and keep the old role until they expire, so routes that
+// must see a demotion at once should authenticate by session, not JWTAuth.
+func (uc *AuthUseCase) ChangeRole(ctx context.Context, userID string, role user.Role) (*user.User, error) {
+	if !user.ValidRole(role) {
+		return nil, fmt.Errorf("%w: %q", user.ErrInvalidRole, role)
+	}
+
+	u, err := uc.userUseCase.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	if err := uc.userUseCase.userRepo.Update(u); err != nil {
+		return nil, err
+	}
+
+	revoked, err := uc.sessionRepo.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("role changed but sessions were not revoked: %w", err)
+	}
+	log.Info().Str("user_id", userID).Str("role", string(role)).Int("sessions_revoked", revoked).Msg("user role changed")
+	return u, nil
+}
+
 // Logout invalidates a session
 func (uc *AuthUseCase) Logout(ctx context.Context, sessionID string) error {
 	if err := uc.sessionRepo.DeleteSession(ctx, sessionID); err != nil {
-		log.Error().Err(err).Str("session_id", sessionID).Msg("failed to delete session")
+		log.Error().Err(err).Str("session", sessionLogID(sessionID)).Msg("failed to delete session")
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
-	log.Info().Str("session_id", sessionID).Msg("user logged out")
+	log.Info().Str("session", sessionLogID(sessionID)).Msg("user logged out")
 	return nil
 }
+
+// sessionLogID identifies a session in logs by a short hash of its ID, which
+// correlates log lines without writing a usable credential to them
+func sessionLogID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:6])
+}
+
+// generateToken returns a random hex-encoded token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// nonceTokenMatches compares the stored and presented nonce tokens in constant time
+func nonceTokenMatches(expected, presented string) bool {
+	if expected == "" || presented == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(presented)) == 1
+}
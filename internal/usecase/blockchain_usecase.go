@@ -3,8 +3,10 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
 	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
 	"github.com/google/uuid"
@@ -12,15 +14,33 @@ import (
 
 // BlockchainUseCase handles blockchain transaction verification business logic
 type BlockchainUseCase struct {
-	walletRepo wallet.Repository
+	walletRepo       wallet.Repository
+	userRepo         user.Repository
+	depositAddresses blockchain.DepositAddresses
+	priceOracle      wallet.PriceOracle
+	verify           func(txHash string, chainID int64) (*blockchain.TransactionVerification, error)
 }
 
-// NewBlockchainUseCase creates a new blockchain use case
-func NewBlockchainUseCase(walletRepo wallet.Repository) *BlockchainUseCase {
-	return &BlockchainUseCase{walletRepo: walletRepo}
+// NewBlockchainUseCase creates a new blockchain use case. Only transactions
+// sent to depositAddresses from the user's linked wallet address are
+// accepted as deposits. priceOracle may be nil, in which case only deposits
+// in the wallet's own currency are accepted.
+func NewBlockchainUseCase(walletRepo wallet.Repository, userRepo user.Repository, depositAddresses blockchain.DepositAddresses, priceOracle wallet.PriceOracle) *BlockchainUseCase {
+	return &BlockchainUseCase{
+		walletRepo:       walletRepo,
+		userRepo:         userRepo,
+		depositAddresses: depositAddresses,
+		priceOracle:      priceOracle,
+		verify:           blockchain.VerifyTransaction,
+	}
 }
 
-// VerifyAndLogTransaction verifies an on-chain transaction and logs it to the database
+// VerifyAndLogTransaction verifies an on-chain deposit from the user's
+// linked wallet address to the platform's deposit address and credits its
+// value, converted into the wallet's currency, to the user's wallet.
+// Submitting the same transaction again returns the original record without
+// crediting twice; a transaction already credited to another user returns
+// wallet.ErrDepositClaimed.
 func (uc *BlockchainUseCase) VerifyAndLogTransaction(userID, txHash string, chainID int64) (*wallet.Transaction, error) {
 	// Validate chain ID
 	if !blockchain.ValidateChainID(chainID) {
@@ -28,14 +48,14 @@ func (uc *BlockchainUseCase) VerifyAndLogTransaction(userID, txHash string, chai
 	}
 
 	// Verify the transaction on-chain
-	verification, err := blockchain.VerifyTransaction(txHash, chainID)
+	verification, err := uc.verify(txHash, chainID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if transaction is still pending
 	if verification.IsPending {
-		return nil, errors.New("transaction is still pending")
+		return nil, fmt.Errorf("transaction is still pending (%d of %d confirmations)", verification.Confirmations, verification.RequiredConfirmations)
 	}
 
 	// Check if transaction was successful
@@ -43,54 +63,83 @@ func (uc *BlockchainUseCase) VerifyAndLogTransaction(userID, txHash string, chai
 		return nil, errors.New("transaction failed on-chain")
 	}
 
+	// Only funds sent to the platform count as a deposit
+	if err := uc.depositAddresses.CheckRecipient(chainID, verification.To); err != nil {
+		return nil, err
+	}
+
+	// Only the owner of the sending address may claim the deposit
+	u, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := blockchain.CheckSender(verification.From, u.WalletAddress); err != nil {
+		return nil, err
+	}
+
+	value, err := blockchain.EtherAmount(verification.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get user's wallet
 	w, err := uc.walletRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert wei value to float (simplified - in production, use proper decimal handling)
-	valueEth, err := blockchain.FormatValue(verification.Value)
+	amount, err := uc.depositAmount(verification.ChainID, value, w.Currency)
 	if err != nil {
-		// If conversion fails, use 0 as amount
-		valueEth = "0"
+		return nil, err
 	}
 
-	// Determine transaction type (credit if to address matches wallet, debit if from matches)
-	txType := wallet.TransactionTypeCredit
-	// Note: This is a simplified approach. In production, you'd need to map
-	// Ethereum addresses to user wallet addresses properly
-
-	// Create transaction log
-	// Note: Amount field set to 0 as blockchain values are stored in wei format in the Value field
-	// and tracked via TxHash. In production, implement proper decimal conversion to match
-	// your wallet's currency (JAM/USD/USDC) or store the raw wei value.
+	txHash = strings.ToLower(verification.TxHash)
 	tx := &wallet.Transaction{
 		ID:        uuid.New().String(),
 		WalletID:  w.ID,
-		Type:      txType,
-		Amount:    0, // Blockchain transaction amount tracked via TxHash and blockchain-specific fields
-		Reference: fmt.Sprintf("Blockchain verification: %s (Value: %s ETH)", txHash, valueEth),
-		Status:    wallet.TransactionStatusSuccess,
+		Type:      wallet.TransactionTypeCredit,
+		Amount:    amount,
+		Reference: "deposit:" + txHash,
+		Status:    wallet.TransactionStatusPending,
 		CreatedAt: time.Now(),
-		TxHash:    verification.TxHash,
+		TxHash:    txHash,
 		ChainID:   verification.ChainID,
 		From:      verification.From,
 		To:        verification.To,
 	}
 
-	// Store transaction in database
-	err = uc.walletRepo.CreateTransaction(tx)
-	if err != nil {
+	// The reference is unique per wallet, so a resubmitted hash stops here;
+	// the record and the balance commit together
+	var dup *wallet.DuplicateTransactionError
+	if err := uc.walletRepo.Credit(tx); errors.As(err, &dup) {
+		return dup.Existing, nil
+	} else if err != nil {
 		return nil, err
 	}
 
-	// Note: Consider whether to update wallet balance based on verified transaction
-	// This depends on your business logic (e.g., only for deposits, not all transactions)
-
 	return tx, nil
 }
 
+// depositAmount converts value, in the chain's native currency, into the
+// wallet's currency. Deposits the price oracle cannot convert are rejected
+// with wallet.ErrCurrencyMismatch.
+func (uc *BlockchainUseCase) depositAmount(chainID int64, value float64, to wallet.Currency) (float64, error) {
+	chain, _ := blockchain.LookupChain(chainID)
+	from := wallet.Currency(chain.Currency)
+	if from == "" {
+		return 0, fmt.Errorf("%w: chain %d has no native currency configured", wallet.ErrCurrencyMismatch, chainID)
+	}
+	if from == to {
+		return value, nil
+	}
+	if uc.priceOracle != nil {
+		if rate, err := uc.priceOracle.Rate(from, to); err == nil {
+			return value * rate, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: deposit is in %s, wallet holds %s", wallet.ErrCurrencyMismatch, from, to)
+}
+
 // GetTransactionVerification retrieves verification details for a transaction hash
 func (uc *BlockchainUseCase) GetTransactionVerification(txHash string, chainID int64) (*blockchain.TransactionVerification, error) {
 	// Validate chain ID
@@ -99,7 +148,7 @@ func (uc *BlockchainUseCase) GetTransactionVerification(txHash string, chainID i
 	}
 
 	// Verify the transaction on-chain
-	verification, err := blockchain.VerifyTransaction(txHash, chainID)
+	verification, err := uc.verify(txHash, chainID)
 	if err != nil {
 		return nil, err
 	}
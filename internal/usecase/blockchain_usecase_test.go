@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
+)
+
+const (
+	testDepositAddress = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb1"
+	testSenderAddress  = "0x1111111111111111111111111111111111111111"
+)
+
+// newDepositFixture verifies every hash as a 0.25 ETH transfer from
+// testSenderAddress to to. user-1 links testSenderAddress and holds a USD
+// wallet; ETH is priced at 2000 USD.
+func newDepositFixture(t *testing.T, to string) (*BlockchainUseCase, *mockWalletRepo) {
+	t.Helper()
+	repo := newMockWalletRepo(
+		&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 1, Currency: wallet.CurrencyUSD},
+		&wallet.Wallet{ID: "wallet-2", UserID: "user-2", Currency: wallet.CurrencyUSD},
+	)
+	users := newMockUserRepo(
+		&user.User{ID: "user-1", WalletAddress: strings.ToLower(testSenderAddress)},
+		&user.User{ID: "user-2", WalletAddress: "0x3333333333333333333333333333333333333333"},
+	)
+	addresses, err := blockchain.ParseDepositAddresses("1:" + testDepositAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle := &mockPriceOracle{rates: map[[2]wallet.Currency]float64{{"ETH", wallet.CurrencyUSD}: 2000}}
+
+	uc := NewBlockchainUseCase(repo, users, addresses, oracle)
+	uc.verify = func(txHash string, chainID int64) (*blockchain.TransactionVerification, error) {
+		return &blockchain.TransactionVerification{
+			TxHash:   txHash,
+			From:     testSenderAddress,
+			To:       to,
+			Value:    "250000000000000000", // 0.25 ETH
+			ChainID:  chainID,
+			Verified: true,
+			Status:   1,
+		}, nil
+	}
+	return uc, repo
+}
+
+func TestVerifyAndLogTransaction_CreditsDepositToPlatform(t *testing.T) {
+	// Recipients match regardless of letter case
+	for _, to := range []string{testDepositAddress, strings.ToLower(testDepositAddress), "0x" + strings.ToUpper(testDepositAddress[2:])} {
+		t.Run(to, func(t *testing.T) {
+			uc, repo := newDepositFixture(t, to)
+
+			tx, err := uc.VerifyAndLogTransaction("user-1", "0xabc", 1)
+			if err != nil {
+				t.Fatalf("VerifyAndLogTransaction() error = %v", err)
+			}
+			if tx.Type != wallet.TransactionTypeCredit || tx.Amount != 500 || tx.Status != wallet.TransactionStatusSuccess {
+				t.Errorf("transaction = %+v, want a successful 500 USD credit", tx)
+			}
+			if got := repo.wallets["user-1"].Balance; got != 501 {
+				t.Errorf("balance = %v, want 501", got)
+			}
+
+			// Submitting the same hash again does not credit twice
+			again, err := uc.VerifyAndLogTransaction("user-1", "0xabc", 1)
+			if err != nil {
+				t.Fatalf("second VerifyAndLogTransaction() error = %v", err)
+			}
+			if again.ID != tx.ID {
+				t.Errorf("second call returned %s, want the original %s", again.ID, tx.ID)
+			}
+			if got := repo.wallets["user-1"].Balance; got != 501 {
+				t.Errorf("balance after resubmission = %v, want 501", got)
+			}
+		})
+	}
+}
+
+func TestVerifyAndLogTransaction_KeepsChainDetails(t *testing.T) {
+	uc, repo := newDepositFixture(t, testDepositAddress)
+	if _, err := uc.VerifyAndLogTransaction("user-1", "0xabc", 1); err != nil {
+		t.Fatalf("VerifyAndLogTransaction() error = %v", err)
+	}
+
+	history, _, err := NewWalletUseCase(repo, nil).GetTransactions("user-1", wallet.TransactionFilter{}, 1, 20)
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history has %d transactions, want 1", len(history))
+	}
+	tx := history[0]
+	if tx.TxHash != "0xabc" || tx.ChainID != 1 || tx.From != testSenderAddress || tx.To != testDepositAddress {
+		t.Errorf("history entry = %+v, want the verified hash, chain and addresses", tx)
+	}
+}
+
+func TestVerifyAndLogTransaction_RejectsOtherRecipients(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		chainID int64
+	}{
+		{"Different address", "0x2222222222222222222222222222222222222222", 1},
+		{"Contract creation", "", 1},
+		{"Chain without a deposit address", testDepositAddress, 137},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newDepositFixture(t, tt.to)
+
+			_, err := uc.VerifyAndLogTransaction("user-1", "0xabc", tt.chainID)
+			if !errors.Is(err, blockchain.ErrRecipientMismatch) {
+				t.Errorf("VerifyAndLogTransaction() error = %v, want ErrRecipientMismatch", err)
+			}
+			if len(repo.transactions) != 0 || repo.wallets["user-1"].Balance != 1 {
+				t.Error("a transaction to another recipient was recorded or credited")
+			}
+		})
+	}
+}
+
+func TestVerifyAndLogTransaction_RejectsOtherSenders(t *testing.T) {
+	uc, repo := newDepositFixture(t, testDepositAddress)
+
+	// user-2 links a different address than the one that sent the deposit
+	_, err := uc.VerifyAndLogTransaction("user-2", "0xabc", 1)
+	if !errors.Is(err, blockchain.ErrSenderMismatch) {
+		t.Errorf("VerifyAndLogTransaction() error = %v, want ErrSenderMismatch", err)
+	}
+	if len(repo.transactions) != 0 {
+		t.Error("a deposit from another sender was recorded")
+	}
+}
+
+func TestVerifyAndLogTransaction_RejectsClaimedDeposit(t *testing.T) {
+	uc, repo := newDepositFixture(t, testDepositAddress)
+	if _, err := uc.VerifyAndLogTransaction("user-1", "0xABC", 1); err != nil {
+		t.Fatalf("VerifyAndLogTransaction() error = %v", err)
+	}
+
+	// Another account linking the same address cannot claim it again
+	users := newMockUserRepo(&user.User{ID: "user-2", WalletAddress: testSenderAddress})
+	uc.userRepo = users
+	_, err := uc.VerifyAndLogTransaction("user-2", "0xabc", 1)
+	if !errors.Is(err, wallet.ErrDepositClaimed) {
+		t.Errorf("VerifyAndLogTransaction() error = %v, want ErrDepositClaimed", err)
+	}
+	if got := repo.wallets["user-2"].Balance; got != 0 {
+		t.Errorf("second claimant balance = %v, want 0", got)
+	}
+}
+
+func TestVerifyAndLogTransaction_RejectsUnconvertibleCurrency(t *testing.T) {
+	uc, repo := newDepositFixture(t, testDepositAddress)
+	repo.wallets["user-1"].Currency = wallet.CurrencyJAM
+
+	_, err := uc.VerifyAndLogTransaction("user-1", "0xabc", 1)
+	if !errors.Is(err, wallet.ErrCurrencyMismatch) {
+		t.Errorf("VerifyAndLogTransaction() error = %v, want ErrCurrencyMismatch", err)
+	}
+	if len(repo.transactions) != 0 || repo.wallets["user-1"].Balance != 1 {
+		t.Error("an unconvertible deposit was recorded or credited")
+	}
+}
@@ -1,20 +1,32 @@
 package usecase
 
 import (
+	"errors"
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
 // CartUseCase handles cart business logic
 type CartUseCase struct {
-	cartRepo cart.Repository
+	cartRepo        cart.Repository
+	productRepo     product.Repository
+	reservationRepo product.ReservationRepository
 }
 
+// cartReservationTTL is how long adding an item holds its stock for the cart
+const cartReservationTTL = 30 * time.Minute
+
 // NewCartUseCase creates a new cart use case
-func NewCartUseCase(cartRepo cart.Repository) *CartUseCase {
-	return &CartUseCase{cartRepo: cartRepo}
+func NewCartUseCase(cartRepo cart.Repository, productRepo product.Repository, reservationRepo product.ReservationRepository) *CartUseCase {
+	return &CartUseCase{
+		cartRepo:        cartRepo,
+		productRepo:     productRepo,
+		reservationRepo: reservationRepo,
+	}
 }
 
 // GetCartByUserID retrieves a cart by user ID
@@ -22,44 +34,270 @@ func (uc *CartUseCase) GetCartByUserID(userID string) (*cart.Cart, error) {
 	return uc.cartRepo.GetByUserID(userID)
 }
 
-// GetCartItems retrieves all items in a cart
+// GetOrCreateActiveCart returns the user's active cart, creating an empty one
+// when they have none
+func (uc *CartUseCase) GetOrCreateActiveCart(userID string) (*cart.Cart, error) {
+	c, err := uc.cartRepo.GetByUserID(userID)
+	if !errors.Is(err, cart.ErrNotFound) {
+		return c, err
+	}
+
+	now := time.Now()
+	c = &cart.Cart{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    cart.CartStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = uc.cartRepo.CreateCart(c)
+	if errors.Is(err, cart.ErrActiveCartExists) {
+		// A concurrent request created the cart first
+		return uc.cartRepo.GetByUserID(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCartItems retrieves all items in a cart, flagging items whose product
+// is no longer available
 func (uc *CartUseCase) GetCartItems(cartID string) ([]*cart.CartItem, error) {
-	return uc.cartRepo.GetItems(cartID)
+	items, err := uc.cartRepo.GetItems(cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.flagUnavailableItems(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// flagUnavailableItems marks items whose product was deleted, deactivated, or sold out
+func (uc *CartUseCase) flagUnavailableItems(items []*cart.CartItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	products, err := uc.productRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	available := make(map[string]bool, len(products))
+	for _, p := range products {
+		available[p.ID] = isProductAvailable(p)
+	}
+	for _, item := range items {
+		item.Unavailable = !available[item.ProductID]
+	}
+	return nil
+}
+
+// isProductAvailable reports whether a product can currently be bought
+func isProductAvailable(p *product.Product) bool {
+	return p.IsActive && p.Quantity > 0
+}
+
+// AddItemToCart adds an item to the cart at the product's current price,
+// reserving its stock so concurrent shoppers cannot claim the same units.
+// It returns an
+// *product.InsufficientStockError when too few units remain unreserved.
+func (uc *CartUseCase) AddItemToCart(cartID, productID string, quantity int) (*cart.CartItem, error) {
+	p, err := uc.purchasableProduct(productID, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return uc.addItem(cartID, p, quantity)
+}
+
+// purchasableProduct loads a product that is for sale with at least quantity
+// units in stock
+func (uc *CartUseCase) purchasableProduct(productID string, quantity int) (*product.Product, error) {
+	p, err := uc.productRepo.GetByID(productID)
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, &cart.ProductUnavailableError{ProductID: productID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isProductAvailable(p) {
+		return nil, &cart.ProductUnavailableError{ProductID: productID}
+	}
+	if quantity > p.Quantity {
+		return nil, &product.InsufficientStockError{ProductID: productID, Available: p.Quantity}
+	}
+	return p, nil
 }
 
-// AddItemToCart adds an item to the cart
-func (uc *CartUseCase) AddItemToCart(cartID, productID string, quantity int, price float64) (*cart.CartItem, error) {
+// addItem reserves quantity units of p for the cart and adds them as an item
+// priced at the product's current price
+func (uc *CartUseCase) addItem(cartID string, p *product.Product, quantity int) (*cart.CartItem, error) {
+	productID := p.ID
+	if err := uc.reserve(cartID, productID, quantity); err != nil {
+		return nil, err
+	}
+
 	item := &cart.CartItem{
 		ID:        uuid.New().String(),
 		CartID:    cartID,
 		ProductID: productID,
 		Quantity:  quantity,
-		Price:     price,
+		Price:     p.Price,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	err := uc.cartRepo.AddItem(item)
-	if err != nil {
+	if err := uc.cartRepo.AddItem(item); err != nil {
+		// Only the units just reserved; the cart may already hold others
+		if releaseErr := uc.reservationRepo.Unreserve(productID, cartID, quantity); releaseErr != nil {
+			log.Warn().Err(releaseErr).Str("product_id", productID).Str("cart_id", cartID).Msg("failed to release reservation")
+		}
 		return nil, err
 	}
 
 	// Update cart total
-	items, err := uc.cartRepo.GetItems(cartID)
+	if _, err := uc.cartRepo.RecalculateTotal(cartID); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// reserve adds quantity units of the product to the cart's stock hold
+func (uc *CartUseCase) reserve(cartID, productID string, quantity int) error {
+	now := time.Now()
+	err := uc.reservationRepo.Reserve(&product.Reservation{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		CartID:    cartID,
+		Quantity:  quantity,
+		ExpiresAt: now.Add(cartReservationTTL),
+		CreatedAt: now,
+	})
+	if errors.Is(err, product.ErrNotFound) {
+		return &cart.ProductUnavailableError{ProductID: productID}
+	}
+	return err
+}
+
+// MergeItem is a product and quantity carried over from a guest cart
+type MergeItem struct {
+	ProductID string
+	Quantity  int
+}
+
+// MergeRejection is a guest cart item left out of a merge. Err is a
+// *cart.ProductUnavailableError or *product.InsufficientStockError.
+type MergeRejection struct {
+	ProductID string
+	Err       error
+}
+
+// MergeItems adds the items of a guest cart to the user's active cart,
+// creating the cart if they have none. Quantities of the same product are
+// summed, both within items and with what the cart already holds, at the
+// product's current price. Items that are unavailable or exceed the
+// unreserved stock are skipped and reported so one stale item does not
+// block the rest.
+func (uc *CartUseCase) MergeItems(userID string, items []MergeItem) (*cart.Cart, []MergeRejection, error) {
+	c, err := uc.GetOrCreateActiveCart(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var productIDs []string
+	quantities := make(map[string]int, len(items))
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	var rejected []MergeRejection
+	for _, productID := range productIDs {
+		quantity := quantities[productID]
+		p, err := uc.purchasableProduct(productID, quantity)
+		if err == nil {
+			_, err = uc.addItem(c.ID, p, quantity)
+		}
+		var unavailable *cart.ProductUnavailableError
+		var insufficient *product.InsufficientStockError
+		if errors.As(err, &unavailable) || errors.As(err, &insufficient) {
+			rejected = append(rejected, MergeRejection{ProductID: productID, Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Reload for the recalculated total
+	c, err = uc.cartRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, rejected, nil
+}
+
+// UpdateItemQuantity sets the quantity of an item in the user's active cart,
+// growing or shrinking its stock reservation to match. It returns
+// cart.ErrItemNotFound for items outside the user's cart and an
+// *product.InsufficientStockError when the new quantity cannot be covered.
+func (uc *CartUseCase) UpdateItemQuantity(userID, itemID string, quantity int) (*cart.CartItem, error) {
+	item, err := uc.userCartItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if quantity == item.Quantity {
+		return item, nil
+	}
+	if _, err := uc.purchasableProduct(item.ProductID, quantity); err != nil {
+		return nil, err
+	}
+
+	if quantity > item.Quantity {
+		err = uc.reserve(item.CartID, item.ProductID, quantity-item.Quantity)
+	} else {
+		err = uc.reservationRepo.Unreserve(item.ProductID, item.CartID, item.Quantity-quantity)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	total := 0.0
-	for _, i := range items {
-		total += i.Price * float64(i.Quantity)
+	item.Quantity = quantity
+	if err := uc.UpdateCartItem(item); err != nil {
+		return nil, err
 	}
+	return item, nil
+}
 
-	err = uc.cartRepo.UpdateTotal(cartID, total)
+// userCartItem loads an item from the user's active cart, returning
+// cart.ErrItemNotFound when it belongs to another cart
+func (uc *CartUseCase) userCartItem(userID, itemID string) (*cart.CartItem, error) {
+	c, err := uc.cartRepo.GetByUserID(userID)
+	if errors.Is(err, cart.ErrNotFound) {
+		return nil, cart.ErrItemNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	item, err := uc.cartRepo.GetItemByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.CartID != c.ID {
+		return nil, cart.ErrItemNotFound
+	}
 	return item, nil
 }
 
@@ -72,41 +310,72 @@ func (uc *CartUseCase) UpdateCartItem(item *cart.CartItem) error {
 	}
 
 	// Update cart total
-	items, err := uc.cartRepo.GetItems(item.CartID)
+	_, err = uc.cartRepo.RecalculateTotal(item.CartID)
+	return err
+}
+
+// RemoveCartItem removes an item from the user's active cart and releases
+// its stock reservation. It returns cart.ErrItemNotFound for items outside
+// the user's cart.
+func (uc *CartUseCase) RemoveCartItem(userID, itemID string) error {
+	item, err := uc.userCartItem(userID, itemID)
 	if err != nil {
 		return err
 	}
 
-	total := 0.0
-	for _, i := range items {
-		total += i.Price * float64(i.Quantity)
+	if err := uc.cartRepo.RemoveItem(item.ID); err != nil {
+		return err
+	}
+	if err := uc.reservationRepo.Release(item.ProductID, item.CartID); err != nil {
+		log.Warn().Err(err).Str("product_id", item.ProductID).Str("cart_id", item.CartID).Msg("failed to release reservation")
 	}
 
-	return uc.cartRepo.UpdateTotal(item.CartID, total)
+	// Update cart total
+	_, err = uc.cartRepo.RecalculateTotal(item.CartID)
+	return err
 }
 
-// RemoveCartItem removes an item from the cart
-func (uc *CartUseCase) RemoveCartItem(cartID, itemID string) error {
-	err := uc.cartRepo.RemoveItem(itemID)
+// ClearCart empties the user's active cart, releasing the stock its items
+// held and zeroing the total. A user without a cart has nothing to clear.
+func (uc *CartUseCase) ClearCart(userID string) error {
+	c, err := uc.cartRepo.GetByUserID(userID)
+	if errors.Is(err, cart.ErrNotFound) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	// Update cart total
-	items, err := uc.cartRepo.GetItems(cartID)
+	items, err := uc.cartRepo.GetItems(c.ID)
 	if err != nil {
 		return err
 	}
-
-	total := 0.0
-	for _, i := range items {
-		total += i.Price * float64(i.Quantity)
+	if err := uc.cartRepo.ClearItems(c.ID); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := uc.reservationRepo.Release(item.ProductID, c.ID); err != nil {
+			log.Warn().Err(err).Str("product_id", item.ProductID).Str("cart_id", c.ID).Msg("failed to release reservation")
+		}
 	}
 
-	return uc.cartRepo.UpdateTotal(cartID, total)
+	_, err = uc.cartRepo.RecalculateTotal(c.ID)
+	return err
 }
 
-// CheckoutCart converts cart to checked out status
+// CheckoutCart converts cart to checked out status, refusing carts that
+// still hold unavailable products
 func (uc *CartUseCase) CheckoutCart(cartID string) error {
+	items, err := uc.GetCartItems(cartID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Unavailable {
+			return &cart.ProductUnavailableError{ProductID: item.ProductID}
+		}
+	}
+
 	return uc.cartRepo.SetStatus(cartID, cart.CartStatusCheckedOut)
 }
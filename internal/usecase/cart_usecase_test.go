@@ -0,0 +1,441 @@
+package usecase
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+)
+
+func newCartFixture(productIDs ...string) (*CartUseCase, *mockCartRepo, *mockProductRepo) {
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "user-1", Status: cart.CartStatusActive})
+	productRepo := newMockProductRepo()
+	for _, id := range productIDs {
+		productRepo.products[id] = &product.Product{ID: id, SellerID: "seller-a", Price: 10, Quantity: 100, IsActive: true}
+	}
+	reservationRepo := newMockReservationRepo()
+	reservationRepo.products = productRepo
+	return NewCartUseCase(cartRepo, productRepo, reservationRepo), cartRepo, productRepo
+}
+
+func TestCartUseCase_AddItemRecalculatesTotal(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture("product-a", "product-b")
+
+	adds := []struct {
+		productID string
+		quantity  int
+	}{
+		{"product-a", 2},
+		{"product-b", 1},
+		{"product-a", 1},
+	}
+	for _, add := range adds {
+		if _, err := uc.AddItemToCart("cart-1", add.productID, add.quantity); err != nil {
+			t.Fatalf("AddItemToCart(%s) error = %v", add.productID, err)
+		}
+	}
+
+	if got := cartRepo.carts["cart-1"].Total; got != 40 {
+		t.Errorf("cart total = %v, want 40", got)
+	}
+}
+
+func TestCartUseCase_RemoveItemRecalculatesTotal(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture("product-1", "product-2")
+
+	first, err := uc.AddItemToCart("cart-1", "product-1", 2)
+	if err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+	if _, err := uc.AddItemToCart("cart-1", "product-2", 1); err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+
+	if err := uc.RemoveCartItem("user-1", first.ID); err != nil {
+		t.Fatalf("RemoveCartItem() error = %v", err)
+	}
+
+	if got := cartRepo.carts["cart-1"].Total; got != 10 {
+		t.Errorf("cart total after removal = %v, want 10", got)
+	}
+}
+
+func TestAddItemToCart_RejectsUnavailableProduct(t *testing.T) {
+	uc, _, productRepo := newCartFixture("inactive", "sold-out")
+	productRepo.products["inactive"].IsActive = false
+	productRepo.products["sold-out"].Quantity = 0
+
+	for _, productID := range []string{"inactive", "sold-out", "deleted"} {
+		t.Run(productID, func(t *testing.T) {
+			_, err := uc.AddItemToCart("cart-1", productID, 1)
+			if !errors.Is(err, cart.ErrProductUnavailable) {
+				t.Fatalf("AddItemToCart() error = %v, want ErrProductUnavailable", err)
+			}
+			var unavailable *cart.ProductUnavailableError
+			if !errors.As(err, &unavailable) || unavailable.ProductID != productID {
+				t.Errorf("error does not carry product ID %q: %v", productID, err)
+			}
+		})
+	}
+}
+
+func TestAddItemToCart_LookupErrorIsNotUnavailable(t *testing.T) {
+	uc, _, productRepo := newCartFixture("product-a")
+	dbErr := errors.New("connection reset")
+	productRepo.getErr = dbErr
+
+	_, err := uc.AddItemToCart("cart-1", "product-a", 1)
+	if !errors.Is(err, dbErr) || errors.Is(err, cart.ErrProductUnavailable) {
+		t.Errorf("AddItemToCart() error = %v, want the lookup error", err)
+	}
+}
+
+func TestGetCartItems_FlagsNowInactiveProduct(t *testing.T) {
+	uc, _, productRepo := newCartFixture("product-1", "product-2")
+
+	for _, id := range []string{"product-1", "product-2"} {
+		if _, err := uc.AddItemToCart("cart-1", id, 1); err != nil {
+			t.Fatalf("AddItemToCart() error = %v", err)
+		}
+	}
+
+	// product-2 is deactivated after it was added to the cart
+	productRepo.products["product-2"].IsActive = false
+
+	items, err := uc.GetCartItems("cart-1")
+	if err != nil {
+		t.Fatalf("GetCartItems() error = %v", err)
+	}
+	for _, item := range items {
+		want := item.ProductID == "product-2"
+		if item.Unavailable != want {
+			t.Errorf("item for %s unavailable = %v, want %v", item.ProductID, item.Unavailable, want)
+		}
+	}
+
+	err = uc.CheckoutCart("cart-1")
+	var unavailable *cart.ProductUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.ProductID != "product-2" {
+		t.Errorf("CheckoutCart() error = %v, want ProductUnavailableError for product-2", err)
+	}
+}
+
+func TestAddItemToCart_InsufficientStock(t *testing.T) {
+	uc, cartRepo, productRepo := newCartFixture("coffee")
+	productRepo.products["coffee"].Quantity = 3
+
+	_, err := uc.AddItemToCart("cart-1", "coffee", 4)
+	var insufficient *product.InsufficientStockError
+	if !errors.As(err, &insufficient) || !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("AddItemToCart() over stock error = %v, want InsufficientStockError", err)
+	}
+	if insufficient.Available != 3 {
+		t.Errorf("Available = %d, want 3", insufficient.Available)
+	}
+
+	if _, err := uc.AddItemToCart("cart-1", "coffee", 2); err != nil {
+		t.Fatalf("AddItemToCart() within stock error = %v", err)
+	}
+	// Two of three units are now held, so a further two cannot be reserved
+	if _, err := uc.AddItemToCart("cart-1", "coffee", 2); !errors.Is(err, product.ErrInsufficientStock) {
+		t.Errorf("AddItemToCart() past reserved stock error = %v, want ErrInsufficientStock", err)
+	}
+	if len(cartRepo.items) != 1 {
+		t.Errorf("cart items = %d, want 1", len(cartRepo.items))
+	}
+}
+
+func TestAddItemToCart_FailedAddReleasesOnlyNewUnits(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture("coffee")
+
+	if _, err := uc.AddItemToCart("cart-1", "coffee", 2); err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+	cartRepo.addItemErr = errors.New("connection reset")
+	if _, err := uc.AddItemToCart("cart-1", "coffee", 3); err == nil {
+		t.Fatal("AddItemToCart() succeeded, want the repository error")
+	}
+
+	reserved, _ := uc.reservationRepo.ReservedQuantities([]string{"coffee"})
+	if reserved["coffee"] != 2 {
+		t.Errorf("reserved = %d, want the 2 units already in the cart", reserved["coffee"])
+	}
+}
+
+func TestAddItemToCart_ConcurrentLastUnit(t *testing.T) {
+	cartRepo := newMockCartRepo(
+		&cart.Cart{ID: "cart-1", UserID: "user-1", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "cart-2", UserID: "user-2", Status: cart.CartStatusActive},
+	)
+	productRepo := newMockProductRepo(&product.Product{ID: "last", Price: 10, Quantity: 1, IsActive: true})
+	reservationRepo := newMockReservationRepo()
+	reservationRepo.products = productRepo
+	uc := NewCartUseCase(cartRepo, productRepo, reservationRepo)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for _, cartID := range []string{"cart-1", "cart-2"} {
+		wg.Add(1)
+		go func(cartID string) {
+			defer wg.Done()
+			_, err := uc.AddItemToCart(cartID, "last", 1)
+			results <- err
+		}(cartID)
+	}
+	wg.Wait()
+	close(results)
+
+	var succeeded, rejected int
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, product.ErrInsufficientStock):
+			rejected++
+		default:
+			t.Fatalf("AddItemToCart() unexpected error = %v", err)
+		}
+	}
+	if succeeded != 1 || rejected != 1 {
+		t.Errorf("succeeded = %d, rejected = %d; want exactly one shopper to get the last unit", succeeded, rejected)
+	}
+}
+
+func TestMergeItems_CreatesCart(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture("product-1", "product-2")
+
+	c, rejected, err := uc.MergeItems("guest-turned-user", []MergeItem{
+		{ProductID: "product-1", Quantity: 2},
+		{ProductID: "product-2", Quantity: 1},
+		{ProductID: "product-1", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("MergeItems() error = %v", err)
+	}
+	if len(rejected) != 0 {
+		t.Errorf("rejected = %v, want none", rejected)
+	}
+	if c.UserID != "guest-turned-user" || c.Status != cart.CartStatusActive {
+		t.Errorf("cart = %+v, want a new active cart for the user", c)
+	}
+
+	items, _ := cartRepo.GetItems(c.ID)
+	got := make(map[string]int)
+	for _, item := range items {
+		got[item.ProductID] = item.Quantity
+		if item.Price != 10 {
+			t.Errorf("%s price = %v, want the product price 10", item.ProductID, item.Price)
+		}
+	}
+	if got["product-1"] != 3 || got["product-2"] != 1 || len(got) != 2 {
+		t.Errorf("cart quantities = %v, want product-1: 3, product-2: 1", got)
+	}
+	if c.Total != 40 {
+		t.Errorf("cart total = %v, want 40", c.Total)
+	}
+}
+
+func TestMergeItems_SumsWithExistingItems(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture("product-1", "product-2")
+	if _, err := uc.AddItemToCart("cart-1", "product-1", 2); err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+
+	c, _, err := uc.MergeItems("user-1", []MergeItem{
+		{ProductID: "product-1", Quantity: 3},
+		{ProductID: "product-2", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("MergeItems() error = %v", err)
+	}
+	if c.ID != "cart-1" {
+		t.Errorf("merged into cart %s, want the existing cart-1", c.ID)
+	}
+
+	items, _ := cartRepo.GetItems("cart-1")
+	got := make(map[string]int)
+	for _, item := range items {
+		got[item.ProductID] = item.Quantity
+	}
+	if got["product-1"] != 5 || got["product-2"] != 1 || len(got) != 2 {
+		t.Errorf("cart quantities = %v, want product-1: 5, product-2: 1", got)
+	}
+	if c.Total != 60 {
+		t.Errorf("cart total = %v, want 60", c.Total)
+	}
+}
+
+func TestMergeItems_RejectsOverStock(t *testing.T) {
+	uc, cartRepo, productRepo := newCartFixture("scarce", "plenty", "inactive")
+	productRepo.products["scarce"].Quantity = 3
+	productRepo.products["inactive"].IsActive = false
+	if _, err := uc.AddItemToCart("cart-1", "scarce", 2); err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+
+	_, rejected, err := uc.MergeItems("user-1", []MergeItem{
+		{ProductID: "scarce", Quantity: 2},
+		{ProductID: "plenty", Quantity: 1},
+		{ProductID: "inactive", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("MergeItems() error = %v", err)
+	}
+
+	if len(rejected) != 2 {
+		t.Fatalf("rejected = %v, want scarce and inactive", rejected)
+	}
+	var insufficient *product.InsufficientStockError
+	if rejected[0].ProductID != "scarce" || !errors.As(rejected[0].Err, &insufficient) || insufficient.Available != 1 {
+		t.Errorf("rejected[0] = %+v, want scarce with 1 available", rejected[0])
+	}
+	if rejected[1].ProductID != "inactive" || !errors.Is(rejected[1].Err, cart.ErrProductUnavailable) {
+		t.Errorf("rejected[1] = %+v, want inactive as unavailable", rejected[1])
+	}
+
+	items, _ := cartRepo.GetItems("cart-1")
+	got := make(map[string]int)
+	for _, item := range items {
+		got[item.ProductID] = item.Quantity
+	}
+	if got["scarce"] != 2 || got["plenty"] != 1 || len(got) != 2 {
+		t.Errorf("cart quantities = %v, want scarce unchanged at 2 and plenty added", got)
+	}
+}
+
+func TestGetOrCreateActiveCart_ConcurrentCreate(t *testing.T) {
+	uc, cartRepo, _ := newCartFixture()
+
+	var wg sync.WaitGroup
+	ids := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := uc.GetOrCreateActiveCart("new-user")
+			if err != nil {
+				t.Errorf("GetOrCreateActiveCart() error = %v", err)
+				return
+			}
+			ids <- c.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		seen[id] = true
+	}
+	if len(seen) != 1 || len(cartRepo.carts) != 2 {
+		t.Errorf("got carts %v (%d stored), want one shared cart", seen, len(cartRepo.carts))
+	}
+}
+
+func TestUpdateItemQuantity_AdjustsReservation(t *testing.T) {
+	cartRepo := newMockCartRepo(
+		&cart.Cart{ID: "cart-1", UserID: "user-1", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "cart-2", UserID: "user-2", Status: cart.CartStatusActive},
+	)
+	productRepo := newMockProductRepo(&product.Product{ID: "coffee", Price: 10, Quantity: 3, IsActive: true})
+	reservationRepo := newMockReservationRepo()
+	reservationRepo.products = productRepo
+	uc := NewCartUseCase(cartRepo, productRepo, reservationRepo)
+
+	item, err := uc.AddItemToCart("cart-1", "coffee", 1)
+	if err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+
+	if _, err := uc.UpdateItemQuantity("user-1", item.ID, 3); err != nil {
+		t.Fatalf("UpdateItemQuantity() up to stock error = %v", err)
+	}
+	if got := cartRepo.carts["cart-1"].Total; got != 30 {
+		t.Errorf("cart total = %v, want 30", got)
+	}
+	// Every unit is now held by cart-1
+	if _, err := uc.AddItemToCart("cart-2", "coffee", 1); !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("AddItemToCart() while held error = %v, want ErrInsufficientStock", err)
+	}
+
+	if _, err := uc.UpdateItemQuantity("user-1", item.ID, 1); err != nil {
+		t.Fatalf("UpdateItemQuantity() down error = %v", err)
+	}
+	// Shrinking the item frees two units for other shoppers
+	if _, err := uc.AddItemToCart("cart-2", "coffee", 2); err != nil {
+		t.Errorf("AddItemToCart() after release error = %v", err)
+	}
+
+	if _, err := uc.UpdateItemQuantity("user-2", item.ID, 2); !errors.Is(err, cart.ErrItemNotFound) {
+		t.Errorf("UpdateItemQuantity() on another user's item error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestClearCart(t *testing.T) {
+	cartRepo := newMockCartRepo(
+		&cart.Cart{ID: "cart-1", UserID: "user-1", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "cart-2", UserID: "user-2", Status: cart.CartStatusActive},
+	)
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "product-1", Price: 10, Quantity: 2, IsActive: true},
+		&product.Product{ID: "product-2", Price: 5, Quantity: 10, IsActive: true},
+	)
+	reservationRepo := newMockReservationRepo()
+	reservationRepo.products = productRepo
+	uc := NewCartUseCase(cartRepo, productRepo, reservationRepo)
+
+	for _, add := range []struct {
+		cartID, productID string
+		quantity          int
+	}{{"cart-1", "product-1", 2}, {"cart-1", "product-2", 3}, {"cart-2", "product-2", 1}} {
+		if _, err := uc.AddItemToCart(add.cartID, add.productID, add.quantity); err != nil {
+			t.Fatalf("AddItemToCart() error = %v", err)
+		}
+	}
+
+	if err := uc.ClearCart("user-1"); err != nil {
+		t.Fatalf("ClearCart() error = %v", err)
+	}
+
+	if items, _ := cartRepo.GetItems("cart-1"); len(items) != 0 {
+		t.Errorf("cart-1 items = %d, want 0", len(items))
+	}
+	if got := cartRepo.carts["cart-1"].Total; got != 0 {
+		t.Errorf("cart-1 total = %v, want 0", got)
+	}
+	if items, _ := cartRepo.GetItems("cart-2"); len(items) != 1 {
+		t.Errorf("cart-2 items = %d, want its item kept", len(items))
+	}
+	// The released units can be reserved again
+	if _, err := uc.AddItemToCart("cart-2", "product-1", 2); err != nil {
+		t.Errorf("AddItemToCart() after clear error = %v", err)
+	}
+
+	if err := uc.ClearCart("user-without-cart"); err != nil {
+		t.Errorf("ClearCart() without a cart error = %v", err)
+	}
+}
+
+func TestAddItemToCart_SnapshotsProductPrice(t *testing.T) {
+	uc, cartRepo, productRepo := newCartFixture("product-1")
+	productRepo.products["product-1"].Price = 12.5
+
+	item, err := uc.AddItemToCart("cart-1", "product-1", 2)
+	if err != nil {
+		t.Fatalf("AddItemToCart() error = %v", err)
+	}
+	if item.Price != 12.5 {
+		t.Errorf("item price = %v, want 12.5", item.Price)
+	}
+
+	// A later price change does not reprice the item already in the cart
+	productRepo.products["product-1"].Price = 20
+	if got := cartRepo.items[item.ID].Price; got != 12.5 {
+		t.Errorf("stored price = %v, want the 12.5 snapshot", got)
+	}
+	if got := cartRepo.carts["cart-1"].Total; got != 25 {
+		t.Errorf("cart total = %v, want 25", got)
+	}
+}
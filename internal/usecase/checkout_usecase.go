@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// CheckoutUseCase converts carts into orders
+type CheckoutUseCase struct {
+	checkoutRepo checkout.Repository
+	productRepo  product.Repository
+}
+
+// NewCheckoutUseCase creates a new checkout use case
+func NewCheckoutUseCase(checkoutRepo checkout.Repository, productRepo product.Repository) *CheckoutUseCase {
+	return &CheckoutUseCase{checkoutRepo: checkoutRepo, productRepo: productRepo}
+}
+
+// Checkout turns the user's active cart into a pending order in a single
+// transaction: the order and its items are created, stock is decremented,
+// and the cart is marked checked out. The total is always computed from the
+// cart items. If any step fails nothing is written.
+func (uc *CheckoutUseCase) Checkout(userID, cartID string) (*order.Order, []*order.OrderItem, error) {
+	var (
+		o     *order.Order
+		items []*order.OrderItem
+	)
+
+	err := uc.checkoutRepo.WithinTx(func(tx checkout.Tx) error {
+		c, err := tx.GetCart(cartID)
+		if err != nil {
+			return err
+		}
+		if c.UserID != userID {
+			return checkout.ErrCartNotFound
+		}
+		if c.Status != cart.CartStatusActive {
+			return checkout.ErrCartNotActive
+		}
+
+		cartItems, err := tx.GetCartItems(cartID)
+		if err != nil {
+			return err
+		}
+		if len(cartItems) == 0 {
+			return cart.ErrEmptyCart
+		}
+		if err := validateOrderProducts(uc.productRepo, cartItemProductIDs(cartItems)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		o = &order.Order{
+			ID:         uuid.New().String(),
+			UserID:     userID,
+			CartID:     cartID,
+			Status:     order.OrderStatusPending,
+			Total:      cartItemsTotal(cartItems),
+			PaymentRef: newPaymentRef(),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := tx.CreateOrder(o); err != nil {
+			return err
+		}
+
+		items = make([]*order.OrderItem, 0, len(cartItems))
+		for _, ci := range cartItems {
+			items = append(items, &order.OrderItem{
+				ID:        uuid.New().String(),
+				OrderID:   o.ID,
+				ProductID: ci.ProductID,
+				Quantity:  ci.Quantity,
+				Price:     ci.Price,
+				Status:    order.ItemStatusPending,
+			})
+		}
+		if err := tx.CreateOrderItems(items); err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := tx.DecrementStock(item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.SetCartStatus(cartID, cart.CartStatusCheckedOut); err != nil {
+			return err
+		}
+		return tx.ReleaseReservations(cartID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return o, items, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+)
+
+func newCheckoutFixture() (*CheckoutUseCase, *mockCheckoutRepo) {
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "user-1", Status: cart.CartStatusActive})
+	cartRepo.items["item-a"] = &cart.CartItem{ID: "item-a", CartID: "cart-1", ProductID: "product-a", Quantity: 2, Price: 10.25}
+	cartRepo.items["item-b"] = &cart.CartItem{ID: "item-b", CartID: "cart-1", ProductID: "product-b", Quantity: 1, Price: 5}
+
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "product-a", Price: 10.25, Quantity: 5, IsActive: true},
+		&product.Product{ID: "product-b", Price: 5, Quantity: 3, IsActive: true},
+	)
+	reservationRepo := newMockReservationRepo(
+		&product.Reservation{ProductID: "product-a", CartID: "cart-1", Quantity: 2, ExpiresAt: time.Now().Add(time.Hour)},
+	)
+
+	repo := &mockCheckoutRepo{
+		carts:        cartRepo,
+		products:     productRepo,
+		orders:       newMockOrderRepo(),
+		reservations: reservationRepo,
+	}
+	return NewCheckoutUseCase(repo, productRepo), repo
+}
+
+func TestCheckout(t *testing.T) {
+	uc, repo := newCheckoutFixture()
+
+	o, items, err := uc.Checkout("user-1", "cart-1")
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if o.Total != 25.5 {
+		t.Errorf("order total = %v, want 25.5", o.Total)
+	}
+	if len(items) != 2 {
+		t.Errorf("order items = %d, want 2", len(items))
+	}
+	if _, ok := repo.orders.orders[o.ID]; !ok {
+		t.Error("order was not persisted")
+	}
+	if found, err := repo.orders.GetByPaymentRef(o.PaymentRef); o.PaymentRef == "" || err != nil || found.ID != o.ID {
+		t.Errorf("order payment reference %q does not find the order: %v", o.PaymentRef, err)
+	}
+	if got := repo.products.products["product-a"].Quantity; got != 3 {
+		t.Errorf("product-a stock = %d, want 3", got)
+	}
+	if got := repo.products.products["product-b"].Quantity; got != 2 {
+		t.Errorf("product-b stock = %d, want 2", got)
+	}
+	if got := repo.carts.carts["cart-1"].Status; got != cart.CartStatusCheckedOut {
+		t.Errorf("cart status = %s, want %s", got, cart.CartStatusCheckedOut)
+	}
+	if len(repo.reservations.reservations) != 0 {
+		t.Error("reservations were not released")
+	}
+}
+
+func TestCheckout_ErrorCommitsNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(repo *mockCheckoutRepo)
+		wantErr error
+	}{
+		{
+			name: "Stock update fails mid-transaction",
+			setup: func(repo *mockCheckoutRepo) {
+				repo.failStock = map[string]error{"product-b": errors.New("connection reset")}
+			},
+		},
+		{
+			name: "Insufficient stock",
+			setup: func(repo *mockCheckoutRepo) {
+				repo.products.products["product-b"].Quantity = 0
+			},
+			wantErr: product.ErrInsufficientStock,
+		},
+		{
+			name: "Product no longer for sale",
+			setup: func(repo *mockCheckoutRepo) {
+				repo.products.products["product-b"].IsActive = false
+			},
+			wantErr: order.ErrInvalidProducts,
+		},
+		{
+			name:    "Empty cart",
+			setup:   func(repo *mockCheckoutRepo) { repo.carts.items = map[string]*cart.CartItem{} },
+			wantErr: cart.ErrEmptyCart,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newCheckoutFixture()
+			tt.setup(repo)
+			stockA := repo.products.products["product-a"].Quantity
+			stockB := repo.products.products["product-b"].Quantity
+
+			_, _, err := uc.Checkout("user-1", "cart-1")
+			if err == nil {
+				t.Fatal("Checkout() succeeded, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Checkout() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(repo.orders.orders) != 0 || len(repo.orders.items) != 0 {
+				t.Error("order was written despite the error")
+			}
+			if got := repo.products.products["product-a"].Quantity; got != stockA {
+				t.Errorf("product-a stock = %d, want %d", got, stockA)
+			}
+			if got := repo.products.products["product-b"].Quantity; got != stockB {
+				t.Errorf("product-b stock = %d, want %d", got, stockB)
+			}
+			if got := repo.carts.carts["cart-1"].Status; got != cart.CartStatusActive {
+				t.Errorf("cart status = %s, want %s", got, cart.CartStatusActive)
+			}
+			if len(repo.reservations.reservations) != 1 {
+				t.Error("reservations were released despite the error")
+			}
+		})
+	}
+}
+
+func TestCheckout_RejectsOtherUsersCart(t *testing.T) {
+	uc, _ := newCheckoutFixture()
+
+	if _, _, err := uc.Checkout("user-2", "cart-1"); !errors.Is(err, checkout.ErrCartNotFound) {
+		t.Errorf("Checkout() error = %v, want %v", err, checkout.ErrCartNotFound)
+	}
+}
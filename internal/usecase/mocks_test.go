@@ -0,0 +1,1543 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/notification"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+// mockOrderRepo is an in-memory order.Repository for tests
+type mockOrderRepo struct {
+	mu     sync.Mutex
+	orders map[string]*order.Order
+	items  map[string][]*order.OrderItem
+	// products backs the join in GetItemsWithProduct
+	products      *mockProductRepo
+	paymentEvents map[string]*order.PaymentEvent
+}
+
+func newMockOrderRepo() *mockOrderRepo {
+	return &mockOrderRepo{
+		orders:        make(map[string]*order.Order),
+		items:         make(map[string][]*order.OrderItem),
+		paymentEvents: make(map[string]*order.PaymentEvent),
+	}
+}
+
+func (m *mockOrderRepo) Create(o *order.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.orders {
+		if o.PaymentRef != "" && existing.PaymentRef == o.PaymentRef {
+			return order.ErrDuplicatePaymentRef
+		}
+	}
+	m.orders[o.ID] = o
+	return nil
+}
+
+func (m *mockOrderRepo) GetByID(id string) (*order.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil, errors.New("order not found")
+	}
+	return o, nil
+}
+
+func (m *mockOrderRepo) GetByPaymentRef(paymentRef string) (*order.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found *order.Order
+	for _, o := range m.orders {
+		if o.PaymentRef != paymentRef {
+			continue
+		}
+		if found != nil {
+			return nil, order.ErrDuplicatePaymentRef
+		}
+		found = o
+	}
+	if found == nil {
+		return nil, order.ErrNotFound
+	}
+	return found, nil
+}
+
+func (m *mockOrderRepo) ApplyPaymentEvent(e *order.PaymentEvent) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.paymentEvents[e.ID]; ok {
+		return false, order.ErrDuplicatePaymentEvent
+	}
+	m.paymentEvents[e.ID] = e
+	o, ok := m.orders[e.OrderID]
+	if !ok || o.Status != order.OrderStatusPending {
+		return false, nil
+	}
+	o.Status = order.OrderStatusPaid
+	return true, nil
+}
+
+func (m *mockOrderRepo) GetByUserID(userID string, page, pageSize int) ([]*order.Order, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var orders []*order.Order
+	for _, o := range m.orders {
+		if o.UserID == userID {
+			orders = append(orders, o)
+		}
+	}
+	return orders, len(orders), nil
+}
+
+func (m *mockOrderRepo) GetItems(orderID string) ([]*order.OrderItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.items[orderID], nil
+}
+
+// GetItemsWithProduct mirrors the LEFT JOIN against products
+func (m *mockOrderRepo) GetItemsWithProduct(orderID string) ([]*order.OrderItemWithProduct, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var items []*order.OrderItemWithProduct
+	for _, item := range m.items[orderID] {
+		joined := &order.OrderItemWithProduct{OrderItem: *item, Images: []string{}}
+		if m.products != nil {
+			if p, err := m.products.GetByID(item.ProductID); err == nil {
+				if p.Title != "" {
+					joined.Title = p.Title
+				}
+				if p.Images != nil {
+					joined.Images = p.Images
+				}
+				joined.ProductAvailable = p.IsActive && p.DeletedAt == nil
+			}
+		}
+		items = append(items, joined)
+	}
+	return items, nil
+}
+
+func (m *mockOrderRepo) UpdateStatus(orderID string, from, to order.OrderStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[orderID]
+	if !ok || o.Status != from {
+		return order.ErrStatusConflict
+	}
+	o.Status = to
+	return nil
+}
+
+func (m *mockOrderRepo) UpdateItemsStatus(orderID string, itemIDs []string, status order.ItemStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		ids[id] = true
+	}
+	for _, item := range m.items[orderID] {
+		if ids[item.ID] {
+			item.Status = status
+		}
+	}
+	return nil
+}
+
+// mockProductRepo is an in-memory product.Repository for tests
+type mockProductRepo struct {
+	mu           sync.Mutex
+	products     map[string]*product.Product
+	priceChanges []*product.PriceChange
+	variants     map[string]*product.ImageVariants
+	// uploads maps each recorded image URL to the seller who uploaded it
+	uploads map[string]string
+	// getErr makes GetByID fail as if the database were unreachable
+	getErr error
+	// staleSlugChecks makes that many SlugExists calls miss existing slugs,
+	// as if another product claimed the slug right after the check
+	staleSlugChecks int
+}
+
+func newMockProductRepo(products ...*product.Product) *mockProductRepo {
+	m := &mockProductRepo{
+		products: make(map[string]*product.Product),
+		variants: make(map[string]*product.ImageVariants),
+		uploads:  make(map[string]string),
+	}
+	for _, p := range products {
+		m.products[p.ID] = p
+	}
+	return m
+}
+
+func (m *mockProductRepo) Create(p *product.Product) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.products {
+		if p.Slug != "" && existing.Slug == p.Slug {
+			return product.ErrSlugTaken
+		}
+	}
+	cp := *p
+	m.products[p.ID] = &cp
+	return nil
+}
+
+func (m *mockProductRepo) GetByID(id string) (*product.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	p, ok := m.products[id]
+	if !ok {
+		return nil, product.ErrNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (m *mockProductRepo) GetByIDWithCategory(id string) (*product.ProductWithCategory, error) {
+	p, err := m.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &product.ProductWithCategory{
+		ID:          p.ID,
+		SellerID:    p.SellerID,
+		Title:       p.Title,
+		Slug:        p.Slug,
+		Description: p.Description,
+		Price:       p.Price,
+		Quantity:    p.Quantity,
+		Images:      p.Images,
+		CategoryID:  p.CategoryID,
+		IsActive:    p.IsActive,
+		DeletedAt:   p.DeletedAt,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}, nil
+}
+
+func (m *mockProductRepo) GetBySlug(slug string) (*product.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.products {
+		if p.Slug == slug {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, product.ErrNotFound
+}
+
+func (m *mockProductRepo) SlugExists(slug, excludeID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.staleSlugChecks > 0 {
+		m.staleSlugChecks--
+		return false, nil
+	}
+	for _, p := range m.products {
+		if p.Slug == slug && p.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockProductRepo) List(filters map[string]interface{}, page, pageSize int) ([]*product.Product, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var products []*product.Product
+	for _, p := range m.products {
+		if p.IsActive && p.DeletedAt == nil {
+			products = append(products, p)
+		}
+	}
+	return products, len(products), nil
+}
+
+func (m *mockProductRepo) ListWithCategory(filters map[string]interface{}, page, pageSize int, sortBy, sortOrder string) ([]*product.ProductWithCategory, int, error) {
+	products, total, err := m.List(filters, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	var result []*product.ProductWithCategory
+	for _, p := range products {
+		pc, _ := m.GetByIDWithCategory(p.ID)
+		result = append(result, pc)
+	}
+	return result, total, nil
+}
+
+// ListWithCategoryCursor pages by (created_at, id) whatever sortBy says,
+// which is enough to exercise cursor handling
+func (m *mockProductRepo) ListWithCategoryCursor(filters map[string]interface{}, cursor string, limit int, sortBy, sortOrder string) ([]*product.ProductWithCategory, string, error) {
+	field, desc := product.NormalizeSort(sortBy, sortOrder)
+	all, _, err := m.ListWithCategory(filters, 1, 0, sortBy, sortOrder)
+	if err != nil {
+		return nil, "", err
+	}
+	before := func(a, b *product.ProductWithCategory) bool {
+		if desc {
+			a, b = b, a
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(all, func(i, j int) bool { return before(all[i], all[j]) })
+
+	var result []*product.ProductWithCategory
+	if cursor == "" {
+		result = all
+	} else {
+		c, err := product.DecodeCursor(cursor, field, desc)
+		if err != nil {
+			return nil, "", err
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, c.Value)
+		pivot := &product.ProductWithCategory{ID: c.ID, CreatedAt: createdAt}
+		for _, p := range all {
+			if before(pivot, p) {
+				result = append(result, p)
+			}
+		}
+	}
+
+	if len(result) <= limit {
+		return result, "", nil
+	}
+	result = result[:limit]
+	return result, product.NewCursor(result[limit-1], field, desc).Encode(), nil
+}
+
+func (m *mockProductRepo) ListBySeller(sellerID string, page, pageSize int) ([]*product.ProductWithCategory, int, error) {
+	m.mu.Lock()
+	var ids []string
+	for _, p := range m.products {
+		if p.SellerID == sellerID && p.DeletedAt == nil {
+			ids = append(ids, p.ID)
+		}
+	}
+	m.mu.Unlock()
+	sort.Strings(ids)
+
+	var result []*product.ProductWithCategory
+	for _, id := range ids {
+		pc, _ := m.GetByIDWithCategory(id)
+		result = append(result, pc)
+	}
+	return result, len(result), nil
+}
+
+func (m *mockProductRepo) Update(p *product.Product) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.products[p.ID]; !ok {
+		return errors.New("product not found")
+	}
+	cp := *p
+	m.products[p.ID] = &cp
+	return nil
+}
+
+func (m *mockProductRepo) SoftDelete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.products[id]
+	if !ok {
+		return product.ErrNotFound
+	}
+	if p.DeletedAt == nil {
+		now := time.Now()
+		p.DeletedAt = &now
+	}
+	p.IsActive = false
+	return nil
+}
+
+func (m *mockProductRepo) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.products, id)
+	return nil
+}
+
+func (m *mockProductRepo) GetCategories() ([]*product.Category, error) {
+	return nil, nil
+}
+
+func (m *mockProductRepo) GetByIDs(ids []string) ([]*product.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var products []*product.Product
+	for _, id := range ids {
+		if p, ok := m.products[id]; ok {
+			cp := *p
+			products = append(products, &cp)
+		}
+	}
+	return products, nil
+}
+
+func (m *mockProductRepo) ListBySellerAndCategory(sellerID, categoryID string) ([]*product.Product, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var products []*product.Product
+	for _, p := range m.products {
+		if p.SellerID == sellerID && p.CategoryID == categoryID {
+			cp := *p
+			products = append(products, &cp)
+		}
+	}
+	return products, nil
+}
+
+func (m *mockProductRepo) UpdatePrices(changes []*product.PriceChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range changes {
+		p, ok := m.products[c.ProductID]
+		if !ok {
+			return errors.New("product not found")
+		}
+		p.Price = c.NewPrice
+		m.priceChanges = append(m.priceChanges, c)
+	}
+	return nil
+}
+
+func (m *mockProductRepo) SaveImageVariants(v *product.ImageVariants) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.variants[v.URL] = v
+	return nil
+}
+
+func (m *mockProductRepo) DeleteImageVariants(urls []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, url := range urls {
+		delete(m.variants, url)
+	}
+	return nil
+}
+
+func (m *mockProductRepo) RecordImageUploads(sellerID string, urls []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, url := range urls {
+		if _, ok := m.uploads[url]; !ok {
+			m.uploads[url] = sellerID
+		}
+	}
+	return nil
+}
+
+func (m *mockProductRepo) UploadedImages(sellerID string, urls []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var uploaded []string
+	for _, url := range urls {
+		if m.uploads[url] == sellerID {
+			uploaded = append(uploaded, url)
+		}
+	}
+	return uploaded, nil
+}
+
+// mockReservationRepo is an in-memory product.ReservationRepository for tests
+type mockReservationRepo struct {
+	mu           sync.Mutex
+	reservations []*product.Reservation
+	// products supplies stock levels for Reserve
+	products *mockProductRepo
+}
+
+func newMockReservationRepo(reservations ...*product.Reservation) *mockReservationRepo {
+	return &mockReservationRepo{reservations: reservations}
+}
+
+func (m *mockReservationRepo) ReservedQuantities(productIDs []string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := make(map[string]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+	reserved := make(map[string]int)
+	now := time.Now()
+	for _, r := range m.reservations {
+		if wanted[r.ProductID] && r.ExpiresAt.After(now) {
+			reserved[r.ProductID] += r.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+func (m *mockReservationRepo) Reserve(res *product.Reservation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.products.GetByID(res.ProductID)
+	if err != nil {
+		return product.ErrNotFound
+	}
+
+	now := time.Now()
+	var reserved int
+	var existing *product.Reservation
+	for _, r := range m.reservations {
+		if r.ProductID == res.ProductID && r.CartID == res.CartID {
+			existing = r
+		}
+		if r.ProductID == res.ProductID && r.ExpiresAt.After(now) {
+			reserved += r.Quantity
+		}
+	}
+
+	available := product.AvailableQuantity(p.Quantity, reserved)
+	if res.Quantity > available {
+		return &product.InsufficientStockError{ProductID: res.ProductID, Available: available}
+	}
+
+	switch {
+	case existing == nil:
+		cp := *res
+		m.reservations = append(m.reservations, &cp)
+	case existing.ExpiresAt.After(now):
+		existing.Quantity += res.Quantity
+		existing.ExpiresAt = res.ExpiresAt
+	default:
+		existing.Quantity = res.Quantity
+		existing.ExpiresAt = res.ExpiresAt
+	}
+	return nil
+}
+
+func (m *mockReservationRepo) Release(productID, cartID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.reservations[:0]
+	for _, r := range m.reservations {
+		if r.ProductID != productID || r.CartID != cartID {
+			kept = append(kept, r)
+		}
+	}
+	m.reservations = kept
+	return nil
+}
+
+func (m *mockReservationRepo) Unreserve(productID, cartID string, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.reservations[:0]
+	for _, r := range m.reservations {
+		if r.ProductID == productID && r.CartID == cartID {
+			r.Quantity -= quantity
+			if r.Quantity <= 0 {
+				continue
+			}
+		}
+		kept = append(kept, r)
+	}
+	m.reservations = kept
+	return nil
+}
+
+// mockAdjustmentRepo is an in-memory product.AdjustmentRepository for tests
+// that saves products to the given product repository
+type mockAdjustmentRepo struct {
+	mu          sync.Mutex
+	products    *mockProductRepo
+	adjustments []*product.InventoryAdjustment
+}
+
+func newMockAdjustmentRepo(products *mockProductRepo) *mockAdjustmentRepo {
+	return &mockAdjustmentRepo{products: products}
+}
+
+func (m *mockAdjustmentRepo) UpdateWithAdjustment(p *product.Product, userID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, err := m.products.GetByID(p.ID)
+	if err != nil {
+		return err
+	}
+	if err := m.products.Update(p); err != nil {
+		return err
+	}
+	if delta := p.Quantity - previous.Quantity; delta != 0 {
+		m.adjustments = append(m.adjustments, &product.InventoryAdjustment{
+			ID:        uuid.New().String(),
+			ProductID: p.ID,
+			UserID:    &userID,
+			Delta:     delta,
+			Reason:    reason,
+			CreatedAt: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (m *mockAdjustmentRepo) ListAdjustments(productID string, page, pageSize int) ([]*product.InventoryAdjustment, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var matched []*product.InventoryAdjustment
+	for i := len(m.adjustments) - 1; i >= 0; i-- {
+		if m.adjustments[i].ProductID == productID {
+			matched = append(matched, m.adjustments[i])
+		}
+	}
+	total := len(matched)
+	start := pagination.Params{Page: page, PageSize: pageSize}.Offset()
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return matched[start:end], total, nil
+}
+
+// mockPayoutRepo is an in-memory payout.Repository for tests. CompleteOrder
+// updates orders, and leaves everything unchanged when completeErr is set.
+type mockPayoutRepo struct {
+	mu          sync.Mutex
+	payouts     map[string]*payout.Payout
+	orders      *mockOrderRepo
+	completeErr error
+}
+
+func newMockPayoutRepo(orders *mockOrderRepo) *mockPayoutRepo {
+	return &mockPayoutRepo{payouts: make(map[string]*payout.Payout), orders: orders}
+}
+
+func (m *mockPayoutRepo) Accrue(payouts []*payout.Payout) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range payouts {
+		for _, existing := range m.payouts {
+			if existing.OrderID == p.OrderID && existing.SellerID == p.SellerID {
+				return payout.ErrAlreadyAccrued
+			}
+		}
+	}
+	for _, p := range payouts {
+		m.payouts[p.ID] = p
+	}
+	return nil
+}
+
+func (m *mockPayoutRepo) CompleteOrder(orderID string, payouts []*payout.Payout) (bool, error) {
+	if m.completeErr != nil {
+		return false, m.completeErr
+	}
+	exists, _ := m.ExistsForOrder(orderID)
+	if err := m.orders.UpdateStatus(orderID, order.OrderStatusShipped, order.OrderStatusCompleted); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	return true, m.Accrue(payouts)
+}
+
+func (m *mockPayoutRepo) ExistsForOrder(orderID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.payouts {
+		if p.OrderID == orderID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockPayoutRepo) GetByID(id string) (*payout.Payout, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.payouts[id]
+	if !ok {
+		return nil, payout.ErrNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (m *mockPayoutRepo) GetEarnings(sellerID string) (*payout.Earnings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &payout.Earnings{SellerID: sellerID}
+	for _, p := range m.payouts {
+		if p.SellerID != sellerID {
+			continue
+		}
+		if p.Status == payout.StatusPaid {
+			e.Paid += p.Net
+		} else {
+			e.Pending += p.Net
+		}
+	}
+	e.Total = e.Pending + e.Paid
+	return e, nil
+}
+
+func (m *mockPayoutRepo) MarkPaid(id string, paidAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.payouts[id]
+	if !ok || p.Status == payout.StatusPaid {
+		return payout.ErrAlreadyPaid
+	}
+	p.Status = payout.StatusPaid
+	p.PaidAt = &paidAt
+	return nil
+}
+
+// mockCartRepo is an in-memory cart.Repository for tests
+type mockCartRepo struct {
+	mu    sync.Mutex
+	carts map[string]*cart.Cart
+	items map[string]*cart.CartItem
+	// addItemErr, when set, fails AddItem
+	addItemErr error
+}
+
+func newMockCartRepo(carts ...*cart.Cart) *mockCartRepo {
+	m := &mockCartRepo{
+		carts: make(map[string]*cart.Cart),
+		items: make(map[string]*cart.CartItem),
+	}
+	for _, c := range carts {
+		m.carts[c.ID] = c
+	}
+	return m
+}
+
+func (m *mockCartRepo) GetByUserID(userID string) (*cart.Cart, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.carts {
+		if c.UserID == userID && c.Status == cart.CartStatusActive {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, cart.ErrNotFound
+}
+
+func (m *mockCartRepo) CreateCart(c *cart.Cart) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.carts {
+		if existing.UserID == c.UserID && existing.Status == cart.CartStatusActive {
+			return cart.ErrActiveCartExists
+		}
+	}
+	cp := *c
+	m.carts[c.ID] = &cp
+	return nil
+}
+
+func (m *mockCartRepo) GetItems(cartID string) ([]*cart.CartItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var items []*cart.CartItem
+	for _, item := range m.items {
+		if item.CartID == cartID {
+			cp := *item
+			items = append(items, &cp)
+		}
+	}
+	return items, nil
+}
+
+func (m *mockCartRepo) GetItemByID(itemID string) (*cart.CartItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[itemID]
+	if !ok {
+		return nil, cart.ErrItemNotFound
+	}
+	cp := *item
+	return &cp, nil
+}
+
+func (m *mockCartRepo) AddItem(item *cart.CartItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.addItemErr != nil {
+		return m.addItemErr
+	}
+	for _, existing := range m.items {
+		if existing.CartID == item.CartID && existing.ProductID == item.ProductID {
+			existing.Quantity += item.Quantity
+			existing.UpdatedAt = item.UpdatedAt
+			return nil
+		}
+	}
+	cp := *item
+	m.items[item.ID] = &cp
+	return nil
+}
+
+func (m *mockCartRepo) UpdateItem(item *cart.CartItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.items[item.ID]
+	if !ok {
+		return errors.New("cart item not found")
+	}
+	existing.Quantity = item.Quantity
+	existing.Price = item.Price
+	existing.UpdatedAt = item.UpdatedAt
+	return nil
+}
+
+func (m *mockCartRepo) RemoveItem(itemID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, itemID)
+	return nil
+}
+
+func (m *mockCartRepo) ClearItems(cartID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, item := range m.items {
+		if item.CartID == cartID {
+			delete(m.items, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockCartRepo) RecalculateTotal(cartID string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.carts[cartID]
+	if !ok {
+		return 0, errors.New("cart not found")
+	}
+	total := 0.0
+	for _, item := range m.items {
+		if item.CartID == cartID {
+			total += item.Price * float64(item.Quantity)
+		}
+	}
+	c.Total = total
+	return total, nil
+}
+
+func (m *mockCartRepo) SetStatus(cartID string, status cart.CartStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.carts[cartID]
+	if !ok {
+		return errors.New("cart not found")
+	}
+	c.Status = status
+	return nil
+}
+
+// mockSessionRepo is an in-memory auth.SessionRepository for tests
+type mockSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]*auth.Session
+	nonces   map[string]*auth.Nonce
+}
+
+func newMockSessionRepo() *mockSessionRepo {
+	return &mockSessionRepo{
+		sessions: make(map[string]*auth.Session),
+		nonces:   make(map[string]*auth.Nonce),
+	}
+}
+
+func (m *mockSessionRepo) SaveSession(ctx context.Context, session *auth.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *session
+	m.sessions[session.ID] = &cp
+	return nil
+}
+
+func (m *mockSessionRepo) GetSession(ctx context.Context, sessionID string) (*auth.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (m *mockSessionRepo) DeleteSession(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *mockSessionRepo) DeleteUserSessions(ctx context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deleted := 0
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockSessionRepo) SaveNonce(ctx context.Context, nonce *auth.Nonce) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *nonce
+	m.nonces[nonce.Value] = &cp
+	return nil
+}
+
+func (m *mockSessionRepo) GetNonce(ctx context.Context, nonceValue string) (*auth.Nonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.nonces[nonceValue]
+	if !ok {
+		return nil, errors.New("nonce not found")
+	}
+	cp := *n
+	return &cp, nil
+}
+
+func (m *mockSessionRepo) DeleteNonce(ctx context.Context, nonceValue string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nonces, nonceValue)
+	return nil
+}
+
+func (m *mockSessionRepo) ConsumeNonce(ctx context.Context, nonceValue string) (*auth.Nonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.nonces[nonceValue]
+	if !ok {
+		return nil, errors.New("nonce not found")
+	}
+	delete(m.nonces, nonceValue)
+	return n, nil
+}
+
+// mockUserRepo is an in-memory user.Repository for tests
+type mockUserRepo struct {
+	mu    sync.Mutex
+	users map[string]*user.User
+}
+
+func newMockUserRepo(users ...*user.User) *mockUserRepo {
+	m := &mockUserRepo{users: make(map[string]*user.User)}
+	for _, u := range users {
+		m.users[u.ID] = u
+	}
+	return m
+}
+
+func (m *mockUserRepo) Create(u *user.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.users {
+		if existing.WalletAddress == u.WalletAddress {
+			return errors.New("duplicate wallet address")
+		}
+	}
+	cp := *u
+	m.users[u.ID] = &cp
+	return nil
+}
+
+func (m *mockUserRepo) GetByID(id string) (*user.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (m *mockUserRepo) GetByWalletAddress(address string) (*user.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.WalletAddress == address {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (m *mockUserRepo) Update(u *user.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[u.ID]; !ok {
+		return errors.New("user not found")
+	}
+	cp := *u
+	m.users[u.ID] = &cp
+	return nil
+}
+
+func (m *mockUserRepo) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.users, id)
+	return nil
+}
+
+// mockRateLimiter allows a fixed number of attempts per user
+type mockRateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	attempts map[string]int
+}
+
+func newMockRateLimiter(limit int) *mockRateLimiter {
+	return &mockRateLimiter{limit: limit, attempts: make(map[string]int)}
+}
+
+func (m *mockRateLimiter) Allow(ctx context.Context, userID string) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts[userID]++
+	if m.attempts[userID] > m.limit {
+		return false, 30 * time.Second, nil
+	}
+	return true, 0, nil
+}
+
+// mockCheckoutRepo runs checkouts against the in-memory repos. Writes are
+// staged on the transaction and only applied when fn succeeds, so tests can
+// see that a failed checkout commits nothing. Row locking is not modelled;
+// checkouts simply run one at a time.
+type mockCheckoutRepo struct {
+	mu           sync.Mutex
+	carts        *mockCartRepo
+	products     *mockProductRepo
+	orders       *mockOrderRepo
+	reservations *mockReservationRepo
+	// failStock makes DecrementStock fail for the given product IDs
+	failStock map[string]error
+}
+
+func (m *mockCheckoutRepo) WithinTx(fn func(tx checkout.Tx) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &mockCheckoutTx{repo: m, stock: make(map[string]int)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// Commit
+	m.orders.mu.Lock()
+	for _, o := range tx.orders {
+		m.orders.orders[o.ID] = o
+	}
+	for _, item := range tx.items {
+		m.orders.items[item.OrderID] = append(m.orders.items[item.OrderID], item)
+	}
+	m.orders.mu.Unlock()
+
+	m.products.mu.Lock()
+	for id, qty := range tx.stock {
+		m.products.products[id].Quantity = qty
+	}
+	m.products.mu.Unlock()
+
+	m.carts.mu.Lock()
+	for id, status := range tx.cartStatus {
+		m.carts.carts[id].Status = status
+	}
+	m.carts.mu.Unlock()
+
+	for _, cartID := range tx.released {
+		m.reservations.mu.Lock()
+		kept := m.reservations.reservations[:0]
+		for _, r := range m.reservations.reservations {
+			if r.CartID != cartID {
+				kept = append(kept, r)
+			}
+		}
+		m.reservations.reservations = kept
+		m.reservations.mu.Unlock()
+	}
+	return nil
+}
+
+type mockCheckoutTx struct {
+	repo       *mockCheckoutRepo
+	orders     []*order.Order
+	items      []*order.OrderItem
+	stock      map[string]int
+	cartStatus map[string]cart.CartStatus
+	released   []string
+}
+
+func (t *mockCheckoutTx) GetCart(cartID string) (*cart.Cart, error) {
+	t.repo.carts.mu.Lock()
+	defer t.repo.carts.mu.Unlock()
+	c, ok := t.repo.carts.carts[cartID]
+	if !ok {
+		return nil, checkout.ErrCartNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (t *mockCheckoutTx) GetCartItems(cartID string) ([]*cart.CartItem, error) {
+	return t.repo.carts.GetItems(cartID)
+}
+
+func (t *mockCheckoutTx) CreateOrder(o *order.Order) error {
+	t.orders = append(t.orders, o)
+	return nil
+}
+
+func (t *mockCheckoutTx) CreateOrderItems(items []*order.OrderItem) error {
+	t.items = append(t.items, items...)
+	return nil
+}
+
+func (t *mockCheckoutTx) DecrementStock(productID string, quantity int) error {
+	if err := t.repo.failStock[productID]; err != nil {
+		return err
+	}
+	available, ok := t.stock[productID]
+	if !ok {
+		p, err := t.repo.products.GetByID(productID)
+		if err != nil {
+			return err
+		}
+		available = p.Quantity
+	}
+	if available < quantity {
+		return &product.InsufficientStockError{ProductID: productID, Available: available}
+	}
+	t.stock[productID] = available - quantity
+	return nil
+}
+
+func (t *mockCheckoutTx) SetCartStatus(cartID string, status cart.CartStatus) error {
+	if t.cartStatus == nil {
+		t.cartStatus = make(map[string]cart.CartStatus)
+	}
+	t.cartStatus[cartID] = status
+	return nil
+}
+
+func (t *mockCheckoutTx) ReleaseReservations(cartID string) error {
+	t.released = append(t.released, cartID)
+	return nil
+}
+
+// mockWalletRepo is an in-memory wallet.Repository for tests
+type mockWalletRepo struct {
+	mu           sync.Mutex
+	wallets      map[string]*wallet.Wallet
+	transactions []*wallet.Transaction
+	// creditErr, when set, fails Credit before anything is recorded
+	creditErr error
+}
+
+func newMockWalletRepo(wallets ...*wallet.Wallet) *mockWalletRepo {
+	m := &mockWalletRepo{wallets: make(map[string]*wallet.Wallet)}
+	for _, w := range wallets {
+		m.wallets[w.UserID] = w
+	}
+	return m
+}
+
+func (m *mockWalletRepo) GetByUserID(userID string) (*wallet.Wallet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.wallets[userID]
+	if !ok {
+		return nil, wallet.ErrNotFound
+	}
+	cp := *w
+	return &cp, nil
+}
+
+func (m *mockWalletRepo) Create(w *wallet.Wallet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.wallets[w.UserID]; ok {
+		return wallet.ErrWalletExists
+	}
+	cp := *w
+	m.wallets[w.UserID] = &cp
+	return nil
+}
+
+func (m *mockWalletRepo) CreateTransaction(tx *wallet.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReference(tx); err != nil {
+		return err
+	}
+	m.transactions = append(m.transactions, tx)
+	return nil
+}
+
+// checkReference mirrors the unique (wallet_id, reference) index
+func (m *mockWalletRepo) checkReference(tx *wallet.Transaction) error {
+	if tx.Reference == "" {
+		return nil
+	}
+	for _, existing := range m.transactions {
+		if existing.WalletID == tx.WalletID && existing.Reference == tx.Reference {
+			return &wallet.DuplicateTransactionError{Existing: existing}
+		}
+	}
+	return nil
+}
+
+func (m *mockWalletRepo) GetTransactions(walletID string, filter wallet.TransactionFilter, page, pageSize int) ([]*wallet.Transaction, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var txs []*wallet.Transaction
+	for _, tx := range m.transactions {
+		if tx.WalletID == walletID && filter.Matches(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, len(txs), nil
+}
+
+func (m *mockWalletRepo) GetTransactionAggregates(walletID string, from, to time.Time, bucket wallet.Bucket) ([]*wallet.TransactionAggregate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	byPeriod := make(map[time.Time]*wallet.TransactionAggregate)
+	var aggregates []*wallet.TransactionAggregate
+	for _, tx := range m.transactions {
+		if tx.WalletID != walletID || tx.Status == wallet.TransactionStatusFailed ||
+			tx.CreatedAt.Before(from) || !tx.CreatedAt.Before(to) {
+			continue
+		}
+		period := bucket.Truncate(tx.CreatedAt)
+		a, ok := byPeriod[period]
+		if !ok {
+			a = &wallet.TransactionAggregate{PeriodStart: period}
+			byPeriod[period] = a
+			aggregates = append(aggregates, a)
+		}
+		if tx.Type == wallet.TransactionTypeCredit {
+			a.Credit += tx.Amount
+		} else {
+			a.Debit += tx.Amount
+		}
+		a.Count++
+	}
+	return aggregates, nil
+}
+
+func (m *mockWalletRepo) UpdateBalance(walletID string, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.wallets {
+		if w.ID == walletID {
+			w.Balance += amount
+			return nil
+		}
+	}
+	return errors.New("wallet not found")
+}
+
+func (m *mockWalletRepo) Debit(tx *wallet.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.wallets {
+		if w.ID == tx.WalletID {
+			if w.Balance < tx.Amount {
+				return wallet.ErrInsufficientBalance
+			}
+			if err := m.checkReference(tx); err != nil {
+				return err
+			}
+			w.Balance -= tx.Amount
+			tx.Status = wallet.TransactionStatusSuccess
+			m.transactions = append(m.transactions, tx)
+			return nil
+		}
+	}
+	return errors.New("wallet not found")
+}
+
+func (m *mockWalletRepo) Credit(tx *wallet.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.creditErr != nil {
+		return m.creditErr
+	}
+	for _, w := range m.wallets {
+		if w.ID == tx.WalletID {
+			if err := m.checkReference(tx); err != nil {
+				return err
+			}
+			// Mirrors the unique (chain_id, lower(tx_hash)) index
+			for _, existing := range m.transactions {
+				if tx.TxHash != "" && existing.ChainID == tx.ChainID && strings.EqualFold(existing.TxHash, tx.TxHash) {
+					return wallet.ErrDepositClaimed
+				}
+			}
+			w.Balance += tx.Amount
+			tx.Status = wallet.TransactionStatusSuccess
+			m.transactions = append(m.transactions, tx)
+			return nil
+		}
+	}
+	return wallet.ErrNotFound
+}
+
+func (m *mockWalletRepo) Transfer(debit, credit *wallet.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var from, to *wallet.Wallet
+	for _, w := range m.wallets {
+		switch w.ID {
+		case debit.WalletID:
+			from = w
+		case credit.WalletID:
+			to = w
+		}
+	}
+	if from == nil || to == nil {
+		return wallet.ErrNotFound
+	}
+	if from.Balance < debit.Amount {
+		return wallet.ErrInsufficientBalance
+	}
+	if err := m.checkReference(debit); err != nil {
+		return err
+	}
+	from.Balance -= debit.Amount
+	to.Balance += credit.Amount
+	debit.Status = wallet.TransactionStatusSuccess
+	credit.Status = wallet.TransactionStatusSuccess
+	m.transactions = append(m.transactions, debit, credit)
+	return nil
+}
+
+// mockIdempotencyStore is an in-memory order.IdempotencyStore for tests
+type mockIdempotencyStore struct {
+	mu           sync.Mutex
+	keys         map[string]string
+	fingerprints map[string]string
+}
+
+func newMockIdempotencyStore() *mockIdempotencyStore {
+	return &mockIdempotencyStore{keys: make(map[string]string), fingerprints: make(map[string]string)}
+}
+
+func (m *mockIdempotencyStore) Claim(ctx context.Context, userID, key, fingerprint string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if orderID, ok := m.keys[userID+":"+key]; ok {
+		if m.fingerprints[userID+":"+key] != fingerprint {
+			return "", false, order.ErrIdempotencyKeyReused
+		}
+		return orderID, false, nil
+	}
+	m.keys[userID+":"+key] = ""
+	m.fingerprints[userID+":"+key] = fingerprint
+	return "", true, nil
+}
+
+func (m *mockIdempotencyStore) Complete(ctx context.Context, userID, key, fingerprint, orderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[userID+":"+key] = orderID
+	m.fingerprints[userID+":"+key] = fingerprint
+	return nil
+}
+
+func (m *mockIdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, userID+":"+key)
+	return nil
+}
+
+// expire simulates the key's TTL elapsing
+func (m *mockIdempotencyStore) expire(userID, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, userID+":"+key)
+}
+
+// mockPriceOracle quotes fixed rates and counts lookups
+type mockPriceOracle struct {
+	rates map[[2]wallet.Currency]float64
+	calls int
+}
+
+func (m *mockPriceOracle) Rate(from, to wallet.Currency) (float64, error) {
+	m.calls++
+	rate, ok := m.rates[[2]wallet.Currency{from, to}]
+	if !ok {
+		return 0, wallet.ErrUnsupportedCurrency
+	}
+	return rate, nil
+}
+
+// mockViewCounter is an in-memory product.ViewCounter that counts every view
+type mockViewCounter struct {
+	mu      sync.Mutex
+	pending map[string]int64
+}
+
+func newMockViewCounter() *mockViewCounter {
+	return &mockViewCounter{pending: make(map[string]int64)}
+}
+
+func (m *mockViewCounter) RecordView(ctx context.Context, productID, viewer string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[productID]++
+	return true, nil
+}
+
+func (m *mockViewCounter) DrainViews(ctx context.Context) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := m.pending
+	m.pending = make(map[string]int64)
+	return counts, nil
+}
+
+func (m *mockViewCounter) RestoreViews(ctx context.Context, counts map[string]int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, n := range counts {
+		m.pending[id] += n
+	}
+	return nil
+}
+
+// mockViewRepo stores view totals in memory and can be made to fail
+type mockViewRepo struct {
+	views    map[string]int64
+	products []*product.Product
+	err      error
+}
+
+func (m *mockViewRepo) AddViews(counts map[string]int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	for id, n := range counts {
+		m.views[id] += n
+	}
+	return nil
+}
+
+func (m *mockViewRepo) GetSellerViews(sellerID string) ([]*product.ProductViews, error) {
+	var views []*product.ProductViews
+	for _, p := range m.products {
+		if p.SellerID == sellerID {
+			views = append(views, &product.ProductViews{ProductID: p.ID, Title: p.Title, Views: m.views[p.ID]})
+		}
+	}
+	return views, nil
+}
+
+// mockNotificationRepo is an in-memory notification.Repository for tests
+type mockNotificationRepo struct {
+	mu         sync.Mutex
+	webhooks   []*notification.Webhook
+	deliveries []*notification.Delivery
+}
+
+func newMockNotificationRepo(webhooks ...*notification.Webhook) *mockNotificationRepo {
+	return &mockNotificationRepo{webhooks: webhooks}
+}
+
+func (m *mockNotificationRepo) Create(w *notification.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks = append(m.webhooks, w)
+	return nil
+}
+
+func (m *mockNotificationRepo) ListByUser(userID string) ([]*notification.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*notification.Webhook
+	for _, w := range m.webhooks {
+		if w.UserID == userID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) ListSubscribed(userIDs []string, event string) ([]*notification.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*notification.Webhook
+	for _, w := range m.webhooks {
+		for _, id := range userIDs {
+			if w.UserID == id && w.Active && w.Subscribes(event) {
+				result = append(result, w)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) Delete(id, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, w := range m.webhooks {
+		if w.ID == id && w.UserID == userID {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return notification.ErrNotFound
+}
+
+func (m *mockNotificationRepo) RecordDelivery(d *notification.Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
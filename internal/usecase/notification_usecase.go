@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"sync"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/notification"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// notificationQueueSize is how many status changes may wait for delivery
+// before further changes are dropped
+const notificationQueueSize = 256
+
+// NotificationUseCase manages webhook subscriptions and delivers order events
+// to them in the background
+type NotificationUseCase struct {
+	repo        notification.Repository
+	orderRepo   order.Repository
+	productRepo product.Repository
+	dispatcher  webhook.Dispatcher
+	allowLocal  bool
+	queue       chan order.Order
+	lookupIP    func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// webhookLookupTimeout bounds resolving a webhook URL's host
+const webhookLookupTimeout = 5 * time.Second
+
+// NewNotificationUseCase creates a new notification use case. allowLocal
+// accepts plain HTTP webhook URLs and hosts on private networks, for local
+// development. Events are only delivered while RunDispatcher is running.
+func NewNotificationUseCase(repo notification.Repository, orderRepo order.Repository, productRepo product.Repository, dispatcher webhook.Dispatcher, allowLocal bool) *NotificationUseCase {
+	return &NotificationUseCase{
+		repo:        repo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		dispatcher:  dispatcher,
+		allowLocal:  allowLocal,
+		queue:       make(chan order.Order, notificationQueueSize),
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip", host)
+		},
+	}
+}
+
+// CreateWebhook subscribes url to events for the user. No events subscribes
+// to all of them. The returned webhook carries the signing secret, which is
+// not shown again.
+func (uc *NotificationUseCase) CreateWebhook(userID, url string, events []string) (*notification.Webhook, error) {
+	if err := notification.ValidateURL(url, uc.allowLocal); err != nil {
+		return nil, err
+	}
+	if !uc.allowLocal {
+		if err := uc.checkPublicHost(url); err != nil {
+			return nil, err
+		}
+	}
+	if len(events) == 0 {
+		events = notification.Events
+	}
+	if err := notification.ValidateEvents(events); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	w := &notification.Webhook{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		URL:       url,
+		Events:    events,
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		Active:    true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.repo.Create(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// checkPublicHost resolves the webhook URL's host and rejects it with
+// notification.ErrPrivateURL unless every address is public. The dispatcher
+// checks the address again when it connects.
+func (uc *NotificationUseCase) checkPublicHost(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return notification.ErrInvalidURL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+	defer cancel()
+	ips, err := uc.lookupIP(ctx, u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("%w: cannot resolve %s", notification.ErrPrivateURL, u.Hostname())
+	}
+	for _, ip := range ips {
+		if !webhook.PublicIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", notification.ErrPrivateURL, u.Hostname(), ip)
+		}
+	}
+	return nil
+}
+
+// ListWebhooks returns the user's webhooks
+func (uc *NotificationUseCase) ListWebhooks(userID string) ([]*notification.Webhook, error) {
+	return uc.repo.ListByUser(userID)
+}
+
+// DeleteWebhook removes one of the user's webhooks
+func (uc *NotificationUseCase) DeleteWebhook(id, userID string) error {
+	return uc.repo.Delete(id, userID)
+}
+
+// OrderStatusEvent is the data of an order.status_changed event
+type OrderStatusEvent struct {
+	OrderID   string            `json:"order_id"`
+	Status    order.OrderStatus `json:"status"`
+	Total     float64           `json:"total"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// OrderStatusChanged queues a notification to the order's sellers. It never
+// blocks: when the queue is full the notification is dropped and logged.
+func (uc *NotificationUseCase) OrderStatusChanged(o *order.Order) {
+	select {
+	case uc.queue <- *o:
+	default:
+		log.Warn().Str("order_id", o.ID).Str("status", string(o.Status)).
+			Msg("webhook queue full, dropping order status notification")
+	}
+}
+
+// RunDispatcher delivers queued notifications until the context is
+// cancelled, then waits for deliveries in flight to give up
+func (uc *NotificationUseCase) RunDispatcher(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case o := <-uc.queue:
+			if err := uc.dispatchOrderStatus(ctx, &wg, &o); err != nil {
+				log.Error().Err(err).Str("order_id", o.ID).Msg("failed to notify order status change")
+			}
+		}
+	}
+}
+
+// dispatchOrderStatus delivers an order.status_changed event to each webhook
+// of the order's sellers that subscribes to it, each in its own goroutine
+func (uc *NotificationUseCase) dispatchOrderStatus(ctx context.Context, wg *sync.WaitGroup, o *order.Order) error {
+	sellerIDs, err := uc.sellersOf(o.ID)
+	if err != nil {
+		return err
+	}
+	webhooks, err := uc.repo.ListSubscribed(sellerIDs, notification.EventOrderStatusChanged)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		event := webhook.Event{
+			ID:        uuid.New().String(),
+			Type:      notification.EventOrderStatusChanged,
+			CreatedAt: time.Now(),
+			Data: OrderStatusEvent{
+				OrderID:   o.ID,
+				Status:    o.Status,
+				Total:     o.Total,
+				UpdatedAt: o.UpdatedAt,
+			},
+		}
+		endpoint := webhook.Endpoint{ID: w.ID, URL: w.URL, Secret: w.Secret}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := uc.dispatcher.Dispatch(ctx, endpoint, event); err != nil {
+				log.Warn().Err(err).Str("webhook_id", endpoint.ID).Str("order_id", o.ID).
+					Msg("failed to deliver order status webhook")
+			}
+		}()
+	}
+	return nil
+}
+
+// sellersOf returns the distinct sellers with items in the order
+func (uc *NotificationUseCase) sellersOf(orderID string) ([]string, error) {
+	items, err := uc.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sellers []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		p, err := uc.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve seller for product %s: %w", item.ProductID, err)
+		}
+		if !seen[p.SellerID] {
+			seen[p.SellerID] = true
+			sellers = append(sellers, p.SellerID)
+		}
+	}
+	return sellers, nil
+}
+
+// RecordDeliveries returns a webhook.DispatcherConfig OnAttempt hook that
+// stores every delivery attempt in repo
+func RecordDeliveries(repo notification.Repository) func(webhook.Attempt) {
+	return func(a webhook.Attempt) {
+		d := &notification.Delivery{
+			ID:         uuid.New().String(),
+			WebhookID:  a.EndpointID,
+			EventID:    a.EventID,
+			EventType:  a.EventType,
+			Attempt:    a.Number,
+			StatusCode: a.StatusCode,
+			DurationMS: a.Duration.Milliseconds(),
+			CreatedAt:  a.At,
+		}
+		if a.Err != nil {
+			d.Error = a.Err.Error()
+		}
+		if err := repo.RecordDelivery(d); err != nil {
+			log.Error().Err(err).Str("webhook_id", a.EndpointID).Msg("failed to record webhook delivery")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/notification"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/pkg/webhook"
+)
+
+// mockDispatcher records dispatched events and signals each one on sent
+type mockDispatcher struct {
+	mu         sync.Mutex
+	dispatched map[string][]webhook.Event
+	sent       chan struct{}
+}
+
+func newMockDispatcher() *mockDispatcher {
+	return &mockDispatcher{dispatched: make(map[string][]webhook.Event), sent: make(chan struct{}, 16)}
+}
+
+func (d *mockDispatcher) Dispatch(ctx context.Context, endpoint webhook.Endpoint, event webhook.Event) error {
+	d.mu.Lock()
+	d.dispatched[endpoint.URL] = append(d.dispatched[endpoint.URL], event)
+	d.mu.Unlock()
+	d.sent <- struct{}{}
+	return nil
+}
+
+func TestUpdateOrderStatus_NotifiesSellerWebhooks(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	orderRepo.orders["order-1"] = &order.Order{ID: "order-1", UserID: "buyer", Status: order.OrderStatusPaid, Total: 70}
+	orderRepo.items["order-1"] = []*order.OrderItem{
+		{ID: "i1", OrderID: "order-1", ProductID: "p1", Quantity: 1, Price: 50},
+		{ID: "i2", OrderID: "order-1", ProductID: "p2", Quantity: 1, Price: 20},
+	}
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-1"},
+		&product.Product{ID: "p2", SellerID: "seller-1"},
+	)
+	notificationRepo := newMockNotificationRepo(
+		&notification.Webhook{ID: "wh-1", UserID: "seller-1", URL: "https://seller-1.example/hooks", Events: notification.Events, Active: true},
+		&notification.Webhook{ID: "wh-2", UserID: "seller-1", URL: "https://seller-1.example/paused", Events: notification.Events},
+		&notification.Webhook{ID: "wh-3", UserID: "seller-2", URL: "https://seller-2.example/hooks", Events: notification.Events, Active: true},
+		&notification.Webhook{ID: "wh-4", UserID: "buyer", URL: "https://buyer.example/hooks", Events: notification.Events, Active: true},
+	)
+	dispatcher := newMockDispatcher()
+	notifications := NewNotificationUseCase(notificationRepo, orderRepo, productRepo, dispatcher, false)
+	uc := NewOrderUseCase(orderRepo, nil, productRepo, nil, nil, nil, notifications)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		notifications.RunDispatcher(ctx)
+		close(done)
+	}()
+
+	if err := uc.UpdateOrderStatus("order-1", order.OrderStatusPaid, order.OrderStatusShipped); err != nil {
+		t.Fatalf("UpdateOrderStatus() error = %v", err)
+	}
+	select {
+	case <-dispatcher.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook was dispatched")
+	}
+	cancel()
+	<-done
+
+	if len(dispatcher.dispatched) != 1 {
+		t.Fatalf("dispatched to %v, want only the seller's active webhook", dispatcher.dispatched)
+	}
+	events := dispatcher.dispatched["https://seller-1.example/hooks"]
+	if len(events) != 1 {
+		t.Fatalf("seller webhook received %d events, want 1", len(events))
+	}
+	data, ok := events[0].Data.(OrderStatusEvent)
+	if events[0].Type != notification.EventOrderStatusChanged || !ok || data.OrderID != "order-1" || data.Status != order.OrderStatusShipped {
+		t.Errorf("event = %+v, want order-1 shipped", events[0])
+	}
+}
+
+func TestRecordDeliveries(t *testing.T) {
+	repo := newMockNotificationRepo()
+	record := RecordDeliveries(repo)
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	record(webhook.Attempt{EndpointID: "wh-1", EventID: "evt-1", EventType: notification.EventOrderStatusChanged,
+		Number: 1, StatusCode: 503, Err: errors.New("endpoint responded 503"), Duration: 120 * time.Millisecond, At: at})
+	record(webhook.Attempt{EndpointID: "wh-1", EventID: "evt-1", EventType: notification.EventOrderStatusChanged,
+		Number: 2, StatusCode: 200, Duration: 80 * time.Millisecond, At: at.Add(time.Second)})
+
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("recorded %d deliveries, want 2", len(repo.deliveries))
+	}
+	failed, succeeded := repo.deliveries[0], repo.deliveries[1]
+	if failed.WebhookID != "wh-1" || failed.Attempt != 1 || failed.StatusCode != 503 || failed.Error == "" || failed.DurationMS != 120 {
+		t.Errorf("failed attempt = %+v", failed)
+	}
+	if succeeded.Attempt != 2 || succeeded.StatusCode != 200 || succeeded.Error != "" || !succeeded.CreatedAt.Equal(at.Add(time.Second)) {
+		t.Errorf("successful attempt = %+v", succeeded)
+	}
+}
+
+func TestCreateWebhook(t *testing.T) {
+	uc := NewNotificationUseCase(newMockNotificationRepo(), nil, nil, nil, false)
+	uc.lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+		switch host {
+		case "seller.example":
+			return []net.IP{net.ParseIP("93.184.215.14")}, nil
+		case "internal.example":
+			// One public and one private address is still refused
+			return []net.IP{net.ParseIP("93.184.215.14"), net.ParseIP("10.0.0.5")}, nil
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			return []net.IP{ip}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	w, err := uc.CreateWebhook("seller-1", "https://seller.example/hooks", nil)
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	if !strings.HasPrefix(w.Secret, "whsec_") || !w.Active || !w.Subscribes(notification.EventOrderStatusChanged) {
+		t.Errorf("CreateWebhook() = %+v, want an active webhook with a secret subscribed to every event", w)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		events  []string
+		wantErr error
+	}{
+		{"Plain HTTP", "http://seller.example/hooks", nil, notification.ErrInvalidURL},
+		{"Relative URL", "/hooks", nil, notification.ErrInvalidURL},
+		{"Loopback", "https://127.0.0.1/hooks", nil, notification.ErrPrivateURL},
+		{"Cloud metadata", "https://169.254.169.254/latest", nil, notification.ErrPrivateURL},
+		{"IPv6 loopback", "https://[::1]/hooks", nil, notification.ErrPrivateURL},
+		{"Resolves to a private address", "https://internal.example/hooks", nil, notification.ErrPrivateURL},
+		{"Unresolvable", "https://missing.example/hooks", nil, notification.ErrPrivateURL},
+		{"Unknown event", "https://seller.example/hooks", []string{"order.deleted"}, notification.ErrUnknownEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.CreateWebhook("seller-1", tt.url, tt.events); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,48 +1,199 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/pkg/money"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
 // OrderUseCase handles order business logic
 type OrderUseCase struct {
-	orderRepo order.Repository
+	orderRepo     order.Repository
+	checkout      *CheckoutUseCase
+	productRepo   product.Repository
+	payoutUseCase *PayoutUseCase
+	rateLimiter   order.RateLimiter
+	idempotency   order.IdempotencyStore
+	notifier      order.StatusNotifier
 }
 
-// NewOrderUseCase creates a new order use case
-func NewOrderUseCase(orderRepo order.Repository) *OrderUseCase {
-	return &OrderUseCase{orderRepo: orderRepo}
+// NewOrderUseCase creates a new order use case. A nil rateLimiter or
+// idempotency store disables that protection, and a nil notifier sends no
+// status change notifications.
+func NewOrderUseCase(orderRepo order.Repository, checkout *CheckoutUseCase, productRepo product.Repository, payoutUseCase *PayoutUseCase, rateLimiter order.RateLimiter, idempotency order.IdempotencyStore, notifier order.StatusNotifier) *OrderUseCase {
+	return &OrderUseCase{
+		orderRepo:     orderRepo,
+		checkout:      checkout,
+		productRepo:   productRepo,
+		payoutUseCase: payoutUseCase,
+		rateLimiter:   rateLimiter,
+		idempotency:   idempotency,
+		notifier:      notifier,
+	}
 }
 
-// CreateOrder creates a new order
-func (uc *OrderUseCase) CreateOrder(userID, cartID string, total float64, paymentRef string) (*order.Order, error) {
-	o := &order.Order{
-		ID:         uuid.New().String(),
-		UserID:     userID,
-		CartID:     cartID,
-		Status:     order.OrderStatusPending,
-		Total:      total,
-		PaymentRef: paymentRef,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+// CreateOrder checks out the user's active cart through CheckoutUseCase, so
+// the order, its items, the stock decrement and the cart status change are
+// written together. It is rate limited per user.
+func (uc *OrderUseCase) CreateOrder(userID, cartID string) (*order.Order, error) {
+	if err := uc.checkRateLimit(userID); err != nil {
+		return nil, err
 	}
 
-	err := uc.orderRepo.Create(o)
+	o, _, err := uc.checkout.Checkout(userID, cartID)
 	if err != nil {
 		return nil, err
 	}
-
 	return o, nil
 }
 
+// CreateOrderIdempotent creates an order at most once per user and
+// idempotency key. A retry with a key that already produced an order returns
+// that order with replayed set instead of creating another, and reusing the
+// key for a different cart returns
+// order.ErrIdempotencyKeyReused. An empty key, a nil store, or a store
+// failure falls back to CreateOrder.
+func (uc *OrderUseCase) CreateOrderIdempotent(userID, key, cartID string) (o *order.Order, replayed bool, err error) {
+	if key == "" || uc.idempotency == nil {
+		o, err = uc.CreateOrder(userID, cartID)
+		return o, false, err
+	}
+
+	ctx := context.Background()
+	fingerprint := orderRequestFingerprint(cartID)
+	orderID, claimed, err := uc.idempotency.Claim(ctx, userID, key, fingerprint)
+	if errors.Is(err, order.ErrIdempotencyKeyReused) {
+		return nil, false, err
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("idempotency check failed")
+		o, err = uc.CreateOrder(userID, cartID)
+		return o, false, err
+	}
+	if !claimed {
+		if orderID == "" {
+			return nil, false, order.ErrIdempotencyInProgress
+		}
+		o, err = uc.orderRepo.GetByID(orderID)
+		return o, err == nil, err
+	}
+
+	o, err = uc.CreateOrder(userID, cartID)
+	if err != nil {
+		if releaseErr := uc.idempotency.Release(ctx, userID, key); releaseErr != nil {
+			log.Warn().Err(releaseErr).Str("user_id", userID).Msg("failed to release idempotency key")
+		}
+		return nil, false, err
+	}
+	if err := uc.idempotency.Complete(ctx, userID, key, fingerprint, o.ID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("order_id", o.ID).Msg("failed to record idempotency key")
+	}
+
+	return o, false, nil
+}
+
+// orderRequestFingerprint identifies the order request an idempotency key
+// was first used for
+func orderRequestFingerprint(cartID string) string {
+	sum := sha256.Sum256([]byte(cartID))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkRateLimit rejects the order when the user has exceeded the per-user
+// order rate. A nil limiter disables the check, and limiter failures are
+// logged and let through so a Redis outage does not block checkout.
+func (uc *OrderUseCase) checkRateLimit(userID string) error {
+	if uc.rateLimiter == nil {
+		return nil
+	}
+
+	allowed, retryAfter, err := uc.rateLimiter.Allow(context.Background(), userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("order rate limit check failed")
+		return nil
+	}
+	if !allowed {
+		log.Warn().Str("user_id", userID).Dur("retry_after", retryAfter).Msg("order rate limit exceeded")
+		return &order.RateLimitError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// validateOrderProducts checks in one query that every product still exists
+// and is for sale, returning an *order.InvalidProductsError listing the
+// ones that are not
+func validateOrderProducts(productRepo product.Repository, productIDs []string) error {
+	products, err := productRepo.GetByIDs(productIDs)
+	if err != nil {
+		return err
+	}
+
+	available := make(map[string]bool, len(products))
+	for _, p := range products {
+		available[p.ID] = p.IsActive && p.DeletedAt == nil
+	}
+
+	var invalid []string
+	seen := make(map[string]bool, len(productIDs))
+	for _, id := range productIDs {
+		if !available[id] && !seen[id] {
+			invalid = append(invalid, id)
+		}
+		seen[id] = true
+	}
+	if len(invalid) > 0 {
+		return &order.InvalidProductsError{ProductIDs: invalid}
+	}
+	return nil
+}
+
+// cartItemProductIDs returns the product ID of each cart item
+func cartItemProductIDs(items []*cart.CartItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
+
+// cartItemsTotal sums price times quantity across cart items in exact cents
+func cartItemsTotal(items []*cart.CartItem) float64 {
+	var total money.Amount
+	for _, item := range items {
+		total = total.Add(money.FromFloat(item.Price).Mul(int64(item.Quantity)))
+	}
+	return total.Float64()
+}
+
+// newPaymentRef issues the reference a new order is paid under. The server
+// chooses it so that no buyer can place an order under another's payment.
+func newPaymentRef() string {
+	return uuid.New().String()
+}
+
 // GetOrderByID retrieves an order by ID
 func (uc *OrderUseCase) GetOrderByID(id string) (*order.Order, error) {
 	return uc.orderRepo.GetByID(id)
 }
 
+// GetOrderItemsWithProduct retrieves an order's items with product details for receipts
+func (uc *OrderUseCase) GetOrderItemsWithProduct(orderID string) ([]*order.OrderItemWithProduct, error) {
+	return uc.orderRepo.GetItemsWithProduct(orderID)
+}
+
 // GetOrdersByUserID retrieves all orders for a user
 func (uc *OrderUseCase) GetOrdersByUserID(userID string, page, pageSize int) ([]*order.Order, int, error) {
 	return uc.orderRepo.GetByUserID(userID, page, pageSize)
@@ -53,7 +204,266 @@ func (uc *OrderUseCase) GetOrderItems(orderID string) ([]*order.OrderItem, error
 	return uc.orderRepo.GetItems(orderID)
 }
 
-// UpdateOrderStatus updates the status of an order
-func (uc *OrderUseCase) UpdateOrderStatus(orderID string, status order.OrderStatus) error {
-	return uc.orderRepo.UpdateStatus(orderID, status)
+// UpdateOrderStatus moves an order from one status to another, notifying its
+// sellers and accruing their earnings once the order is completed. It
+// returns order.ErrStatusConflict if the order is no longer in the from
+// status.
+func (uc *OrderUseCase) UpdateOrderStatus(orderID string, from, to order.OrderStatus) error {
+	if to == order.OrderStatusCompleted {
+		if err := uc.completeOrder(orderID); err != nil {
+			return err
+		}
+	} else if err := uc.orderRepo.UpdateStatus(orderID, from, to); err != nil {
+		return err
+	}
+
+	if uc.notifier != nil {
+		if o, err := uc.orderRepo.GetByID(orderID); err != nil {
+			log.Warn().Err(err).Str("order_id", orderID).Msg("failed to load order for status notification")
+		} else {
+			uc.notifier.OrderStatusChanged(o)
+		}
+	}
+
+	return nil
+}
+
+// completeOrder marks the order completed together with accruing its
+// sellers' earnings, so a failed accrual leaves the order uncompleted
+func (uc *OrderUseCase) completeOrder(orderID string) error {
+	if _, err := uc.payoutUseCase.CompleteOrder(orderID); err != nil {
+		if errors.Is(err, payout.ErrAlreadyAccrued) {
+			log.Warn().Str("order_id", orderID).Msg("seller earnings already accrued for order")
+			return nil
+		}
+		log.Error().Err(err).Str("order_id", orderID).Msg("failed to complete order and accrue seller earnings")
+		return err
+	}
+	return nil
+}
+
+// ConfirmPayment applies a payment provider's report that the payment made
+// under paymentRef has settled, moving a pending order to paid. Each provider
+// event is applied once; a redelivered event returns the order with
+// duplicate set and changes nothing. A payment short of the order total is
+// rejected with order.ErrPaymentAmountMismatch.
+func (uc *OrderUseCase) ConfirmPayment(eventID, paymentRef string, amount float64) (o *order.Order, duplicate bool, err error) {
+	o, err = uc.orderRepo.GetByPaymentRef(paymentRef)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if money.FromFloat(amount) < money.FromFloat(o.Total) {
+		return nil, false, fmt.Errorf("%w: paid %s of %s", order.ErrPaymentAmountMismatch,
+			money.FromFloat(amount), money.FromFloat(o.Total))
+	}
+
+	paid, err := uc.orderRepo.ApplyPaymentEvent(&order.PaymentEvent{
+		ID:         eventID,
+		OrderID:    o.ID,
+		Type:       order.PaymentEventSucceeded,
+		Amount:     amount,
+		ReceivedAt: time.Now(),
+	})
+	if errors.Is(err, order.ErrDuplicatePaymentEvent) {
+		return o, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if paid {
+		o.Status = order.OrderStatusPaid
+		o.UpdatedAt = time.Now()
+		uc.notifyStatusChange(o)
+	} else {
+		// The order was already paid, or was cancelled before the payment
+		// arrived and needs a refund
+		log.Warn().Str("order_id", o.ID).Str("status", string(o.Status)).Str("event_id", eventID).
+			Msg("payment confirmed for an order that is not pending")
+	}
+	return o, false, nil
+}
+
+// TransitionOrderStatus moves an order to status on behalf of the caller,
+// only along the transitions allowed by order.CanTransition. Admins may make
+// any of them, and the buyer may cancel a pending order. Payment is confirmed
+// by the provider through ConfirmPayment, and the shipped statuses follow
+// from sellers shipping their items through ShipSellerItems.
+func (uc *OrderUseCase) TransitionOrderStatus(orderID, actorID string, actorRole user.Role, status order.OrderStatus) (*order.Order, error) {
+	o, err := uc.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := uc.CanAccessOrder(o, actorID, actorRole)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, order.ErrNotParticipant
+	}
+
+	if !order.CanTransition(o.Status, status) {
+		return nil, fmt.Errorf("%w: %s to %s", order.ErrInvalidTransition, o.Status, status)
+	}
+	if status == order.OrderStatusPartiallyShipped || status == order.OrderStatusShipped {
+		return nil, fmt.Errorf("%w: %s is set by shipping the order's items", order.ErrInvalidTransition, status)
+	}
+
+	allowed, err = uc.mayTransition(o, actorID, actorRole, status)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: cannot move it to %s", order.ErrNotParticipant, status)
+	}
+
+	if err := uc.UpdateOrderStatus(orderID, o.Status, status); err != nil {
+		return nil, err
+	}
+	o.Status = status
+	o.UpdatedAt = time.Now()
+
+	return o, nil
+}
+
+// mayTransition reports whether the actor's part in the order lets them move
+// it to status
+func (uc *OrderUseCase) mayTransition(o *order.Order, actorID string, actorRole user.Role, status order.OrderStatus) (bool, error) {
+	if actorRole == user.RoleAdmin {
+		return true, nil
+	}
+	if status == order.OrderStatusCancelled {
+		return o.UserID == actorID && o.Status == order.OrderStatusPending, nil
+	}
+	return false, nil
+}
+
+// CanAccessOrder reports whether the actor is the buyer, an admin, or a
+// seller with at least one item in the order
+func (uc *OrderUseCase) CanAccessOrder(o *order.Order, actorID string, actorRole user.Role) (bool, error) {
+	if o.UserID == actorID || actorRole == user.RoleAdmin {
+		return true, nil
+	}
+	if actorRole != user.RoleSeller {
+		return false, nil
+	}
+	return uc.sellsInOrder(o.ID, actorID)
+}
+
+// sellsInOrder reports whether the seller has at least one item in the order
+func (uc *OrderUseCase) sellsInOrder(orderID, sellerID string) (bool, error) {
+	items, err := uc.orderRepo.GetItems(orderID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		p, err := uc.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return false, err
+		}
+		if p.SellerID == sellerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ShipSellerItems marks the seller's pending items in an order as shipped and
+// updates the order status derived from all of its items
+func (uc *OrderUseCase) ShipSellerItems(orderID, sellerID string) (*order.Order, []*order.OrderItem, error) {
+	o, err := uc.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.Status == order.OrderStatusCancelled || o.Status == order.OrderStatusCompleted {
+		return nil, nil, order.ErrOrderClosed
+	}
+	if o.Status == order.OrderStatusPending {
+		return nil, nil, order.ErrOrderNotPaid
+	}
+
+	items, err := uc.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var itemIDs []string
+	for _, item := range items {
+		if item.Status != order.ItemStatusPending {
+			continue
+		}
+		p, err := uc.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.SellerID == sellerID {
+			itemIDs = append(itemIDs, item.ID)
+		}
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil, order.ErrNoSellerItems
+	}
+
+	if err := uc.orderRepo.UpdateItemsStatus(orderID, itemIDs, order.ItemStatusShipped); err != nil {
+		return nil, nil, err
+	}
+
+	shipped := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		shipped[id] = true
+	}
+	for _, item := range items {
+		if shipped[item.ID] {
+			item.Status = order.ItemStatusShipped
+		}
+	}
+
+	return uc.applyDerivedStatus(o, items)
+}
+
+// derivedStatusAttempts bounds how often applyDerivedStatus rereads an order
+// whose status another request changed in the meantime
+const derivedStatusAttempts = 3
+
+// applyDerivedStatus moves the order to the status derived from its items.
+// When another seller changes the status first, the order and its items are
+// read again, so concurrent shipments all count towards the final status.
+func (uc *OrderUseCase) applyDerivedStatus(o *order.Order, items []*order.OrderItem) (*order.Order, []*order.OrderItem, error) {
+	for attempt := 1; ; attempt++ {
+		status, ok := order.DeriveStatus(items)
+		if !ok || status == o.Status {
+			return o, items, nil
+		}
+		if !order.CanTransition(o.Status, status) {
+			return nil, nil, fmt.Errorf("%w: %s to %s", order.ErrInvalidTransition, o.Status, status)
+		}
+
+		err := uc.orderRepo.UpdateStatus(o.ID, o.Status, status)
+		if err == nil {
+			o.Status = status
+			o.UpdatedAt = time.Now()
+			uc.notifyStatusChange(o)
+			return o, items, nil
+		}
+		if !errors.Is(err, order.ErrStatusConflict) || attempt == derivedStatusAttempts {
+			return nil, nil, err
+		}
+
+		if o, err = uc.orderRepo.GetByID(o.ID); err != nil {
+			return nil, nil, err
+		}
+		if items, err = uc.orderRepo.GetItems(o.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// notifyStatusChange tells the notifier, if any, that the order has a new
+// status
+func (uc *OrderUseCase) notifyStatusChange(o *order.Order) {
+	if uc.notifier != nil {
+		uc.notifier.OrderStatusChanged(o)
+	}
 }
@@ -0,0 +1,668 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/cart"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/checkout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+)
+
+func TestDeriveStatus(t *testing.T) {
+	item := func(status order.ItemStatus) *order.OrderItem {
+		return &order.OrderItem{Status: status}
+	}
+
+	tests := []struct {
+		name       string
+		items      []*order.OrderItem
+		wantStatus order.OrderStatus
+		wantOK     bool
+	}{
+		{"No items", nil, "", false},
+		{"Nothing shipped", []*order.OrderItem{item(order.ItemStatusPending), item(order.ItemStatusPending)}, "", false},
+		{"All shipped", []*order.OrderItem{item(order.ItemStatusShipped), item(order.ItemStatusShipped)}, order.OrderStatusShipped, true},
+		{"Mixed", []*order.OrderItem{item(order.ItemStatusShipped), item(order.ItemStatusPending)}, order.OrderStatusPartiallyShipped, true},
+		{"Shipped with cancelled", []*order.OrderItem{item(order.ItemStatusShipped), item(order.ItemStatusCancelled)}, order.OrderStatusShipped, true},
+		{"Mixed with cancelled", []*order.OrderItem{item(order.ItemStatusShipped), item(order.ItemStatusPending), item(order.ItemStatusCancelled)}, order.OrderStatusPartiallyShipped, true},
+		{"All cancelled", []*order.OrderItem{item(order.ItemStatusCancelled), item(order.ItemStatusCancelled)}, order.OrderStatusCancelled, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, ok := order.DeriveStatus(tt.items)
+			if status != tt.wantStatus || ok != tt.wantOK {
+				t.Errorf("DeriveStatus() = (%q, %v), want (%q, %v)", status, ok, tt.wantStatus, tt.wantOK)
+			}
+		})
+	}
+}
+
+func newFulfillmentFixture() (*OrderUseCase, *mockOrderRepo) {
+	orderRepo := newMockOrderRepo()
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a"},
+		&product.Product{ID: "p2", SellerID: "seller-b"},
+	)
+
+	orderRepo.orders["order-1"] = &order.Order{ID: "order-1", UserID: "buyer", Status: order.OrderStatusPaid}
+	orderRepo.items["order-1"] = []*order.OrderItem{
+		{ID: "i1", OrderID: "order-1", ProductID: "p1", Quantity: 1, Price: 10, Status: order.ItemStatusPending},
+		{ID: "i2", OrderID: "order-1", ProductID: "p2", Quantity: 1, Price: 20, Status: order.ItemStatusPending},
+	}
+
+	return NewOrderUseCase(orderRepo, nil, productRepo, nil, nil, nil, nil), orderRepo
+}
+
+func TestShipSellerItems_PartialThenFull(t *testing.T) {
+	uc, orderRepo := newFulfillmentFixture()
+
+	o, _, err := uc.ShipSellerItems("order-1", "seller-a")
+	if err != nil {
+		t.Fatalf("ShipSellerItems(seller-a) error = %v", err)
+	}
+	if o.Status != order.OrderStatusPartiallyShipped {
+		t.Errorf("status after first seller ships = %q, want %q", o.Status, order.OrderStatusPartiallyShipped)
+	}
+	if got := orderRepo.items["order-1"][1].Status; got != order.ItemStatusPending {
+		t.Errorf("other seller's item status = %q, want pending", got)
+	}
+
+	o, _, err = uc.ShipSellerItems("order-1", "seller-b")
+	if err != nil {
+		t.Fatalf("ShipSellerItems(seller-b) error = %v", err)
+	}
+	if o.Status != order.OrderStatusShipped {
+		t.Errorf("status after all sellers ship = %q, want %q", o.Status, order.OrderStatusShipped)
+	}
+}
+
+// racingOrderRepo runs beforeUpdate once, just before the first status
+// update, to stand in for a concurrent request
+type racingOrderRepo struct {
+	*mockOrderRepo
+	beforeUpdate func()
+}
+
+func (r *racingOrderRepo) UpdateStatus(orderID string, from, to order.OrderStatus) error {
+	if f := r.beforeUpdate; f != nil {
+		r.beforeUpdate = nil
+		f()
+	}
+	return r.mockOrderRepo.UpdateStatus(orderID, from, to)
+}
+
+func TestShipSellerItems_ConcurrentShipmentsBothCount(t *testing.T) {
+	uc, orderRepo := newFulfillmentFixture()
+	racing := &racingOrderRepo{mockOrderRepo: orderRepo}
+	uc.orderRepo = racing
+
+	// seller-b ships and moves the order on while seller-a's request still
+	// holds the paid order
+	racing.beforeUpdate = func() {
+		orderRepo.orders["order-1"] = &order.Order{ID: "order-1", UserID: "buyer", Status: order.OrderStatusPartiallyShipped}
+		orderRepo.items["order-1"] = []*order.OrderItem{
+			{ID: "i1", OrderID: "order-1", ProductID: "p1", Status: order.ItemStatusShipped},
+			{ID: "i2", OrderID: "order-1", ProductID: "p2", Status: order.ItemStatusShipped},
+		}
+	}
+
+	o, _, err := uc.ShipSellerItems("order-1", "seller-a")
+	if err != nil {
+		t.Fatalf("ShipSellerItems() error = %v", err)
+	}
+	if o.Status != order.OrderStatusShipped {
+		t.Errorf("returned status = %q, want %q", o.Status, order.OrderStatusShipped)
+	}
+	if got := orderRepo.orders["order-1"].Status; got != order.OrderStatusShipped {
+		t.Errorf("stored status = %q, want %q", got, order.OrderStatusShipped)
+	}
+}
+
+func TestShipSellerItems_Errors(t *testing.T) {
+	t.Run("Seller without items", func(t *testing.T) {
+		uc, _ := newFulfillmentFixture()
+		if _, _, err := uc.ShipSellerItems("order-1", "seller-c"); !errors.Is(err, order.ErrNoSellerItems) {
+			t.Errorf("error = %v, want ErrNoSellerItems", err)
+		}
+	})
+
+	t.Run("Already shipped", func(t *testing.T) {
+		uc, _ := newFulfillmentFixture()
+		if _, _, err := uc.ShipSellerItems("order-1", "seller-a"); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := uc.ShipSellerItems("order-1", "seller-a"); !errors.Is(err, order.ErrNoSellerItems) {
+			t.Errorf("error = %v, want ErrNoSellerItems", err)
+		}
+	})
+
+	t.Run("Cancelled order", func(t *testing.T) {
+		uc, orderRepo := newFulfillmentFixture()
+		orderRepo.orders["order-1"].Status = order.OrderStatusCancelled
+		if _, _, err := uc.ShipSellerItems("order-1", "seller-a"); !errors.Is(err, order.ErrOrderClosed) {
+			t.Errorf("error = %v, want ErrOrderClosed", err)
+		}
+	})
+
+	t.Run("Unpaid order", func(t *testing.T) {
+		uc, orderRepo := newFulfillmentFixture()
+		orderRepo.orders["order-1"].Status = order.OrderStatusPending
+		if _, _, err := uc.ShipSellerItems("order-1", "seller-a"); !errors.Is(err, order.ErrOrderNotPaid) {
+			t.Errorf("error = %v, want ErrOrderNotPaid", err)
+		}
+		if got := orderRepo.items["order-1"][0].Status; got != order.ItemStatusPending {
+			t.Errorf("item status = %q, want pending", got)
+		}
+	})
+}
+
+// activeProducts returns for-sale products with the given IDs, each with
+// enough stock for a few orders
+func activeProducts(ids ...string) []*product.Product {
+	products := make([]*product.Product, 0, len(ids))
+	for _, id := range ids {
+		products = append(products, &product.Product{ID: id, IsActive: true, Quantity: 10})
+	}
+	return products
+}
+
+// newOrderCheckout returns a checkout use case that writes orders to
+// orderRepo, for the order use case to place orders through
+func newOrderCheckout(cartRepo *mockCartRepo, productRepo *mockProductRepo, orderRepo *mockOrderRepo) *CheckoutUseCase {
+	repo := &mockCheckoutRepo{
+		carts:        cartRepo,
+		products:     productRepo,
+		orders:       orderRepo,
+		reservations: newMockReservationRepo(),
+	}
+	return NewCheckoutUseCase(repo, productRepo)
+}
+
+func TestCreateOrder_ComputesTotal(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	// The stored cart total is stale or tampered; only the items are trusted
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive, Total: 0.01})
+	cartRepo.items["ci1"] = &cart.CartItem{ID: "ci1", CartID: "cart-1", ProductID: "p1", Quantity: 2, Price: 12.50}
+	cartRepo.items["ci2"] = &cart.CartItem{ID: "ci2", CartID: "cart-1", ProductID: "p2", Quantity: 1, Price: 5.25}
+	uc := NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, newMockProductRepo(activeProducts("p1", "p2")...), orderRepo), nil, nil, nil, nil, nil)
+
+	o, err := uc.CreateOrder("buyer", "cart-1")
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	persisted, ok := orderRepo.orders[o.ID]
+	if !ok {
+		t.Fatal("CreateOrder() did not persist the order")
+	}
+	if persisted.Total != 30.25 {
+		t.Errorf("persisted total = %v, want computed 30.25", persisted.Total)
+	}
+	if got := len(orderRepo.items[o.ID]); got != 2 {
+		t.Errorf("order items = %d, want 2", got)
+	}
+	if got := cartRepo.carts["cart-1"].Status; got != cart.CartStatusCheckedOut {
+		t.Errorf("cart status = %s, want %s", got, cart.CartStatusCheckedOut)
+	}
+
+	// The checked-out cart cannot be ordered again
+	if _, err := uc.CreateOrder("buyer", "cart-1"); !errors.Is(err, checkout.ErrCartNotActive) {
+		t.Errorf("second CreateOrder() error = %v, want %v", err, checkout.ErrCartNotActive)
+	}
+	if len(orderRepo.orders) != 1 {
+		t.Errorf("persisted orders = %d, want 1", len(orderRepo.orders))
+	}
+}
+
+func TestCreateOrder_RejectsUnavailableProducts(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive})
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		cartRepo.items["ci-"+id] = &cart.CartItem{ID: "ci-" + id, CartID: "cart-1", ProductID: id, Quantity: 1, Price: 10}
+	}
+	deletedAt := time.Now()
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", IsActive: true},
+		&product.Product{ID: "p2", IsActive: false},
+		&product.Product{ID: "p3", IsActive: false, DeletedAt: &deletedAt},
+	)
+	uc := NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, productRepo, orderRepo), productRepo, nil, nil, nil, nil)
+
+	_, err := uc.CreateOrder("buyer", "cart-1")
+	var invalidErr *order.InvalidProductsError
+	if !errors.As(err, &invalidErr) || !errors.Is(err, order.ErrInvalidProducts) {
+		t.Fatalf("CreateOrder() error = %v, want InvalidProductsError", err)
+	}
+	got := append([]string(nil), invalidErr.ProductIDs...)
+	sort.Strings(got)
+	if strings.Join(got, ",") != "p2,p3,p4" {
+		t.Errorf("invalid products = %v, want p2, p3 and p4", invalidErr.ProductIDs)
+	}
+	if len(orderRepo.orders) != 0 {
+		t.Error("CreateOrder() persisted an order with unavailable products")
+	}
+}
+
+func TestCreateOrder_EmptyCart(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive})
+	uc := NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, newMockProductRepo(), orderRepo), nil, nil, nil, nil, nil)
+
+	if _, err := uc.CreateOrder("buyer", "cart-1"); !errors.Is(err, cart.ErrEmptyCart) {
+		t.Fatalf("CreateOrder() error = %v, want %v", err, cart.ErrEmptyCart)
+	}
+	if len(orderRepo.orders) != 0 {
+		t.Error("CreateOrder() persisted an order for an empty cart")
+	}
+}
+
+func TestCreateOrder_RequiresOwnActiveCart(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	cartRepo := newMockCartRepo(
+		&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive},
+		&cart.Cart{ID: "old-cart", UserID: "buyer", Status: cart.CartStatusCheckedOut},
+	)
+	for _, cartID := range []string{"cart-1", "old-cart"} {
+		cartRepo.items["ci-"+cartID] = &cart.CartItem{ID: "ci-" + cartID, CartID: cartID, ProductID: "p1", Quantity: 1, Price: 10}
+	}
+	uc := NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, newMockProductRepo(activeProducts("p1")...), orderRepo), nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		userID  string
+		cartID  string
+		wantErr error
+	}{
+		{"Another user's cart", "stranger", "cart-1", checkout.ErrCartNotFound},
+		{"Checked-out cart", "buyer", "old-cart", checkout.ErrCartNotActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.CreateOrder(tt.userID, tt.cartID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateOrder() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(orderRepo.orders) != 0 {
+		t.Errorf("persisted orders = %d, want 0", len(orderRepo.orders))
+	}
+}
+
+func TestCreateOrder_RateLimited(t *testing.T) {
+	orderRepo := newMockOrderRepo()
+	cartRepo := newMockCartRepo(&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive})
+	cartRepo.items["ci1"] = &cart.CartItem{ID: "ci1", CartID: "cart-1", ProductID: "p1", Quantity: 1, Price: 10}
+	uc := NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, newMockProductRepo(activeProducts("p1")...), orderRepo), nil, nil, newMockRateLimiter(1), nil, nil)
+
+	if _, err := uc.CreateOrder("buyer", "cart-1"); err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	_, err := uc.CreateOrder("buyer", "cart-1")
+	var rateErr *order.RateLimitError
+	if !errors.As(err, &rateErr) || !errors.Is(err, order.ErrRateLimited) {
+		t.Fatalf("CreateOrder() over the limit error = %v, want RateLimitError", err)
+	}
+	if rateErr.RetryAfter <= 0 {
+		t.Errorf("RetryAfter = %v, want positive", rateErr.RetryAfter)
+	}
+	if len(orderRepo.orders) != 1 {
+		t.Errorf("persisted orders = %d, want 1", len(orderRepo.orders))
+	}
+}
+
+func TestCreateOrderIdempotent(t *testing.T) {
+	newFixture := func() (*OrderUseCase, *mockOrderRepo, *mockIdempotencyStore) {
+		orderRepo := newMockOrderRepo()
+		cartRepo := newMockCartRepo(
+			&cart.Cart{ID: "cart-1", UserID: "buyer", Status: cart.CartStatusActive},
+			&cart.Cart{ID: "cart-2", UserID: "buyer", Status: cart.CartStatusActive},
+		)
+		cartRepo.items["ci1"] = &cart.CartItem{ID: "ci1", CartID: "cart-1", ProductID: "p1", Quantity: 1, Price: 10}
+		cartRepo.items["ci2"] = &cart.CartItem{ID: "ci2", CartID: "cart-2", ProductID: "p1", Quantity: 1, Price: 10}
+		store := newMockIdempotencyStore()
+		return NewOrderUseCase(orderRepo, newOrderCheckout(cartRepo, newMockProductRepo(activeProducts("p1")...), orderRepo), nil, nil, nil, store, nil), orderRepo, store
+	}
+
+	t.Run("First request creates", func(t *testing.T) {
+		uc, orderRepo, _ := newFixture()
+
+		o, replayed, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-1")
+		if err != nil {
+			t.Fatalf("CreateOrderIdempotent() error = %v", err)
+		}
+		if replayed {
+			t.Error("first request reported as replayed")
+		}
+		if _, ok := orderRepo.orders[o.ID]; !ok {
+			t.Error("order was not persisted")
+		}
+	})
+
+	t.Run("Duplicate returns cached order", func(t *testing.T) {
+		uc, orderRepo, _ := newFixture()
+
+		first, _, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-1")
+		if err != nil {
+			t.Fatalf("CreateOrderIdempotent() error = %v", err)
+		}
+		second, replayed, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-1")
+		if err != nil {
+			t.Fatalf("CreateOrderIdempotent() retry error = %v", err)
+		}
+		if !replayed || second.ID != first.ID {
+			t.Errorf("retry = %s (replayed %v), want original order %s", second.ID, replayed, first.ID)
+		}
+		if len(orderRepo.orders) != 1 {
+			t.Errorf("orders persisted = %d, want 1", len(orderRepo.orders))
+		}
+
+		// The same key from another user is not a duplicate
+		if _, replayed, _ := uc.CreateOrderIdempotent("other-buyer", "key-1", "cart-1"); replayed {
+			t.Error("another user's request with the same key was replayed")
+		}
+	})
+
+	t.Run("Key reused for another request", func(t *testing.T) {
+		uc, orderRepo, _ := newFixture()
+
+		if _, _, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-1"); err != nil {
+			t.Fatalf("CreateOrderIdempotent() error = %v", err)
+		}
+		if _, _, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-2"); !errors.Is(err, order.ErrIdempotencyKeyReused) {
+			t.Errorf("CreateOrderIdempotent(cart-2) error = %v, want %v", err, order.ErrIdempotencyKeyReused)
+		}
+		if len(orderRepo.orders) != 1 {
+			t.Errorf("orders persisted = %d, want 1", len(orderRepo.orders))
+		}
+	})
+
+	t.Run("Expired key creates new order", func(t *testing.T) {
+		uc, orderRepo, store := newFixture()
+
+		first, _, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-1")
+		if err != nil {
+			t.Fatalf("CreateOrderIdempotent() error = %v", err)
+		}
+		store.expire("buyer", "key-1")
+
+		second, replayed, err := uc.CreateOrderIdempotent("buyer", "key-1", "cart-2")
+		if err != nil {
+			t.Fatalf("CreateOrderIdempotent() after expiry error = %v", err)
+		}
+		if replayed || second.ID == first.ID {
+			t.Error("request after expiry replayed the original order")
+		}
+		if len(orderRepo.orders) != 2 {
+			t.Errorf("orders persisted = %d, want 2", len(orderRepo.orders))
+		}
+	})
+
+	t.Run("Failed order releases key", func(t *testing.T) {
+		uc, _, store := newFixture()
+
+		if _, _, err := uc.CreateOrderIdempotent("buyer", "key-1", "unknown-cart"); !errors.Is(err, checkout.ErrCartNotFound) {
+			t.Fatalf("CreateOrderIdempotent() error = %v, want %v", err, checkout.ErrCartNotFound)
+		}
+		if _, ok := store.keys["buyer:key-1"]; ok {
+			t.Error("key stayed claimed after the order failed")
+		}
+	})
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to order.OrderStatus
+		want     bool
+	}{
+		// Happy path
+		{order.OrderStatusPending, order.OrderStatusPaid, true},
+		{order.OrderStatusPaid, order.OrderStatusShipped, true},
+		{order.OrderStatusPaid, order.OrderStatusPartiallyShipped, true},
+		{order.OrderStatusPartiallyShipped, order.OrderStatusShipped, true},
+		{order.OrderStatusShipped, order.OrderStatusCompleted, true},
+
+		// Cancellation is only possible before anything ships
+		{order.OrderStatusPending, order.OrderStatusCancelled, true},
+		{order.OrderStatusPaid, order.OrderStatusCancelled, true},
+		{order.OrderStatusPartiallyShipped, order.OrderStatusCancelled, false},
+		{order.OrderStatusShipped, order.OrderStatusCancelled, false},
+
+		// Skipping steps
+		{order.OrderStatusPending, order.OrderStatusShipped, false},
+		{order.OrderStatusPending, order.OrderStatusCompleted, false},
+		{order.OrderStatusPaid, order.OrderStatusCompleted, false},
+
+		// Going backwards
+		{order.OrderStatusCompleted, order.OrderStatusPending, false},
+		{order.OrderStatusShipped, order.OrderStatusPaid, false},
+		{order.OrderStatusPaid, order.OrderStatusPending, false},
+
+		// Final statuses
+		{order.OrderStatusCompleted, order.OrderStatusCancelled, false},
+		{order.OrderStatusCancelled, order.OrderStatusPending, false},
+		{order.OrderStatusCancelled, order.OrderStatusPaid, false},
+
+		// No-op and unknown statuses
+		{order.OrderStatusPaid, order.OrderStatusPaid, false},
+		{order.OrderStatus("refunded"), order.OrderStatusPaid, false},
+		{order.OrderStatusPending, order.OrderStatus("refunded"), false},
+	}
+
+	for _, tt := range tests {
+		if got := order.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionOrderStatus(t *testing.T) {
+	newFixture := func(status order.OrderStatus) (*OrderUseCase, *mockOrderRepo) {
+		orderRepo := newMockOrderRepo()
+		orderRepo.orders["o1"] = &order.Order{ID: "o1", UserID: "buyer", Status: status}
+		orderRepo.items["o1"] = []*order.OrderItem{{ID: "i1", OrderID: "o1", ProductID: "p1", Status: order.ItemStatusPending}}
+		productRepo := newMockProductRepo(&product.Product{ID: "p1", SellerID: "seller-a"})
+		payoutUseCase := NewPayoutUseCase(newMockPayoutRepo(orderRepo), orderRepo, productRepo, 10)
+		return NewOrderUseCase(orderRepo, nil, productRepo, payoutUseCase, nil, nil, nil), orderRepo
+	}
+
+	tests := []struct {
+		name    string
+		from    order.OrderStatus
+		to      order.OrderStatus
+		actorID string
+		role    user.Role
+		wantErr error
+	}{
+		{"Admin marks paid", order.OrderStatusPending, order.OrderStatusPaid, "admin", user.RoleAdmin, nil},
+		{"Admin completes", order.OrderStatusShipped, order.OrderStatusCompleted, "admin", user.RoleAdmin, nil},
+		{"Seller cannot ship the whole order", order.OrderStatusPaid, order.OrderStatusShipped, "seller-a", user.RoleSeller, order.ErrInvalidTransition},
+		{"Admin cannot ship the whole order", order.OrderStatusPaid, order.OrderStatusPartiallyShipped, "admin", user.RoleAdmin, order.ErrInvalidTransition},
+		{"Seller cannot complete", order.OrderStatusShipped, order.OrderStatusCompleted, "seller-a", user.RoleSeller, order.ErrNotParticipant},
+		{"Buyer cancels pending", order.OrderStatusPending, order.OrderStatusCancelled, "buyer", user.RoleCustomer, nil},
+		{"Admin cancels", order.OrderStatusPaid, order.OrderStatusCancelled, "admin", user.RoleAdmin, nil},
+		{"Other customer rejected", order.OrderStatusPending, order.OrderStatusPaid, "stranger", user.RoleCustomer, order.ErrNotParticipant},
+		{"Seller not in order rejected", order.OrderStatusPaid, order.OrderStatusShipped, "seller-b", user.RoleSeller, order.ErrNotParticipant},
+		{"Buyer cannot mark paid", order.OrderStatusPending, order.OrderStatusPaid, "buyer", user.RoleCustomer, order.ErrNotParticipant},
+		{"Seller cannot mark paid", order.OrderStatusPending, order.OrderStatusPaid, "seller-a", user.RoleSeller, order.ErrNotParticipant},
+		{"Buyer cannot cancel paid", order.OrderStatusPaid, order.OrderStatusCancelled, "buyer", user.RoleCustomer, order.ErrNotParticipant},
+		{"Buyer cannot complete", order.OrderStatusShipped, order.OrderStatusCompleted, "buyer", user.RoleCustomer, order.ErrNotParticipant},
+		{"Seller cannot cancel", order.OrderStatusPending, order.OrderStatusCancelled, "seller-a", user.RoleSeller, order.ErrNotParticipant},
+		{"Backwards transition rejected", order.OrderStatusCompleted, order.OrderStatusPending, "admin", user.RoleAdmin, order.ErrInvalidTransition},
+		{"Skipped step rejected", order.OrderStatusPending, order.OrderStatusShipped, "buyer", user.RoleCustomer, order.ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, orderRepo := newFixture(tt.from)
+
+			o, err := uc.TransitionOrderStatus("o1", tt.actorID, tt.role, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TransitionOrderStatus() error = %v, want %v", err, tt.wantErr)
+			}
+
+			want := tt.to
+			if tt.wantErr != nil {
+				want = tt.from
+			} else if o.Status != tt.to {
+				t.Errorf("returned status = %s, want %s", o.Status, tt.to)
+			}
+			if got := orderRepo.orders["o1"].Status; got != want {
+				t.Errorf("persisted status = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestGetOrderItemsWithProduct(t *testing.T) {
+	deletedAt := time.Now()
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "live", Title: "Blue Mountain Coffee", Price: 30, Images: []string{"coffee.jpg"}, IsActive: true},
+		&product.Product{ID: "soft-deleted", Title: "Jerk Seasoning", Price: 9, Images: []string{"jerk.jpg"}, DeletedAt: &deletedAt},
+	)
+	orderRepo := newMockOrderRepo()
+	orderRepo.products = productRepo
+	orderRepo.items["o1"] = []*order.OrderItem{
+		// The live price has since risen from 25 to 30
+		{ID: "i1", OrderID: "o1", ProductID: "live", Quantity: 2, Price: 25, Title: "Coffee (old name)"},
+		{ID: "i2", OrderID: "o1", ProductID: "soft-deleted", Quantity: 1, Price: 8, Title: "Jerk Seasoning"},
+		{ID: "i3", OrderID: "o1", ProductID: "gone", Quantity: 1, Price: 12, Title: "Rasta T-Shirt"},
+	}
+	uc := NewOrderUseCase(orderRepo, nil, productRepo, nil, nil, nil, nil)
+
+	items, err := uc.GetOrderItemsWithProduct("o1")
+	if err != nil {
+		t.Fatalf("GetOrderItemsWithProduct() error = %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("items = %d, want 3", len(items))
+	}
+
+	tests := []struct {
+		title     string
+		price     float64
+		images    int
+		available bool
+	}{
+		{"Blue Mountain Coffee", 25, 1, true},
+		{"Jerk Seasoning", 8, 1, false},
+		{"Rasta T-Shirt", 12, 0, false},
+	}
+	for i, tt := range tests {
+		item := items[i]
+		if item.Title != tt.title {
+			t.Errorf("items[%d].Title = %q, want %q", i, item.Title, tt.title)
+		}
+		if item.Price != tt.price {
+			t.Errorf("items[%d].Price = %v, want price at purchase %v", i, item.Price, tt.price)
+		}
+		if len(item.Images) != tt.images {
+			t.Errorf("items[%d].Images = %v, want %d image(s)", i, item.Images, tt.images)
+		}
+		if item.ProductAvailable != tt.available {
+			t.Errorf("items[%d].ProductAvailable = %v, want %v", i, item.ProductAvailable, tt.available)
+		}
+	}
+}
+
+func TestCartItemsTotal_ExactCents(t *testing.T) {
+	items := []*cart.CartItem{
+		{Price: 0.1, Quantity: 1},
+		{Price: 0.2, Quantity: 1},
+		{Price: 19.99, Quantity: 3},
+		{Price: 0.07, Quantity: 1000},
+	}
+	// 0.10 + 0.20 + 59.97 + 70.00
+	if got := cartItemsTotal(items); got != 130.27 {
+		t.Errorf("cartItemsTotal() = %v, want 130.27", got)
+	}
+}
+
+func TestConfirmPayment(t *testing.T) {
+	newFixture := func(status order.OrderStatus) (*OrderUseCase, *mockOrderRepo) {
+		orderRepo := newMockOrderRepo()
+		orderRepo.Create(&order.Order{ID: "order-1", UserID: "buyer-1", Status: status, Total: 45.5, PaymentRef: "pay_123"})
+		return NewOrderUseCase(orderRepo, nil, newMockProductRepo(), nil, nil, nil, nil), orderRepo
+	}
+
+	t.Run("Pending order becomes paid once", func(t *testing.T) {
+		uc, orderRepo := newFixture(order.OrderStatusPending)
+
+		o, duplicate, err := uc.ConfirmPayment("evt_1", "pay_123", 45.5)
+		if err != nil {
+			t.Fatalf("ConfirmPayment() error = %v", err)
+		}
+		if duplicate || o.ID != "order-1" || o.Status != order.OrderStatusPaid {
+			t.Errorf("ConfirmPayment() = %+v, duplicate %v; want order-1 paid", o, duplicate)
+		}
+
+		// A redelivery is recognised and changes nothing
+		orderRepo.orders["order-1"].Status = order.OrderStatusShipped
+		o, duplicate, err = uc.ConfirmPayment("evt_1", "pay_123", 45.5)
+		if err != nil || !duplicate {
+			t.Fatalf("redelivered ConfirmPayment() duplicate = %v, error = %v; want a duplicate", duplicate, err)
+		}
+		if o.Status != order.OrderStatusShipped {
+			t.Errorf("status after redelivery = %q, want %q", o.Status, order.OrderStatusShipped)
+		}
+		if len(orderRepo.paymentEvents) != 1 {
+			t.Errorf("recorded %d payment events, want 1", len(orderRepo.paymentEvents))
+		}
+	})
+
+	t.Run("Cancelled order stays cancelled", func(t *testing.T) {
+		uc, _ := newFixture(order.OrderStatusCancelled)
+
+		o, _, err := uc.ConfirmPayment("evt_1", "pay_123", 45.5)
+		if err != nil {
+			t.Fatalf("ConfirmPayment() error = %v", err)
+		}
+		if o.Status != order.OrderStatusCancelled {
+			t.Errorf("status = %q, want %q", o.Status, order.OrderStatusCancelled)
+		}
+	})
+
+	t.Run("Short payment rejected", func(t *testing.T) {
+		uc, orderRepo := newFixture(order.OrderStatusPending)
+
+		if _, _, err := uc.ConfirmPayment("evt_1", "pay_123", 45.49); !errors.Is(err, order.ErrPaymentAmountMismatch) {
+			t.Fatalf("ConfirmPayment() error = %v, want %v", err, order.ErrPaymentAmountMismatch)
+		}
+		if status := orderRepo.orders["order-1"].Status; status != order.OrderStatusPending {
+			t.Errorf("status = %q, want %q", status, order.OrderStatusPending)
+		}
+		if len(orderRepo.paymentEvents) != 0 {
+			t.Errorf("recorded %d payment events, want none", len(orderRepo.paymentEvents))
+		}
+	})
+
+	t.Run("Unknown payment reference", func(t *testing.T) {
+		uc, _ := newFixture(order.OrderStatusPending)
+
+		if _, _, err := uc.ConfirmPayment("evt_1", "pay_999", 45.5); !errors.Is(err, order.ErrNotFound) {
+			t.Errorf("ConfirmPayment() error = %v, want %v", err, order.ErrNotFound)
+		}
+	})
+
+	t.Run("Payment reference cannot be reused", func(t *testing.T) {
+		uc, orderRepo := newFixture(order.OrderStatusPending)
+
+		err := orderRepo.Create(&order.Order{ID: "order-2", UserID: "buyer-2", Status: order.OrderStatusPending, Total: 1, PaymentRef: "pay_123"})
+		if !errors.Is(err, order.ErrDuplicatePaymentRef) {
+			t.Fatalf("Create() with a used payment reference error = %v, want %v", err, order.ErrDuplicatePaymentRef)
+		}
+		o, _, err := uc.ConfirmPayment("evt_1", "pay_123", 45.5)
+		if err != nil {
+			t.Fatalf("ConfirmPayment() error = %v", err)
+		}
+		if o.ID != "order-1" {
+			t.Errorf("ConfirmPayment() paid %s, want order-1", o.ID)
+		}
+	})
+}
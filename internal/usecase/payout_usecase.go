@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/pkg/money"
+	"github.com/google/uuid"
+)
+
+// PayoutUseCase handles seller earnings and settlement business logic
+type PayoutUseCase struct {
+	payoutRepo  payout.Repository
+	orderRepo   order.Repository
+	productRepo product.Repository
+	feePercent  float64
+}
+
+// NewPayoutUseCase creates a new payout use case
+func NewPayoutUseCase(payoutRepo payout.Repository, orderRepo order.Repository, productRepo product.Repository, feePercent float64) *PayoutUseCase {
+	return &PayoutUseCase{
+		payoutRepo:  payoutRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		feePercent:  feePercent,
+	}
+}
+
+// AccrueForOrder records each seller's net earnings for a completed order.
+// Accrual is idempotent per order: a second call returns payout.ErrAlreadyAccrued.
+func (uc *PayoutUseCase) AccrueForOrder(orderID string) ([]*payout.Payout, error) {
+	exists, err := uc.payoutRepo.ExistsForOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, payout.ErrAlreadyAccrued
+	}
+
+	payouts, err := uc.payoutsForOrder(orderID)
+	if err != nil || len(payouts) == 0 {
+		return nil, err
+	}
+
+	if err := uc.payoutRepo.Accrue(payouts); err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// CompleteOrder marks an order completed and accrues its sellers' earnings
+// in one transaction. An order whose earnings were already accrued is still
+// completed, and payout.ErrAlreadyAccrued is returned.
+func (uc *PayoutUseCase) CompleteOrder(orderID string) ([]*payout.Payout, error) {
+	payouts, err := uc.payoutsForOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	accrued, err := uc.payoutRepo.CompleteOrder(orderID, payouts)
+	if err != nil {
+		return nil, err
+	}
+	if !accrued {
+		return nil, payout.ErrAlreadyAccrued
+	}
+
+	return payouts, nil
+}
+
+// payoutsForOrder computes each seller's net earnings from an order's items
+func (uc *PayoutUseCase) payoutsForOrder(orderID string) ([]*payout.Payout, error) {
+	items, err := uc.orderRepo.GetItems(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group gross earnings by seller, preserving first-seen order
+	var sellers []string
+	gross := make(map[string]money.Amount)
+	sellerOf := make(map[string]string)
+	for _, item := range items {
+		sellerID, ok := sellerOf[item.ProductID]
+		if !ok {
+			p, err := uc.productRepo.GetByID(item.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve seller for product %s: %w", item.ProductID, err)
+			}
+			sellerID = p.SellerID
+			sellerOf[item.ProductID] = sellerID
+		}
+		if _, seen := gross[sellerID]; !seen {
+			sellers = append(sellers, sellerID)
+		}
+		gross[sellerID] = gross[sellerID].Add(money.FromFloat(item.Price).Mul(int64(item.Quantity)))
+	}
+
+	if len(sellers) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	payouts := make([]*payout.Payout, 0, len(sellers))
+	for _, sellerID := range sellers {
+		fee, net := payout.CalculateFees(gross[sellerID].Float64(), uc.feePercent)
+		payouts = append(payouts, &payout.Payout{
+			ID:        uuid.New().String(),
+			SellerID:  sellerID,
+			OrderID:   orderID,
+			Gross:     gross[sellerID].Float64(),
+			Fee:       fee,
+			Net:       net,
+			Status:    payout.StatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return payouts, nil
+}
+
+// GetSellerEarnings retrieves a seller's pending and paid balances
+func (uc *PayoutUseCase) GetSellerEarnings(sellerID string) (*payout.Earnings, error) {
+	return uc.payoutRepo.GetEarnings(sellerID)
+}
+
+// MarkPaid transitions a pending payout to paid
+func (uc *PayoutUseCase) MarkPaid(payoutID string) (*payout.Payout, error) {
+	p, err := uc.payoutRepo.GetByID(payoutID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == payout.StatusPaid {
+		return nil, payout.ErrAlreadyPaid
+	}
+
+	now := time.Now()
+	if err := uc.payoutRepo.MarkPaid(payoutID, now); err != nil {
+		return nil, err
+	}
+
+	p.Status = payout.StatusPaid
+	p.PaidAt = &now
+	p.UpdatedAt = now
+	return p, nil
+}
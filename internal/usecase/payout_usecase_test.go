@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/order"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/payout"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+)
+
+func newPayoutFixture(t *testing.T) (*OrderUseCase, *PayoutUseCase, *mockPayoutRepo) {
+	t.Helper()
+
+	orderRepo := newMockOrderRepo()
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", Price: 50, IsActive: true},
+		&product.Product{ID: "p2", SellerID: "seller-b", Price: 20, IsActive: true},
+		&product.Product{ID: "p3", SellerID: "seller-a", Price: 10, IsActive: true},
+	)
+	payoutRepo := newMockPayoutRepo(orderRepo)
+
+	orderRepo.orders["order-1"] = &order.Order{ID: "order-1", UserID: "buyer", Status: order.OrderStatusShipped}
+	orderRepo.items["order-1"] = []*order.OrderItem{
+		{ID: "i1", OrderID: "order-1", ProductID: "p1", Quantity: 2, Price: 50},
+		{ID: "i2", OrderID: "order-1", ProductID: "p2", Quantity: 1, Price: 20},
+		{ID: "i3", OrderID: "order-1", ProductID: "p3", Quantity: 3, Price: 10},
+	}
+
+	payoutUseCase := NewPayoutUseCase(payoutRepo, orderRepo, productRepo, 10)
+	return NewOrderUseCase(orderRepo, nil, productRepo, payoutUseCase, nil, nil, nil), payoutUseCase, payoutRepo
+}
+
+func TestCalculateFees(t *testing.T) {
+	tests := []struct {
+		name       string
+		gross      float64
+		feePercent float64
+		wantFee    float64
+		wantNet    float64
+	}{
+		{"No fee", 100, 0, 0, 100},
+		{"Ten percent", 100, 10, 10, 90},
+		{"Rounded to cents", 33.33, 5, 1.67, 31.66},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, net := payout.CalculateFees(tt.gross, tt.feePercent)
+			if fee != tt.wantFee || net != tt.wantNet {
+				t.Errorf("CalculateFees(%v, %v) = (%v, %v), want (%v, %v)", tt.gross, tt.feePercent, fee, net, tt.wantFee, tt.wantNet)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatus_CompletionAccruesEarnings(t *testing.T) {
+	orderUseCase, payoutUseCase, _ := newPayoutFixture(t)
+
+	if err := orderUseCase.UpdateOrderStatus("order-1", order.OrderStatusShipped, order.OrderStatusCompleted); err != nil {
+		t.Fatalf("UpdateOrderStatus() error = %v", err)
+	}
+
+	tests := []struct {
+		sellerID    string
+		wantPending float64
+	}{
+		{"seller-a", 117}, // (2*50 + 3*10) - 10% fee
+		{"seller-b", 18},  // 20 - 10% fee
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sellerID, func(t *testing.T) {
+			earnings, err := payoutUseCase.GetSellerEarnings(tt.sellerID)
+			if err != nil {
+				t.Fatalf("GetSellerEarnings() error = %v", err)
+			}
+			if earnings.Pending != tt.wantPending || earnings.Paid != 0 {
+				t.Errorf("earnings = %+v, want pending %v and nothing paid", earnings, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatus_FailedAccrualLeavesOrderUncompleted(t *testing.T) {
+	orderUseCase, _, payoutRepo := newPayoutFixture(t)
+	payoutRepo.completeErr = errors.New("connection reset")
+
+	if err := orderUseCase.UpdateOrderStatus("order-1", order.OrderStatusShipped, order.OrderStatusCompleted); err == nil {
+		t.Fatal("UpdateOrderStatus() error = nil, want the accrual error")
+	}
+	if got := payoutRepo.orders.orders["order-1"].Status; got != order.OrderStatusShipped {
+		t.Errorf("order status = %q, want %q", got, order.OrderStatusShipped)
+	}
+}
+
+func TestUpdateOrderStatus_NonCompletionDoesNotAccrue(t *testing.T) {
+	orderUseCase, _, payoutRepo := newPayoutFixture(t)
+	payoutRepo.orders.orders["order-1"].Status = order.OrderStatusPaid
+
+	if err := orderUseCase.UpdateOrderStatus("order-1", order.OrderStatusPaid, order.OrderStatusShipped); err != nil {
+		t.Fatalf("UpdateOrderStatus() error = %v", err)
+	}
+	if len(payoutRepo.payouts) != 0 {
+		t.Errorf("expected no payouts before completion, got %d", len(payoutRepo.payouts))
+	}
+}
+
+func TestAccrueForOrder_DoubleAccrualGuard(t *testing.T) {
+	orderUseCase, payoutUseCase, payoutRepo := newPayoutFixture(t)
+
+	if _, err := payoutUseCase.AccrueForOrder("order-1"); err != nil {
+		t.Fatalf("first AccrueForOrder() error = %v", err)
+	}
+
+	if _, err := payoutUseCase.AccrueForOrder("order-1"); !errors.Is(err, payout.ErrAlreadyAccrued) {
+		t.Errorf("second AccrueForOrder() error = %v, want ErrAlreadyAccrued", err)
+	}
+
+	// Completing the order again must not double the ledger
+	if err := orderUseCase.UpdateOrderStatus("order-1", order.OrderStatusShipped, order.OrderStatusCompleted); err != nil {
+		t.Fatalf("UpdateOrderStatus() error = %v", err)
+	}
+	if len(payoutRepo.payouts) != 2 {
+		t.Errorf("expected 2 payouts after repeated accrual, got %d", len(payoutRepo.payouts))
+	}
+
+	earnings, _ := payoutUseCase.GetSellerEarnings("seller-a")
+	if earnings.Pending != 117 {
+		t.Errorf("seller-a pending = %v, want 117", earnings.Pending)
+	}
+}
+
+func TestMarkPaid(t *testing.T) {
+	_, payoutUseCase, _ := newPayoutFixture(t)
+
+	payouts, err := payoutUseCase.AccrueForOrder("order-1")
+	if err != nil {
+		t.Fatalf("AccrueForOrder() error = %v", err)
+	}
+
+	var target *payout.Payout
+	for _, p := range payouts {
+		if p.SellerID == "seller-b" {
+			target = p
+		}
+	}
+
+	paid, err := payoutUseCase.MarkPaid(target.ID)
+	if err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if paid.Status != payout.StatusPaid || paid.PaidAt == nil {
+		t.Errorf("MarkPaid() = %+v, want paid status with timestamp", paid)
+	}
+
+	earnings, _ := payoutUseCase.GetSellerEarnings("seller-b")
+	if earnings.Pending != 0 || earnings.Paid != 18 {
+		t.Errorf("earnings after payout = %+v, want 0 pending and 18 paid", earnings)
+	}
+
+	if _, err := payoutUseCase.MarkPaid(target.ID); !errors.Is(err, payout.ErrAlreadyPaid) {
+		t.Errorf("second MarkPaid() error = %v, want ErrAlreadyPaid", err)
+	}
+	if _, err := payoutUseCase.MarkPaid("missing"); !errors.Is(err, payout.ErrNotFound) {
+		t.Errorf("MarkPaid(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -1,24 +1,48 @@
 package usecase
 
 import (
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/pkg/bulk"
 	"github.com/google/uuid"
 )
 
 // ProductUseCase handles product business logic
 type ProductUseCase struct {
-	productRepo product.Repository
+	productRepo     product.Repository
+	reservationRepo product.ReservationRepository
+	adjustmentRepo  product.AdjustmentRepository
+	config          ProductConfig
+}
+
+// ProductConfig holds product listing settings
+type ProductConfig struct {
+	// RegenerateSlugOnTitleChange derives a new slug when a product is
+	// renamed; by default slugs stay stable so shared links keep working
+	RegenerateSlugOnTitleChange bool
 }
 
 // NewProductUseCase creates a new product use case
-func NewProductUseCase(productRepo product.Repository) *ProductUseCase {
-	return &ProductUseCase{productRepo: productRepo}
+func NewProductUseCase(productRepo product.Repository, reservationRepo product.ReservationRepository, adjustmentRepo product.AdjustmentRepository, config ProductConfig) *ProductUseCase {
+	return &ProductUseCase{
+		productRepo:     productRepo,
+		reservationRepo: reservationRepo,
+		adjustmentRepo:  adjustmentRepo,
+		config:          config,
+	}
 }
 
 // CreateProduct creates a new product
 func (uc *ProductUseCase) CreateProduct(sellerID, title, description string, price float64, quantity int, images []string, categoryID string) (*product.Product, error) {
+	images, err := product.NormalizeImages(images)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &product.Product{
 		ID:          uuid.New().String(),
 		SellerID:    sellerID,
@@ -33,7 +57,9 @@ func (uc *ProductUseCase) CreateProduct(sellerID, title, description string, pri
 		UpdatedAt:   time.Now(),
 	}
 
-	err := uc.productRepo.Create(p)
+	err = uc.saveWithUniqueSlug(p, func() error {
+		return uc.productRepo.Create(p)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -41,14 +67,129 @@ func (uc *ProductUseCase) CreateProduct(sellerID, title, description string, pri
 	return p, nil
 }
 
+// slugAttempts is how many slugs are tried when concurrent writes keep
+// claiming the one just picked
+const slugAttempts = 3
+
+// saveWithUniqueSlug gives p a slug derived from its title that no other
+// product uses and saves it, picking a new slug if another product claimed
+// it between the check and the save
+func (uc *ProductUseCase) saveWithUniqueSlug(p *product.Product, save func() error) error {
+	for attempt := 1; ; attempt++ {
+		slug, err := uc.uniqueSlug(p.Title, p.ID)
+		if err != nil {
+			return err
+		}
+		p.Slug = slug
+
+		err = save()
+		if !errors.Is(err, product.ErrSlugTaken) || attempt == slugAttempts {
+			return err
+		}
+	}
+}
+
+// uniqueSlug derives a slug from the title that no other product uses
+func (uc *ProductUseCase) uniqueSlug(title, productID string) (string, error) {
+	return product.UniqueSlug(product.Slugify(title), func(slug string) (bool, error) {
+		return uc.productRepo.SlugExists(slug, productID)
+	})
+}
+
 // GetProductByID retrieves a product by ID
 func (uc *ProductUseCase) GetProductByID(id string) (*product.Product, error) {
 	return uc.productRepo.GetByID(id)
 }
 
+// ProductImageKey resolves key to the storage key of one of the product's
+// images. Inactive or deleted products are only visible to their seller.
+// Only images the product's seller uploaded resolve, so listing someone
+// else's file on a product never gets it presigned.
+func (uc *ProductUseCase) ProductImageKey(productID, viewerID, key string, keyFromURL func(string) (string, bool)) (string, error) {
+	p, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return "", product.ErrNotFound
+	}
+	if (!p.IsActive || p.DeletedAt != nil) && p.SellerID != viewerID {
+		return "", product.ErrNotFound
+	}
+
+	image, imageKey, ok := product.FindImageKey(p.Images, key, keyFromURL)
+	if !ok {
+		return "", product.ErrImageNotFound
+	}
+	uploaded, err := uc.productRepo.UploadedImages(p.SellerID, []string{image})
+	if err != nil {
+		return "", err
+	}
+	if len(uploaded) == 0 {
+		return "", product.ErrImageNotFound
+	}
+	return imageKey, nil
+}
+
+// SellerImages returns the product's images its seller uploaded, with the
+// copies recorded for them. Only these may be removed from storage along with
+// the product; anything else it lists belongs to someone else.
+func (uc *ProductUseCase) SellerImages(p *product.Product) ([]string, []*product.ImageVariants, error) {
+	images, err := uc.productRepo.UploadedImages(p.SellerID, p.Images)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var variants []*product.ImageVariants
+	for _, v := range p.ImageVariants {
+		if slices.Contains(images, v.URL) {
+			variants = append(variants, v)
+		}
+	}
+	return images, variants, nil
+}
+
+// RecordImageUploads records the seller as the uploader of the image URLs,
+// which is what lets them be presigned once listed on the seller's products
+func (uc *ProductUseCase) RecordImageUploads(sellerID string, urls []string) error {
+	return uc.productRepo.RecordImageUploads(sellerID, urls)
+}
+
+// SaveImageVariants records the copies stored alongside an uploaded image so
+// they are listed with, and deleted along with, products showing it
+func (uc *ProductUseCase) SaveImageVariants(variants *product.ImageVariants) error {
+	return uc.productRepo.SaveImageVariants(variants)
+}
+
+// DeleteImageVariants forgets the copies recorded for the image URLs once
+// they have been removed from storage
+func (uc *ProductUseCase) DeleteImageVariants(urls []string) error {
+	return uc.productRepo.DeleteImageVariants(urls)
+}
+
 // GetProductByIDWithCategory retrieves a product by ID with category details
 func (uc *ProductUseCase) GetProductByIDWithCategory(id string) (*product.ProductWithCategory, error) {
-	return uc.productRepo.GetByIDWithCategory(id)
+	p, err := uc.productRepo.GetByIDWithCategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.setAvailableQuantities([]*product.ProductWithCategory{p}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetProductByIDOrSlugWithCategory resolves a product from either its UUID or
+// its slug and returns it with category details
+func (uc *ProductUseCase) GetProductByIDOrSlugWithCategory(idOrSlug string) (*product.ProductWithCategory, error) {
+	id := idOrSlug
+	if _, err := uuid.Parse(idOrSlug); err != nil {
+		p, err := uc.productRepo.GetBySlug(idOrSlug)
+		if err != nil {
+			return nil, err
+		}
+		id = p.ID
+	}
+
+	return uc.GetProductByIDWithCategory(id)
 }
 
 // ListProducts retrieves a list of products with filters
@@ -58,20 +199,248 @@ func (uc *ProductUseCase) ListProducts(filters map[string]interface{}, page, pag
 
 // ListProductsWithCategory retrieves a list of products with category details and sorting
 func (uc *ProductUseCase) ListProductsWithCategory(filters map[string]interface{}, page, pageSize int, sortBy, sortOrder string) ([]*product.ProductWithCategory, int, error) {
-	return uc.productRepo.ListWithCategory(filters, page, pageSize, sortBy, sortOrder)
+	products, total, err := uc.productRepo.ListWithCategory(filters, page, pageSize, sortBy, sortOrder)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := uc.setAvailableQuantities(products); err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// ListProductsWithCategoryCursor retrieves a page of products after the given
+// cursor, returning the cursor for the following page or "" on the last one
+func (uc *ProductUseCase) ListProductsWithCategoryCursor(filters map[string]interface{}, cursor string, limit int, sortBy, sortOrder string) ([]*product.ProductWithCategory, string, error) {
+	products, next, err := uc.productRepo.ListWithCategoryCursor(filters, cursor, limit, sortBy, sortOrder)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := uc.setAvailableQuantities(products); err != nil {
+		return nil, "", err
+	}
+	return products, next, nil
+}
+
+// setAvailableQuantities fills in sellable stock from active reservations
+func (uc *ProductUseCase) setAvailableQuantities(products []*product.ProductWithCategory) error {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	reserved, err := uc.reservationRepo.ReservedQuantities(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		p.AvailableQuantity = product.AvailableQuantity(p.Quantity, reserved[p.ID])
+	}
+	return nil
 }
 
-// UpdateProduct updates product information
-func (uc *ProductUseCase) UpdateProduct(p *product.Product) error {
+// UpdateProduct updates product information on behalf of the actor, who
+// must be the product's seller or an admin. The seller cannot be reassigned,
+// and activation and deletion are left as they are.
+func (uc *ProductUseCase) UpdateProduct(actorID string, actorRole user.Role, p *product.Product) error {
+	if err := product.ValidatePrice(p.Price); err != nil {
+		return err
+	}
+	if p.Quantity < 0 {
+		return product.ErrInvalidQuantity
+	}
+	images, err := product.NormalizeImages(p.Images)
+	if err != nil {
+		return err
+	}
+	p.Images = images
+
+	existing, err := uc.productRepo.GetByID(p.ID)
+	if err != nil {
+		return err
+	}
+	if !canModifyProduct(existing, actorID, actorRole) {
+		return product.ErrNotOwner
+	}
+	p.SellerID = existing.SellerID
+	// Activation and deletion have their own endpoints
+	p.IsActive = existing.IsActive
+	p.DeletedAt = existing.DeletedAt
+
+	// The slug is server-managed: keep it unless the title changed and
+	// regeneration is enabled, or the product predates slugs
+	p.Slug = existing.Slug
+	p.UpdatedAt = time.Now()
+	save := func() error {
+		return uc.adjustmentRepo.UpdateWithAdjustment(p, actorID, "product update")
+	}
+	if p.Slug == "" || (uc.config.RegenerateSlugOnTitleChange && p.Title != existing.Title) {
+		return uc.saveWithUniqueSlug(p, save)
+	}
+	return save()
+}
+
+// UpdateProductQuantity sets a product's stock on behalf of its seller or an
+// admin and records the change, with the optional reason, in the inventory
+// audit trail
+func (uc *ProductUseCase) UpdateProductQuantity(actorID string, actorRole user.Role, productID string, quantity int, reason string) (*product.Product, error) {
+	if quantity < 0 {
+		return nil, product.ErrInvalidQuantity
+	}
+	if len(reason) > product.MaxAdjustmentReasonLength {
+		return nil, product.ErrReasonTooLong
+	}
+
+	p, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModifyProduct(p, actorID, actorRole) {
+		return nil, product.ErrNotOwner
+	}
+
+	p.Quantity = quantity
 	p.UpdatedAt = time.Now()
-	return uc.productRepo.Update(p)
+	if err := uc.adjustmentRepo.UpdateWithAdjustment(p, actorID, reason); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListInventoryAdjustments retrieves a product's stock history for its
+// seller or an admin, newest first
+func (uc *ProductUseCase) ListInventoryAdjustments(actorID string, actorRole user.Role, productID string, page, pageSize int) ([]*product.InventoryAdjustment, int, error) {
+	p, err := uc.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !canModifyProduct(p, actorID, actorRole) {
+		return nil, 0, product.ErrNotOwner
+	}
+	return uc.adjustmentRepo.ListAdjustments(productID, page, pageSize)
 }
 
-// DeleteProduct deletes a product
+// DeleteProduct permanently removes a product row; reserved for admins
 func (uc *ProductUseCase) DeleteProduct(id string) error {
 	return uc.productRepo.Delete(id)
 }
 
+// DeactivateProduct soft-deletes a product on behalf of its seller or an
+// admin. It drops out of listings but stays fetchable by ID so existing cart
+// and order items keep resolving.
+func (uc *ProductUseCase) DeactivateProduct(actorID string, actorRole user.Role, id string) error {
+	existing, err := uc.productRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !canModifyProduct(existing, actorID, actorRole) {
+		return product.ErrNotOwner
+	}
+	return uc.productRepo.SoftDelete(id)
+}
+
+// canModifyProduct reports whether the actor listed the product or is an admin
+func canModifyProduct(p *product.Product, actorID string, actorRole user.Role) bool {
+	return p.SellerID == actorID || actorRole == user.RoleAdmin
+}
+
+// ListSellerProducts retrieves the seller's own listings, inactive ones included
+func (uc *ProductUseCase) ListSellerProducts(sellerID string, page, pageSize int) ([]*product.ProductWithCategory, int, error) {
+	products, total, err := uc.productRepo.ListBySeller(sellerID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := uc.setAvailableQuantities(products); err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// BulkUpdatePrices applies a price operation to the seller's products selected
+// by ID or category. Products the seller does not own, or whose new price fails
+// validation, are reported as failed; the rest are updated in one transaction.
+// Item indexes refer to productIDs, or to the seller's products in the category.
+func (uc *ProductUseCase) BulkUpdatePrices(sellerID string, productIDs []string, categoryID string, op product.PriceOperation, value float64) (*bulk.Result[*product.PriceUpdateResult], error) {
+	if (len(productIDs) == 0) == (categoryID == "") {
+		return nil, product.ErrInvalidPriceSelector
+	}
+	if err := op.Validate(value); err != nil {
+		return nil, err
+	}
+
+	var products []*product.Product
+	var err error
+	if categoryID != "" {
+		products, err = uc.productRepo.ListBySellerAndCategory(sellerID, categoryID)
+	} else {
+		products, err = uc.productRepo.GetByIDs(productIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*product.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	if categoryID != "" {
+		productIDs = make([]string, 0, len(products))
+		for _, p := range products {
+			productIDs = append(productIDs, p.ID)
+		}
+	}
+
+	now := time.Now()
+	results := bulk.New[*product.PriceUpdateResult]()
+	var changes []*product.PriceChange
+	seen := make(map[string]bool, len(productIDs))
+	for i, id := range productIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		p, ok := byID[id]
+		if !ok || p.SellerID != sellerID {
+			// Don't reveal whether another seller's product exists
+			results.Fail(i, id, product.ErrNotFound)
+			continue
+		}
+
+		newPrice, err := op.Apply(p.Price, value)
+		if err != nil {
+			return nil, err
+		}
+		if err := product.ValidatePrice(newPrice); err != nil {
+			results.Fail(i, id, err)
+			continue
+		}
+
+		results.Succeed(i, &product.PriceUpdateResult{ProductID: id, OldPrice: p.Price, NewPrice: newPrice})
+		changes = append(changes, &product.PriceChange{
+			ID:        uuid.New().String(),
+			ProductID: p.ID,
+			ChangedBy: sellerID,
+			OldPrice:  p.Price,
+			NewPrice:  newPrice,
+			Reason:    "bulk:" + string(op),
+			CreatedAt: now,
+		})
+	}
+
+	if len(changes) > 0 {
+		if err := uc.productRepo.UpdatePrices(changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
 // GetCategories retrieves all product categories
 func (uc *ProductUseCase) GetCategories() ([]*product.Category, error) {
 	return uc.productRepo.GetCategories()
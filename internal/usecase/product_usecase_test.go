@@ -0,0 +1,668 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+)
+
+func newBulkPriceFixture() (*ProductUseCase, *mockProductRepo) {
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", CategoryID: "food", Price: 100},
+		&product.Product{ID: "p2", SellerID: "seller-a", CategoryID: "food", Price: 19.99},
+		&product.Product{ID: "p3", SellerID: "seller-a", CategoryID: "fashion", Price: 40},
+		&product.Product{ID: "p4", SellerID: "seller-b", CategoryID: "food", Price: 60},
+	)
+	return NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{}), productRepo
+}
+
+func TestBulkUpdatePrices_Operations(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    product.PriceOperation
+		value float64
+		want  map[string]float64
+	}{
+		{"Set fixed price", product.PriceOpSet, 25, map[string]float64{"p1": 25, "p2": 25}},
+		{"Increase by percentage", product.PriceOpIncreasePct, 10, map[string]float64{"p1": 110, "p2": 21.99}},
+		{"Decrease by percentage", product.PriceOpDecreasePct, 25, map[string]float64{"p1": 75, "p2": 14.99}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newBulkPriceFixture()
+
+			results, err := uc.BulkUpdatePrices("seller-a", []string{"p1", "p2"}, "", tt.op, tt.value)
+			if err != nil {
+				t.Fatalf("BulkUpdatePrices() error = %v", err)
+			}
+			if len(results.Succeeded) != 2 || len(results.Failed) != 0 {
+				t.Fatalf("got %+v, want 2 succeeded", results)
+			}
+
+			for _, r := range results.Values() {
+				if r.NewPrice != tt.want[r.ProductID] {
+					t.Errorf("result for %s = %+v, want updated to %v", r.ProductID, r, tt.want[r.ProductID])
+				}
+				if got := repo.products[r.ProductID].Price; got != tt.want[r.ProductID] {
+					t.Errorf("stored price for %s = %v, want %v", r.ProductID, got, tt.want[r.ProductID])
+				}
+			}
+			if len(repo.priceChanges) != 2 {
+				t.Errorf("recorded %d price changes, want 2", len(repo.priceChanges))
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePrices_OwnershipFiltering(t *testing.T) {
+	uc, repo := newBulkPriceFixture()
+
+	results, err := uc.BulkUpdatePrices("seller-a", []string{"p1", "p4", "missing"}, "", product.PriceOpSet, 50)
+	if err != nil {
+		t.Fatalf("BulkUpdatePrices() error = %v", err)
+	}
+
+	if len(results.Succeeded) != 1 || results.Succeeded[0].Index != 0 || results.Succeeded[0].Value.ProductID != "p1" {
+		t.Errorf("own product p1 was not updated: %+v", results.Succeeded)
+	}
+	if len(results.Failed) != 2 {
+		t.Fatalf("got %d failures, want 2", len(results.Failed))
+	}
+	for i, id := range []string{"p4", "missing"} {
+		f := results.Failed[i]
+		if f.ID != id || f.Index != i+1 || f.Error != product.ErrNotFound.Error() {
+			t.Errorf("failure %d = %+v, want %s at index %d reported as not found", i, f, id, i+1)
+		}
+	}
+	if repo.products["p4"].Price != 60 {
+		t.Errorf("another seller's product price changed to %v", repo.products["p4"].Price)
+	}
+}
+
+func TestBulkUpdatePrices_ByCategory(t *testing.T) {
+	uc, repo := newBulkPriceFixture()
+
+	results, err := uc.BulkUpdatePrices("seller-a", nil, "food", product.PriceOpIncreasePct, 50)
+	if err != nil {
+		t.Fatalf("BulkUpdatePrices() error = %v", err)
+	}
+	if len(results.Succeeded) != 2 {
+		t.Fatalf("got %d updates, want only seller-a's food products", len(results.Succeeded))
+	}
+	if repo.products["p3"].Price != 40 || repo.products["p4"].Price != 60 {
+		t.Error("products outside the seller's category were changed")
+	}
+}
+
+func TestBulkUpdatePrices_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		productIDs []string
+		categoryID string
+		op         product.PriceOperation
+		value      float64
+		wantErr    error
+	}{
+		{"No selector", nil, "", product.PriceOpSet, 10, product.ErrInvalidPriceSelector},
+		{"Both selectors", []string{"p1"}, "food", product.PriceOpSet, 10, product.ErrInvalidPriceSelector},
+		{"Unknown operation", []string{"p1"}, "", "double", 10, product.ErrInvalidPriceOperation},
+		{"Non-positive fixed price", []string{"p1"}, "", product.PriceOpSet, 0, product.ErrInvalidPrice},
+		{"Fixed price above max", []string{"p1"}, "", product.PriceOpSet, product.MaxPrice + 1, product.ErrInvalidPrice},
+		{"Decrease of 100 percent", []string{"p1"}, "", product.PriceOpDecreasePct, 100, product.ErrInvalidPriceAdjustment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, repo := newBulkPriceFixture()
+
+			_, err := uc.BulkUpdatePrices("seller-a", tt.productIDs, tt.categoryID, tt.op, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("BulkUpdatePrices() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.priceChanges) != 0 {
+				t.Error("prices were changed despite a validation error")
+			}
+		})
+	}
+}
+
+func TestBulkUpdatePrices_SkipsPriceAboveMax(t *testing.T) {
+	uc, repo := newBulkPriceFixture()
+	repo.products["p1"].Price = product.MaxPrice
+
+	results, err := uc.BulkUpdatePrices("seller-a", []string{"p1", "p2"}, "", product.PriceOpIncreasePct, 10)
+	if err != nil {
+		t.Fatalf("BulkUpdatePrices() error = %v", err)
+	}
+	if len(results.Failed) != 1 || results.Failed[0].ID != "p1" || results.Failed[0].Error != product.ErrInvalidPrice.Error() {
+		t.Errorf("p1 exceeding max price should fail, got %+v", results.Failed)
+	}
+	if len(results.Succeeded) != 1 || results.Succeeded[0].Index != 1 {
+		t.Errorf("p2 should still be updated, got %+v", results.Succeeded)
+	}
+}
+
+func TestAvailableQuantity_ExcludesActiveReservations(t *testing.T) {
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", Quantity: 10, IsActive: true},
+		&product.Product{ID: "p2", SellerID: "seller-a", Quantity: 3, IsActive: true},
+		&product.Product{ID: "p3", SellerID: "seller-a", Quantity: 5, IsActive: true},
+	)
+	future := time.Now().Add(10 * time.Minute)
+	reservationRepo := newMockReservationRepo(
+		&product.Reservation{ProductID: "p1", CartID: "c1", Quantity: 3, ExpiresAt: future},
+		&product.Reservation{ProductID: "p1", CartID: "c2", Quantity: 2, ExpiresAt: future},
+		&product.Reservation{ProductID: "p1", CartID: "c3", Quantity: 4, ExpiresAt: time.Now().Add(-time.Minute)},
+		&product.Reservation{ProductID: "p2", CartID: "c1", Quantity: 5, ExpiresAt: future},
+	)
+	uc := NewProductUseCase(productRepo, reservationRepo, newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	want := map[string]struct{ quantity, available int }{
+		"p1": {10, 5}, // expired reservation is ignored
+		"p2": {3, 0},  // over-reserved stock never goes negative
+		"p3": {5, 5},
+	}
+
+	products, _, err := uc.ListProductsWithCategory(nil, 1, 20, "", "")
+	if err != nil {
+		t.Fatalf("ListProductsWithCategory() error = %v", err)
+	}
+	for _, p := range products {
+		if p.Quantity != want[p.ID].quantity || p.AvailableQuantity != want[p.ID].available {
+			t.Errorf("%s: quantity=%d available=%d, want quantity=%d available=%d",
+				p.ID, p.Quantity, p.AvailableQuantity, want[p.ID].quantity, want[p.ID].available)
+		}
+	}
+
+	p, err := uc.GetProductByIDWithCategory("p1")
+	if err != nil {
+		t.Fatalf("GetProductByIDWithCategory() error = %v", err)
+	}
+	if p.AvailableQuantity != 5 {
+		t.Errorf("detail available_quantity = %d, want 5", p.AvailableQuantity)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Blue Mountain Coffee", "blue-mountain-coffee"},
+		{"  Jerk Seasoning -- Extra Hot!! ", "jerk-seasoning-extra-hot"},
+		{"Café Crème Brûlée", "cafe-creme-brulee"},
+		{"100% Pure Coconut Oil (16oz)", "100-pure-coconut-oil-16oz"},
+		{"!!!", "product"},
+		{strings.Repeat("a", 120), strings.Repeat("a", 80)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := product.Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateProduct_SlugCollisions(t *testing.T) {
+	productRepo := newMockProductRepo()
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	want := []string{"rasta-t-shirt", "rasta-t-shirt-2", "rasta-t-shirt-3"}
+	for _, slug := range want {
+		p, err := uc.CreateProduct("seller-a", "Rasta T-Shirt", "", 25, 1, nil, "")
+		if err != nil {
+			t.Fatalf("CreateProduct() error = %v", err)
+		}
+		if p.Slug != slug {
+			t.Errorf("slug = %q, want %q", p.Slug, slug)
+		}
+	}
+}
+
+func TestCreateProduct_RetriesSlugClaimedConcurrently(t *testing.T) {
+	productRepo := newMockProductRepo(&product.Product{ID: "p1", Title: "Blue Mug", Slug: "blue-mug"})
+	productRepo.staleSlugChecks = 1
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	p, err := uc.CreateProduct("seller-a", "Blue Mug", "", 12, 1, nil, "")
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if p.Slug != "blue-mug-2" {
+		t.Errorf("slug = %q, want %q", p.Slug, "blue-mug-2")
+	}
+}
+
+func TestGetProductByIDOrSlug(t *testing.T) {
+	productRepo := newMockProductRepo()
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	created, err := uc.CreateProduct("seller-a", "Bamboo Wind Chimes", "", 40, 2, nil, "")
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+
+	for _, key := range []string{created.ID, created.Slug} {
+		p, err := uc.GetProductByIDOrSlugWithCategory(key)
+		if err != nil {
+			t.Fatalf("GetProductByIDOrSlugWithCategory(%q) error = %v", key, err)
+		}
+		if p.ID != created.ID {
+			t.Errorf("lookup by %q returned product %s, want %s", key, p.ID, created.ID)
+		}
+	}
+
+	if _, err := uc.GetProductByIDOrSlugWithCategory("no-such-product"); !errors.Is(err, product.ErrNotFound) {
+		t.Errorf("unknown slug error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateProduct_SlugStability(t *testing.T) {
+	tests := []struct {
+		name       string
+		regenerate bool
+		wantSlug   string
+	}{
+		{"Stable by default", false, "reggae-cd"},
+		{"Regenerated when configured", true, "reggae-vinyl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepo := newMockProductRepo()
+			uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{RegenerateSlugOnTitleChange: tt.regenerate})
+
+			p, err := uc.CreateProduct("seller-a", "Reggae CD", "", 15, 1, nil, "")
+			if err != nil {
+				t.Fatalf("CreateProduct() error = %v", err)
+			}
+
+			p.Title = "Reggae Vinyl"
+			p.Slug = "client-supplied"
+			if err := uc.UpdateProduct("seller-a", user.RoleSeller, p); err != nil {
+				t.Fatalf("UpdateProduct() error = %v", err)
+			}
+			if p.Slug != tt.wantSlug {
+				t.Errorf("slug after rename = %q, want %q", p.Slug, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestCreateProduct_CollapsesDuplicateImages(t *testing.T) {
+	productRepo := newMockProductRepo()
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	images := []string{
+		"https://cdn.example.com/a.jpg",
+		"https://cdn.example.com/b.jpg",
+		"https://cdn.example.com/a.jpg",
+		" https://cdn.example.com/b.jpg ",
+		"",
+		"https://cdn.example.com/c.jpg",
+	}
+	p, err := uc.CreateProduct("seller-a", "Beach Ball", "", 5, 10, images, "")
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+
+	want := []string{
+		"https://cdn.example.com/a.jpg",
+		"https://cdn.example.com/b.jpg",
+		"https://cdn.example.com/c.jpg",
+	}
+	if strings.Join(p.Images, ",") != strings.Join(want, ",") {
+		t.Errorf("images = %v, want %v", p.Images, want)
+	}
+
+	p.Images = append(p.Images, "https://cdn.example.com/c.jpg")
+	if err := uc.UpdateProduct("seller-a", user.RoleSeller, p); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if len(p.Images) != 3 {
+		t.Errorf("images after update = %v, want duplicates collapsed", p.Images)
+	}
+}
+
+func TestCreateProduct_RejectsOnlyBlankImages(t *testing.T) {
+	productRepo := newMockProductRepo()
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	if _, err := uc.CreateProduct("seller-a", "Beach Ball", "", 5, 10, []string{"", "  "}, ""); !errors.Is(err, product.ErrNoValidImages) {
+		t.Errorf("CreateProduct() error = %v, want ErrNoValidImages", err)
+	}
+	if _, err := uc.CreateProduct("seller-a", "Beach Ball", "", 5, 10, nil, ""); err != nil {
+		t.Errorf("CreateProduct() without images error = %v", err)
+	}
+}
+
+func TestDeactivateProduct_SoftDeletes(t *testing.T) {
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", Title: "Blue Mountain Coffee", IsActive: true},
+		&product.Product{ID: "p2", SellerID: "seller-a", Title: "Jerk Seasoning", IsActive: true},
+	)
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	if err := uc.DeactivateProduct("seller-a", user.RoleSeller, "p1"); err != nil {
+		t.Fatalf("DeactivateProduct() error = %v", err)
+	}
+
+	p, err := uc.GetProductByID("p1")
+	if err != nil {
+		t.Fatalf("GetProductByID() after soft delete error = %v", err)
+	}
+	if p.IsActive || p.DeletedAt == nil {
+		t.Errorf("soft-deleted product IsActive = %v, DeletedAt = %v; want inactive with deleted_at", p.IsActive, p.DeletedAt)
+	}
+
+	products, total, err := uc.ListProducts(nil, 1, 20)
+	if err != nil {
+		t.Fatalf("ListProducts() error = %v", err)
+	}
+	if total != 1 || len(products) != 1 || products[0].ID != "p2" {
+		t.Errorf("ListProducts() = %d products (total %d), want only p2", len(products), total)
+	}
+
+	if err := uc.DeactivateProduct("seller-a", user.RoleSeller, "missing"); !errors.Is(err, product.ErrNotFound) {
+		t.Errorf("DeactivateProduct(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestProductImageKey(t *testing.T) {
+	const bucketURL = "https://storage.example.com/object/products-bucket/"
+	keyFromURL := func(raw string) (string, bool) { return strings.CutPrefix(raw, bucketURL) }
+
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", IsActive: true, Images: []string{
+			bucketURL + "products/1111.jpg",
+			bucketURL + "products/2222.png",
+			bucketURL + "products/4444.jpg",
+			bucketURL + "documents/seller-b/passport.pdf",
+			"documents/seller-b/licence.pdf",
+		}},
+		&product.Product{ID: "p2", SellerID: "seller-a", IsActive: false, Images: []string{
+			bucketURL + "products/3333.jpg",
+		}},
+	)
+	productRepo.RecordImageUploads("seller-a", []string{
+		bucketURL + "products/1111.jpg",
+		bucketURL + "products/2222.png",
+		bucketURL + "products/3333.jpg",
+	})
+	productRepo.RecordImageUploads("seller-b", []string{
+		bucketURL + "products/4444.jpg",
+		bucketURL + "documents/seller-b/passport.pdf",
+	})
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	tests := []struct {
+		name      string
+		productID string
+		viewerID  string
+		key       string
+		wantKey   string
+		wantErr   error
+	}{
+		{"File name on product", "p1", "", "2222.png", "products/2222.png", nil},
+		{"Full key on product", "p1", "", "products/1111.jpg", "products/1111.jpg", nil},
+		{"Key not on product rejected", "p1", "", "3333.jpg", "", product.ErrImageNotFound},
+		{"Key from another bucket path rejected", "p1", "", "other/1111.png", "", product.ErrImageNotFound},
+		{"Image another seller uploaded rejected", "p1", "", "4444.jpg", "", product.ErrImageNotFound},
+		{"Document URL listed as an image rejected", "p1", "", "passport.pdf", "", product.ErrImageNotFound},
+		{"Bare document key listed as an image rejected", "p1", "", "documents/seller-b/licence.pdf", "", product.ErrImageNotFound},
+		{"Empty key rejected", "p1", "", "", "", product.ErrImageNotFound},
+		{"Inactive product hidden from buyers", "p2", "buyer", "3333.jpg", "", product.ErrNotFound},
+		{"Inactive product visible to its seller", "p2", "seller-a", "3333.jpg", "products/3333.jpg", nil},
+		{"Missing product", "missing", "", "1111.jpg", "", product.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := uc.ProductImageKey(tt.productID, tt.viewerID, tt.key, keyFromURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProductImageKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if key != tt.wantKey {
+				t.Errorf("ProductImageKey() = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestListProductsWithCategoryCursor_StableAcrossInserts(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	productRepo := newMockProductRepo()
+	for i, id := range []string{"p1", "p2", "p3", "p4", "p5"} {
+		productRepo.Create(&product.Product{ID: id, IsActive: true, CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	var seen []string
+	page, next, err := uc.ListProductsWithCategoryCursor(nil, "", 2, "created_at", "desc")
+	if err != nil {
+		t.Fatalf("ListProductsWithCategoryCursor() error = %v", err)
+	}
+	for _, p := range page {
+		seen = append(seen, p.ID)
+	}
+
+	// A product listed mid-scroll would shift every OFFSET page by one
+	productRepo.Create(&product.Product{ID: "p6", IsActive: true, CreatedAt: base.Add(10 * time.Hour)})
+
+	for next != "" {
+		page, next, err = uc.ListProductsWithCategoryCursor(nil, next, 2, "created_at", "desc")
+		if err != nil {
+			t.Fatalf("ListProductsWithCategoryCursor() error = %v", err)
+		}
+		for _, p := range page {
+			seen = append(seen, p.ID)
+		}
+	}
+
+	want := []string{"p5", "p4", "p3", "p2", "p1"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("paged through %v, want %v", seen, want)
+	}
+}
+
+func TestListProductsWithCategoryCursor_InvalidCursor(t *testing.T) {
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", IsActive: true, CreatedAt: time.Now()},
+		&product.Product{ID: "p2", IsActive: true, CreatedAt: time.Now()},
+	)
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	_, next, err := uc.ListProductsWithCategoryCursor(nil, "", 1, "created_at", "desc")
+	if err != nil || next == "" {
+		t.Fatalf("ListProductsWithCategoryCursor() next = %q, err = %v", next, err)
+	}
+
+	tests := []struct {
+		name      string
+		cursor    string
+		sortBy    string
+		sortOrder string
+	}{
+		{"Garbage", "not-a-cursor!", "created_at", "desc"},
+		{"Different sort field", next, "price", "desc"},
+		{"Different direction", next, "created_at", "asc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := uc.ListProductsWithCategoryCursor(nil, tt.cursor, 1, tt.sortBy, tt.sortOrder)
+			if !errors.Is(err, product.ErrInvalidCursor) {
+				t.Errorf("error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestProductOwnership(t *testing.T) {
+	newFixture := func() (*ProductUseCase, *mockProductRepo) {
+		productRepo := newMockProductRepo(
+			&product.Product{ID: "p1", SellerID: "seller-a", Title: "Blue Mountain Coffee", Slug: "blue-mountain-coffee", Price: 20, IsActive: true},
+		)
+		return NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{}), productRepo
+	}
+
+	tests := []struct {
+		name    string
+		actorID string
+		role    user.Role
+		wantErr error
+	}{
+		{"Owner", "seller-a", user.RoleSeller, nil},
+		{"Other seller", "seller-b", user.RoleSeller, product.ErrNotOwner},
+		{"Customer", "buyer-1", user.RoleCustomer, product.ErrNotOwner},
+		{"Admin bypasses ownership", "admin-1", user.RoleAdmin, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" updating", func(t *testing.T) {
+			uc, productRepo := newFixture()
+			p, _ := uc.GetProductByID("p1")
+			p.Price = 25
+			p.SellerID = tt.actorID
+
+			err := uc.UpdateProduct(tt.actorID, tt.role, p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProduct() error = %v, want %v", err, tt.wantErr)
+			}
+			stored, _ := productRepo.GetByID("p1")
+			if stored.SellerID != "seller-a" {
+				t.Errorf("seller reassigned to %q", stored.SellerID)
+			}
+			if wantPrice := map[bool]float64{true: 25, false: 20}[tt.wantErr == nil]; stored.Price != wantPrice {
+				t.Errorf("price = %v, want %v", stored.Price, wantPrice)
+			}
+		})
+
+		t.Run(tt.name+" deleting", func(t *testing.T) {
+			uc, productRepo := newFixture()
+			err := uc.DeactivateProduct(tt.actorID, tt.role, "p1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeactivateProduct() error = %v, want %v", err, tt.wantErr)
+			}
+			stored, _ := productRepo.GetByID("p1")
+			if deleted := stored.DeletedAt != nil; deleted != (tt.wantErr == nil) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestListSellerProducts_IncludesInactive(t *testing.T) {
+	deletedAt := time.Now()
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", IsActive: true},
+		&product.Product{ID: "p2", SellerID: "seller-a", IsActive: false},
+		&product.Product{ID: "p3", SellerID: "seller-a", IsActive: false, DeletedAt: &deletedAt},
+		&product.Product{ID: "p4", SellerID: "seller-b", IsActive: true},
+	)
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+
+	products, total, err := uc.ListSellerProducts("seller-a", 1, 20)
+	if err != nil {
+		t.Fatalf("ListSellerProducts() error = %v", err)
+	}
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	if total != 2 || strings.Join(ids, ",") != "p1,p2" {
+		t.Errorf("ListSellerProducts() = %v (total %d), want p1,p2", ids, total)
+	}
+}
+
+func TestUpdateProductQuantity_LogsAdjustment(t *testing.T) {
+	productRepo := newMockProductRepo(
+		&product.Product{ID: "p1", SellerID: "seller-a", Quantity: 10, IsActive: true},
+	)
+	adjustmentRepo := newMockAdjustmentRepo(productRepo)
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), adjustmentRepo, ProductConfig{})
+
+	if _, err := uc.UpdateProductQuantity("seller-a", user.RoleSeller, "p1", 4, "damaged in transit"); err != nil {
+		t.Fatalf("UpdateProductQuantity() error = %v", err)
+	}
+	if _, err := uc.UpdateProductQuantity("admin-1", user.RoleAdmin, "p1", 15, ""); err != nil {
+		t.Fatalf("UpdateProductQuantity() as admin error = %v", err)
+	}
+	// Setting the same quantity is not a stock change
+	if _, err := uc.UpdateProductQuantity("seller-a", user.RoleSeller, "p1", 15, "recount"); err != nil {
+		t.Fatalf("UpdateProductQuantity() unchanged error = %v", err)
+	}
+
+	stored, _ := productRepo.GetByID("p1")
+	if stored.Quantity != 15 {
+		t.Errorf("quantity = %d, want 15", stored.Quantity)
+	}
+
+	adjustments, total, err := uc.ListInventoryAdjustments("seller-a", user.RoleSeller, "p1", 1, 20)
+	if err != nil {
+		t.Fatalf("ListInventoryAdjustments() error = %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("total = %d, want 2", total)
+	}
+	want := []struct {
+		userID string
+		delta  int
+		reason string
+	}{
+		{"admin-1", 11, ""},
+		{"seller-a", -6, "damaged in transit"},
+	}
+	for i, w := range want {
+		a := adjustments[i]
+		if a.ProductID != "p1" || a.UserID == nil || *a.UserID != w.userID || a.Delta != w.delta || a.Reason != w.reason {
+			t.Errorf("adjustment %d = {%s %v %d %q}, want {p1 %s %d %q}",
+				i, a.ProductID, a.UserID, a.Delta, a.Reason, w.userID, w.delta, w.reason)
+		}
+	}
+}
+
+func TestUpdateProductQuantity_Rejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		quantity int
+		reason   string
+		wantErr  error
+	}{
+		{"other seller", "seller-b", 5, "", product.ErrNotOwner},
+		{"negative quantity", "seller-a", -1, "", product.ErrInvalidQuantity},
+		{"long reason", "seller-a", 5, strings.Repeat("x", product.MaxAdjustmentReasonLength+1), product.ErrReasonTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepo := newMockProductRepo(&product.Product{ID: "p1", SellerID: "seller-a", Quantity: 10})
+			adjustmentRepo := newMockAdjustmentRepo(productRepo)
+			uc := NewProductUseCase(productRepo, newMockReservationRepo(), adjustmentRepo, ProductConfig{})
+
+			_, err := uc.UpdateProductQuantity(tt.actorID, user.RoleSeller, "p1", tt.quantity, tt.reason)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProductQuantity() error = %v, want %v", err, tt.wantErr)
+			}
+			if stored, _ := productRepo.GetByID("p1"); stored.Quantity != 10 {
+				t.Errorf("quantity = %d, want unchanged 10", stored.Quantity)
+			}
+			if len(adjustmentRepo.adjustments) != 0 {
+				t.Errorf("logged %d adjustments, want none", len(adjustmentRepo.adjustments))
+			}
+		})
+	}
+
+	productRepo := newMockProductRepo(&product.Product{ID: "p1", SellerID: "seller-a"})
+
+	uc := NewProductUseCase(productRepo, newMockReservationRepo(), newMockAdjustmentRepo(productRepo), ProductConfig{})
+	if _, _, err := uc.ListInventoryAdjustments("seller-b", user.RoleSeller, "p1", 1, 20); !errors.Is(err, product.ErrNotOwner) {
+		t.Errorf("ListInventoryAdjustments() by other seller error = %v, want ErrNotOwner", err)
+	}
+}
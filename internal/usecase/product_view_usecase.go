@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+	"github.com/rs/zerolog/log"
+)
+
+// recordViewTimeout bounds how long a background view increment may take
+const recordViewTimeout = 2 * time.Second
+
+// finalFlushTimeout bounds the flush made when the flusher stops
+const finalFlushTimeout = 5 * time.Second
+
+// ProductViewUseCase counts product detail views and reports them to sellers
+type ProductViewUseCase struct {
+	counter  product.ViewCounter
+	viewRepo product.ViewRepository
+}
+
+// NewProductViewUseCase creates a new product view use case
+func NewProductViewUseCase(counter product.ViewCounter, viewRepo product.ViewRepository) *ProductViewUseCase {
+	return &ProductViewUseCase{counter: counter, viewRepo: viewRepo}
+}
+
+// RecordView counts a view of the product by viewer in the background, so
+// serving the product never waits on Redis
+func (uc *ProductViewUseCase) RecordView(productID, viewer string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recordViewTimeout)
+		defer cancel()
+		if _, err := uc.counter.RecordView(ctx, productID, viewer); err != nil {
+			log.Debug().Err(err).Str("product_id", productID).Msg("failed to record product view")
+		}
+	}()
+}
+
+// FlushViews moves the buffered view counts into the repository, returning
+// how many views were stored. Counts are put back if they cannot be stored.
+func (uc *ProductViewUseCase) FlushViews(ctx context.Context) (int64, error) {
+	counts, err := uc.counter.DrainViews(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	if err := uc.viewRepo.AddViews(counts); err != nil {
+		if restoreErr := uc.counter.RestoreViews(ctx, counts); restoreErr != nil {
+			log.Error().Err(restoreErr).Int("products", len(counts)).Msg("failed to restore unflushed product views")
+		}
+		return 0, err
+	}
+
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total, nil
+}
+
+// RunViewFlusher periodically flushes buffered views until the context is
+// cancelled, then flushes once more so views counted since the last tick are
+// stored before shutdown
+func (uc *ProductViewUseCase) RunViewFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			defer cancel()
+			uc.flush(finalCtx)
+			return
+		case <-ticker.C:
+			uc.flush(ctx)
+		}
+	}
+}
+
+// flush runs FlushViews for the flusher, logging the outcome
+func (uc *ProductViewUseCase) flush(ctx context.Context) {
+	flushed, err := uc.FlushViews(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to flush product views")
+		return
+	}
+	if flushed > 0 {
+		log.Debug().Int64("views", flushed).Msg("flushed product views")
+	}
+}
+
+// GetSellerStats returns the view counts of the seller's products. Views
+// still buffered since the last flush are not included yet.
+func (uc *ProductViewUseCase) GetSellerStats(sellerID string) (*product.SellerStats, error) {
+	views, err := uc.viewRepo.GetSellerViews(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &product.SellerStats{Products: views}
+	for _, v := range views {
+		stats.TotalViews += v.Views
+	}
+	return stats, nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/product"
+)
+
+func TestFlushViews_StoresAndReportsSellerStats(t *testing.T) {
+	counter := newMockViewCounter()
+	repo := &mockViewRepo{
+		views: map[string]int64{"p1": 10},
+		products: []*product.Product{
+			{ID: "p1", SellerID: "seller-1", Title: "Coffee"},
+			{ID: "p2", SellerID: "seller-1", Title: "Rum cake"},
+			{ID: "p3", SellerID: "seller-2", Title: "Hammock"},
+		},
+	}
+	uc := NewProductViewUseCase(counter, repo)
+	ctx := context.Background()
+
+	counter.RecordView(ctx, "p1", "a")
+	counter.RecordView(ctx, "p2", "a")
+	counter.RecordView(ctx, "p2", "b")
+
+	flushed, err := uc.FlushViews(ctx)
+	if err != nil {
+		t.Fatalf("FlushViews() error = %v", err)
+	}
+	if flushed != 3 {
+		t.Errorf("FlushViews() = %d, want 3", flushed)
+	}
+
+	stats, err := uc.GetSellerStats("seller-1")
+	if err != nil {
+		t.Fatalf("GetSellerStats() error = %v", err)
+	}
+	if stats.TotalViews != 13 || len(stats.Products) != 2 {
+		t.Errorf("GetSellerStats() = %+v, want 13 views over 2 products", stats)
+	}
+}
+
+func TestFlushViews_RestoresCountsOnFailure(t *testing.T) {
+	counter := newMockViewCounter()
+	repo := &mockViewRepo{views: map[string]int64{}, err: errors.New("database unavailable")}
+	uc := NewProductViewUseCase(counter, repo)
+	ctx := context.Background()
+
+	counter.RecordView(ctx, "p1", "a")
+	if _, err := uc.FlushViews(ctx); err == nil {
+		t.Fatal("FlushViews() error = nil, want error")
+	}
+
+	// The next flush picks the views up once the database is back
+	repo.err = nil
+	if flushed, err := uc.FlushViews(ctx); err != nil || flushed != 1 {
+		t.Fatalf("FlushViews() = %d, %v; want 1 view", flushed, err)
+	}
+	if repo.views["p1"] != 1 {
+		t.Errorf("stored views = %d, want 1", repo.views["p1"])
+	}
+}
+
+func TestRunViewFlusher_FlushesOnShutdown(t *testing.T) {
+	counter := newMockViewCounter()
+	repo := &mockViewRepo{views: map[string]int64{}}
+	uc := NewProductViewUseCase(counter, repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		uc.RunViewFlusher(ctx, time.Hour)
+	}()
+
+	counter.RecordView(ctx, "p1", "a")
+	cancel()
+	<-done
+
+	if repo.views["p1"] != 1 {
+		t.Errorf("stored views after shutdown = %d, want 1", repo.views["p1"])
+	}
+}
@@ -1,9 +1,11 @@
 package usecase
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
 	"github.com/google/uuid"
 )
 
@@ -19,6 +21,15 @@ func NewUserUseCase(userRepo user.Repository) *UserUseCase {
 
 // CreateUser creates a new user
 func (uc *UserUseCase) CreateUser(username, walletAddress string, role user.Role) (*user.User, error) {
+	if !user.ValidRole(role) {
+		return nil, fmt.Errorf("%w: %q", user.ErrInvalidRole, role)
+	}
+
+	walletAddress, err := blockchain.NormalizeAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	u := &user.User{
 		ID:            uuid.New().String(),
 		Username:      username,
@@ -28,7 +39,7 @@ func (uc *UserUseCase) CreateUser(username, walletAddress string, role user.Role
 		UpdatedAt:     time.Now(),
 	}
 
-	err := uc.userRepo.Create(u)
+	err = uc.userRepo.Create(u)
 	if err != nil {
 		return nil, err
 	}
@@ -43,13 +54,28 @@ func (uc *UserUseCase) GetUserByID(id string) (*user.User, error) {
 
 // GetUserByWalletAddress retrieves a user by wallet address
 func (uc *UserUseCase) GetUserByWalletAddress(address string) (*user.User, error) {
+	address, err := blockchain.NormalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
 	return uc.userRepo.GetByWalletAddress(address)
 }
 
-// UpdateUser updates user information
-func (uc *UserUseCase) UpdateUser(u *user.User) error {
+// UpdateProfile changes a user's username. The role is left as it is; only
+// ChangeRole on AuthUseCase changes it. The wallet address is never changed,
+// since deposits are credited to the user whose address sent them and only
+// signing in proves the user controls it.
+func (uc *UserUseCase) UpdateProfile(id, username string) (*user.User, error) {
+	u, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	u.Username = username
 	u.UpdatedAt = time.Now()
-	return uc.userRepo.Update(u)
+	if err := uc.userRepo.Update(u); err != nil {
+		return nil, err
+	}
+	return u, nil
 }
 
 // DeleteUser deletes a user
@@ -1,7 +1,7 @@
 package usecase
 
 import (
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
@@ -10,12 +10,14 @@ import (
 
 // WalletUseCase handles wallet business logic
 type WalletUseCase struct {
-	walletRepo wallet.Repository
+	walletRepo  wallet.Repository
+	priceOracle wallet.PriceOracle
 }
 
-// NewWalletUseCase creates a new wallet use case
-func NewWalletUseCase(walletRepo wallet.Repository) *WalletUseCase {
-	return &WalletUseCase{walletRepo: walletRepo}
+// NewWalletUseCase creates a new wallet use case. priceOracle may be nil, in
+// which case balance conversion is unavailable.
+func NewWalletUseCase(walletRepo wallet.Repository, priceOracle wallet.PriceOracle) *WalletUseCase {
+	return &WalletUseCase{walletRepo: walletRepo, priceOracle: priceOracle}
 }
 
 // GetWalletByUserID retrieves a wallet by user ID
@@ -23,19 +25,40 @@ func (uc *WalletUseCase) GetWalletByUserID(userID string) (*wallet.Wallet, error
 	return uc.walletRepo.GetByUserID(userID)
 }
 
-// SendFunds sends funds from a wallet
-func (uc *WalletUseCase) SendFunds(walletID string, amount float64, reference string) (*wallet.Transaction, error) {
-	// Get wallet to check balance
-	w, err := uc.walletRepo.GetByUserID(walletID)
+// CreateWallet opens an empty wallet for the user in the given currency. A
+// user holds one wallet, so a second returns wallet.ErrWalletExists.
+func (uc *WalletUseCase) CreateWallet(userID string, currency wallet.Currency) (*wallet.Wallet, error) {
+	currency, err := wallet.ParseCurrency(string(currency))
 	if err != nil {
 		return nil, err
 	}
 
-	if w.Balance < amount {
-		return nil, errors.New("insufficient balance")
+	w := &wallet.Wallet{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Currency:  currency,
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.walletRepo.Create(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SendFunds sends funds from the user's wallet. The balance check and debit
+// happen atomically in the repository, so concurrent sends cannot overdraw it.
+// A reference the wallet has already used returns a
+// *wallet.DuplicateTransactionError without debiting again.
+func (uc *WalletUseCase) SendFunds(userID string, amount float64, reference string) (*wallet.Transaction, error) {
+	if amount <= 0 {
+		return nil, wallet.ErrInvalidAmount
+	}
+
+	w, err := uc.walletRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create debit transaction
 	tx := &wallet.Transaction{
 		ID:        uuid.New().String(),
 		WalletID:  w.ID,
@@ -46,32 +69,27 @@ func (uc *WalletUseCase) SendFunds(walletID string, amount float64, reference st
 		CreatedAt: time.Now(),
 	}
 
-	// Create transaction record
-	err = uc.walletRepo.CreateTransaction(tx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Update wallet balance
-	err = uc.walletRepo.UpdateBalance(w.ID, -amount)
-	if err != nil {
+	if err := uc.walletRepo.Debit(tx); err != nil {
 		return nil, err
 	}
 
-	// Update transaction status
-	tx.Status = wallet.TransactionStatusSuccess
-
 	return tx, nil
 }
 
-// ReceiveFunds receives funds to a wallet
-func (uc *WalletUseCase) ReceiveFunds(walletID string, amount float64, reference string) (*wallet.Transaction, error) {
-	w, err := uc.walletRepo.GetByUserID(walletID)
+// ReceiveFunds receives funds to the user's wallet. The transaction is
+// recorded before the balance moves, so a retry with an already used
+// reference returns a *wallet.DuplicateTransactionError without crediting
+// twice.
+func (uc *WalletUseCase) ReceiveFunds(userID string, amount float64, reference string) (*wallet.Transaction, error) {
+	if amount <= 0 {
+		return nil, wallet.ErrInvalidAmount
+	}
+
+	w, err := uc.walletRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create credit transaction
 	tx := &wallet.Transaction{
 		ID:        uuid.New().String(),
 		WalletID:  w.ID,
@@ -82,25 +100,152 @@ func (uc *WalletUseCase) ReceiveFunds(walletID string, amount float64, reference
 		CreatedAt: time.Now(),
 	}
 
-	// Create transaction record
-	err = uc.walletRepo.CreateTransaction(tx)
+	// The record and the balance commit together, so a failed credit leaves
+	// nothing behind and can be retried with the same reference
+	if err := uc.walletRepo.Credit(tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// Transfer moves funds from one user's wallet to another's. The debit and
+// credit are recorded under a shared transfer ID and commit together, so a
+// failed transfer moves nothing. The reference is checked against the
+// sender's wallet; a reused one returns a *wallet.DuplicateTransactionError
+// carrying the original debit.
+func (uc *WalletUseCase) Transfer(fromUserID, toUserID string, amount float64, reference string) (*wallet.Transaction, error) {
+	if amount <= 0 {
+		return nil, wallet.ErrInvalidAmount
+	}
+	if fromUserID == toUserID {
+		return nil, wallet.ErrSelfTransfer
+	}
+
+	from, err := uc.walletRepo.GetByUserID(fromUserID)
 	if err != nil {
 		return nil, err
 	}
+	to, err := uc.walletRepo.GetByUserID(toUserID)
+	if err != nil {
+		return nil, fmt.Errorf("recipient: %w", err)
+	}
+	if from.Currency != to.Currency {
+		return nil, wallet.ErrCurrencyMismatch
+	}
 
-	// Update wallet balance
-	err = uc.walletRepo.UpdateBalance(w.ID, amount)
+	transferID := uuid.New().String()
+	now := time.Now()
+	debit := &wallet.Transaction{
+		ID:         uuid.New().String(),
+		WalletID:   from.ID,
+		Type:       wallet.TransactionTypeDebit,
+		Amount:     amount,
+		Reference:  reference,
+		Status:     wallet.TransactionStatusPending,
+		CreatedAt:  now,
+		TransferID: transferID,
+	}
+	// The reference belongs to the sender, so the recipient's side carries
+	// none and cannot collide with references they have used themselves
+	credit := &wallet.Transaction{
+		ID:         uuid.New().String(),
+		WalletID:   to.ID,
+		Type:       wallet.TransactionTypeCredit,
+		Amount:     amount,
+		Status:     wallet.TransactionStatusPending,
+		CreatedAt:  now,
+		TransferID: transferID,
+	}
+
+	if err := uc.walletRepo.Transfer(debit, credit); err != nil {
+		return nil, err
+	}
+	return debit, nil
+}
+
+// ConvertBalance expresses the user's wallet balance in another currency for
+// display. Stored balances are left untouched.
+func (uc *WalletUseCase) ConvertBalance(userID string, to wallet.Currency) (*wallet.Conversion, error) {
+	w, err := uc.walletRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update transaction status
-	tx.Status = wallet.TransactionStatusSuccess
+	rate := 1.0
+	if w.Currency != to {
+		if uc.priceOracle == nil {
+			return nil, wallet.ErrRateUnavailable
+		}
+		rate, err = uc.priceOracle.Rate(w.Currency, to)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return tx, nil
+	return &wallet.Conversion{
+		From:      w.Currency,
+		To:        to,
+		Balance:   w.Balance,
+		Rate:      rate,
+		Converted: w.Balance * rate,
+	}, nil
 }
 
-// GetTransactions retrieves transaction history
-func (uc *WalletUseCase) GetTransactions(walletID string, page, pageSize int) ([]*wallet.Transaction, int, error) {
-	return uc.walletRepo.GetTransactions(walletID, page, pageSize)
+// GetTransactions retrieves the transaction history of the user's wallet,
+// narrowed by filter
+func (uc *WalletUseCase) GetTransactions(userID string, filter wallet.TransactionFilter, page, pageSize int) ([]*wallet.Transaction, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	w, err := uc.walletRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return uc.walletRepo.GetTransactions(w.ID, filter, page, pageSize)
+}
+
+// GetTransactionTimeseries returns the user's credit and debit totals per
+// bucket over [from, to). Periods without transactions are included with
+// zero totals so the series can be charted directly.
+func (uc *WalletUseCase) GetTransactionTimeseries(userID string, from, to time.Time, bucket wallet.Bucket) ([]*wallet.TransactionAggregate, error) {
+	if !to.After(from) {
+		return nil, wallet.ErrInvalidRange
+	}
+
+	start := bucket.Truncate(from)
+	var periods []time.Time
+	for p := start; p.Before(to); p = bucket.Next(p) {
+		if len(periods) == wallet.MaxSeriesPoints {
+			return nil, fmt.Errorf("%w: spans more than %d %ss", wallet.ErrInvalidRange, wallet.MaxSeriesPoints, bucket)
+		}
+		periods = append(periods, p)
+	}
+
+	w, err := uc.walletRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := uc.walletRepo.GetTransactionAggregates(w.ID, from, to, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	byPeriod := make(map[int64]*wallet.TransactionAggregate, len(aggregates))
+	for _, a := range aggregates {
+		byPeriod[a.PeriodStart.Unix()] = a
+	}
+
+	series := make([]*wallet.TransactionAggregate, 0, len(periods))
+	for _, p := range periods {
+		if a, ok := byPeriod[p.Unix()]; ok {
+			series = append(series, a)
+			continue
+		}
+		series = append(series, &wallet.TransactionAggregate{PeriodStart: p})
+	}
+
+	return series, nil
 }
@@ -0,0 +1,476 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+	"github.com/Tenoywil/CaribEx-backend/pkg/filter"
+)
+
+func TestGetTransactionTimeseries(t *testing.T) {
+	day1 := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1"})
+	seed := []*wallet.Transaction{
+		{WalletID: "wallet-1", Type: wallet.TransactionTypeCredit, Amount: 100, Status: wallet.TransactionStatusSuccess, CreatedAt: day1.Add(9 * time.Hour)},
+		{WalletID: "wallet-1", Type: wallet.TransactionTypeCredit, Amount: 50, Status: wallet.TransactionStatusSuccess, CreatedAt: day1.Add(23 * time.Hour)},
+		{WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Amount: 30, Status: wallet.TransactionStatusSuccess, CreatedAt: day1.Add(12 * time.Hour)},
+		{WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Amount: 20, Status: wallet.TransactionStatusPending, CreatedAt: day2.Add(time.Hour)},
+		// Failed transactions and other wallets are not counted
+		{WalletID: "wallet-1", Type: wallet.TransactionTypeDebit, Amount: 999, Status: wallet.TransactionStatusFailed, CreatedAt: day2.Add(2 * time.Hour)},
+		{WalletID: "wallet-2", Type: wallet.TransactionTypeCredit, Amount: 999, Status: wallet.TransactionStatusSuccess, CreatedAt: day2.Add(3 * time.Hour)},
+	}
+	for _, tx := range seed {
+		repo.CreateTransaction(tx)
+	}
+	uc := NewWalletUseCase(repo, nil)
+
+	series, err := uc.GetTransactionTimeseries("user-1", day1, day2.AddDate(0, 0, 2), wallet.BucketDay)
+	if err != nil {
+		t.Fatalf("GetTransactionTimeseries() error = %v", err)
+	}
+
+	want := []wallet.TransactionAggregate{
+		{PeriodStart: day1, Credit: 150, Debit: 30, Count: 3},
+		{PeriodStart: day2, Credit: 0, Debit: 20, Count: 1},
+		{PeriodStart: day2.AddDate(0, 0, 1)},
+	}
+	if len(series) != len(want) {
+		t.Fatalf("series has %d points, want %d", len(series), len(want))
+	}
+	for i, w := range want {
+		if got := *series[i]; !got.PeriodStart.Equal(w.PeriodStart) || got.Credit != w.Credit || got.Debit != w.Debit || got.Count != w.Count {
+			t.Errorf("series[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	weekly, err := uc.GetTransactionTimeseries("user-1", day1, day2.AddDate(0, 0, 1), wallet.BucketWeek)
+	if err != nil {
+		t.Fatalf("GetTransactionTimeseries() error = %v", err)
+	}
+	if len(weekly) != 1 || weekly[0].Credit != 150 || weekly[0].Debit != 50 {
+		t.Errorf("weekly series = %+v, want one week with credit 150 and debit 50", weekly)
+	}
+}
+
+func TestGetTransactionTimeseries_InvalidRange(t *testing.T) {
+	uc := NewWalletUseCase(newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1"}), nil)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"Empty range", now, now},
+		{"Reversed range", now, now.Add(-time.Hour)},
+		{"Too many points", now.AddDate(-2, 0, 0), now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.GetTransactionTimeseries("user-1", tt.from, tt.to, wallet.BucketDay)
+			if !errors.Is(err, wallet.ErrInvalidRange) {
+				t.Errorf("GetTransactionTimeseries() error = %v, want %v", err, wallet.ErrInvalidRange)
+			}
+		})
+	}
+}
+
+func TestParseBucket(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"day", false},
+		{"week", false},
+		{"month", false},
+		{"year", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if _, err := wallet.ParseBucket(tt.input); (err != nil) != tt.wantErr {
+			t.Errorf("ParseBucket(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConvertBalance(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 250, Currency: wallet.CurrencyUSDC})
+	oracle := &mockPriceOracle{rates: map[[2]wallet.Currency]float64{
+		{wallet.CurrencyUSDC, wallet.CurrencyJAM}: 156.5,
+	}}
+	uc := NewWalletUseCase(repo, oracle)
+
+	got, err := uc.ConvertBalance("user-1", wallet.CurrencyJAM)
+	if err != nil {
+		t.Fatalf("ConvertBalance() error = %v", err)
+	}
+	want := wallet.Conversion{From: wallet.CurrencyUSDC, To: wallet.CurrencyJAM, Balance: 250, Rate: 156.5, Converted: 39125}
+	if *got != want {
+		t.Errorf("ConvertBalance() = %+v, want %+v", *got, want)
+	}
+
+	w, _ := repo.GetByUserID("user-1")
+	if w.Balance != 250 || w.Currency != wallet.CurrencyUSDC {
+		t.Errorf("stored wallet changed to %v %s", w.Balance, w.Currency)
+	}
+
+	t.Run("Same currency skips the oracle", func(t *testing.T) {
+		oracle.calls = 0
+		got, err := uc.ConvertBalance("user-1", wallet.CurrencyUSDC)
+		if err != nil || got.Converted != 250 || got.Rate != 1 {
+			t.Errorf("ConvertBalance() = %+v, %v; want 250 at rate 1", got, err)
+		}
+		if oracle.calls != 0 {
+			t.Errorf("oracle called %d times, want 0", oracle.calls)
+		}
+	})
+
+	t.Run("Unpriced currency", func(t *testing.T) {
+		if _, err := uc.ConvertBalance("user-1", wallet.CurrencyUSD); !errors.Is(err, wallet.ErrUnsupportedCurrency) {
+			t.Errorf("ConvertBalance() error = %v, want ErrUnsupportedCurrency", err)
+		}
+	})
+
+	t.Run("No oracle configured", func(t *testing.T) {
+		uc := NewWalletUseCase(repo, nil)
+		if _, err := uc.ConvertBalance("user-1", wallet.CurrencyJAM); !errors.Is(err, wallet.ErrRateUnavailable) {
+			t.Errorf("ConvertBalance() error = %v, want ErrRateUnavailable", err)
+		}
+	})
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    wallet.Currency
+		wantErr bool
+	}{
+		{"USD", wallet.CurrencyUSD, false},
+		{"jam", wallet.CurrencyJAM, false},
+		{" usdc ", wallet.CurrencyUSDC, false},
+		{"EUR", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := wallet.ParseCurrency(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCurrency(%q) = %q, %v; want %q, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSendFunds_RejectsDebitBeyondBalance(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 100})
+	uc := NewWalletUseCase(repo, nil)
+
+	if _, err := uc.SendFunds("user-1", 60, ""); err != nil {
+		t.Fatalf("first SendFunds() error = %v", err)
+	}
+	if _, err := uc.SendFunds("user-1", 60, ""); !errors.Is(err, wallet.ErrInsufficientBalance) {
+		t.Fatalf("second SendFunds() error = %v, want ErrInsufficientBalance", err)
+	}
+
+	w, _ := repo.GetByUserID("user-1")
+	if w.Balance != 40 {
+		t.Errorf("balance = %v, want 40", w.Balance)
+	}
+	if len(repo.transactions) != 1 || repo.transactions[0].Status != wallet.TransactionStatusSuccess {
+		t.Errorf("transactions = %v, want one successful debit", repo.transactions)
+	}
+}
+
+func TestSendAndReceiveFunds_RejectNonPositiveAmounts(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 100})
+	uc := NewWalletUseCase(repo, nil)
+
+	for _, amount := range []float64{0, -50} {
+		if _, err := uc.SendFunds("user-1", amount, ""); !errors.Is(err, wallet.ErrInvalidAmount) {
+			t.Errorf("SendFunds(%v) error = %v, want ErrInvalidAmount", amount, err)
+		}
+		if _, err := uc.ReceiveFunds("user-1", amount, ""); !errors.Is(err, wallet.ErrInvalidAmount) {
+			t.Errorf("ReceiveFunds(%v) error = %v, want ErrInvalidAmount", amount, err)
+		}
+	}
+
+	w, _ := repo.GetByUserID("user-1")
+	if w.Balance != 100 || len(repo.transactions) != 0 {
+		t.Errorf("balance = %v with %d transactions, want 100 and none", w.Balance, len(repo.transactions))
+	}
+}
+
+func TestGetTransactions_ResolvesWalletFromUser(t *testing.T) {
+	repo := newMockWalletRepo(
+		&wallet.Wallet{ID: "wallet-1", UserID: "user-1"},
+		&wallet.Wallet{ID: "wallet-2", UserID: "user-2"},
+	)
+	repo.CreateTransaction(&wallet.Transaction{ID: "tx-1", WalletID: "wallet-1", Type: wallet.TransactionTypeCredit, Amount: 10})
+	repo.CreateTransaction(&wallet.Transaction{ID: "tx-2", WalletID: "wallet-2", Type: wallet.TransactionTypeCredit, Amount: 20})
+	uc := NewWalletUseCase(repo, nil)
+
+	txs, total, err := uc.GetTransactions("user-1", wallet.TransactionFilter{}, 1, 20)
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if total != 1 || len(txs) != 1 || txs[0].ID != "tx-1" {
+		t.Errorf("GetTransactions() = %v (total %d), want only tx-1", txs, total)
+	}
+
+	if _, _, err := uc.GetTransactions("user-3", wallet.TransactionFilter{}, 1, 20); !errors.Is(err, wallet.ErrNotFound) {
+		t.Errorf("GetTransactions() for user without wallet error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReceiveFunds_DuplicateReferenceCreditsOnce(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 10})
+	uc := NewWalletUseCase(repo, nil)
+
+	first, err := uc.ReceiveFunds("user-1", 25, "deposit-abc")
+	if err != nil {
+		t.Fatalf("ReceiveFunds() error = %v", err)
+	}
+
+	_, err = uc.ReceiveFunds("user-1", 25, "deposit-abc")
+	var dup *wallet.DuplicateTransactionError
+	if !errors.As(err, &dup) || !errors.Is(err, wallet.ErrDuplicateTransaction) {
+		t.Fatalf("second ReceiveFunds() error = %v, want DuplicateTransactionError", err)
+	}
+	if dup.Existing.ID != first.ID {
+		t.Errorf("existing transaction = %s, want %s", dup.Existing.ID, first.ID)
+	}
+
+	w, _ := repo.GetByUserID("user-1")
+	if w.Balance != 35 {
+		t.Errorf("balance = %v, want 35", w.Balance)
+	}
+	if len(repo.transactions) != 1 {
+		t.Errorf("recorded %d transactions, want 1", len(repo.transactions))
+	}
+
+	// Empty references are never treated as duplicates
+	for i := 0; i < 2; i++ {
+		if _, err := uc.ReceiveFunds("user-1", 5, ""); err != nil {
+			t.Fatalf("ReceiveFunds() without reference error = %v", err)
+		}
+	}
+	if w, _ := repo.GetByUserID("user-1"); w.Balance != 45 {
+		t.Errorf("balance = %v, want 45", w.Balance)
+	}
+}
+
+func TestReceiveFunds_FailedCreditCanBeRetried(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 10})
+	uc := NewWalletUseCase(repo, nil)
+
+	repo.creditErr = errors.New("connection reset")
+	if _, err := uc.ReceiveFunds("user-1", 25, "deposit-abc"); err == nil {
+		t.Fatal("ReceiveFunds() error = nil, want the credit failure")
+	}
+	if len(repo.transactions) != 0 {
+		t.Fatalf("failed credit left %d transactions behind", len(repo.transactions))
+	}
+
+	repo.creditErr = nil
+	tx, err := uc.ReceiveFunds("user-1", 25, "deposit-abc")
+	if err != nil {
+		t.Fatalf("retried ReceiveFunds() error = %v", err)
+	}
+	if tx.Status != wallet.TransactionStatusSuccess {
+		t.Errorf("status = %q, want %q", tx.Status, wallet.TransactionStatusSuccess)
+	}
+	if w, _ := repo.GetByUserID("user-1"); w.Balance != 35 {
+		t.Errorf("balance = %v, want 35", w.Balance)
+	}
+}
+
+func TestSendFunds_DuplicateReferenceDebitsOnce(t *testing.T) {
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 100})
+	uc := NewWalletUseCase(repo, nil)
+
+	if _, err := uc.SendFunds("user-1", 30, "payment-1"); err != nil {
+		t.Fatalf("SendFunds() error = %v", err)
+	}
+	if _, err := uc.SendFunds("user-1", 30, "payment-1"); !errors.Is(err, wallet.ErrDuplicateTransaction) {
+		t.Fatalf("second SendFunds() error = %v, want ErrDuplicateTransaction", err)
+	}
+
+	if w, _ := repo.GetByUserID("user-1"); w.Balance != 70 {
+		t.Errorf("balance = %v, want 70", w.Balance)
+	}
+}
+
+func TestCreateWallet(t *testing.T) {
+	repo := newMockWalletRepo()
+	uc := NewWalletUseCase(repo, nil)
+
+	w, err := uc.CreateWallet("user-1", "usd")
+	if err != nil {
+		t.Fatalf("CreateWallet() error = %v", err)
+	}
+	if w.UserID != "user-1" || w.Currency != wallet.CurrencyUSD || w.Balance != 0 {
+		t.Errorf("CreateWallet() = %+v, want an empty USD wallet for user-1", w)
+	}
+	if _, err := uc.GetWalletByUserID("user-1"); err != nil {
+		t.Errorf("GetWalletByUserID() after create error = %v", err)
+	}
+
+	if _, err := uc.CreateWallet("user-1", wallet.CurrencyJAM); !errors.Is(err, wallet.ErrWalletExists) {
+		t.Errorf("second CreateWallet() error = %v, want %v", err, wallet.ErrWalletExists)
+	}
+	if got, _ := uc.GetWalletByUserID("user-1"); got.Currency != wallet.CurrencyUSD {
+		t.Errorf("wallet currency after duplicate = %q, want %q", got.Currency, wallet.CurrencyUSD)
+	}
+
+	if _, err := uc.CreateWallet("user-2", "EUR"); !errors.Is(err, wallet.ErrUnsupportedCurrency) {
+		t.Errorf("CreateWallet() with EUR error = %v, want %v", err, wallet.ErrUnsupportedCurrency)
+	}
+}
+
+// newTransferRepo holds JAM wallets for user-1 (100) and user-2 (10) and a
+// USD wallet for user-3
+func newTransferRepo() *mockWalletRepo {
+	return newMockWalletRepo(
+		&wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balance: 100, Currency: wallet.CurrencyJAM},
+		&wallet.Wallet{ID: "wallet-2", UserID: "user-2", Balance: 10, Currency: wallet.CurrencyJAM},
+		&wallet.Wallet{ID: "wallet-3", UserID: "user-3", Balance: 10, Currency: wallet.CurrencyUSD},
+	)
+}
+
+// assertBalances checks the balances of user-1 and user-2
+func assertBalances(t *testing.T, repo *mockWalletRepo, want1, want2 float64) {
+	t.Helper()
+	w1, _ := repo.GetByUserID("user-1")
+	w2, _ := repo.GetByUserID("user-2")
+	if w1.Balance != want1 || w2.Balance != want2 {
+		t.Errorf("balances = %v and %v, want %v and %v", w1.Balance, w2.Balance, want1, want2)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	repo := newTransferRepo()
+	uc := NewWalletUseCase(repo, nil)
+
+	debit, err := uc.Transfer("user-1", "user-2", 30, "rent-october")
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	if debit.WalletID != "wallet-1" || debit.Type != wallet.TransactionTypeDebit || debit.Status != wallet.TransactionStatusSuccess {
+		t.Errorf("Transfer() = %+v, want a successful debit from wallet-1", debit)
+	}
+	assertBalances(t, repo, 70, 40)
+
+	if len(repo.transactions) != 2 {
+		t.Fatalf("recorded %d transactions, want 2", len(repo.transactions))
+	}
+	credit := repo.transactions[1]
+	if credit.WalletID != "wallet-2" || credit.Type != wallet.TransactionTypeCredit || credit.Amount != 30 {
+		t.Errorf("credit = %+v, want 30 credited to wallet-2", credit)
+	}
+	if debit.TransferID == "" || credit.TransferID != debit.TransferID {
+		t.Errorf("transfer IDs = %q and %q, want the same non-empty ID", debit.TransferID, credit.TransferID)
+	}
+
+	// A retry with the same reference returns the original debit
+	var dup *wallet.DuplicateTransactionError
+	if _, err := uc.Transfer("user-1", "user-2", 30, "rent-october"); !errors.As(err, &dup) || dup.Existing.ID != debit.ID {
+		t.Fatalf("retried Transfer() error = %v, want a duplicate of %s", err, debit.ID)
+	}
+	assertBalances(t, repo, 70, 40)
+}
+
+func TestTransfer_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		amount  float64
+		wantErr error
+	}{
+		{"Insufficient funds", "user-2", "user-1", 50, wallet.ErrInsufficientBalance},
+		{"Self-transfer", "user-1", "user-1", 10, wallet.ErrSelfTransfer},
+		{"Zero amount", "user-1", "user-2", 0, wallet.ErrInvalidAmount},
+		{"Negative amount", "user-1", "user-2", -5, wallet.ErrInvalidAmount},
+		{"Unknown recipient", "user-1", "user-9", 10, wallet.ErrNotFound},
+		{"Different currencies", "user-1", "user-3", 10, wallet.ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTransferRepo()
+			uc := NewWalletUseCase(repo, nil)
+
+			if _, err := uc.Transfer(tt.from, tt.to, tt.amount, ""); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transfer() error = %v, want %v", err, tt.wantErr)
+			}
+			assertBalances(t, repo, 100, 10)
+			if len(repo.transactions) != 0 {
+				t.Errorf("recorded %d transactions, want none", len(repo.transactions))
+			}
+		})
+	}
+}
+
+func TestGetTransactions_Filter(t *testing.T) {
+	day := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	repo := newMockWalletRepo(&wallet.Wallet{ID: "wallet-1", UserID: "user-1"})
+	for _, tx := range []*wallet.Transaction{
+		{ID: "credit-ok", Type: wallet.TransactionTypeCredit, Status: wallet.TransactionStatusSuccess, CreatedAt: day},
+		{ID: "credit-failed", Type: wallet.TransactionTypeCredit, Status: wallet.TransactionStatusFailed, CreatedAt: day.AddDate(0, 0, 1)},
+		{ID: "debit-ok", Type: wallet.TransactionTypeDebit, Status: wallet.TransactionStatusSuccess, CreatedAt: day.AddDate(0, 0, 2)},
+		{ID: "debit-pending", Type: wallet.TransactionTypeDebit, Status: wallet.TransactionStatusPending, CreatedAt: day.AddDate(0, 0, 3)},
+	} {
+		tx.WalletID = "wallet-1"
+		repo.CreateTransaction(tx)
+	}
+	uc := NewWalletUseCase(repo, nil)
+
+	tests := []struct {
+		name    string
+		filter  wallet.TransactionFilter
+		wantIDs []string
+	}{
+		{"No filter", wallet.TransactionFilter{}, []string{"credit-ok", "credit-failed", "debit-ok", "debit-pending"}},
+		{"Type", wallet.TransactionFilter{Type: wallet.TransactionTypeDebit}, []string{"debit-ok", "debit-pending"}},
+		{"Status", wallet.TransactionFilter{Status: wallet.TransactionStatusSuccess}, []string{"credit-ok", "debit-ok"}},
+		{"From is inclusive", wallet.TransactionFilter{Created: dateRange(day.AddDate(0, 0, 2), time.Time{})}, []string{"debit-ok", "debit-pending"}},
+		{"To is exclusive", wallet.TransactionFilter{Created: dateRange(time.Time{}, day.AddDate(0, 0, 1))}, []string{"credit-ok"}},
+		{"Type and status", wallet.TransactionFilter{Type: wallet.TransactionTypeCredit, Status: wallet.TransactionStatusFailed}, []string{"credit-failed"}},
+		{"Type and range", wallet.TransactionFilter{Type: wallet.TransactionTypeDebit, Created: dateRange(day, day.AddDate(0, 0, 3))}, []string{"debit-ok"}},
+		{"No match", wallet.TransactionFilter{Type: wallet.TransactionTypeCredit, Status: wallet.TransactionStatusPending}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, total, err := uc.GetTransactions("user-1", tt.filter, 1, 20)
+			if err != nil {
+				t.Fatalf("GetTransactions() error = %v", err)
+			}
+			var ids []string
+			for _, tx := range txs {
+				ids = append(ids, tx.ID)
+			}
+			if total != len(tt.wantIDs) || strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("GetTransactions() = %v (total %d), want %v", ids, total, tt.wantIDs)
+			}
+		})
+	}
+
+	inverted := wallet.TransactionFilter{Created: dateRange(day.AddDate(0, 0, 1), day)}
+	if _, _, err := uc.GetTransactions("user-1", inverted, 1, 20); !errors.Is(err, wallet.ErrInvalidRange) {
+		t.Errorf("GetTransactions() with from after to error = %v, want %v", err, wallet.ErrInvalidRange)
+	}
+}
+
+// dateRange builds a date range filter; a zero time leaves that bound open
+func dateRange(after, before time.Time) filter.DateRangeFilter {
+	var f filter.DateRangeFilter
+	if !after.IsZero() {
+		f.After = &after
+	}
+	if !before.IsZero() {
+		f.Before = &before
+	}
+	return f
+}
@@ -0,0 +1,12 @@
+-- Drop RLS policies for payouts
+DROP POLICY IF EXISTS payouts_admin_policy ON payouts;
+DROP POLICY IF EXISTS payouts_seller_policy ON payouts;
+
+-- Disable RLS on payouts
+ALTER TABLE payouts DISABLE ROW LEVEL SECURITY;
+
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_payouts_updated_at ON payouts;
+
+-- Drop payouts table
+DROP TABLE IF EXISTS payouts CASCADE;
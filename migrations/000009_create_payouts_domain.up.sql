@@ -0,0 +1,38 @@
+-- Create payouts table (Payout Domain)
+-- Settlement ledger of seller earnings accrued from completed orders
+CREATE TABLE IF NOT EXISTS payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    gross NUMERIC(12, 2) NOT NULL CHECK (gross >= 0),
+    fee NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    net NUMERIC(12, 2) NOT NULL CHECK (net >= 0),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'paid')),
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(order_id, seller_id)
+);
+
+-- Create indexes for payouts table
+CREATE INDEX idx_payouts_seller_id ON payouts(seller_id);
+CREATE INDEX idx_payouts_seller_status ON payouts(seller_id, status);
+
+-- Add trigger to update updated_at timestamp
+CREATE TRIGGER update_payouts_updated_at
+BEFORE UPDATE ON payouts
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Enable Row-Level Security (RLS) on payouts table
+ALTER TABLE payouts ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Sellers can view their own payouts
+CREATE POLICY payouts_seller_policy ON payouts
+    FOR SELECT
+    USING (seller_id = current_setting('app.current_user_id', true)::UUID);
+
+-- Policy: Admins can view and settle all payouts
+CREATE POLICY payouts_admin_policy ON payouts
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
@@ -0,0 +1,9 @@
+-- Drop RLS policies for product_price_changes
+DROP POLICY IF EXISTS product_price_changes_admin_policy ON product_price_changes;
+DROP POLICY IF EXISTS product_price_changes_seller_policy ON product_price_changes;
+
+-- Disable RLS on product_price_changes
+ALTER TABLE product_price_changes DISABLE ROW LEVEL SECURITY;
+
+-- Drop product_price_changes table
+DROP TABLE IF EXISTS product_price_changes CASCADE;
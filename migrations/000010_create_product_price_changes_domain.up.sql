@@ -0,0 +1,28 @@
+-- Create product_price_changes table (Product Domain)
+-- Audit trail of price movements made through bulk price updates
+CREATE TABLE IF NOT EXISTS product_price_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    changed_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_price NUMERIC(12, 2) NOT NULL CHECK (old_price >= 0),
+    new_price NUMERIC(12, 2) NOT NULL CHECK (new_price > 0),
+    reason VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for product_price_changes table
+CREATE INDEX idx_product_price_changes_product_id ON product_price_changes(product_id, created_at DESC);
+CREATE INDEX idx_product_price_changes_changed_by ON product_price_changes(changed_by);
+
+-- Enable Row-Level Security (RLS) on product_price_changes table
+ALTER TABLE product_price_changes ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Sellers can view price changes they made
+CREATE POLICY product_price_changes_seller_policy ON product_price_changes
+    FOR SELECT
+    USING (changed_by = current_setting('app.current_user_id', true)::UUID);
+
+-- Policy: Admins can view all price changes
+CREATE POLICY product_price_changes_admin_policy ON product_price_changes
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
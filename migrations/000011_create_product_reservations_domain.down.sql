@@ -0,0 +1,2 @@
+-- Drop product_reservations table
+DROP TABLE IF EXISTS product_reservations CASCADE;
@@ -0,0 +1,15 @@
+-- Create product_reservations table (Product Domain)
+-- Holds stock for carts so listings can report sellable (available) quantity
+CREATE TABLE IF NOT EXISTS product_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    cart_id UUID NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(product_id, cart_id)
+);
+
+-- Create indexes for product_reservations table
+CREATE INDEX idx_product_reservations_product_expires ON product_reservations(product_id, expires_at);
+CREATE INDEX idx_product_reservations_cart_id ON product_reservations(cart_id);
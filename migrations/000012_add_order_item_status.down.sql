@@ -0,0 +1,14 @@
+-- Drop seller update policy for order_items
+DROP POLICY IF EXISTS order_items_seller_update_policy ON order_items;
+
+-- Drop index
+DROP INDEX IF EXISTS idx_order_items_order_status;
+
+-- Restore the original order status constraint
+UPDATE orders SET status = 'paid' WHERE status = 'partially_shipped';
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('pending', 'paid', 'shipped', 'completed', 'cancelled'));
+
+-- Drop per-item status
+ALTER TABLE order_items DROP COLUMN IF EXISTS status;
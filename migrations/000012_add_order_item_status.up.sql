@@ -0,0 +1,22 @@
+-- Add per-item fulfillment status to order_items (Order Domain)
+-- Lets each seller ship their own items; the order status is derived from them
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'shipped', 'cancelled'));
+
+-- Allow the derived partially_shipped order status
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('pending', 'paid', 'partially_shipped', 'shipped', 'completed', 'cancelled'));
+
+-- Create index for order_items status
+CREATE INDEX idx_order_items_order_status ON order_items(order_id, status);
+
+-- Policy: Sellers can update fulfillment of order items for their products
+CREATE POLICY order_items_seller_update_policy ON order_items
+    FOR UPDATE
+    USING (
+        product_id IN (
+            SELECT id FROM products WHERE seller_id = current_setting('app.current_user_id', true)::UUID
+        )
+    );
@@ -0,0 +1,5 @@
+-- Drop slug index
+DROP INDEX IF EXISTS idx_products_slug;
+
+-- Drop slug column
+ALTER TABLE products DROP COLUMN IF EXISTS slug;
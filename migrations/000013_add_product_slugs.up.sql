@@ -0,0 +1,14 @@
+-- Add URL-friendly slugs to products (Product Domain)
+-- Products can be addressed by slug as well as UUID
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+
+-- Backfill existing products; the id prefix keeps backfilled slugs unique
+UPDATE products
+SET slug = trim(BOTH '-' FROM left(regexp_replace(lower(title), '[^a-z0-9]+', '-', 'g'), 80))
+    || '-' || left(id::text, 8)
+WHERE slug IS NULL;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+
+-- Create unique index for product slugs
+CREATE UNIQUE INDEX idx_products_slug ON products(slug);
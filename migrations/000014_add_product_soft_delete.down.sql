@@ -0,0 +1,5 @@
+-- Drop live products index
+DROP INDEX IF EXISTS idx_products_live;
+
+-- Drop soft-delete column
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
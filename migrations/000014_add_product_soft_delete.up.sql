@@ -0,0 +1,6 @@
+-- Add soft-delete support to products (Product Domain)
+-- Deleted products keep their row so cart and order items still resolve
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Listings only ever read live products
+CREATE INDEX IF NOT EXISTS idx_products_live ON products(created_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Drop order item title snapshot
+ALTER TABLE order_items DROP COLUMN IF EXISTS title;
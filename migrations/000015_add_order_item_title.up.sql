@@ -0,0 +1,10 @@
+-- Snapshot the product title on each order item (Order Domain)
+-- Receipts fall back to it when the product is no longer available
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS title VARCHAR(500) NOT NULL DEFAULT '';
+
+-- Backfill existing items from their products
+UPDATE order_items oi
+SET title = p.title
+FROM products p
+WHERE p.id = oi.product_id AND oi.title = '';
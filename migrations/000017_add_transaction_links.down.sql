@@ -0,0 +1,5 @@
+-- Drop transaction links
+DROP INDEX IF EXISTS idx_transactions_unlinked_verifications;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS verification_only,
+    DROP COLUMN IF EXISTS order_id;
@@ -0,0 +1,15 @@
+-- Link ledger entries to orders and mark verification-only records (Wallet Domain)
+-- Verification-only records log an on-chain check without moving funds.
+-- Both columns went unused and are dropped again by 000033.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS verification_only BOOLEAN NOT NULL DEFAULT false;
+
+-- Backfill records written by blockchain verification before this column existed
+UPDATE transactions
+SET verification_only = true
+WHERE amount = 0 AND reference LIKE 'Blockchain verification:%';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_unlinked_verifications
+    ON transactions(created_at)
+    WHERE verification_only AND order_id IS NULL;
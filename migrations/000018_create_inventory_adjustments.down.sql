@@ -0,0 +1,9 @@
+-- Drop RLS policies for inventory_adjustments
+DROP POLICY IF EXISTS inventory_adjustments_admin_policy ON inventory_adjustments;
+DROP POLICY IF EXISTS inventory_adjustments_seller_policy ON inventory_adjustments;
+
+-- Disable RLS on inventory_adjustments
+ALTER TABLE inventory_adjustments DISABLE ROW LEVEL SECURITY;
+
+-- Drop inventory_adjustments table
+DROP TABLE IF EXISTS inventory_adjustments CASCADE;
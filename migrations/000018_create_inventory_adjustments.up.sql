@@ -0,0 +1,30 @@
+-- Create inventory_adjustments table (Product Domain)
+-- Audit trail of product stock quantity changes
+CREATE TABLE IF NOT EXISTS inventory_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for inventory_adjustments table
+CREATE INDEX idx_inventory_adjustments_product_id ON inventory_adjustments(product_id, created_at DESC);
+CREATE INDEX idx_inventory_adjustments_user_id ON inventory_adjustments(user_id);
+
+-- Enable Row-Level Security (RLS) on inventory_adjustments table
+ALTER TABLE inventory_adjustments ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Sellers can view adjustments to their own products
+CREATE POLICY inventory_adjustments_seller_policy ON inventory_adjustments
+    FOR SELECT
+    USING (product_id IN (
+        SELECT id FROM products
+        WHERE seller_id = current_setting('app.current_user_id', true)::UUID
+    ));
+
+-- Policy: Admins can view all adjustments
+CREATE POLICY inventory_adjustments_admin_policy ON inventory_adjustments
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
@@ -0,0 +1,2 @@
+-- Drop transaction reference uniqueness
+DROP INDEX IF EXISTS idx_transactions_wallet_reference;
@@ -0,0 +1,6 @@
+-- Make external references unique per wallet (Wallet Domain)
+-- A retried deposit or payment carrying the same reference is recorded once;
+-- entries without a reference are not constrained
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_wallet_reference
+    ON transactions(wallet_id, reference)
+    WHERE reference IS NOT NULL AND reference <> '';
@@ -0,0 +1,9 @@
+-- Drop RLS policies for product_views
+DROP POLICY IF EXISTS product_views_admin_policy ON product_views;
+DROP POLICY IF EXISTS product_views_seller_policy ON product_views;
+
+-- Disable RLS on product_views
+ALTER TABLE product_views DISABLE ROW LEVEL SECURITY;
+
+-- Drop product_views table
+DROP TABLE IF EXISTS product_views CASCADE;
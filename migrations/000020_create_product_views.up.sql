@@ -0,0 +1,23 @@
+-- Create product_views table (Product Domain)
+-- Running total of product detail views, flushed periodically from Redis
+CREATE TABLE IF NOT EXISTS product_views (
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    views BIGINT NOT NULL DEFAULT 0 CHECK (views >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Enable Row-Level Security (RLS) on product_views table
+ALTER TABLE product_views ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Sellers can view the counts of their own products
+CREATE POLICY product_views_seller_policy ON product_views
+    FOR SELECT
+    USING (product_id IN (
+        SELECT id FROM products
+        WHERE seller_id = current_setting('app.current_user_id', true)::UUID
+    ));
+
+-- Policy: Admins can view all counts
+CREATE POLICY product_views_admin_policy ON product_views
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
@@ -0,0 +1,2 @@
+-- Drop active cart uniqueness
+DROP INDEX IF EXISTS idx_carts_user_active;
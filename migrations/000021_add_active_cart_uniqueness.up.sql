@@ -0,0 +1,6 @@
+-- Allow one active cart per user (Cart Domain)
+-- Carts are created on demand, so concurrent requests from the same user
+-- must not each create one; checked out carts are not constrained
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_user_active
+    ON carts(user_id)
+    WHERE status = 'active';
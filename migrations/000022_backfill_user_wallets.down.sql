@@ -0,0 +1,3 @@
+-- Backfilled wallets cannot be told apart from wallets opened at sign-up and
+-- may already hold funds, so they are kept
+SELECT 1;
@@ -0,0 +1,5 @@
+-- Open a wallet for every user without one (Wallet Domain)
+-- Wallets are now created at sign-up; users registered before that have none
+INSERT INTO wallets (user_id)
+SELECT id FROM users
+ON CONFLICT (user_id) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Drop transfer links
+DROP INDEX IF EXISTS idx_transactions_transfer_id;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS transfer_id;
@@ -0,0 +1,8 @@
+-- Link the two sides of a wallet-to-wallet transfer (Wallet Domain)
+-- The sender's debit and the recipient's credit share a transfer_id
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS transfer_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id
+    ON transactions(transfer_id)
+    WHERE transfer_id IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Drop on-chain transaction details
+DROP INDEX IF EXISTS idx_transactions_tx_hash;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS to_address,
+    DROP COLUMN IF EXISTS from_address,
+    DROP COLUMN IF EXISTS chain_id,
+    DROP COLUMN IF EXISTS tx_hash;
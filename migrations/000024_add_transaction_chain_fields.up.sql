@@ -0,0 +1,17 @@
+-- Record the on-chain details of blockchain transactions (Wallet Domain)
+-- Deposits keep the hash, chain and addresses they were verified against
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS tx_hash VARCHAR(66),
+    ADD COLUMN IF NOT EXISTS chain_id BIGINT,
+    ADD COLUMN IF NOT EXISTS from_address VARCHAR(42),
+    ADD COLUMN IF NOT EXISTS to_address VARCHAR(42);
+
+-- Deposits recorded before these columns existed carry their hash in the
+-- reference; the chain and addresses were not kept
+UPDATE transactions
+SET tx_hash = substring(reference FROM 9)
+WHERE tx_hash IS NULL AND reference LIKE 'deposit:0x%';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_tx_hash
+    ON transactions(tx_hash)
+    WHERE tx_hash IS NOT NULL;
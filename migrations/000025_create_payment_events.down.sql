@@ -0,0 +1,10 @@
+-- Drop RLS policies for payment_events
+DROP POLICY IF EXISTS payment_events_admin_policy ON payment_events;
+
+-- Disable RLS on payment_events
+ALTER TABLE payment_events DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_orders_payment_ref;
+
+-- Drop payment_events table
+DROP TABLE IF EXISTS payment_events CASCADE;
@@ -0,0 +1,25 @@
+-- Create payment_events table (Order Domain)
+-- Payment provider callbacks already applied; providers redeliver events, so
+-- each event ID is applied to its order once
+CREATE TABLE IF NOT EXISTS payment_events (
+    id VARCHAR(255) PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    type VARCHAR(100) NOT NULL,
+    amount NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_events_order_id ON payment_events(order_id);
+
+-- Callbacks find their order by payment reference
+CREATE INDEX IF NOT EXISTS idx_orders_payment_ref
+    ON orders(payment_ref)
+    WHERE payment_ref IS NOT NULL AND payment_ref <> '';
+
+-- Enable Row-Level Security (RLS) on payment_events table
+ALTER TABLE payment_events ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Admins can view all payment events
+CREATE POLICY payment_events_admin_policy ON payment_events
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
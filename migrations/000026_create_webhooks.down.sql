@@ -0,0 +1,15 @@
+-- Drop RLS policies for webhook_deliveries
+DROP POLICY IF EXISTS webhook_deliveries_admin_policy ON webhook_deliveries;
+DROP POLICY IF EXISTS webhook_deliveries_user_policy ON webhook_deliveries;
+
+-- Drop RLS policies for webhooks
+DROP POLICY IF EXISTS webhooks_admin_policy ON webhooks;
+DROP POLICY IF EXISTS webhooks_user_policy ON webhooks;
+
+-- Disable RLS
+ALTER TABLE webhook_deliveries DISABLE ROW LEVEL SECURITY;
+ALTER TABLE webhooks DISABLE ROW LEVEL SECURITY;
+
+-- Drop tables
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhooks CASCADE;
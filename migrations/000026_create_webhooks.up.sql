@@ -0,0 +1,59 @@
+-- Create webhooks and webhook_deliveries tables (Notification Domain)
+-- Users subscribe a URL to events; every delivery attempt is recorded
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id) WHERE active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries(event_id);
+
+-- Enable Row-Level Security (RLS) on webhooks table
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Users can only view and modify their own webhooks
+CREATE POLICY webhooks_user_policy ON webhooks
+    FOR ALL
+    USING (user_id = current_setting('app.current_user_id', true)::UUID);
+
+-- Policy: Admins can view all webhooks
+CREATE POLICY webhooks_admin_policy ON webhooks
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
+
+-- Enable Row-Level Security (RLS) on webhook_deliveries table
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+-- Policy: Users can view deliveries to their own webhooks
+CREATE POLICY webhook_deliveries_user_policy ON webhook_deliveries
+    FOR SELECT
+    USING (
+        webhook_id IN (
+            SELECT id FROM webhooks WHERE user_id = current_setting('app.current_user_id', true)::UUID
+        )
+    );
+
+-- Policy: Admins can view all webhook deliveries
+CREATE POLICY webhook_deliveries_admin_policy ON webhook_deliveries
+    FOR ALL
+    USING (current_setting('app.current_user_role', true) = 'admin');
@@ -0,0 +1,2 @@
+-- Drop the on-chain transaction uniqueness
+DROP INDEX IF EXISTS idx_transactions_chain_tx_hash;
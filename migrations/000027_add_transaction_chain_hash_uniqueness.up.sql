@@ -0,0 +1,7 @@
+-- Credit each on-chain transaction at most once (Wallet Domain)
+-- The per-wallet reference index lets two accounts claim the same deposit;
+-- this one is global. Hashes are compared case-insensitively. Existing
+-- duplicate claims must be resolved before applying it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_chain_tx_hash
+    ON transactions(chain_id, lower(tx_hash))
+    WHERE tx_hash IS NOT NULL AND chain_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop the image variants table
+DROP TABLE IF EXISTS image_variants CASCADE;
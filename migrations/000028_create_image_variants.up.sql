@@ -0,0 +1,10 @@
+-- Create image_variants table (Product Domain)
+-- Records the copies stored alongside each uploaded product image: the
+-- original kept when the upload was converted, and its thumbnails. Rows are
+-- keyed by the served image URL, which is what products list in images.
+CREATE TABLE IF NOT EXISTS image_variants (
+    url TEXT PRIMARY KEY,
+    original_url TEXT,
+    thumbnails JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Drop the case-insensitive wallet address uniqueness; addresses stay lowercase
+DROP INDEX IF EXISTS idx_users_wallet_address_lower;
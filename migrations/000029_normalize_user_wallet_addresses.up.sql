@@ -0,0 +1,9 @@
+-- Store wallet addresses in lowercase (User Domain)
+-- The application looks users up by the lowercase address; rows written
+-- before it normalized addresses are lowercased here. The index keeps two
+-- accounts from holding the same address in different letter case. Existing
+-- case-only duplicates must be resolved before applying it.
+UPDATE users SET wallet_address = lower(wallet_address)
+WHERE wallet_address <> lower(wallet_address);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_wallet_address_lower ON users(lower(wallet_address));
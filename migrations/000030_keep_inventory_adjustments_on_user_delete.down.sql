@@ -0,0 +1,9 @@
+-- Restore cascading deletes; adjustments whose user was deleted are dropped
+DELETE FROM inventory_adjustments WHERE user_id IS NULL;
+
+ALTER TABLE inventory_adjustments DROP CONSTRAINT IF EXISTS inventory_adjustments_user_id_fkey;
+ALTER TABLE inventory_adjustments
+    ADD CONSTRAINT inventory_adjustments_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE inventory_adjustments ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,7 @@
+-- Keep the inventory audit trail when the user who made a change is deleted
+ALTER TABLE inventory_adjustments ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE inventory_adjustments DROP CONSTRAINT IF EXISTS inventory_adjustments_user_id_fkey;
+ALTER TABLE inventory_adjustments
+    ADD CONSTRAINT inventory_adjustments_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
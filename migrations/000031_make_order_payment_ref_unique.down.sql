@@ -0,0 +1,5 @@
+-- Restore the non-unique payment reference index; cleared references are not restored
+DROP INDEX IF EXISTS idx_orders_payment_ref;
+CREATE INDEX IF NOT EXISTS idx_orders_payment_ref
+    ON orders(payment_ref)
+    WHERE payment_ref IS NOT NULL AND payment_ref <> '';
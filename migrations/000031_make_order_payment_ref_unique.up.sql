@@ -0,0 +1,16 @@
+-- Payment references are issued by the server at checkout, one per order.
+-- References that were chosen by clients and shared by several orders cannot
+-- be attributed to any of them, so they are cleared before the index is made
+-- unique; those orders need their payment reconciled by hand.
+UPDATE orders SET payment_ref = NULL
+WHERE payment_ref IN (
+    SELECT payment_ref FROM orders
+    WHERE payment_ref IS NOT NULL AND payment_ref <> ''
+    GROUP BY payment_ref
+    HAVING COUNT(*) > 1
+);
+
+DROP INDEX IF EXISTS idx_orders_payment_ref;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_payment_ref
+    ON orders(payment_ref)
+    WHERE payment_ref IS NOT NULL AND payment_ref <> '';
@@ -0,0 +1,2 @@
+-- Drop the product image uploads table
+DROP TABLE IF EXISTS product_image_uploads CASCADE;
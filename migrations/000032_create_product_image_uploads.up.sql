@@ -0,0 +1,21 @@
+-- Create product_image_uploads table (Product Domain)
+-- Records which seller uploaded each product image, so image URLs are only
+-- presigned, and stored files only deleted, for the seller who uploaded them.
+-- Rows are keyed by the served image URL, which is what products list in
+-- images.
+CREATE TABLE IF NOT EXISTS product_image_uploads (
+    url TEXT PRIMARY KEY,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_image_uploads_seller_id ON product_image_uploads(seller_id);
+
+-- Images listed before uploads were recorded belong to the seller of the
+-- earliest product showing them
+INSERT INTO product_image_uploads (url, seller_id, created_at)
+SELECT DISTINCT ON (image) image, seller_id, created_at
+FROM products, unnest(images) AS image
+WHERE image LIKE 'http%/products/%'
+ORDER BY image, created_at
+ON CONFLICT (url) DO NOTHING;
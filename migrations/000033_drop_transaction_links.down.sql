@@ -0,0 +1,12 @@
+-- Restore the transaction links
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS verification_only BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE transactions
+SET verification_only = true
+WHERE amount = 0 AND reference LIKE 'Blockchain verification:%';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_unlinked_verifications
+    ON transactions(created_at)
+    WHERE verification_only AND order_id IS NULL;
@@ -0,0 +1,7 @@
+-- Drop the unused transaction links (Wallet Domain)
+-- Nothing records an order on a ledger entry or marks verification-only
+-- records, and unlinked verification records are kept rather than pruned
+DROP INDEX IF EXISTS idx_transactions_unlinked_verifications;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS verification_only,
+    DROP COLUMN IF EXISTS order_id;
@@ -0,0 +1,74 @@
+package blockchain
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidAddress is returned when a value is not a 0x-prefixed 20-byte hex address
+var ErrInvalidAddress = errors.New("invalid Ethereum address")
+
+// Address is a validated Ethereum address. It renders in EIP-55 checksum
+// form and compares case-insensitively, so callers never need to reach for
+// strings.ToLower or EqualFold on raw address strings.
+type Address struct {
+	addr common.Address
+}
+
+// ParseAddress validates and normalizes an address in any letter case
+func ParseAddress(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return Address{}, ErrInvalidAddress
+	}
+	if !common.IsHexAddress(s) {
+		return Address{}, ErrInvalidAddress
+	}
+	return Address{addr: common.HexToAddress(s)}, nil
+}
+
+// String returns the EIP-55 checksummed form
+func (a Address) String() string {
+	return a.addr.Hex()
+}
+
+// Key returns the lowercase form used for storage and lookups
+func (a Address) Key() string {
+	return strings.ToLower(a.addr.Hex())
+}
+
+// Equal reports whether both values name the same address
+func (a Address) Equal(other Address) bool {
+	return a.addr == other.addr
+}
+
+// IsZero reports whether the address is unset or the zero address
+func (a Address) IsZero() bool {
+	return a.addr == common.Address{}
+}
+
+// MarshalText encodes the address in checksum form
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses and validates an address
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// NormalizeAddress validates an address and returns its storage key
+func NormalizeAddress(s string) (string, error) {
+	a, err := ParseAddress(s)
+	if err != nil {
+		return "", err
+	}
+	return a.Key(), nil
+}
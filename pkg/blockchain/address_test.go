@@ -0,0 +1,113 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Checksummed", checksummed, checksummed, false},
+		{"Lowercase", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", checksummed, false},
+		{"Uppercase hex", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", checksummed, false},
+		{"Surrounding whitespace", "  " + checksummed + "\n", checksummed, false},
+		{"Missing prefix", "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "", true},
+		{"Too short", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", "", true},
+		{"Non-hex", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beazz", "", true},
+		{"Empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddress(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Errorf("ParseAddress(%q) error = %v, want ErrInvalidAddress", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAddress(%q) error = %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseAddress(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressEqualityAndKey(t *testing.T) {
+	lower, _ := ParseAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	mixed, _ := ParseAddress(checksummed)
+	other, _ := ParseAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+
+	if !lower.Equal(mixed) {
+		t.Error("addresses differing only in case are not equal")
+	}
+	if lower.Equal(other) {
+		t.Error("distinct addresses compare equal")
+	}
+	if lower.Key() != mixed.Key() || lower.Key() != "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed" {
+		t.Errorf("Key() = %s / %s, want the lowercase form", lower.Key(), mixed.Key())
+	}
+	if !(Address{}).IsZero() || lower.IsZero() {
+		t.Error("IsZero() misreports")
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	var payload struct {
+		Address Address `json:"address"`
+	}
+	if err := json.Unmarshal([]byte(`{"address":"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}`), &payload); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	out, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"address":"` + checksummed + `"}`; string(out) != want {
+		t.Errorf("Marshal() = %s, want %s", out, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"address":"not-an-address"}`), &payload); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Unmarshal() invalid error = %v, want ErrInvalidAddress", err)
+	}
+}
+
+func TestParseDepositAddresses(t *testing.T) {
+	addresses, err := ParseDepositAddresses(" 1:0x742d35cc6634c0532925a3b844bc9e7595f0beb1, 137:0x1111111111111111111111111111111111111111 ")
+	if err != nil {
+		t.Fatalf("ParseDepositAddresses() error = %v", err)
+	}
+	if len(addresses) != 2 {
+		t.Fatalf("got %d addresses, want 2", len(addresses))
+	}
+	if err := addresses.CheckRecipient(1, "0x742D35CC6634C0532925A3B844BC9E7595F0BEB1"); err != nil {
+		t.Errorf("CheckRecipient() with different case error = %v", err)
+	}
+	if err := addresses.CheckRecipient(137, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"); !errors.Is(err, ErrRecipientMismatch) {
+		t.Errorf("CheckRecipient() of another chain's address error = %v, want ErrRecipientMismatch", err)
+	}
+	if err := addresses.CheckRecipient(11155111, "0x742d35cc6634c0532925a3b844bc9e7595f0beb1"); !errors.Is(err, ErrRecipientMismatch) {
+		t.Errorf("CheckRecipient() on an unconfigured chain error = %v, want ErrRecipientMismatch", err)
+	}
+
+	if empty, err := ParseDepositAddresses(""); err != nil || len(empty) != 0 {
+		t.Errorf("ParseDepositAddresses(\"\") = %v, %v; want empty", empty, err)
+	}
+	for _, in := range []string{"0x742d35cc6634c0532925a3b844bc9e7595f0beb1", "mainnet:0x742d35cc6634c0532925a3b844bc9e7595f0beb1", "1:0x123", "1:0x0000000000000000000000000000000000000000"} {
+		if _, err := ParseDepositAddresses(in); err == nil {
+			t.Errorf("ParseDepositAddresses(%q) error = nil, want error", in)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Chain is the configuration of a supported network
+type Chain struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	RPCURL string `json:"rpc_url"`
+	// MinConfirmations overrides the default confirmation count for the
+	// chain when non-zero
+	MinConfirmations uint64 `json:"min_confirmations"`
+	ExplorerURL      string `json:"explorer_url"`
+	// Currency is the symbol of the chain's native currency, in which
+	// deposit values are denominated
+	Currency string `json:"currency"`
+}
+
+// TxURL links to the transaction on the chain's block explorer, or returns
+// "" when the chain has no explorer configured
+func (c Chain) TxURL(txHash string) string {
+	if c.ExplorerURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(c.ExplorerURL, "/") + "/tx/" + txHash
+}
+
+// defaultChains are supported without any configuration. RPC URLs are left
+// to configuration. Goerli (5) is deliberately absent as it has been shut down.
+var defaultChains = []Chain{
+	{ID: 1, Name: "Ethereum Mainnet", ExplorerURL: "https://etherscan.io", Currency: "ETH"},
+	{ID: 11155111, Name: "Sepolia Testnet", ExplorerURL: "https://sepolia.etherscan.io", Currency: "ETH"},
+	{ID: 137, Name: "Polygon Mainnet", ExplorerURL: "https://polygonscan.com", Currency: "POL"},
+	{ID: 80001, Name: "Mumbai Testnet", ExplorerURL: "https://mumbai.polygonscan.com", Currency: "POL"},
+	{ID: 8453, Name: "Base", ExplorerURL: "https://basescan.org", Currency: "ETH"},
+	{ID: 42161, Name: "Arbitrum One", ExplorerURL: "https://arbiscan.io", Currency: "ETH"},
+}
+
+// Registry holds the supported chains by ID
+type Registry struct {
+	chains map[int64]Chain
+}
+
+// NewRegistry creates a registry of the given chains
+func NewRegistry(chains ...Chain) *Registry {
+	r := &Registry{chains: make(map[int64]Chain, len(chains))}
+	for _, c := range chains {
+		r.chains[c.ID] = c
+	}
+	return r
+}
+
+// LoadRegistry builds the registry from the default chains and a JSON array
+// of chain settings, e.g. `[{"id":8453,"rpc_url":"https://mainnet.base.org"}]`.
+// An entry for a default chain only overrides the fields it sets; an entry
+// for any other chain adds it. An empty string yields the default chains.
+func LoadRegistry(config string) (*Registry, error) {
+	r := NewRegistry(defaultChains...)
+	if strings.TrimSpace(config) == "" {
+		return r, nil
+	}
+
+	var overrides []Chain
+	if err := json.Unmarshal([]byte(config), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid chain configuration: %w", err)
+	}
+	for _, o := range overrides {
+		if o.ID <= 0 {
+			return nil, fmt.Errorf("invalid chain configuration: chain ID must be positive, got %d", o.ID)
+		}
+		c, ok := r.chains[o.ID]
+		if !ok {
+			c = Chain{ID: o.ID, Name: fmt.Sprintf("Chain %d", o.ID)}
+		}
+		if o.Name != "" {
+			c.Name = o.Name
+		}
+		if o.RPCURL != "" {
+			c.RPCURL = o.RPCURL
+		}
+		if o.MinConfirmations != 0 {
+			c.MinConfirmations = o.MinConfirmations
+		}
+		if o.ExplorerURL != "" {
+			c.ExplorerURL = o.ExplorerURL
+		}
+		if o.Currency != "" {
+			c.Currency = strings.ToUpper(o.Currency)
+		}
+		r.chains[o.ID] = c
+	}
+	return r, nil
+}
+
+// Lookup returns the chain with the given ID
+func (r *Registry) Lookup(chainID int64) (Chain, bool) {
+	c, ok := r.chains[chainID]
+	return c, ok
+}
+
+// Chains returns the registered chains ordered by ID
+func (r *Registry) Chains() []Chain {
+	chains := make([]Chain, 0, len(r.chains))
+	for _, c := range r.chains {
+		chains = append(chains, c)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ID < chains[j].ID })
+	return chains
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = NewRegistry(defaultChains...)
+)
+
+// SetRegistry replaces the chains the package supports
+func SetRegistry(r *Registry) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = r
+}
+
+// LookupChain returns the supported chain with the given ID
+func LookupChain(chainID int64) (Chain, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry.Lookup(chainID)
+}
+
+// ParseChainIDs reads a comma-separated list of chain IDs, e.g. "1,8453". An
+// empty string yields no IDs.
+func ParseChainIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid chain ID %q", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ValidateChainID checks if the chain ID is in the list of supported networks
+func ValidateChainID(chainID int64) bool {
+	_, ok := LookupChain(chainID)
+	return ok
+}
+
+// requiredConfirmations is the confirmation count for the chain: its own
+// setting, or the package default
+func requiredConfirmations(chainID int64) uint64 {
+	if c, ok := LookupChain(chainID); ok && c.MinConfirmations > 0 {
+		return c.MinConfirmations
+	}
+	return minConfirmations
+}
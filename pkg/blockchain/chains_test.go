@@ -0,0 +1,74 @@
+package blockchain
+
+import (
+	"testing"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	r, err := LoadRegistry(`[
+		{"id": 8453, "rpc_url": "https://mainnet.base.org", "min_confirmations": 10},
+		{"id": 10, "name": "OP Mainnet", "explorer_url": "https://optimistic.etherscan.io/"}
+	]`)
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	base, ok := r.Lookup(8453)
+	if !ok {
+		t.Fatal("Base not registered")
+	}
+	// Overrides keep the default fields they don't set
+	if base.Name != "Base" || base.RPCURL != "https://mainnet.base.org" || base.MinConfirmations != 10 || base.ExplorerURL != "https://basescan.org" {
+		t.Errorf("Base = %+v", base)
+	}
+
+	op, ok := r.Lookup(10)
+	if !ok || op.Name != "OP Mainnet" {
+		t.Errorf("added chain = %+v, %v", op, ok)
+	}
+	if got := op.TxURL("0xabc"); got != "https://optimistic.etherscan.io/tx/0xabc" {
+		t.Errorf("TxURL() = %q", got)
+	}
+
+	if _, ok := r.Lookup(5); ok {
+		t.Error("Goerli should not be registered")
+	}
+	if got := len(r.Chains()); got != len(defaultChains)+1 {
+		t.Errorf("got %d chains, want %d", got, len(defaultChains)+1)
+	}
+}
+
+func TestLoadRegistry_Invalid(t *testing.T) {
+	for _, config := range []string{`{"id": 1}`, `[{"id": 0, "name": "none"}]`, `[{"id": "one"}]`} {
+		if _, err := LoadRegistry(config); err == nil {
+			t.Errorf("LoadRegistry(%s) error = nil, want error", config)
+		}
+	}
+	if r, err := LoadRegistry(""); err != nil || len(r.Chains()) != len(defaultChains) {
+		t.Errorf("LoadRegistry(\"\") = %v, %v; want the default chains", r, err)
+	}
+}
+
+func TestParseChainIDs(t *testing.T) {
+	ids, err := ParseChainIDs(" 1, 8453,,")
+	if err != nil || len(ids) != 2 || ids[0] != 1 || ids[1] != 8453 {
+		t.Errorf("ParseChainIDs() = %v, %v; want [1 8453]", ids, err)
+	}
+	for _, s := range []string{"1,base", "0", "-1"} {
+		if _, err := ParseChainIDs(s); err == nil {
+			t.Errorf("ParseChainIDs(%q) error = nil, want error", s)
+		}
+	}
+}
+
+func TestRequiredConfirmations(t *testing.T) {
+	t.Cleanup(func() { SetRegistry(NewRegistry(defaultChains...)) })
+	SetRegistry(NewRegistry(Chain{ID: 1}, Chain{ID: 8453, MinConfirmations: 10}))
+
+	if got := requiredConfirmations(8453); got != 10 {
+		t.Errorf("requiredConfirmations(8453) = %d, want 10", got)
+	}
+	if got := requiredConfirmations(1); got != DefaultMinConfirmations {
+		t.Errorf("requiredConfirmations(1) = %d, want the default %d", got, DefaultMinConfirmations)
+	}
+}
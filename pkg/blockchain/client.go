@@ -2,40 +2,355 @@ package blockchain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-var client *ethclient.Client
+// ErrChainNotConfigured is returned when no RPC endpoint is configured for a
+// chain
+var ErrChainNotConfigured = errors.New("no RPC endpoint configured for chain")
 
-// InitRPC initializes the Ethereum RPC client
-func InitRPC(rpcURL string) error {
-	var err error
-	client, err = ethclient.Dial(rpcURL)
+// ErrRPCDisabled is returned by HealthCheck when no chain has an RPC endpoint
+var ErrRPCDisabled = errors.New("blockchain RPC is not configured")
+
+// dialTimeout bounds how long connecting to an endpoint may take
+const dialTimeout = 10 * time.Second
+
+// healthCheckTimeout bounds how long HealthCheck may wait on each endpoint
+const healthCheckTimeout = 2 * time.Second
+
+// Backoff before a chain whose connection failed is dialed again. It doubles
+// with each consecutive failure.
+const (
+	minRedialBackoff = time.Second
+	maxRedialBackoff = time.Minute
+)
+
+// ClientPool holds one RPC client per chain, connecting to each chain's
+// configured URL
+type ClientPool struct {
+	mu    sync.Mutex
+	urls  map[int64]string
+	conns map[int64]*chainConn
+}
+
+// chainConn is the connection state of one chain
+type chainConn struct {
+	client *ethclient.Client
+	// dialing is the connection attempt in progress, shared by every caller
+	// waiting on the chain
+	dialing *dialAttempt
+	// After a failed attempt, lastErr is returned until retryAt
+	lastErr error
+	retryAt time.Time
+	backoff time.Duration
+}
+
+// dialAttempt is one connection attempt. client and err are set before done
+// is closed.
+type dialAttempt struct {
+	done   chan struct{}
+	client *ethclient.Client
+	err    error
+}
+
+// NewClientPool creates a pool for the given RPC URLs by chain ID. Nothing is
+// dialed until Connect or Get.
+func NewClientPool(urls map[int64]string) *ClientPool {
+	p := &ClientPool{urls: make(map[int64]string, len(urls)), conns: make(map[int64]*chainConn)}
+	for id, url := range urls {
+		if url != "" {
+			p.urls[id] = url
+		}
+	}
+	return p
+}
+
+// NewClientPoolFromRegistry creates a pool for the chains in the registry
+// that have an RPC URL
+func NewClientPoolFromRegistry(r *Registry) *ClientPool {
+	urls := make(map[int64]string)
+	for _, c := range r.Chains() {
+		urls[c.ID] = c.RPCURL
+	}
+	return NewClientPool(urls)
+}
+
+// Connect dials every configured chain. Chains that fail are reported in the
+// returned error; Get retries them later.
+func (p *ClientPool) Connect() error {
+	var errs []error
+	for _, id := range p.Configured() {
+		if _, err := p.Get(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Get returns the client for the chain, connecting first if needed. It
+// returns ErrChainNotConfigured for a chain without an RPC URL.
+func (p *ClientPool) Get(chainID int64) (*ethclient.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	return p.get(ctx, chainID)
+}
+
+// get is Get, waiting at most until ctx is done. Callers for the same chain
+// share one connection attempt, made without holding the pool lock, so a slow
+// endpoint does not hold up other chains. A chain whose last attempt failed
+// is not dialed again until its backoff has passed.
+func (p *ClientPool) get(ctx context.Context, chainID int64) (*ethclient.Client, error) {
+	p.mu.Lock()
+	url, ok := p.urls[chainID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w %d", ErrChainNotConfigured, chainID)
+	}
+	conn := p.conn(chainID)
+	if conn.client != nil {
+		c := conn.client
+		p.mu.Unlock()
+		return c, nil
+	}
+	attempt := conn.dialing
+	if attempt == nil {
+		if wait := time.Until(conn.retryAt); wait > 0 {
+			err := conn.lastErr
+			p.mu.Unlock()
+			return nil, fmt.Errorf("chain %d: %w (retrying in %s)", chainID, err, wait.Round(time.Second))
+		}
+		attempt = &dialAttempt{done: make(chan struct{})}
+		conn.dialing = attempt
+		go p.connect(chainID, url, conn, attempt)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-attempt.done:
+		return attempt.client, attempt.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("chain %d: %w", chainID, ctx.Err())
+	}
+}
+
+// conn returns the chain's connection state, creating it if needed. p.mu
+// must be held.
+func (p *ClientPool) conn(chainID int64) *chainConn {
+	conn, ok := p.conns[chainID]
+	if !ok {
+		conn = &chainConn{}
+		p.conns[chainID] = conn
+	}
+	return conn
+}
+
+// connect makes the connection attempt for a chain and records its outcome.
+// It has its own timeout, so callers that stop waiting do not cancel it.
+func (p *ClientPool) connect(chainID int64, url string, conn *chainConn, attempt *dialAttempt) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	c, id, err := dial(ctx, url)
+	if err == nil && id != chainID {
+		c.Close()
+		c, err = nil, fmt.Errorf("RPC endpoint serves chain %d", id)
+	}
+	if err != nil {
+		err = fmt.Errorf("chain %d: %w", chainID, err)
+	}
+
+	p.mu.Lock()
+	conn.dialing = nil
+	if err != nil {
+		conn.lastErr = err
+		conn.backoff = min(max(2*conn.backoff, minRedialBackoff), maxRedialBackoff)
+		conn.retryAt = time.Now().Add(conn.backoff)
+	} else {
+		conn.client = c
+		conn.lastErr = nil
+		conn.backoff = 0
+		log.Printf("Successfully connected to Ethereum RPC for chain %d", chainID)
+	}
+	p.mu.Unlock()
+
+	attempt.client, attempt.err = c, err
+	close(attempt.done)
+}
+
+// Add connects to url and serves whichever supported chain it reports,
+// returning that chain's ID
+func (p *ClientPool) Add(url string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	c, id, err := dial(ctx, url)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Ethereum RPC: %w", err)
+		return 0, err
+	}
+	if !ValidateChainID(id) {
+		c.Close()
+		return 0, fmt.Errorf("RPC endpoint serves unsupported chain %d", id)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn := p.conn(id)
+	if conn.client != nil {
+		conn.client.Close()
+	}
+	p.urls[id] = url
+	conn.client = c
+	conn.lastErr = nil
+	conn.backoff = 0
+	conn.retryAt = time.Time{}
+	log.Printf("Successfully connected to Ethereum RPC for chain %d", id)
+	return id, nil
+}
+
+// HealthCheck asks every configured chain for its chain ID in parallel,
+// connecting to those not yet connected, and returns an error naming each
+// chain that did not answer within its own short timeout. It returns
+// ErrRPCDisabled when no chain has an RPC URL.
+func (p *ClientPool) HealthCheck(ctx context.Context) error {
+	chains := p.Configured()
+	if len(chains) == 0 {
+		return ErrRPCDisabled
+	}
+
+	errs := make([]error, len(chains))
+	var wg sync.WaitGroup
+	for i, chainID := range chains {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			c, err := p.get(ctx, chainID)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			id, err := c.ChainID(ctx)
+			switch {
+			case err != nil:
+				errs[i] = fmt.Errorf("chain %d: %w", chainID, err)
+			case id.Int64() != chainID:
+				errs[i] = fmt.Errorf("chain %d: RPC endpoint serves chain %d", chainID, id.Int64())
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Chains returns the IDs of the connected chains in order
+func (p *ClientPool) Chains() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]int64, 0, len(p.conns))
+	for id, conn := range p.conns {
+		if conn.client != nil {
+			ids = append(ids, id)
+		}
 	}
-	
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Close closes every client. A later Get reconnects.
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range p.conns {
+		if conn.client != nil {
+			conn.client.Close()
+			conn.client = nil
+		}
+	}
+}
+
+// Configured returns the IDs of the chains with an RPC URL in order, whether
+// or not they are connected
+func (p *ClientPool) Configured() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]int64, 0, len(p.urls))
+	for id := range p.urls {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// dial connects to url and returns the chain it serves
+func dial(ctx context.Context, url string) (*ethclient.Client, int64, error) {
+	c, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to connect to Ethereum RPC: %w", err)
+	}
+
 	// Test connection
-	_, err = client.ChainID(context.Background())
+	id, err := c.ChainID(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get chain ID from RPC: %w", err)
+		c.Close()
+		return nil, 0, fmt.Errorf("failed to get chain ID from RPC: %w", err)
 	}
-	
-	log.Printf("Successfully connected to Ethereum RPC at %s", rpcURL)
-	return nil
+	return c, id.Int64(), nil
 }
 
-// GetClient returns the global RPC client instance
-func GetClient() *ethclient.Client {
-	return client
+var (
+	poolMu sync.RWMutex
+	pool   = NewClientPool(nil)
+)
+
+// InitRPC makes r the package registry and connects to each of its chains
+// that has an RPC URL. Chains that fail to connect are reported in the
+// returned error and retried on use.
+func InitRPC(r *Registry) error {
+	SetRegistry(r)
+	p := NewClientPoolFromRegistry(r)
+
+	poolMu.Lock()
+	old := pool
+	pool = p
+	poolMu.Unlock()
+	old.Close()
+
+	return p.Connect()
 }
 
-// Close closes the RPC client connection
+// ConnectRPC adds rpcURL to the package pool for whichever supported chain it
+// serves, returning that chain's ID
+func ConnectRPC(rpcURL string) (int64, error) {
+	return Pool().Add(rpcURL)
+}
+
+// Pool returns the package client pool
+func Pool() *ClientPool {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	return pool
+}
+
+// ConnectedChains returns the IDs of the chains that have an RPC client
+func ConnectedChains() []int64 {
+	return Pool().Chains()
+}
+
+// HealthCheck checks the package pool's RPC endpoints, returning
+// ErrRPCDisabled when none is configured
+func HealthCheck(ctx context.Context) error {
+	return Pool().HealthCheck(ctx)
+}
+
+// Close closes every RPC client connection
 func Close() {
-	if client != nil {
-		client.Close()
-	}
+	Pool().Close()
 }
@@ -0,0 +1,255 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRPC answers eth_chainId with a fixed chain
+func fakeRPC(t *testing.T, chainID int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_chainId" {
+			http.Error(w, "unsupported", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, chainID)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientPool_SelectsClientByChain(t *testing.T) {
+	p := NewClientPool(map[int64]string{
+		8453:  fakeRPC(t, 8453).URL,
+		42161: fakeRPC(t, 42161).URL,
+		// Configured as Polygon but actually serving Ethereum mainnet
+		137: fakeRPC(t, 1).URL,
+		1:   "",
+	})
+	t.Cleanup(p.Close)
+
+	err := p.Connect()
+	if err == nil || !strings.Contains(err.Error(), "chain 137: RPC endpoint serves chain 1") {
+		t.Errorf("Connect() error = %v, want the misconfigured Polygon endpoint reported", err)
+	}
+	if got := fmt.Sprint(p.Chains()); got != "[8453 42161]" {
+		t.Errorf("Chains() = %s, want [8453 42161]", got)
+	}
+
+	base, err := p.Get(8453)
+	if err != nil {
+		t.Fatalf("Get(8453) error = %v", err)
+	}
+	arbitrum, err := p.Get(42161)
+	if err != nil {
+		t.Fatalf("Get(42161) error = %v", err)
+	}
+	if base == arbitrum {
+		t.Error("Get() returned the same client for two chains")
+	}
+	if again, _ := p.Get(8453); again != base {
+		t.Error("Get() dialed a new client for an already connected chain")
+	}
+}
+
+// blockingRPC counts the requests it receives and holds each one until
+// release is closed
+func blockingRPC(t *testing.T, calls *atomic.Int32, release <-chan struct{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x2105"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientPool_ConcurrentGetsShareOneDial(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	p := NewClientPool(map[int64]string{8453: blockingRPC(t, &calls, release).URL})
+	t.Cleanup(p.Close)
+
+	var wg sync.WaitGroup
+	clients := make([]any, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := p.Get(8453)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+			clients[i] = c
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("endpoint received %d requests, want 1", n)
+	}
+	for _, c := range clients[1:] {
+		if c != clients[0] {
+			t.Fatal("concurrent Get() calls returned different clients")
+		}
+	}
+}
+
+func TestClientPool_SlowChainDoesNotBlockOthers(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	p := NewClientPool(map[int64]string{
+		8453:  blockingRPC(t, &calls, release).URL,
+		42161: fakeRPC(t, 42161).URL,
+	})
+	t.Cleanup(p.Close)
+	// Runs before the server is closed, which waits for the held request
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go p.get(ctx, 8453)
+	time.Sleep(10 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := p.Get(42161); err != nil {
+		t.Fatalf("Get(42161) error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get(42161) took %s while chain 8453 was dialing", elapsed)
+	}
+}
+
+func TestClientPool_BacksOffAfterFailedDial(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+	p := NewClientPool(map[int64]string{8453: srv.URL})
+	t.Cleanup(p.Close)
+
+	if _, err := p.Get(8453); err == nil {
+		t.Fatal("Get() error = nil, want the failed dial reported")
+	}
+	_, err := p.Get(8453)
+	if err == nil || !strings.Contains(err.Error(), "retrying in") {
+		t.Errorf("Get() during backoff error = %v, want the last failure reported", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("endpoint received %d requests, want 1", n)
+	}
+
+	p.mu.Lock()
+	p.conns[8453].retryAt = time.Time{}
+	p.mu.Unlock()
+	p.Get(8453)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("endpoint received %d requests after the backoff, want 2", n)
+	}
+}
+
+func TestClientPool_UnconfiguredChain(t *testing.T) {
+	p := NewClientPool(map[int64]string{8453: fakeRPC(t, 8453).URL, 1: ""})
+	t.Cleanup(p.Close)
+
+	for _, chainID := range []int64{1, 42161} {
+		if _, err := p.Get(chainID); !errors.Is(err, ErrChainNotConfigured) {
+			t.Errorf("Get(%d) error = %v, want ErrChainNotConfigured", chainID, err)
+		}
+	}
+}
+
+func TestClientPool_Add(t *testing.T) {
+	p := NewClientPool(nil)
+	t.Cleanup(p.Close)
+
+	chainID, err := p.Add(fakeRPC(t, 1).URL)
+	if err != nil || chainID != 1 {
+		t.Fatalf("Add() = %d, %v; want chain 1", chainID, err)
+	}
+	if _, err := p.Get(1); err != nil {
+		t.Errorf("Get(1) after Add() error = %v", err)
+	}
+	if _, err := p.Add(fakeRPC(t, 5).URL); err == nil {
+		t.Error("Add() of an unsupported chain error = nil, want error")
+	}
+
+	p.Close()
+	if len(p.Chains()) != 0 {
+		t.Error("Close() left clients connected")
+	}
+}
+
+func TestVerifyTransaction_UnconfiguredChain(t *testing.T) {
+	t.Cleanup(func() { InitRPC(NewRegistry(defaultChains...)) })
+	if err := InitRPC(NewRegistry(Chain{ID: 8453, Name: "Base", RPCURL: fakeRPC(t, 8453).URL}, Chain{ID: 42161})); err != nil {
+		t.Fatalf("InitRPC() error = %v", err)
+	}
+	if got := fmt.Sprint(ConnectedChains()); got != "[8453]" {
+		t.Errorf("ConnectedChains() = %s, want [8453]", got)
+	}
+
+	if _, err := VerifyTransaction("0xabc", 42161); !errors.Is(err, ErrChainNotConfigured) {
+		t.Errorf("VerifyTransaction() on a chain without RPC error = %v, want ErrChainNotConfigured", err)
+	}
+}
+
+func TestClientPool_HealthCheck(t *testing.T) {
+	t.Run("Healthy", func(t *testing.T) {
+		p := NewClientPool(map[int64]string{8453: fakeRPC(t, 8453).URL, 42161: fakeRPC(t, 42161).URL})
+		t.Cleanup(p.Close)
+
+		if err := p.HealthCheck(context.Background()); err != nil {
+			t.Errorf("HealthCheck() error = %v", err)
+		}
+		if got := fmt.Sprint(p.Chains()); got != "[8453 42161]" {
+			t.Errorf("Chains() after HealthCheck = %s, want [8453 42161]", got)
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		down := fakeRPC(t, 42161)
+		p := NewClientPool(map[int64]string{8453: fakeRPC(t, 8453).URL, 42161: down.URL})
+		t.Cleanup(p.Close)
+		if err := p.Connect(); err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+		down.Close()
+
+		err := p.HealthCheck(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "chain 42161") || strings.Contains(err.Error(), "chain 8453") {
+			t.Errorf("HealthCheck() error = %v, want only chain 42161 reported", err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := NewClientPool(map[int64]string{1: ""})
+		if err := p.HealthCheck(context.Background()); !errors.Is(err, ErrRPCDisabled) {
+			t.Errorf("HealthCheck() error = %v, want %v", err, ErrRPCDisabled)
+		}
+	})
+}
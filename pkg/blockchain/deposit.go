@@ -0,0 +1,75 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRecipientMismatch is returned when a transaction was not sent to the
+// platform's deposit address on its chain
+var ErrRecipientMismatch = errors.New("transaction recipient is not the platform deposit address")
+
+// ErrSenderMismatch is returned when a deposit was not sent from the wallet
+// address linked to the account claiming it
+var ErrSenderMismatch = errors.New("transaction sender is not your linked wallet address")
+
+// DepositAddresses maps a chain ID to the platform address that receives
+// deposits on that chain
+type DepositAddresses map[int64]Address
+
+// ParseDepositAddresses reads comma-separated "chainID:address" pairs, e.g.
+// "1:0xabc...,137:0xdef...". An empty string yields no addresses.
+func ParseDepositAddresses(s string) (DepositAddresses, error) {
+	addresses := DepositAddresses{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		chain, addr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid deposit address %q: want chainID:address", pair)
+		}
+		chainID, err := strconv.ParseInt(strings.TrimSpace(chain), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID in deposit address %q", pair)
+		}
+		a, err := ParseAddress(addr)
+		if err != nil || a.IsZero() {
+			return nil, fmt.Errorf("%w in deposit address %q", ErrInvalidAddress, pair)
+		}
+		addresses[chainID] = a
+	}
+	return addresses, nil
+}
+
+// CheckRecipient returns ErrRecipientMismatch unless to is the deposit
+// address configured for chainID. Letter case is ignored.
+func (d DepositAddresses) CheckRecipient(chainID int64, to string) error {
+	want, ok := d[chainID]
+	if !ok {
+		return fmt.Errorf("%w: no deposit address for chain %d", ErrRecipientMismatch, chainID)
+	}
+	got, err := ParseAddress(to)
+	if err != nil || !got.Equal(want) {
+		return ErrRecipientMismatch
+	}
+	return nil
+}
+
+// CheckSender returns ErrSenderMismatch unless from is the linked wallet
+// address. Letter case is ignored; an account without a linked address
+// cannot claim deposits.
+func CheckSender(from, linked string) error {
+	want, err := ParseAddress(linked)
+	if err != nil || want.IsZero() {
+		return fmt.Errorf("%w: no wallet address is linked to the account", ErrSenderMismatch)
+	}
+	got, err := ParseAddress(from)
+	if err != nil || !got.Equal(want) {
+		return ErrSenderMismatch
+	}
+	return nil
+}
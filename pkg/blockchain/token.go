@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoTokenTransfer is returned when a transaction moved none of the
+// expected token
+var ErrNoTokenTransfer = errors.New("transaction has no transfer of the token")
+
+// transferTopic is the topic of the ERC-20 Transfer(address,address,uint256) event
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// TokenTransfer is an ERC-20 Transfer event. Amount is in the token's
+// smallest unit, e.g. millionths of a USDC.
+type TokenTransfer struct {
+	Token  string `json:"token"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+// VerifyTokenTransfer verifies a transaction like VerifyTransaction and
+// decodes its ERC-20 transfers of tokenAddr, returning ErrNoTokenTransfer if
+// it has none
+func VerifyTokenTransfer(txHash string, chainID int64, tokenAddr string) (*TransactionVerification, error) {
+	token, err := ParseAddress(tokenAddr)
+	if err != nil || token.IsZero() {
+		return nil, fmt.Errorf("invalid token address: %w", ErrInvalidAddress)
+	}
+	client, err := Pool().Get(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return verifyTransaction(context.Background(), client, txHash, chainID, requiredConfirmations(chainID), token.addr)
+}
+
+// decodeTransfers returns the Transfer events emitted by token, in log order.
+// Logs of other contracts, and events that only share the topic such as
+// ERC-721 transfers with an indexed token ID, are skipped.
+func decodeTransfers(logs []*types.Log, token common.Address) []TokenTransfer {
+	var transfers []TokenTransfer
+	for _, l := range logs {
+		if l.Address != token || len(l.Topics) != 3 || l.Topics[0] != transferTopic || len(l.Data) != 32 {
+			continue
+		}
+		transfers = append(transfers, TokenTransfer{
+			Token:  l.Address.Hex(),
+			From:   common.BytesToAddress(l.Topics[1].Bytes()).Hex(),
+			To:     common.BytesToAddress(l.Topics[2].Bytes()).Hex(),
+			Amount: new(big.Int).SetBytes(l.Data).String(),
+		})
+	}
+	return transfers
+}
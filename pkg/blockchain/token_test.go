@@ -0,0 +1,86 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	usdc      = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	otherCoin = common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+)
+
+// sampleTransferLog is a USDC Transfer of 125.50 USDC as it appears in a
+// mainnet receipt
+func sampleTransferLog() *types.Log {
+	return &types.Log{
+		Address: usdc,
+		Topics: []common.Hash{
+			common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+			common.HexToHash("0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb1"),
+			common.HexToHash("0x0000000000000000000000001234567890123456789012345678901234567890"),
+		},
+		Data: hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000077afa60"),
+	}
+}
+
+func TestDecodeTransfers(t *testing.T) {
+	if transferTopic != sampleTransferLog().Topics[0] {
+		t.Fatalf("transferTopic = %s, want the ERC-20 Transfer topic", transferTopic.Hex())
+	}
+
+	otherToken := sampleTransferLog()
+	otherToken.Address = otherCoin
+	nft := sampleTransferLog()
+	nft.Topics = append(nft.Topics, common.HexToHash("0x01"))
+	nft.Data = nil
+	approval := sampleTransferLog()
+	approval.Topics[0] = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+
+	transfers := decodeTransfers([]*types.Log{otherToken, nft, approval, sampleTransferLog()}, usdc)
+	if len(transfers) != 1 {
+		t.Fatalf("decoded %d transfers, want 1: %+v", len(transfers), transfers)
+	}
+
+	want := TokenTransfer{
+		Token:  usdc.Hex(),
+		From:   common.HexToAddress("0x742d35cc6634c0532925a3b844bc9e7595f0beb1").Hex(),
+		To:     "0x1234567890123456789012345678901234567890",
+		Amount: "125500000",
+	}
+	if transfers[0] != want {
+		t.Errorf("transfer = %+v, want %+v", transfers[0], want)
+	}
+}
+
+func TestVerifyTransaction_TokenTransfer(t *testing.T) {
+	// A token transfer calls the token contract and moves no ether
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &usdc, Value: big.NewInt(0)})
+	client := &stubClient{tx: tx, receiptBlock: 100, status: 1, currentBlock: 110, logs: []*types.Log{sampleTransferLog()}}
+
+	v, err := verifyTransaction(context.Background(), client, "0xabc", 1, 3, usdc)
+	if err != nil {
+		t.Fatalf("verifyTransaction() error = %v", err)
+	}
+	if !v.Verified || len(v.TokenTransfers) != 1 || v.TokenTransfers[0].Amount != "125500000" {
+		t.Errorf("verification = %+v, want one verified 125.5 USDC transfer", v)
+	}
+
+	if _, err := verifyTransaction(context.Background(), client, "0xabc", 1, 3, otherCoin); !errors.Is(err, ErrNoTokenTransfer) {
+		t.Errorf("verifyTransaction() for another token error = %v, want ErrNoTokenTransfer", err)
+	}
+}
+
+func TestVerifyTokenTransfer_InvalidToken(t *testing.T) {
+	for _, token := range []string{"", "usdc", "0x0000000000000000000000000000000000000000"} {
+		if _, err := VerifyTokenTransfer("0xabc", 1, token); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("VerifyTokenTransfer() with token %q error = %v, want ErrInvalidAddress", token, err)
+		}
+	}
+}
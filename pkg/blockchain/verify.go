@@ -6,8 +6,30 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
+// DefaultMinConfirmations is how many blocks must be mined on top of a
+// transaction's block before it is treated as verified. A transaction with
+// fewer can still be reorged out.
+const DefaultMinConfirmations uint64 = 3
+
+var minConfirmations = DefaultMinConfirmations
+
+// SetMinConfirmations sets how many confirmations VerifyTransaction requires
+// on chains without their own setting
+func SetMinConfirmations(n uint64) {
+	minConfirmations = n
+}
+
+// rpcClient is the subset of the Ethereum client used for verification
+type rpcClient interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
 // TransactionVerification contains the result of transaction verification
 type TransactionVerification struct {
 	TxHash    string `json:"txHash"`
@@ -18,15 +40,28 @@ type TransactionVerification struct {
 	Verified  bool   `json:"verified"`
 	IsPending bool   `json:"isPending"`
 	Status    uint64 `json:"status"` // 1 = success, 0 = failed
+	// Confirmations is how many blocks have been mined on top of the
+	// transaction's block
+	Confirmations         uint64 `json:"confirmations"`
+	RequiredConfirmations uint64 `json:"requiredConfirmations"`
+	// TokenTransfers are the transfers of the expected token in the
+	// transaction, set by VerifyTokenTransfer
+	TokenTransfers []TokenTransfer `json:"tokenTransfers,omitempty"`
 }
 
-// VerifyTransaction validates that a transaction exists, is confirmed, and matches the intended parameters
+// VerifyTransaction validates that a transaction exists, is confirmed, and matches the intended parameters.
+// A mined transaction with fewer than the required confirmations is reported as pending.
 func VerifyTransaction(txHash string, expectedChainID int64) (*TransactionVerification, error) {
-	if client == nil {
-		return nil, fmt.Errorf("RPC client not initialized - please configure RPC_URL environment variable and restart the server")
+	client, err := Pool().Get(expectedChainID)
+	if err != nil {
+		return nil, err
 	}
+	return verifyTransaction(context.Background(), client, txHash, expectedChainID, requiredConfirmations(expectedChainID), common.Address{})
+}
 
-	ctx := context.Background()
+// verifyTransaction verifies txHash, also decoding the transfers of token
+// unless it is the zero address
+func verifyTransaction(ctx context.Context, client rpcClient, txHash string, expectedChainID int64, required uint64, token common.Address) (*TransactionVerification, error) {
 	hash := common.HexToHash(txHash)
 
 	// Get transaction details
@@ -49,6 +84,8 @@ func VerifyTransaction(txHash string, expectedChainID int64) (*TransactionVerifi
 				Verified:  false,
 				IsPending: true,
 				Status:    0,
+
+				RequiredConfirmations: required,
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
@@ -80,6 +117,8 @@ func VerifyTransaction(txHash string, expectedChainID int64) (*TransactionVerifi
 		Verified:  receipt.Status == 1,
 		IsPending: isPending,
 		Status:    receipt.Status,
+
+		RequiredConfirmations: required,
 	}
 
 	// Check for success
@@ -87,20 +126,26 @@ func VerifyTransaction(txHash string, expectedChainID int64) (*TransactionVerifi
 		return verification, fmt.Errorf("transaction failed on-chain")
 	}
 
-	return verification, nil
-}
+	if token != (common.Address{}) {
+		verification.TokenTransfers = decodeTransfers(receipt.Logs, token)
+		if len(verification.TokenTransfers) == 0 {
+			return nil, fmt.Errorf("%w from %s", ErrNoTokenTransfer, token.Hex())
+		}
+	}
 
-// ValidateChainID checks if the chain ID is in the list of supported networks
-func ValidateChainID(chainID int64) bool {
-	// Supported networks: Ethereum Mainnet (1), Sepolia (11155111), etc.
-	supportedChains := map[int64]bool{
-		1:        true, // Ethereum Mainnet
-		11155111: true, // Sepolia Testnet
-		137:      true, // Polygon Mainnet
-		80001:    true, // Mumbai (Polygon Testnet)
-		// Note: Goerli (5) was removed as it's deprecated and shut down
+	currentBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current block number: %w", err)
+	}
+	if receipt.BlockNumber != nil && currentBlock > receipt.BlockNumber.Uint64() {
+		verification.Confirmations = currentBlock - receipt.BlockNumber.Uint64()
 	}
-	return supportedChains[chainID]
+	if verification.Confirmations < required {
+		verification.Verified = false
+		verification.IsPending = true
+	}
+
+	return verification, nil
 }
 
 // FormatValue converts wei value to a human-readable format
@@ -119,3 +164,20 @@ func FormatValue(weiValue string) (string, error) {
 
 	return eth.String(), nil
 }
+
+// EtherAmount converts a wei value to ether, rounded to the 8 decimal places
+// wallet balances are stored with
+func EtherAmount(weiValue string) (float64, error) {
+	wei, ok := new(big.Int).SetString(weiValue, 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid wei value")
+	}
+
+	// Work in units of 1e-8 ether so the only rounding is to the stored precision
+	units := new(big.Int).Div(wei, big.NewInt(1e10))
+	if rem := new(big.Int).Mod(wei, big.NewInt(1e10)); rem.Cmp(big.NewInt(5e9)) >= 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(units), big.NewFloat(1e8)).Float64()
+	return eth, nil
+}
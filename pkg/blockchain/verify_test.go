@@ -1,7 +1,12 @@
 package blockchain
 
 import (
+	"context"
+	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 func TestValidateChainID(t *testing.T) {
@@ -14,6 +19,8 @@ func TestValidateChainID(t *testing.T) {
 		{"Sepolia", 11155111, true},
 		{"Polygon", 137, true},
 		{"Mumbai", 80001, true},
+		{"Base", 8453, true},
+		{"Arbitrum One", 42161, true},
 		{"Goerli (deprecated)", 5, false},
 		{"Invalid Chain", 999999, false},
 		{"Zero Chain", 0, false},
@@ -60,3 +67,104 @@ func TestFormatValue(t *testing.T) {
 		})
 	}
 }
+
+// stubClient serves one mined transaction at receiptBlock
+type stubClient struct {
+	tx           *types.Transaction
+	receiptBlock uint64
+	status       uint64
+	currentBlock uint64
+	logs         []*types.Log
+}
+
+func (c *stubClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return c.tx, false, nil
+}
+
+func (c *stubClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: c.status, BlockNumber: new(big.Int).SetUint64(c.receiptBlock), Logs: c.logs}, nil
+}
+
+func (c *stubClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	return common.HexToAddress("0x1111111111111111111111111111111111111111"), nil
+}
+
+func (c *stubClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.currentBlock, nil
+}
+
+func TestVerifyTransaction_Confirmations(t *testing.T) {
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to, Value: big.NewInt(1e18)})
+
+	tests := []struct {
+		name              string
+		currentBlock      uint64
+		required          uint64
+		wantConfirmations uint64
+		wantVerified      bool
+	}{
+		{"In the latest block", 100, 3, 0, false},
+		{"Below threshold", 102, 3, 2, false},
+		{"At threshold", 103, 3, 3, true},
+		{"Above threshold", 150, 3, 50, true},
+		{"No confirmations required", 100, 0, 0, true},
+		{"Node behind receipt", 99, 3, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{tx: tx, receiptBlock: 100, status: 1, currentBlock: tt.currentBlock}
+
+			v, err := verifyTransaction(context.Background(), client, "0xabc", 1, tt.required, common.Address{})
+			if err != nil {
+				t.Fatalf("verifyTransaction() error = %v", err)
+			}
+			if v.Confirmations != tt.wantConfirmations {
+				t.Errorf("Confirmations = %d, want %d", v.Confirmations, tt.wantConfirmations)
+			}
+			if v.Verified != tt.wantVerified || v.IsPending == tt.wantVerified {
+				t.Errorf("Verified = %v, IsPending = %v; want verified %v", v.Verified, v.IsPending, tt.wantVerified)
+			}
+			if v.RequiredConfirmations != tt.required {
+				t.Errorf("RequiredConfirmations = %d, want %d", v.RequiredConfirmations, tt.required)
+			}
+		})
+	}
+}
+
+func TestVerifyTransaction_FailedIsNotPending(t *testing.T) {
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to, Value: big.NewInt(0)})
+	client := &stubClient{tx: tx, receiptBlock: 100, status: 0, currentBlock: 101}
+
+	v, err := verifyTransaction(context.Background(), client, "0xabc", 1, 3, common.Address{})
+	if err == nil {
+		t.Fatal("verifyTransaction() of a reverted transaction returned no error")
+	}
+	if v.Verified || v.IsPending {
+		t.Errorf("reverted transaction reported as verified %v, pending %v", v.Verified, v.IsPending)
+	}
+}
+
+func TestEtherAmount(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want float64
+	}{
+		{"1000000000000000000", 1},
+		{"250000000000000000", 0.25},
+		{"123456789", 0},           // below 1e-8 ether
+		{"5000000000", 0.00000001}, // rounds half up
+		{"1234567891234567890", 1.23456789},
+	}
+	for _, tt := range tests {
+		got, err := EtherAmount(tt.wei)
+		if err != nil || got != tt.want {
+			t.Errorf("EtherAmount(%s) = %v, %v; want %v", tt.wei, got, err, tt.want)
+		}
+	}
+	if _, err := EtherAmount("0x10"); err == nil {
+		t.Error("EtherAmount() of a non-decimal value error = nil, want error")
+	}
+}
@@ -0,0 +1,115 @@
+// Package bulk is the shared envelope for endpoints that act on a batch of
+// items and may partly succeed.
+//
+// Bulk endpoints answer 200 whenever the request itself was valid, even if
+// some or all items failed; the body says which items went through and why
+// the others did not. 4xx/5xx are kept for failures of the whole request.
+package bulk
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status summarises the outcome of a batch
+type Status string
+
+const (
+	// StatusSucceeded means every item succeeded
+	StatusSucceeded Status = "succeeded"
+	// StatusPartial means some items succeeded and some failed
+	StatusPartial Status = "partial"
+	// StatusFailed means no item succeeded
+	StatusFailed Status = "failed"
+)
+
+// Item is an item of the batch that succeeded. Index is its position in the
+// request.
+type Item[T any] struct {
+	Index int `json:"index"`
+	Value T   `json:"value"`
+}
+
+// Failure is an item of the batch that failed. Index is its position in the
+// request and ID identifies it when the request names items by ID.
+type Failure struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// Result collects the per-item outcomes of a bulk operation
+type Result[T any] struct {
+	Succeeded []Item[T] `json:"succeeded"`
+	Failed    []Failure `json:"failed"`
+}
+
+// New creates an empty result
+func New[T any]() *Result[T] {
+	return &Result[T]{Succeeded: []Item[T]{}, Failed: []Failure{}}
+}
+
+// Succeed records the item at index as succeeded
+func (r *Result[T]) Succeed(index int, value T) {
+	r.Succeeded = append(r.Succeeded, Item[T]{Index: index, Value: value})
+}
+
+// Fail records the item at index as failed with err
+func (r *Result[T]) Fail(index int, id string, err error) {
+	r.Failed = append(r.Failed, Failure{Index: index, ID: id, Error: err.Error()})
+}
+
+// Status reports whether all, some or none of the items succeeded. An empty
+// batch counts as succeeded.
+func (r *Result[T]) Status() Status {
+	switch {
+	case len(r.Failed) == 0:
+		return StatusSucceeded
+	case len(r.Succeeded) == 0:
+		return StatusFailed
+	default:
+		return StatusPartial
+	}
+}
+
+// Values returns the values of the succeeded items in order
+func (r *Result[T]) Values() []T {
+	values := make([]T, 0, len(r.Succeeded))
+	for _, item := range r.Succeeded {
+		values = append(values, item.Value)
+	}
+	return values
+}
+
+// Response is the JSON body of a bulk endpoint
+type Response[T any] struct {
+	Status         Status    `json:"status"`
+	SucceededCount int       `json:"succeeded_count"`
+	FailedCount    int       `json:"failed_count"`
+	Succeeded      []Item[T] `json:"succeeded"`
+	Failed         []Failure `json:"failed"`
+}
+
+// Response builds the JSON body for the result
+func (r *Result[T]) Response() Response[T] {
+	succeeded, failed := r.Succeeded, r.Failed
+	if succeeded == nil {
+		succeeded = []Item[T]{}
+	}
+	if failed == nil {
+		failed = []Failure{}
+	}
+	return Response[T]{
+		Status:         r.Status(),
+		SucceededCount: len(succeeded),
+		FailedCount:    len(failed),
+		Succeeded:      succeeded,
+		Failed:         failed,
+	}
+}
+
+// Respond writes the result as a 200 response
+func Respond[T any](ctx *gin.Context, r *Result[T]) {
+	ctx.JSON(http.StatusOK, r.Response())
+}
@@ -0,0 +1,70 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResult_MixedBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := New[string]()
+	r.Succeed(0, "a")
+	r.Fail(1, "b", errors.New("product not found"))
+	r.Succeed(2, "c")
+
+	if got := r.Status(); got != StatusPartial {
+		t.Errorf("Status() = %q, want %q", got, StatusPartial)
+	}
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	Respond(ctx, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	want := `{"status":"partial","succeeded_count":2,"failed_count":1,` +
+		`"succeeded":[{"index":0,"value":"a"},{"index":2,"value":"c"}],` +
+		`"failed":[{"index":1,"id":"b","error":"product not found"}]}`
+	if w.Body.String() != want {
+		t.Errorf("body = %s\nwant   %s", w.Body.String(), want)
+	}
+}
+
+func TestResult_Status(t *testing.T) {
+	allFailed := New[int]()
+	allFailed.Fail(0, "", errors.New("bad"))
+
+	tests := []struct {
+		name string
+		r    *Result[int]
+		want Status
+	}{
+		{"Empty batch", New[int](), StatusSucceeded},
+		{"All failed", allFailed, StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Status(); got != tt.want {
+				t.Errorf("Status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResult_ResponseNeverNull(t *testing.T) {
+	data, err := json.Marshal((&Result[int]{}).Response())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"status":"succeeded","succeeded_count":0,"failed_count":0,"succeeded":[],"failed":[]}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
@@ -21,12 +21,26 @@ type Config struct {
 	ServerWriteTimeout    string `mapstructure:"SERVER_WRITE_TIMEOUT"`
 	ServerShutdownTimeout string `mapstructure:"SERVER_SHUTDOWN_TIMEOUT"`
 	AllowedOrigins        string `mapstructure:"ALLOWED_ORIGINS"`
+	SlowRequestThreshold  string `mapstructure:"SLOW_REQUEST_THRESHOLD"`
+	// CORSAllowSchemeMismatch accepts origins that differ from an allowed
+	// one only in scheme, without credentials
+	CORSAllowSchemeMismatch bool `mapstructure:"CORS_ALLOW_SCHEME_MISMATCH"`
+	// CORSDebug logs every cross-origin request outside development too
+	CORSDebug bool `mapstructure:"CORS_DEBUG"`
+	// TrustedProxies lists the IPs or CIDRs of proxies whose X-Forwarded-*
+	// headers are believed, comma-separated
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+	// CookieDomain and CookieSameSite set the Domain and SameSite attributes
+	// of the auth cookies
+	CookieDomain   string `mapstructure:"COOKIE_DOMAIN"`
+	CookieSameSite string `mapstructure:"COOKIE_SAMESITE"`
 
 	// Database Configuration
-	DBConnectionString string `mapstructure:"DB_CONNECTION_STRING"`
-	DBMaxConnections   int    `mapstructure:"DB_MAX_CONNECTIONS"`
-	DBMaxIdleTime      string `mapstructure:"DB_MAX_IDLE_TIME"`
-	DBMaxConnLifetime  string `mapstructure:"DB_MAX_CONN_LIFETIME"`
+	DBConnectionString     string `mapstructure:"DB_CONNECTION_STRING"`
+	DBReadConnectionString string `mapstructure:"DB_READ_CONNECTION_STRING"`
+	DBMaxConnections       int    `mapstructure:"DB_MAX_CONNECTIONS"`
+	DBMaxIdleTime          string `mapstructure:"DB_MAX_IDLE_TIME"`
+	DBMaxConnLifetime      string `mapstructure:"DB_MAX_CONN_LIFETIME"`
 
 	// Redis Configuration
 	RedisConnectionString string `mapstructure:"REDIS_CONNECTION_STRING"`
@@ -41,6 +55,22 @@ type Config struct {
 	JWTSecret       string `mapstructure:"JWT_SECRET"`
 	JWTExpiration   string `mapstructure:"JWT_EXPIRATION"`
 	SIWEDomain      string `mapstructure:"SIWE_DOMAIN"`
+	SIWEBindNonce   bool   `mapstructure:"SIWE_BIND_NONCE"`
+	SIWEAllowedURIs string `mapstructure:"SIWE_ALLOWED_URIS"`
+	// AuthRateLimit is how many auth requests each client may make per
+	// AuthRateWindow; zero disables the limit
+	AuthRateLimit  int    `mapstructure:"AUTH_RATE_LIMIT"`
+	AuthRateWindow string `mapstructure:"AUTH_RATE_WINDOW"`
+	// SessionRenewWindow renews sessions used within this long of expiring;
+	// SessionMaxLifetime caps how long after login they can be renewed to
+	SessionRenewWindow string `mapstructure:"SESSION_RENEW_WINDOW"`
+	SessionMaxLifetime string `mapstructure:"SESSION_MAX_LIFETIME"`
+	// SIWEMaxAge is how long after its Issued At time a SIWE message is
+	// accepted
+	SIWEMaxAge string `mapstructure:"SIWE_MAX_AGE"`
+	// SIWEChainIDs lists the chains users may sign in on; empty allows every
+	// supported chain
+	SIWEChainIDs string `mapstructure:"SIWE_CHAIN_IDS"`
 
 	// Cache Configuration
 	CacheEnableL1  bool   `mapstructure:"CACHE_ENABLE_L1"`
@@ -50,10 +80,22 @@ type Config struct {
 	CacheL2TTL     string `mapstructure:"CACHE_L2_TTL"`
 
 	// Supabase Storage Configuration
-	SupabaseURL        string `mapstructure:"SUPABASE_URL"`
-	SupabaseKey        string `mapstructure:"SUPABASE_KEY"`
-	SupabaseBucket     string `mapstructure:"SUPABASE_BUCKET"`
-	StorageMaxFileSize int64  `mapstructure:"STORAGE_MAX_FILE_SIZE"`
+	SupabaseURL         string `mapstructure:"SUPABASE_URL"`
+	SupabaseKey         string `mapstructure:"SUPABASE_KEY"`
+	SupabaseBucket      string `mapstructure:"SUPABASE_BUCKET"`
+	StorageMaxFileSize  int64  `mapstructure:"STORAGE_MAX_FILE_SIZE"`
+	StorageConvertWebP  bool   `mapstructure:"STORAGE_CONVERT_WEBP"`
+	StorageKeepOriginal bool   `mapstructure:"STORAGE_KEEP_ORIGINAL"`
+	// Pixel limits for uploaded images; zero maximums use the storage
+	// default and zero minimums or aspect ratio are unchecked
+	StorageMaxImageWidth  int     `mapstructure:"STORAGE_MAX_IMAGE_WIDTH"`
+	StorageMaxImageHeight int     `mapstructure:"STORAGE_MAX_IMAGE_HEIGHT"`
+	StorageMinImageWidth  int     `mapstructure:"STORAGE_MIN_IMAGE_WIDTH"`
+	StorageMinImageHeight int     `mapstructure:"STORAGE_MIN_IMAGE_HEIGHT"`
+	StorageMaxAspectRatio float64 `mapstructure:"STORAGE_MAX_ASPECT_RATIO"`
+	// StorageThumbnailWidths lists the widths of the resized copies stored
+	// with each image, comma-separated; empty stores none
+	StorageThumbnailWidths string `mapstructure:"STORAGE_THUMBNAIL_WIDTHS"`
 
 	// S3-Compatible Storage Configuration (for Supabase/MinIO/AWS S3)
 	SupabaseS3AccessKeyID     string `mapstructure:"SUPABASE_S3_ACCESS_KEY_ID"`
@@ -62,10 +104,44 @@ type Config struct {
 	SupabaseRegion            string `mapstructure:"SUPABASE_REGION"`
 
 	// Blockchain Configuration
+	// RPCURL is an RPC endpoint for whichever supported chain it serves
 	RPCURL string `mapstructure:"RPC_URL"`
+	// Chains is a JSON array of per-chain settings (id, name, rpc_url,
+	// min_confirmations, explorer_url) merged over the built-in chains
+	Chains string `mapstructure:"CHAINS"`
+	// RPCMinConfirmations is how many blocks must follow a transaction before
+	// it counts as verified. Nil keeps the default of 3 and zero disables the
+	// requirement.
+	RPCMinConfirmations *int `mapstructure:"RPC_MIN_CONFIRMATIONS"`
+	// PlatformDepositAddresses lists the platform's deposit address per
+	// chain as comma-separated "chainID:address" pairs
+	PlatformDepositAddresses string `mapstructure:"PLATFORM_DEPOSIT_ADDRESSES"`
+
+	// Marketplace Configuration
+	PlatformFeePercent    float64 `mapstructure:"PLATFORM_FEE_PERCENT"`
+	ProductSlugRegenerate bool    `mapstructure:"PRODUCT_SLUG_REGENERATE"`
+	OrderRateLimit        int     `mapstructure:"ORDER_RATE_LIMIT"`
+	OrderRateWindow       string  `mapstructure:"ORDER_RATE_WINDOW"`
+	FXJAMPerUSD           float64 `mapstructure:"FX_JAM_PER_USD"`
+	// PaymentWebhookSecret is the key payment provider callbacks are signed
+	// with; empty refuses them
+	PaymentWebhookSecret string `mapstructure:"PAYMENT_WEBHOOK_SECRET"`
+	// WebhookMaxAttempts is how many times an outbound webhook delivery is
+	// tried before giving up; zero uses the default of 5
+	WebhookMaxAttempts int `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	// WalletDefaultCurrency is the currency of the wallet opened for each
+	// new user; empty means JAM
+	WalletDefaultCurrency string `mapstructure:"WALLET_DEFAULT_CURRENCY"`
+	// ProductViewWindow is how long repeat views by the same viewer count
+	// once; empty or zero disables view tracking
+	ProductViewWindow        string `mapstructure:"PRODUCT_VIEW_WINDOW"`
+	ProductViewFlushInterval string `mapstructure:"PRODUCT_VIEW_FLUSH_INTERVAL"`
 
 	// Parsed values
 	AllowedOriginsSlice []string
+	SIWEDomainsSlice    []string
+	SIWEURIsSlice       []string
+	TrustedProxiesSlice []string
 }
 
 // Load loads configuration from environment variables
@@ -98,8 +174,11 @@ func Load() *Config {
 		log.Println("The App is running in development env")
 	}
 
-	// Parse allowed origins into slice
+	// Parse allowed origins and SIWE domains into slices
 	cfg.AllowedOriginsSlice = allowedOriginSlice(cfg.AllowedOrigins)
+	cfg.SIWEDomainsSlice = splitList(cfg.SIWEDomain)
+	cfg.SIWEURIsSlice = splitList(cfg.SIWEAllowedURIs)
+	cfg.TrustedProxiesSlice = splitList(cfg.TrustedProxies)
 	log.Printf("[CONFIG] Loaded ALLOWED_ORIGINS: %s", cfg.AllowedOrigins)
 	log.Printf("[CONFIG] Parsed AllowedOriginsSlice: %v", cfg.AllowedOriginsSlice)
 
@@ -116,9 +195,16 @@ func loadEnvFromOS(cfg *Config) {
 	cfg.ServerWriteTimeout = os.Getenv("SERVER_WRITE_TIMEOUT")
 	cfg.ServerShutdownTimeout = os.Getenv("SERVER_SHUTDOWN_TIMEOUT")
 	cfg.AllowedOrigins = os.Getenv("ALLOWED_ORIGINS")
+	cfg.SlowRequestThreshold = os.Getenv("SLOW_REQUEST_THRESHOLD")
+	cfg.CORSAllowSchemeMismatch = getenvBool("CORS_ALLOW_SCHEME_MISMATCH")
+	cfg.CORSDebug = getenvBool("CORS_DEBUG")
+	cfg.TrustedProxies = os.Getenv("TRUSTED_PROXIES")
+	cfg.CookieDomain = os.Getenv("COOKIE_DOMAIN")
+	cfg.CookieSameSite = os.Getenv("COOKIE_SAMESITE")
 
 	// Database Configuration
 	cfg.DBConnectionString = os.Getenv("DB_CONNECTION_STRING")
+	cfg.DBReadConnectionString = os.Getenv("DB_READ_CONNECTION_STRING")
 	cfg.DBMaxConnections = getenvInt("DB_MAX_CONNECTIONS")
 	cfg.DBMaxIdleTime = os.Getenv("DB_MAX_IDLE_TIME")
 	cfg.DBMaxConnLifetime = os.Getenv("DB_MAX_CONN_LIFETIME")
@@ -133,9 +219,17 @@ func loadEnvFromOS(cfg *Config) {
 	// Authentication Configuration
 	cfg.SessionSecret = os.Getenv("SESSION_SECRET")
 	cfg.SessionDuration = os.Getenv("SESSION_DURATION")
+	cfg.SessionRenewWindow = os.Getenv("SESSION_RENEW_WINDOW")
+	cfg.SessionMaxLifetime = os.Getenv("SESSION_MAX_LIFETIME")
 	cfg.JWTSecret = os.Getenv("JWT_SECRET")
 	cfg.JWTExpiration = os.Getenv("JWT_EXPIRATION")
 	cfg.SIWEDomain = os.Getenv("SIWE_DOMAIN")
+	cfg.SIWEBindNonce = getenvBool("SIWE_BIND_NONCE")
+	cfg.SIWEAllowedURIs = os.Getenv("SIWE_ALLOWED_URIS")
+	cfg.SIWEMaxAge = os.Getenv("SIWE_MAX_AGE")
+	cfg.SIWEChainIDs = os.Getenv("SIWE_CHAIN_IDS")
+	cfg.AuthRateLimit = getenvInt("AUTH_RATE_LIMIT")
+	cfg.AuthRateWindow = os.Getenv("AUTH_RATE_WINDOW")
 
 	// Cache Configuration
 	cfg.CacheEnableL1 = getenvBool("CACHE_ENABLE_L1")
@@ -149,6 +243,14 @@ func loadEnvFromOS(cfg *Config) {
 	cfg.SupabaseKey = os.Getenv("SUPABASE_KEY")
 	cfg.SupabaseBucket = os.Getenv("SUPABASE_BUCKET")
 	cfg.StorageMaxFileSize = getenvInt64("STORAGE_MAX_FILE_SIZE")
+	cfg.StorageConvertWebP = getenvBool("STORAGE_CONVERT_WEBP")
+	cfg.StorageKeepOriginal = getenvBool("STORAGE_KEEP_ORIGINAL")
+	cfg.StorageMaxImageWidth = getenvInt("STORAGE_MAX_IMAGE_WIDTH")
+	cfg.StorageMaxImageHeight = getenvInt("STORAGE_MAX_IMAGE_HEIGHT")
+	cfg.StorageMinImageWidth = getenvInt("STORAGE_MIN_IMAGE_WIDTH")
+	cfg.StorageMinImageHeight = getenvInt("STORAGE_MIN_IMAGE_HEIGHT")
+	cfg.StorageMaxAspectRatio = getenvFloat("STORAGE_MAX_ASPECT_RATIO")
+	cfg.StorageThumbnailWidths = os.Getenv("STORAGE_THUMBNAIL_WIDTHS")
 
 	// S3-Compatible Storage Configuration
 	cfg.SupabaseS3AccessKeyID = os.Getenv("SUPABASE_S3_ACCESS_KEY_ID")
@@ -158,9 +260,27 @@ func loadEnvFromOS(cfg *Config) {
 	cfg.StorageMaxFileSize = getenvInt64("STORAGE_MAX_FILE_SIZE")
 	// Blockchain Configuration
 	cfg.RPCURL = os.Getenv("RPC_URL")
+	cfg.Chains = os.Getenv("CHAINS")
+	cfg.RPCMinConfirmations = getenvOptionalInt("RPC_MIN_CONFIRMATIONS")
+	cfg.PlatformDepositAddresses = os.Getenv("PLATFORM_DEPOSIT_ADDRESSES")
+
+	// Marketplace Configuration
+	cfg.PlatformFeePercent = getenvFloat("PLATFORM_FEE_PERCENT")
+	cfg.ProductSlugRegenerate = getenvBool("PRODUCT_SLUG_REGENERATE")
+	cfg.OrderRateLimit = getenvInt("ORDER_RATE_LIMIT")
+	cfg.OrderRateWindow = os.Getenv("ORDER_RATE_WINDOW")
+	cfg.FXJAMPerUSD = getenvFloat("FX_JAM_PER_USD")
+	cfg.WalletDefaultCurrency = os.Getenv("WALLET_DEFAULT_CURRENCY")
+	cfg.PaymentWebhookSecret = os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	cfg.WebhookMaxAttempts = getenvInt("WEBHOOK_MAX_ATTEMPTS")
+	cfg.ProductViewWindow = os.Getenv("PRODUCT_VIEW_WINDOW")
+	cfg.ProductViewFlushInterval = os.Getenv("PRODUCT_VIEW_FLUSH_INTERVAL")
 
-	// Parse allowed origins into slice
+	// Parse allowed origins and SIWE domains into slices
 	cfg.AllowedOriginsSlice = allowedOriginSlice(cfg.AllowedOrigins)
+	cfg.SIWEDomainsSlice = splitList(cfg.SIWEDomain)
+	cfg.SIWEURIsSlice = splitList(cfg.SIWEAllowedURIs)
+	cfg.TrustedProxiesSlice = splitList(cfg.TrustedProxies)
 }
 
 func getenvInt(key string) int {
@@ -175,6 +295,20 @@ func getenvInt(key string) int {
 	return i
 }
 
+// getenvOptionalInt is like getenvInt but returns nil when the variable is
+// unset or invalid, so an explicit zero can be told apart from no value
+func getenvOptionalInt(key string) *int {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
 func getenvInt64(key string) int64 {
 	v := os.Getenv(key)
 	if v == "" {
@@ -187,6 +321,18 @@ func getenvInt64(key string) int64 {
 	return i
 }
 
+func getenvFloat(key string) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
 func getenvBool(key string) bool {
 	v := os.Getenv(key)
 	if v == "" {
@@ -204,9 +350,14 @@ func allowedOriginSlice(origins string) []string {
 		log.Println("[CONFIG] WARNING: ALLOWED_ORIGINS is empty! CORS will not work properly.")
 		return []string{}
 	}
+	return splitList(origins)
+}
+
+// splitList parses a comma-separated value, dropping blank entries
+func splitList(value string) []string {
 	var result []string
-	for _, origin := range strings.Split(origins, ",") {
-		trimmed := strings.TrimSpace(origin)
+	for _, item := range strings.Split(value, ",") {
+		trimmed := strings.TrimSpace(item)
 		if trimmed != "" {
 			result = append(result, trimmed)
 		}
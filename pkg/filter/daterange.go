@@ -0,0 +1,104 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameter names read by DateRangeFromQuery and ParseDateRange
+const (
+	CreatedAfterParam  = "created_after"
+	CreatedBeforeParam = "created_before"
+)
+
+// ErrInvalidDateRange is returned when a date range is malformed or empty
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// DateRangeFilter bounds a timestamp column. After is inclusive and Before is
+// exclusive; a nil bound is not applied.
+type DateRangeFilter struct {
+	After  *time.Time
+	Before *time.Time
+}
+
+// ParseDateRange parses the created_after and created_before bounds. Each is
+// an RFC3339 timestamp or a YYYY-MM-DD date, meaning midnight UTC; an empty
+// string leaves that bound open.
+func ParseDateRange(after, before string) (DateRangeFilter, error) {
+	return parseDateRange(CreatedAfterParam, after, CreatedBeforeParam, before)
+}
+
+// DateRangeFromQuery reads the created_after and created_before query parameters
+func DateRangeFromQuery(ctx *gin.Context) (DateRangeFilter, error) {
+	return DateRangeFromParams(ctx, CreatedAfterParam, CreatedBeforeParam)
+}
+
+// DateRangeFromParams reads a date range from the named query parameters,
+// for endpoints whose bounds are not called created_after and created_before
+func DateRangeFromParams(ctx *gin.Context, afterParam, beforeParam string) (DateRangeFilter, error) {
+	return parseDateRange(afterParam, ctx.Query(afterParam), beforeParam, ctx.Query(beforeParam))
+}
+
+func parseDateRange(afterName, after, beforeName, before string) (DateRangeFilter, error) {
+	var f DateRangeFilter
+	var err error
+	if f.After, err = parseBound(afterName, after); err != nil {
+		return DateRangeFilter{}, err
+	}
+	if f.Before, err = parseBound(beforeName, before); err != nil {
+		return DateRangeFilter{}, err
+	}
+	if f.Validate() != nil {
+		return DateRangeFilter{}, fmt.Errorf("%w: %s must be before %s", ErrInvalidDateRange, afterName, beforeName)
+	}
+	return f, nil
+}
+
+func parseBound(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, value); err != nil {
+			return nil, fmt.Errorf("%w: %s must be an RFC3339 timestamp or YYYY-MM-DD date", ErrInvalidDateRange, name)
+		}
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// Validate rejects ranges whose lower bound is not before the upper bound
+func (f DateRangeFilter) Validate() error {
+	if f.After != nil && f.Before != nil && !f.After.Before(*f.Before) {
+		return fmt.Errorf("%w: %s must be before %s", ErrInvalidDateRange, CreatedAfterParam, CreatedBeforeParam)
+	}
+	return nil
+}
+
+// IsZero reports whether neither bound is set
+func (f DateRangeFilter) IsZero() bool {
+	return f.After == nil && f.Before == nil
+}
+
+// SQL builds the WHERE conditions for column, numbering placeholders from
+// argIndex. The fragment starts with " AND " so it can be appended to an
+// existing clause, and is empty when no bound is set. The returned args are
+// in placeholder order; the next free index is argIndex+len(args).
+func (f DateRangeFilter) SQL(column string, argIndex int) (string, []interface{}) {
+	var b strings.Builder
+	var args []interface{}
+	if f.After != nil {
+		fmt.Fprintf(&b, " AND %s >= $%d", column, argIndex+len(args))
+		args = append(args, *f.After)
+	}
+	if f.Before != nil {
+		fmt.Fprintf(&b, " AND %s < $%d", column, argIndex+len(args))
+		args = append(args, *f.Before)
+	}
+	return b.String(), args
+}
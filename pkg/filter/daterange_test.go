@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		after      string
+		before     string
+		wantAfter  string
+		wantBefore string
+		wantErr    bool
+	}{
+		{"No bounds", "", "", "", "", false},
+		{"After only", "2025-10-01T00:00:00Z", "", "2025-10-01T00:00:00Z", "", false},
+		{"Before only", "", "2025-10-31T00:00:00Z", "", "2025-10-31T00:00:00Z", false},
+		{"Both bounds", "2025-10-01T00:00:00Z", "2025-10-31T00:00:00Z", "2025-10-01T00:00:00Z", "2025-10-31T00:00:00Z", false},
+		{"Offset normalized to UTC", "2025-10-01T00:00:00-05:00", "", "2025-10-01T05:00:00Z", "", false},
+		{"Date is midnight UTC", "2025-10-01", "2025-10-02", "2025-10-01T00:00:00Z", "2025-10-02T00:00:00Z", false},
+		{"Other date formats rejected", "01/10/2025", "", "", "", true},
+		{"Garbage rejected", "", "yesterday", "", "", true},
+		{"After later than before rejected", "2025-10-31T00:00:00Z", "2025-10-01T00:00:00Z", "", "", true},
+		{"Equal bounds rejected", "2025-10-01T00:00:00Z", "2025-10-01T00:00:00Z", "", "", true},
+	}
+
+	format := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseDateRange(tt.after, tt.before)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDateRange) {
+					t.Fatalf("ParseDateRange() error = %v, want %v", err, ErrInvalidDateRange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDateRange() error = %v", err)
+			}
+			if got := format(f.After); got != tt.wantAfter {
+				t.Errorf("After = %q, want %q", got, tt.wantAfter)
+			}
+			if got := format(f.Before); got != tt.wantBefore {
+				t.Errorf("Before = %q, want %q", got, tt.wantBefore)
+			}
+		})
+	}
+}
+
+func TestDateRangeFilter_SQL(t *testing.T) {
+	after := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   DateRangeFilter
+		argIndex int
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"No bounds", DateRangeFilter{}, 1, "", nil},
+		{"After only", DateRangeFilter{After: &after}, 1, " AND created_at >= $1", []interface{}{after}},
+		{"Before only", DateRangeFilter{Before: &before}, 3, " AND created_at < $3", []interface{}{before}},
+		{"Both bounds continue numbering", DateRangeFilter{After: &after, Before: &before}, 4,
+			" AND created_at >= $4 AND created_at < $5", []interface{}{after, before}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.filter.SQL("created_at", tt.argIndex)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("SQL() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package jwtauth issues and verifies the signed access tokens handed out at
+// sign-in. Services that cannot reach the session store can check a token
+// with the shared secret alone.
+package jwtauth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, tampered with,
+// signed with another key or expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// DefaultTTL is how long tokens last when no expiration is configured
+const DefaultTTL = time.Hour
+
+// MinSecretLength is the shortest secret accepted, matching the HS256 key
+// size
+const MinSecretLength = 32
+
+// placeholderSecrets are fragments of the sample values found in example
+// configs, which must never sign real tokens
+var placeholderSecrets = []string{"change-me", "changeme", "replace-me", "your-secret"}
+
+// Claims are the contents of an access token
+type Claims struct {
+	UserID        string `json:"user_id"`
+	WalletAddress string `json:"wallet_address"`
+	Role          string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Signer signs and verifies HS256 tokens with a shared secret
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner creates a signer. A ttl of zero or less uses DefaultTTL. The
+// secret must be at least MinSecretLength bytes and not a placeholder.
+func NewSigner(secret string, ttl time.Duration) (*Signer, error) {
+	if err := checkSecret(secret); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Signer{secret: []byte(secret), ttl: ttl, now: time.Now}, nil
+}
+
+// Sign issues a token for the user, returning it with its expiry
+func (s *Signer) Sign(userID, walletAddress, role string) (string, time.Time, error) {
+	now := s.now().UTC()
+	expiresAt := now.Add(s.ttl)
+	claims := Claims{
+		UserID:        userID,
+		WalletAddress: walletAddress,
+		Role:          role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// Verify checks the token's signature and expiry and returns its claims
+func (s *Signer) Verify(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if claims.UserID == "" {
+		return nil, fmt.Errorf("%w: missing user ID", ErrInvalidToken)
+	}
+	return &claims, nil
+}
+
+// checkSecret rejects secrets too short or too well known to be safe
+func checkSecret(secret string) error {
+	if secret == "" {
+		return errors.New("JWT secret must not be empty")
+	}
+	lower := strings.ToLower(secret)
+	for _, placeholder := range placeholderSecrets {
+		if strings.Contains(lower, placeholder) {
+			return errors.New("JWT secret is a placeholder value; generate a random one")
+		}
+	}
+	if len(secret) < MinSecretLength {
+		return fmt.Errorf("JWT secret must be at least %d bytes", MinSecretLength)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package jwtauth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testSecret is long enough for NewSigner
+const testSecret = "test-secret-that-is-long-enough!"
+
+func newTestSigner(t *testing.T, secret string) *Signer {
+	t.Helper()
+	s, err := NewSigner(secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	s := newTestSigner(t, testSecret)
+
+	token, expiresAt, err := s.Sign("user-1", "0xabc", "seller")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("expiresAt in %v, want about 1h", d)
+	}
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.UserID != "user-1" || claims.WalletAddress != "0xabc" || claims.Role != "seller" {
+		t.Errorf("claims = %+v, want user-1/0xabc/seller", claims)
+	}
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	s := newTestSigner(t, testSecret)
+	token, _, err := s.Sign("user-1", "0xabc", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap in a payload claiming the admin role, keeping the signature
+	forged, _, err := newTestSigner(t, testSecret).Sign("user-1", "0xabc", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+	tampered := parts[0] + "." + forgedParts[1] + "." + parts[2]
+
+	otherKey, _, err := newTestSigner(t, "another-secret-that-is-long-enough").Sign("user-1", "0xabc", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		UserID:           "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, bad := range map[string]string{
+		"tampered payload": tampered,
+		"other key":        otherKey,
+		"alg none":         unsigned,
+		"garbage":          "not.a.token",
+	} {
+		if _, err := s.Verify(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%s) error = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestSigner_RejectsExpired(t *testing.T) {
+	s := newTestSigner(t, testSecret)
+	token, _, err := s.Sign("user-1", "0xabc", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = s.Verify(token)
+	if !errors.Is(err, ErrInvalidToken) || !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("Verify() error = %v, want an expired-token error", err)
+	}
+}
+
+func TestNewSigner_RequiresSecret(t *testing.T) {
+	for name, secret := range map[string]string{
+		"empty":            "",
+		"short":            "too-short",
+		"placeholder":      "change-me-in-production",
+		"long placeholder": "CHANGE-ME-in-production-0123456789",
+	} {
+		if _, err := NewSigner(secret, time.Hour); err == nil {
+			t.Errorf("NewSigner() with a %s secret succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package lifecycle runs background workers under a shared context so they
+// can be stopped together on shutdown.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Manager starts background workers and stops them on Shutdown
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// New creates a manager whose workers run until parent is cancelled or
+// Shutdown is called
+func New(parent context.Context) *Manager {
+	ctx, cancel := context.WithCancel(parent)
+	return &Manager{ctx: ctx, cancel: cancel, running: make(map[string]int)}
+}
+
+// Go runs worker in its own goroutine with the manager's context. The worker
+// must return once the context is cancelled. name identifies it in Shutdown
+// errors.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.mu.Lock()
+	m.running[name]++
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			if m.running[name]--; m.running[name] == 0 {
+				delete(m.running, name)
+			}
+			m.mu.Unlock()
+		}()
+		worker(m.ctx)
+	}()
+}
+
+// Shutdown cancels the workers' context and waits for them to return. If ctx
+// ends first it returns an error naming the workers still running.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers did not stop: %s: %w", strings.Join(m.Running(), ", "), ctx.Err())
+	}
+}
+
+// Running returns the names of the workers that have not returned yet
+func (m *Manager) Running() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
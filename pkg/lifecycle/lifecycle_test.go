@@ -0,0 +1,62 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdown_CancelsAndWaitsForWorkers(t *testing.T) {
+	m := New(context.Background())
+
+	var stopped atomic.Int32
+	started := make(chan struct{}, 2)
+	for _, name := range []string{"flusher", "pruner"} {
+		m.Go(name, func(ctx context.Context) {
+			started <- struct{}{}
+			<-ctx.Done()
+			// Simulate cleanup that takes a moment after cancellation
+			time.Sleep(20 * time.Millisecond)
+			stopped.Add(1)
+		})
+	}
+	<-started
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := stopped.Load(); got != 2 {
+		t.Errorf("Shutdown() returned with %d of 2 workers stopped", got)
+	}
+	if running := m.Running(); len(running) != 0 {
+		t.Errorf("Running() = %v after shutdown", running)
+	}
+}
+
+func TestShutdown_TimesOutOnStuckWorker(t *testing.T) {
+	m := New(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	m.Go("stuck", func(ctx context.Context) {
+		<-release
+	})
+	m.Go("well-behaved", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want DeadlineExceeded", err)
+	}
+	if running := m.Running(); len(running) != 1 || running[0] != "stuck" {
+		t.Errorf("Running() = %v, want [stuck]", running)
+	}
+}
@@ -27,6 +27,16 @@ func New() *Logger {
 	}
 }
 
+// FromZerolog wraps an existing zerolog logger
+func FromZerolog(l zerolog.Logger) *Logger {
+	return &Logger{logger: l}
+}
+
+// Zerolog returns the underlying zerolog logger for structured events
+func (l *Logger) Zerolog() zerolog.Logger {
+	return l.logger
+}
+
 // Info logs an info message
 func (l *Logger) Info(msg string) {
 	l.logger.Info().Msg(msg)
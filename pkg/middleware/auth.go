@@ -1,42 +1,75 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
 	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
+// SessionToken returns the session ID presented with the request, read from
+// an "Authorization: Bearer" header for mobile and server-to-server clients
+// or else from the session_id cookie. A bearer JWT access token is not a
+// session ID, so the cookie is used instead when one is sent.
+func SessionToken(ctx *gin.Context) (string, bool) {
+	if header := ctx.GetHeader("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			if token = strings.TrimSpace(token); token != "" && !isJWT(token) {
+				return token, true
+			}
+		}
+	}
+	if sessionID, err := ctx.Cookie("session_id"); err == nil && sessionID != "" {
+		return sessionID, true
+	}
+	return "", false
+}
+
+// isJWT reports whether a bearer token has the three dot-separated parts of
+// a JWT. Session IDs are hex and never contain a dot.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
 // AuthMiddleware creates a middleware that validates session authentication
 func AuthMiddleware(authUseCase *usecase.AuthUseCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Get session ID from cookie
-		sessionID, err := ctx.Cookie("session_id")
-		if err != nil {
-			log.Debug().Msg("no session cookie found")
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
-			ctx.Abort()
+		sessionID, ok := SessionToken(ctx)
+		if !ok {
+			log.Debug().Msg("no session token found")
+			AbortUnauthenticated(ctx, "authentication required")
 			return
 		}
 
 		// Validate session
 		session, err := authUseCase.ValidateSession(ctx.Request.Context(), sessionID)
-		if err != nil {
-			log.Debug().Err(err).Str("session_id", sessionID).Msg("invalid session")
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired session"})
+		if errors.Is(err, auth.ErrStoreUnavailable) {
+			log.Warn().Err(err).Str("request_id", ctx.GetString("request_id")).Msg("session store degraded")
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "authentication temporarily unavailable"})
 			ctx.Abort()
 			return
 		}
+		if err != nil {
+			log.Debug().Err(err).Str("request_id", ctx.GetString("request_id")).Msg("invalid session")
+			AbortUnauthenticated(ctx, "invalid or expired session")
+			return
+		}
 
 		// Set user info in context
 		ctx.Set("user_id", session.UserID)
 		ctx.Set("wallet_address", session.WalletAddress)
 		ctx.Set("session_id", session.ID)
+		ctx.Set("user_role", session.Role)
 
 		log.Debug().
 			Str("user_id", session.UserID).
 			Str("wallet", session.WalletAddress).
+			Str("request_id", ctx.GetString("request_id")).
 			Msg("request authenticated")
 
 		ctx.Next()
@@ -47,9 +80,8 @@ func AuthMiddleware(authUseCase *usecase.AuthUseCase) gin.HandlerFunc {
 // If authenticated, it sets user context; otherwise, it continues without error
 func OptionalAuthMiddleware(authUseCase *usecase.AuthUseCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Get session ID from cookie
-		sessionID, err := ctx.Cookie("session_id")
-		if err != nil {
+		sessionID, ok := SessionToken(ctx)
+		if !ok {
 			// No session, continue without auth
 			ctx.Next()
 			return
@@ -67,6 +99,7 @@ func OptionalAuthMiddleware(authUseCase *usecase.AuthUseCase) gin.HandlerFunc {
 		ctx.Set("user_id", session.UserID)
 		ctx.Set("wallet_address", session.WalletAddress)
 		ctx.Set("session_id", session.ID)
+		ctx.Set("user_role", session.Role)
 
 		ctx.Next()
 	}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	redisrepo "github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSessionToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		header    string
+		cookie    string
+		wantToken string
+		wantOK    bool
+	}{
+		{"Header only", "Bearer header-token", "", "header-token", true},
+		{"Cookie only", "", "cookie-token", "cookie-token", true},
+		{"Both present, header wins", "Bearer header-token", "cookie-token", "header-token", true},
+		{"Neither", "", "", "", false},
+		{"Scheme is case-insensitive", "bearer header-token", "", "header-token", true},
+		{"Other scheme falls back to cookie", "Basic dXNlcjpwYXNz", "cookie-token", "cookie-token", true},
+		{"Empty bearer token", "Bearer ", "", "", false},
+		{"JWT bearer falls back to cookie", "Bearer aaa.bbb.ccc", "cookie-token", "cookie-token", true},
+		{"JWT bearer is not a session", "Bearer aaa.bbb.ccc", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session_id", Value: tt.cookie})
+			}
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = req
+
+			token, ok := SessionToken(ctx)
+			if token != tt.wantToken || ok != tt.wantOK {
+				t.Errorf("SessionToken() = %q, %v, want %q, %v", token, ok, tt.wantToken, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_BearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	sessions := redisrepo.NewSessionRepository(client)
+	authUseCase := usecase.NewAuthUseCase(sessions, usecase.NewUserUseCase(stubUserRepo{}), usecase.AuthConfig{Domains: []string{"localhost:3000"}})
+
+	s := auth.NewSession("user-1", "0x0000000000000000000000000000000000000001", user.RoleCustomer, time.Hour)
+	if err := sessions.SaveSession(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(authUseCase), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("user_id"))
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		cookie     string
+		wantStatus int
+	}{
+		{"Valid bearer token", "Bearer " + s.ID, "", http.StatusOK},
+		{"Invalid bearer token wins over a valid cookie", "Bearer not-a-session", s.ID, http.StatusUnauthorized},
+		{"No token", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session_id", Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != "user-1" {
+				t.Errorf("user_id = %q, want %q", w.Body.String(), "user-1")
+			}
+		})
+	}
+}
@@ -1,38 +1,78 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 )
 
-// SetupCORS sets up CORS middleware for the given gin engine.
-func SetupCORS(allowedOrigins []string) gin.HandlerFunc {
+// CORSConfig controls which origins may make cross-origin requests
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as "https://app.caribex.com",
+	// subdomain patterns such as "https://*.caribex.com", or "*" for any
+	// origin without credentials
+	AllowedOrigins []string
+	// AllowSchemeMismatch also accepts an origin that differs from an
+	// allowed one only in its http/https scheme. Such origins are not sent
+	// Access-Control-Allow-Credentials.
+	AllowSchemeMismatch bool
+	// Debug logs every request's origin, headers and match result. Without
+	// it only denied origins are logged.
+	Debug bool
+	// Logger receives the CORS logs
+	Logger zerolog.Logger
+}
+
+// originMatch is how a request origin matched the allowed origins
+type originMatch int
+
+const (
+	originDenied originMatch = iota
+	// originAny matched "*": any origin, never with credentials
+	originAny
+	// originLenient matched only once the scheme was ignored
+	originLenient
+	// originExact matched an allowed origin or subdomain pattern exactly
+	originExact
+)
+
+// SetupCORS sets up CORS middleware for the given gin engine. Credentials
+// are only allowed for origins that exactly match an allowed origin or
+// pattern, scheme included.
+func SetupCORS(cfg CORSConfig) gin.HandlerFunc {
+	allowedOrigins := cfg.AllowedOrigins
+	logger := cfg.Logger
 	// Log once when middleware is created
 	if len(allowedOrigins) == 0 {
-		log.Println("[CORS] ERROR: No allowed origins configured! Check your ALLOWED_ORIGINS env var.")
+		logger.Error().Msg("[CORS] No allowed origins configured! Check your ALLOWED_ORIGINS env var.")
 	} else {
-		log.Printf("[CORS] Middleware initialized with allowed origins: %v", allowedOrigins)
+		logger.Info().Strs("allowed_origins", allowedOrigins).Bool("debug", cfg.Debug).Msg("[CORS] Middleware initialized")
 	}
 
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin") // exact string browsers send
-		requestID, _ := c.Get("request_id")
+		requestID := c.GetString("request_id")
 
-		// Rich request-level logging
-		log.Printf("[CORS] %s Request from origin='%s' method=%s path=%s remote_ip=%s request_id=%v",
-			time.Now().Format(time.RFC3339), origin, c.Request.Method, c.Request.URL.Path, c.ClientIP(), requestID)
-
-		// Snapshot a few headers useful for debugging
-		log.Printf("[CORS] Headers: User-Agent='%s' Referer='%s' Content-Type='%s'",
-			c.GetHeader("User-Agent"), c.GetHeader("Referer"), c.GetHeader("Content-Type"))
+		// Rich request-level logging, including a few headers useful for
+		// debugging, only in debug mode as it runs on every request
+		if cfg.Debug {
+			logger.Info().
+				Str("origin", origin).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Str("remote_ip", c.ClientIP()).
+				Str("request_id", requestID).
+				Str("user_agent", c.GetHeader("User-Agent")).
+				Str("referer", c.GetHeader("Referer")).
+				Str("content_type", c.GetHeader("Content-Type")).
+				Msg("[CORS] Request")
+		}
 
 		// Always set common headers first
 		c.Header("Vary", "Origin")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Request-ID")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Request-ID, Idempotency-Key")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, X-Request-ID")
 		c.Header("Access-Control-Max-Age", "600") // seconds
@@ -40,27 +80,34 @@ func SetupCORS(allowedOrigins []string) gin.HandlerFunc {
 		// Check if origin is in the allowed list
 		isAllowed := false
 		if origin != "" {
-			for _, allowed := range allowedOrigins {
-				log.Printf("[CORS] Comparing origin '%s' with allowed '%s'", origin, allowed)
-				// allow exact match or scheme-insensitive match (strip scheme)
-				if origin == allowed || stripScheme(origin) == stripScheme(allowed) {
-					isAllowed = true
-					c.Header("Access-Control-Allow-Origin", origin)
-					// Only set credentials when origin is explicit
-					c.Header("Access-Control-Allow-Credentials", "true")
-					log.Printf("[CORS] ✓ Origin %s is ALLOWED (matched %s)", origin, allowed)
-					break
-				}
+			match, allowed := matchOrigin(origin, allowedOrigins, cfg.AllowSchemeMismatch)
+			switch match {
+			case originAny:
+				c.Header("Access-Control-Allow-Origin", "*")
+			case originLenient:
+				c.Header("Access-Control-Allow-Origin", origin)
+			case originExact:
+				c.Header("Access-Control-Allow-Origin", origin)
+				// Only set credentials when origin is explicit
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			isAllowed = match != originDenied
+			if isAllowed && cfg.Debug {
+				logger.Info().Str("origin", origin).Str("matched", allowed).Msg("[CORS] Origin allowed")
 			}
 		}
 
 		if !isAllowed && origin != "" {
-			log.Printf("[CORS] ✗ Origin '%s' is NOT in allowed list: %v", origin, allowedOrigins)
+			logger.Warn().
+				Str("origin", origin).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Str("request_id", requestID).
+				Msg("[CORS] Origin not in allowed list")
 		}
 
 		// Handle preflight
 		if c.Request.Method == http.MethodOptions {
-			log.Printf("[CORS] Handling OPTIONS preflight request origin=%s allowed=%t", origin, isAllowed)
 			if isAllowed {
 				c.AbortWithStatus(http.StatusNoContent)
 			} else {
@@ -74,6 +121,52 @@ func SetupCORS(allowedOrigins []string) gin.HandlerFunc {
 	}
 }
 
+// matchOrigin finds the best match for origin among the allowed origins,
+// returning it with the entry it matched. An exact match wins over the
+// others.
+func matchOrigin(origin string, allowedOrigins []string, lenient bool) (originMatch, string) {
+	best, bestEntry := originDenied, ""
+	for _, allowed := range allowedOrigins {
+		allowed = strings.TrimSuffix(strings.TrimSpace(allowed), "/")
+		var m originMatch
+		switch {
+		case allowed == "*":
+			m = originAny
+		case originMatches(origin, allowed):
+			m = originExact
+		case lenient && originMatches(stripScheme(origin), stripScheme(allowed)):
+			m = originLenient
+		}
+		if m == originExact {
+			return m, allowed
+		}
+		if m > best {
+			best, bestEntry = m, allowed
+		}
+	}
+	return best, bestEntry
+}
+
+// originMatches reports whether origin equals pattern or, for a pattern such
+// as "https://*.example.com", is a subdomain of it with the same scheme and
+// port
+func originMatches(origin, pattern string) bool {
+	if origin == pattern {
+		return true
+	}
+	scheme, host, ok := strings.Cut(pattern, "*.")
+	if !ok || (scheme != "" && !strings.HasSuffix(scheme, "://")) {
+		return false
+	}
+	sub, ok := strings.CutPrefix(origin, scheme)
+	if !ok {
+		return false
+	}
+	label, ok := strings.CutSuffix(sub, "."+host)
+	// The wildcard covers one or more whole labels, never the scheme or a port
+	return ok && label != "" && !strings.ContainsAny(label, "/:@")
+}
+
 // stripScheme removes http(s) scheme and trailing slashes for simple comparison
 func stripScheme(u string) string {
 	u = strings.TrimSpace(u)
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func TestSetupCORS_Origins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		cfg             CORSConfig
+		origin          string
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{"Exact match", CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, "https://app.example.com", "https://app.example.com", true},
+		{"Trailing slash in config", CORSConfig{AllowedOrigins: []string{"https://app.example.com/"}}, "https://app.example.com", "https://app.example.com", true},
+		{"Scheme mismatch rejected", CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, "http://app.example.com", "", false},
+		{"Scheme mismatch allowed without credentials", CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowSchemeMismatch: true}, "http://app.example.com", "http://app.example.com", false},
+		{"Port mismatch rejected", CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, "https://app.example.com:8443", "", false},
+		{"Wildcard subdomain", CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://shop.example.com", "https://shop.example.com", true},
+		{"Wildcard nested subdomain", CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://a.b.example.com", "https://a.b.example.com", true},
+		{"Wildcard does not match the apex", CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://example.com", "", false},
+		{"Wildcard does not match a lookalike", CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "https://evilexample.com", "", false},
+		{"Wildcard scheme mismatch rejected", CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}, "http://shop.example.com", "", false},
+		{"Any origin without credentials", CORSConfig{AllowedOrigins: []string{"*"}}, "https://elsewhere.test", "*", false},
+		{"Exact match wins over any", CORSConfig{AllowedOrigins: []string{"*", "https://app.example.com"}}, "https://app.example.com", "https://app.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SetupCORS(tt.cfg))
+			router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			req.Header.Set("Origin", tt.origin)
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("credentials allowed = %v, want %v", got, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestSetupCORS_PreflightFromDeniedOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SetupCORS(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodOptions, "/products", nil)
+	req.Header.Set("Origin", "http://app.example.com")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestSetupCORS_Logging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(debug bool, origin string) string {
+		var buf bytes.Buffer
+		router := gin.New()
+		router.Use(SetupCORS(CORSConfig{
+			AllowedOrigins: []string{"https://app.example.com"},
+			Debug:          debug,
+			Logger:         zerolog.New(&buf),
+		}))
+		router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		// Drop the one-time initialization log
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Referer", "https://app.example.com/private/page")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return buf.String()
+	}
+
+	if logs := send(false, "https://app.example.com"); logs != "" {
+		t.Errorf("allowed request logged without debug: %s", logs)
+	}
+	logs := send(false, "https://evil.example")
+	if !strings.Contains(logs, `"level":"warn"`) || !strings.Contains(logs, "https://evil.example") {
+		t.Errorf("denied origin not logged at warn: %s", logs)
+	}
+	if strings.Contains(logs, "/private/page") {
+		t.Errorf("Referer logged without debug: %s", logs)
+	}
+	if logs := send(true, "https://app.example.com"); !strings.Contains(logs, "/private/page") {
+		t.Errorf("debug mode did not log the request: %s", logs)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned alongside the message in JSON error responses
+const (
+	CodeNotFound         = "NOT_FOUND"
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	// CodeUnauthenticated accompanies 401s: no session, or an invalid one
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	// CodeForbidden accompanies 403s: the caller is known but lacks the role
+	// or ownership the action needs
+	CodeForbidden = "FORBIDDEN"
+	// CodeRateLimited accompanies 429s from RateLimit
+	CodeRateLimited = "RATE_LIMITED"
+)
+
+// AbortWithError aborts the request with the standard JSON error envelope
+func AbortWithError(ctx *gin.Context, status int, code, message string) {
+	ctx.AbortWithStatusJSON(status, gin.H{
+		"error": message,
+		"code":  code,
+	})
+}
+
+// AbortUnauthenticated rejects a request that has no valid session
+func AbortUnauthenticated(ctx *gin.Context, message string) {
+	AbortWithError(ctx, http.StatusUnauthorized, CodeUnauthenticated, message)
+}
+
+// AbortForbidden rejects an authenticated request lacking permission
+func AbortForbidden(ctx *gin.Context, message string) {
+	AbortWithError(ctx, http.StatusForbidden, CodeForbidden, message)
+}
+
+// RegisterFallbackHandlers makes unknown routes and unsupported methods
+// answer with the JSON error envelope instead of gin's plain-text defaults
+func RegisterFallbackHandlers(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+
+	router.NoRoute(func(ctx *gin.Context) {
+		AbortWithError(ctx, http.StatusNotFound, CodeNotFound, "route not found")
+	})
+	router.NoMethod(func(ctx *gin.Context) {
+		AbortWithError(ctx, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+	})
+}
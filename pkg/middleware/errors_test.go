@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterFallbackHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/products", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"products": []string{}})
+	})
+	RegisterFallbackHandlers(router)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"Unknown path", http.MethodGet, "/v1/does-not-exist", http.StatusNotFound, CodeNotFound},
+		{"Wrong method", http.MethodDelete, "/v1/products", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Content-Type = %q, want JSON", ct)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if body["code"] != tt.wantCode || body["error"] == "" {
+				t.Errorf("body = %v, want code %q with an error message", body, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedProxies decides whether a request's forwarding headers can be
+// believed. Only a proxy whose address is listed may speak for the client;
+// anyone else could set X-Forwarded-Proto themselves. A nil *TrustedProxies
+// trusts no one.
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// NewTrustedProxies parses proxy addresses given as IPs or CIDR ranges
+func NewTrustedProxies(proxies []string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			t.nets = append(t.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		t.nets = append(t.nets, ipNet)
+	}
+	return t, nil
+}
+
+// Trusts reports whether the request came directly from a trusted proxy
+func (t *TrustedProxies) Trusts(ctx *gin.Context) bool {
+	if t == nil {
+		return false
+	}
+	ip := net.ParseIP(ctx.RemoteIP())
+	if ip == nil {
+		return false
+	}
+	for _, n := range t.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHTTPS reports whether the client reached us over TLS, either directly or
+// through a trusted proxy that sent X-Forwarded-Proto: https. When proxies
+// are chained the header lists one protocol per hop; the first is the
+// client's.
+func (t *TrustedProxies) IsHTTPS(ctx *gin.Context) bool {
+	if ctx.Request.TLS != nil {
+		return true
+	}
+	if !t.Trusts(ctx) {
+		return false
+	}
+	proto, _, _ := strings.Cut(ctx.GetHeader("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrustedProxies_IsHTTPS(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.10 ", "::1"})
+	if err != nil {
+		t.Fatalf("NewTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    *TrustedProxies
+		remoteAddr string
+		proto      string
+		tls        bool
+		want       bool
+	}{
+		{"trusted CIDR", proxies, "10.4.5.6:1234", "https", false, true},
+		{"trusted single IP", proxies, "192.168.1.10:1234", "HTTPS", false, true},
+		{"trusted IPv6", proxies, "[::1]:1234", "https", false, true},
+		{"first hop of chained proxies", proxies, "10.4.5.6:1234", "https, http", false, true},
+		{"forwarded http", proxies, "10.4.5.6:1234", "http", false, false},
+		{"untrusted source", proxies, "192.168.1.11:1234", "https", false, false},
+		{"nil trusts no one", nil, "10.4.5.6:1234", "https", false, false},
+		{"direct TLS", nil, "203.0.113.7:1234", "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx.Request.RemoteAddr = tt.remoteAddr
+			if tt.proto != "" {
+				ctx.Request.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				ctx.Request.TLS = &tls.ConnectionState{}
+			}
+			if got := tt.proxies.IsHTTPS(ctx); got != tt.want {
+				t.Errorf("IsHTTPS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTrustedProxies_RejectsInvalid(t *testing.T) {
+	for _, p := range []string{"proxy.internal", "10.0.0.0/33"} {
+		if _, err := NewTrustedProxies([]string{p}); err == nil {
+			t.Errorf("NewTrustedProxies(%q) error = nil, want error", p)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/pkg/jwtauth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// JWTAuth authenticates requests by an access token in the
+// "Authorization: Bearer" header, checking only its signature and expiry so
+// no session store is needed. It sets the same context keys as
+// AuthMiddleware apart from session_id. Routes opt into it in place of
+// AuthMiddleware; a token revoked by logout, or issued before the user's role
+// changed, stays valid with its old role until it expires.
+func JWTAuth(secret string) gin.HandlerFunc {
+	signer, err := jwtauth.NewSigner(secret, 0)
+	if err != nil {
+		// Without a secret no token can be trusted
+		log.Error().Err(err).Msg("JWT authentication is not configured")
+	}
+
+	return func(ctx *gin.Context) {
+		scheme, token, found := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || signer == nil {
+			AbortUnauthenticated(ctx, "authentication required")
+			return
+		}
+
+		claims, err := signer.Verify(strings.TrimSpace(token))
+		if err != nil {
+			log.Debug().Err(err).Str("request_id", ctx.GetString("request_id")).Msg("invalid access token")
+			AbortUnauthenticated(ctx, "invalid or expired token")
+			return
+		}
+
+		ctx.Set("user_id", claims.UserID)
+		ctx.Set("wallet_address", claims.WalletAddress)
+		ctx.Set("user_role", user.Role(claims.Role))
+		ctx.Next()
+	}
+}
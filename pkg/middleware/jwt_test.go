@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/pkg/jwtauth"
+	"github.com/gin-gonic/gin"
+)
+
+func TestJWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	signer, err := jwtauth.NewSigner("test-secret-that-is-long-enough!", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := signer.Sign("user-1", "0xabc", "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/stats", JWTAuth("test-secret-that-is-long-enough!"), func(ctx *gin.Context) {
+		role, _ := ContextRole(ctx)
+		ctx.String(http.StatusOK, ctx.GetString("user_id")+" "+string(role))
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"Valid token", "Bearer " + token, http.StatusOK},
+		{"Tampered token", "Bearer " + token + "x", http.StatusUnauthorized},
+		{"Session ID is not a token", "Bearer 4b0f6a9e-session", http.StatusUnauthorized},
+		{"No token", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != "user-1 seller" {
+				t.Errorf("context = %q, want %q", w.Body.String(), "user-1 seller")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// slidingWindowScript records a request in a sorted set of request times when
+// fewer than limit fall within the window. It returns whether the request is
+// allowed, how many remain, and how many milliseconds until the oldest
+// request leaves the window when it is not.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// RedisClient supplies the current Redis client. The connection monitor
+// rebuilds its client after an outage, so the client is fetched per request.
+type RedisClient interface {
+	Client() *redis.Client
+}
+
+// requestSeq keeps request entries unique when two share a timestamp
+var requestSeq atomic.Uint64
+
+// RateLimit allows each client IP limit requests per sliding window, counted
+// in Redis so every instance shares the budget. Requests over the limit get
+// 429 with Retry-After; if Redis cannot be reached, requests are let through
+// rather than locking everyone out.
+func RateLimit(redisClient RedisClient, limit int, window time.Duration) gin.HandlerFunc {
+	return rateLimit(redisClient, limit, window, time.Now)
+}
+
+func rateLimit(redisClient RedisClient, limit int, window time.Duration, now func() time.Time) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := "ratelimit:ip:" + ctx.ClientIP()
+
+		ts := now().UnixMilli()
+		member := fmt.Sprintf("%d-%d", ts, requestSeq.Add(1))
+		res, err := slidingWindowScript.Run(ctx.Request.Context(), redisClient.Client(), []string{key},
+			ts, window.Milliseconds(), limit, member).Int64Slice()
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("rate limit check failed, allowing request")
+			ctx.Next()
+			return
+		}
+
+		allowed, remaining, retryAfter := res[0] == 1, res[1], time.Duration(res[2])*time.Millisecond
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		ctx.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		if !allowed {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			AbortWithError(ctx, http.StatusTooManyRequests, CodeRateLimited, "too many requests")
+			return
+		}
+		ctx.Next()
+	}
+}
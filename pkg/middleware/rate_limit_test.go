@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type staticClient struct {
+	client *redis.Client
+}
+
+func (s staticClient) Client() *redis.Client {
+	return s.client
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	clock := time.Unix(1700000000, 0)
+	now := func() time.Time { return clock }
+
+	router := gin.New()
+	router.Use(rateLimit(staticClient{client}, 2, time.Minute, now))
+	router.GET("/auth/nonce", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/auth/nonce", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, want := range []string{"1", "0"} {
+		w := send("10.0.0.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != want {
+			t.Errorf("request %d X-RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+	}
+
+	clock = clock.Add(20 * time.Second)
+	w := send("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over-limit status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "40" {
+		t.Errorf("Retry-After = %q, want %q", got, "40")
+	}
+	if !strings.Contains(w.Body.String(), `"code":"RATE_LIMITED"`) {
+		t.Errorf("over-limit body = %s, want code RATE_LIMITED", w.Body.String())
+	}
+
+	// Other clients have their own budget
+	if w := send("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("another IP status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// The limit resets once the earlier requests leave the window
+	clock = clock.Add(40 * time.Second)
+	if w := send("10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("status after the window = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimit_AllowsWhenRedisUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	server.Close()
+
+	router := gin.New()
+	router.Use(RateLimit(staticClient{client}, 1, time.Minute))
+	router.GET("/auth/nonce", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/nonce", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+}
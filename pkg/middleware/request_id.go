@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs supplied by clients
+const maxRequestIDLength = 128
+
+// RequestID stores the request's ID in the context under "request_id" and
+// echoes it in the X-Request-ID response header. A well-formed ID sent by
+// the client or a proxy is kept so logs can be correlated across services;
+// otherwise a UUID is generated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs of printable ASCII so a client cannot
+// inject line breaks or control characters into the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var stored string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ping", func(ctx *gin.Context) {
+		stored = ctx.GetString("request_id")
+		ctx.Status(http.StatusOK)
+	})
+
+	send := func(header string) string {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header().Get(RequestIDHeader)
+	}
+
+	t.Run("Supplied ID is propagated", func(t *testing.T) {
+		got := send("req-123")
+		if got != "req-123" || stored != "req-123" {
+			t.Errorf("header = %q, context = %q, want %q", got, stored, "req-123")
+		}
+	})
+
+	t.Run("Missing ID is generated", func(t *testing.T) {
+		got := send("")
+		if _, err := uuid.Parse(got); err != nil {
+			t.Errorf("header = %q, want a UUID", got)
+		}
+		if stored != got {
+			t.Errorf("context = %q, want %q", stored, got)
+		}
+	})
+
+	t.Run("Malformed ID is replaced", func(t *testing.T) {
+		for _, bad := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+			if got := send(bad); got == bad {
+				t.Errorf("malformed ID %q was kept", bad)
+			}
+		}
+	})
+}
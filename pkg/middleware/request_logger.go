@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionCookie is the cookie holding the session ID, which must never be
+// written to the logs
+const sessionCookie = "session_id"
+
+// DefaultSlowRequestThreshold is used when no threshold is configured
+const DefaultSlowRequestThreshold = 500 * time.Millisecond
+
+// RequestLogger writes one access log line per request with its method,
+// path, route, status, response size, latency, client IP and request ID.
+// Server errors are logged at ERROR, requests slower than slowThreshold at
+// WARN so slow endpoints stand out, and everything else at DEBUG. Register
+// it after RequestID; the ID is only taken from there, never from the raw
+// header.
+func RequestLogger(appLogger *logger.Logger, slowThreshold time.Duration) gin.HandlerFunc {
+	l := appLogger.Zerolog()
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowRequestThreshold
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		status := c.Writer.Status()
+		event := l.Debug()
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = l.Error()
+		case latency > slowThreshold:
+			event = l.Warn()
+		}
+		if !event.Enabled() {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("route", route).
+			Int("status", status).
+			Int("bytes", c.Writer.Size()).
+			Dur("latency", latency).
+			Str("client_ip", c.ClientIP()).
+			Str("request_id", c.GetString("request_id"))
+		if cookies := redactCookies(c.Request); cookies != "" {
+			event.Str("cookies", cookies)
+		}
+		if len(c.Errors) > 0 {
+			event.Str("errors", c.Errors.String())
+		}
+		event.Msg("request")
+	}
+}
+
+// redactCookies renders the request's cookies with the session ID replaced
+func redactCookies(r *http.Request) string {
+	cookies := r.Cookies()
+	parts := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		value := cookie.Value
+		if cookie.Name == sessionCookie {
+			value = "[REDACTED]"
+		}
+		parts = append(parts, cookie.Name+"="+value)
+	}
+	return strings.Join(parts, "; ")
+}
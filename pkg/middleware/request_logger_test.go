@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(RequestLogger(logger.FromZerolog(zerolog.New(&buf)), 20*time.Millisecond))
+	router.GET("/v1/products/:id", func(ctx *gin.Context) {
+		if ctx.Query("slow") != "" {
+			time.Sleep(40 * time.Millisecond)
+		}
+		ctx.String(http.StatusOK, "hello")
+	})
+	router.GET("/boom", func(ctx *gin.Context) {
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/products/p1?page=2", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(RequestIDHeader, "req-123")
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "secret-session"})
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "secret-session") {
+		t.Fatalf("session cookie value was logged: %s", buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("request was not logged as JSON: %v (%q)", err, buf.String())
+	}
+	want := map[string]interface{}{
+		"level":      "debug",
+		"method":     "GET",
+		"path":       "/v1/products/p1",
+		"route":      "/v1/products/:id",
+		"status":     float64(http.StatusOK),
+		"bytes":      float64(len("hello")),
+		"client_ip":  "10.0.0.1",
+		"request_id": "req-123",
+		"cookies":    "session_id=[REDACTED]; theme=dark",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+	if _, ok := entry["latency"].(float64); !ok {
+		t.Errorf("latency = %v, want a number", entry["latency"])
+	}
+
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("request was not logged as JSON: %v (%q)", err, buf.String())
+	}
+	if entry["level"] != "error" {
+		t.Errorf("level for a 500 = %v, want error", entry["level"])
+	}
+
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/products/p1?slow=1", nil))
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("request was not logged as JSON: %v (%q)", err, buf.String())
+	}
+	if entry["level"] != "warn" {
+		t.Errorf("level for a slow request = %v, want warn", entry["level"])
+	}
+}
+
+func TestRequestLogger_OnlyLogsFastRequestsAtDebug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(RequestLogger(logger.FromZerolog(zerolog.New(&buf).Level(zerolog.InfoLevel)), time.Second))
+	router.GET("/health", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if buf.Len() != 0 {
+		t.Errorf("fast request logged at info level: %s", buf.String())
+	}
+}
+
+func TestRequestLogger_IgnoresRequestIDHeaderWithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+
+	router := gin.New()
+	router.Use(RequestLogger(logger.FromZerolog(zerolog.New(&buf)), time.Second))
+	router.GET("/health", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(RequestIDHeader, "forged\nid")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if strings.Contains(buf.String(), "forged") {
+		t.Errorf("unvalidated request ID header was logged: %s", buf.String())
+	}
+}
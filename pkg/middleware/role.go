@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RequireRole lets the request through only when the authenticated user has
+// one of roles. Register it after AuthMiddleware, which puts the session's
+// role in the context under "user_role"; the user is only loaded when no
+// role is there, and the role found is stored. A user that cannot be loaded
+// is refused.
+func RequireRole(userUseCase *usecase.UserUseCase, roles ...user.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role, ok := ContextRole(ctx)
+		if !ok {
+			userID := ctx.GetString("user_id")
+			u, err := userUseCase.GetUserByID(userID)
+			if err != nil {
+				log.Debug().Err(err).Str("user_id", userID).Msg("role check could not load user")
+				AbortForbidden(ctx, "insufficient role")
+				return
+			}
+			role = u.Role
+			ctx.Set("user_role", role)
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+		AbortForbidden(ctx, "insufficient role")
+	}
+}
+
+// ContextRole returns the authenticated user's role stored in the context
+func ContextRole(ctx *gin.Context) (user.Role, bool) {
+	role, ok := ctx.Value("user_role").(user.Role)
+	return role, ok && role != ""
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/auth"
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/user"
+	redisrepo "github.com/Tenoywil/CaribEx-backend/internal/repository/redis"
+	"github.com/Tenoywil/CaribEx-backend/internal/usecase"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// stubUserRepo is a map-backed user.Repository
+type stubUserRepo map[string]*user.User
+
+func (r stubUserRepo) Create(u *user.User) error { r[u.ID] = u; return nil }
+func (r stubUserRepo) GetByID(id string) (*user.User, error) {
+	u, ok := r[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return u, nil
+}
+func (r stubUserRepo) GetByWalletAddress(string) (*user.User, error) {
+	return nil, errors.New("user not found")
+}
+func (r stubUserRepo) Update(u *user.User) error { r[u.ID] = u; return nil }
+func (r stubUserRepo) Delete(id string) error    { delete(r, id); return nil }
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := usecase.NewUserUseCase(stubUserRepo{
+		"seller-1":   {ID: "seller-1", Role: user.RoleSeller},
+		"customer-1": {ID: "customer-1", Role: user.RoleCustomer},
+	})
+
+	var gotRole interface{}
+	router := gin.New()
+	router.POST("/products", func(ctx *gin.Context) {
+		ctx.Set("user_id", ctx.GetHeader("X-Test-User"))
+	}, RequireRole(users, user.RoleSeller, user.RoleAdmin), func(ctx *gin.Context) {
+		gotRole, _ = ctx.Get("user_role")
+		ctx.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{"Allowed role", "seller-1", http.StatusCreated},
+		{"Wrong role", "customer-1", http.StatusForbidden},
+		{"Missing user", "deleted-1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRole = nil
+			req := httptest.NewRequest(http.MethodPost, "/products", nil)
+			req.Header.Set("X-Test-User", tt.userID)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusCreated && gotRole != user.RoleSeller {
+				t.Errorf("user_role = %v, want %v", gotRole, user.RoleSeller)
+			}
+		})
+	}
+}
+
+func TestRequireRole_UsesSessionRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	sessions := redisrepo.NewSessionRepository(client)
+
+	// No users are stored, so passing requires the role from the session
+	users := usecase.NewUserUseCase(stubUserRepo{})
+	authUseCase := usecase.NewAuthUseCase(sessions, users, usecase.AuthConfig{Domains: []string{"localhost:3000"}})
+
+	var gotRole interface{}
+	router := gin.New()
+	router.POST("/products", AuthMiddleware(authUseCase), RequireRole(users, user.RoleSeller), func(ctx *gin.Context) {
+		gotRole, _ = ctx.Get("user_role")
+		ctx.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name       string
+		role       user.Role
+		wantStatus int
+	}{
+		{"Seller session", user.RoleSeller, http.StatusCreated},
+		{"Customer session", user.RoleCustomer, http.StatusForbidden},
+		{"Session without role", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := auth.NewSession("user-1", "0x0000000000000000000000000000000000000001", tt.role, time.Hour)
+			if err := sessions.SaveSession(context.Background(), s); err != nil {
+				t.Fatal(err)
+			}
+			gotRole = nil
+			req := httptest.NewRequest(http.MethodPost, "/products", nil)
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: s.ID})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusCreated && gotRole != tt.role {
+				t.Errorf("user_role = %v, want %v", gotRole, tt.role)
+			}
+		})
+	}
+}
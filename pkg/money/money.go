@@ -0,0 +1,153 @@
+// Package money does exact arithmetic on amounts held as integer cents, so
+// totals do not pick up float64 rounding errors.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when text is not a decimal amount with at most
+// two fractional digits
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// Amount is a monetary value in minor units (cents)
+type Amount int64
+
+// Zero is the zero amount
+const Zero Amount = 0
+
+// FromCents returns the amount of the given number of cents
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// FromFloat converts a float value, such as a price read from the database,
+// rounding to the nearest cent
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * 100))
+}
+
+// Parse reads a decimal amount such as "12", "12.3" or "-0.05". More than two
+// fractional digits are rejected rather than silently rounded.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" || len(frac) > 2 || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	cents, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if neg {
+		cents = -cents
+	}
+	return Amount(cents), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Sum adds up amounts
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+// Mul returns a multiplied by a whole quantity
+func (a Amount) Mul(n int64) Amount {
+	return a * Amount(n)
+}
+
+// Percent returns pct percent of a, rounded half away from zero to the
+// nearest cent. pct may have up to two decimal places, e.g. 2.75.
+func (a Amount) Percent(pct float64) Amount {
+	// Work in ten-thousandths of a cent so the only rounding is the last step
+	scaled := int64(a) * int64(math.Round(pct*100))
+	return Amount(divRound(scaled, 10000))
+}
+
+// divRound divides, rounding half away from zero
+func divRound(n, d int64) int64 {
+	q, r := n/d, n%d
+	if 2*abs(r) >= d {
+		if n < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Cents returns the amount in minor units
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Float64 returns the amount in major units, for storage in float fields
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// String formats the amount with two decimal places
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,121 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSum_ExactWhereFloatIsNot(t *testing.T) {
+	// 0.1 + 0.2 and ten dimes are the classic float64 failures
+	a, b := 0.1, 0.2
+	if a+b == 0.3 {
+		t.Fatal("float64 sum of 0.1 and 0.2 is exact, test premise broken")
+	}
+	if got := FromFloat(a).Add(FromFloat(b)); got != FromCents(30) {
+		t.Errorf("0.10 + 0.20 = %s, want 0.30", got)
+	}
+
+	var f float64
+	var total Amount
+	for i := 0; i < 10; i++ {
+		f += 0.1
+		total = total.Add(FromFloat(0.1))
+	}
+	if f == 1 {
+		t.Fatal("float64 sum of ten 0.1s is exact, test premise broken")
+	}
+	if total != FromCents(100) {
+		t.Errorf("ten 0.10s = %s, want 1.00", total)
+	}
+
+	// A cart of many small-priced items
+	prices := []float64{19.99, 0.01, 4.35, 0.7, 1.15, 2.3, 9.95}
+	var floatTotal float64
+	var amounts []Amount
+	for _, p := range prices {
+		for i := 0; i < 1000; i++ {
+			floatTotal += p
+			amounts = append(amounts, FromFloat(p))
+		}
+	}
+	if got := Sum(amounts...); got != FromCents(3845000) {
+		t.Errorf("Sum() = %s, want 38450.00 (float64 gave %v)", got, floatTotal)
+	}
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	price := FromCents(1999)
+	if got := price.Mul(3); got != FromCents(5997) {
+		t.Errorf("Mul() = %s, want 59.97", got)
+	}
+	if got := price.Sub(FromCents(2000)); got != FromCents(-1) {
+		t.Errorf("Sub() = %s, want -0.01", got)
+	}
+
+	tests := []struct {
+		amount Amount
+		pct    float64
+		want   Amount
+	}{
+		{FromCents(10000), 10, FromCents(1000)},
+		{FromCents(3333), 5, FromCents(167)}, // 1.6665 rounds up
+		{FromCents(3333), 2.75, FromCents(92)},
+		{FromCents(-3333), 5, FromCents(-167)},
+		{FromCents(100), 0, Zero},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Percent(tt.pct); got != tt.want {
+			t.Errorf("%s.Percent(%v) = %s, want %s", tt.amount, tt.pct, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+	}{
+		{"12", 1200},
+		{"12.3", 1230},
+		{"12.34", 1234},
+		{"-0.05", -5},
+		{" 0.10 ", 10},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".5", "1.234", "1e3", "abc", "1.2.3"} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidAmount", in, err)
+		}
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	type line struct {
+		Total Amount `json:"total"`
+	}
+
+	data, err := json.Marshal(line{Total: FromCents(-1205)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"total":-12.05}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	for _, in := range []string{`{"total":0.30}`, `{"total":"0.3"}`} {
+		var l line
+		if err := json.Unmarshal([]byte(in), &l); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", in, err)
+		}
+		if l.Total != FromCents(30) {
+			t.Errorf("Unmarshal(%s) = %s, want 0.30", in, l.Total)
+		}
+	}
+}
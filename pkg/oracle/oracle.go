@@ -0,0 +1,89 @@
+package oracle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+)
+
+// StaticOracle prices currencies from fixed USD rates, such as those set in
+// configuration. Rates are units of the currency per one USD.
+type StaticOracle struct {
+	perUSD map[wallet.Currency]float64
+}
+
+// NewStaticOracle creates an oracle from per-USD rates. USD itself is always
+// 1; currencies without a positive rate are unsupported.
+func NewStaticOracle(perUSD map[wallet.Currency]float64) *StaticOracle {
+	rates := map[wallet.Currency]float64{wallet.CurrencyUSD: 1}
+	for c, r := range perUSD {
+		if r > 0 {
+			rates[c] = r
+		}
+	}
+	return &StaticOracle{perUSD: rates}
+}
+
+// Rate converts through USD
+func (o *StaticOracle) Rate(from, to wallet.Currency) (float64, error) {
+	fromRate, ok := o.perUSD[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", wallet.ErrUnsupportedCurrency, from)
+	}
+	toRate, ok := o.perUSD[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", wallet.ErrUnsupportedCurrency, to)
+	}
+	return toRate / fromRate, nil
+}
+
+type cachedRate struct {
+	rate      float64
+	expiresAt time.Time
+}
+
+// CachedOracle remembers rates from another oracle for a fixed TTL so
+// repeated conversions do not hit the upstream source
+type CachedOracle struct {
+	oracle wallet.PriceOracle
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	rates map[[2]wallet.Currency]cachedRate
+}
+
+// NewCachedOracle wraps oracle with a rate cache
+func NewCachedOracle(oracle wallet.PriceOracle, ttl time.Duration) *CachedOracle {
+	return &CachedOracle{
+		oracle: oracle,
+		ttl:    ttl,
+		now:    time.Now,
+		rates:  make(map[[2]wallet.Currency]cachedRate),
+	}
+}
+
+// Rate returns the cached rate when fresh, otherwise asks the wrapped oracle.
+// Errors are not cached.
+func (o *CachedOracle) Rate(from, to wallet.Currency) (float64, error) {
+	key := [2]wallet.Currency{from, to}
+
+	o.mu.Lock()
+	cached, ok := o.rates[key]
+	o.mu.Unlock()
+	if ok && o.now().Before(cached.expiresAt) {
+		return cached.rate, nil
+	}
+
+	rate, err := o.oracle.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	o.mu.Lock()
+	o.rates[key] = cachedRate{rate: rate, expiresAt: o.now().Add(o.ttl)}
+	o.mu.Unlock()
+	return rate, nil
+}
@@ -0,0 +1,75 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tenoywil/CaribEx-backend/internal/domain/wallet"
+)
+
+func TestStaticOracle(t *testing.T) {
+	o := NewStaticOracle(map[wallet.Currency]float64{
+		wallet.CurrencyUSDC: 1,
+		wallet.CurrencyJAM:  160,
+	})
+
+	tests := []struct {
+		from, to wallet.Currency
+		want     float64
+	}{
+		{wallet.CurrencyUSD, wallet.CurrencyJAM, 160},
+		{wallet.CurrencyUSDC, wallet.CurrencyJAM, 160},
+		{wallet.CurrencyJAM, wallet.CurrencyUSD, 1.0 / 160},
+		{wallet.CurrencyUSDC, wallet.CurrencyUSD, 1},
+	}
+	for _, tt := range tests {
+		got, err := o.Rate(tt.from, tt.to)
+		if err != nil || got != tt.want {
+			t.Errorf("Rate(%s, %s) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	if _, err := NewStaticOracle(nil).Rate(wallet.CurrencyUSD, wallet.CurrencyJAM); !errors.Is(err, wallet.ErrUnsupportedCurrency) {
+		t.Errorf("Rate() without a JAM rate error = %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+type countingOracle struct {
+	rate  float64
+	calls int
+}
+
+func (c *countingOracle) Rate(from, to wallet.Currency) (float64, error) {
+	c.calls++
+	return c.rate, nil
+}
+
+func TestCachedOracle(t *testing.T) {
+	upstream := &countingOracle{rate: 155}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	o := NewCachedOracle(upstream, time.Minute)
+	o.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := o.Rate(wallet.CurrencyUSDC, wallet.CurrencyJAM); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if upstream.calls != 1 {
+		t.Errorf("upstream called %d times within TTL, want 1", upstream.calls)
+	}
+
+	// A different pair is cached separately
+	o.Rate(wallet.CurrencyJAM, wallet.CurrencyUSDC)
+	if upstream.calls != 2 {
+		t.Errorf("upstream called %d times, want 2", upstream.calls)
+	}
+
+	upstream.rate = 157
+	now = now.Add(time.Minute)
+	got, _ := o.Rate(wallet.CurrencyUSDC, wallet.CurrencyJAM)
+	if got != 157 || upstream.calls != 3 {
+		t.Errorf("after TTL Rate() = %v with %d calls, want refreshed 157 with 3 calls", got, upstream.calls)
+	}
+}
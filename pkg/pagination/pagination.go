@@ -0,0 +1,81 @@
+package pagination
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultPageSize is used when page_size is missing or invalid
+	DefaultPageSize = 20
+	// MaxPageSize caps how many records a single page may return
+	MaxPageSize = 100
+)
+
+// ErrInvalidPageSize is returned when page math is attempted with a page size below 1
+var ErrInvalidPageSize = errors.New("page size must be at least 1")
+
+// Params holds validated paging parameters; Page and PageSize are always >= 1
+type Params struct {
+	Page     int
+	PageSize int
+}
+
+// Meta is the pagination metadata returned alongside list results
+type Meta struct {
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalPages int `json:"total_pages"`
+}
+
+// New clamps raw values into valid paging parameters
+func New(page, pageSize int) Params {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return Params{Page: page, PageSize: pageSize}
+}
+
+// FromQuery reads the page and page_size query parameters
+func FromQuery(ctx *gin.Context) Params {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", strconv.Itoa(DefaultPageSize)))
+	return New(page, pageSize)
+}
+
+// Offset returns the number of records to skip for the page
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// Meta builds the response metadata for the given total record count
+func (p Params) Meta(total int) Meta {
+	// PageSize is clamped to >= 1 by New, so the error cannot occur here
+	totalPages, _ := TotalPages(total, p.PageSize)
+	return Meta{
+		Total:      total,
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		TotalPages: totalPages,
+	}
+}
+
+// TotalPages returns how many pages are needed to hold total records
+func TotalPages(total, pageSize int) (int, error) {
+	if pageSize < 1 {
+		return 0, ErrInvalidPageSize
+	}
+	if total <= 0 {
+		return 0, nil
+	}
+	return (total + pageSize - 1) / pageSize, nil
+}
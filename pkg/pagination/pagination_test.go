@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		page         int
+		pageSize     int
+		wantPage     int
+		wantPageSize int
+	}{
+		{"Valid values", 3, 50, 3, 50},
+		{"Zero page", 0, 20, 1, 20},
+		{"Negative page", -4, 20, 1, 20},
+		{"Zero page size", 1, 0, 1, DefaultPageSize},
+		{"Negative page size", 1, -10, 1, DefaultPageSize},
+		{"Page size above max", 1, 500, 1, MaxPageSize},
+		{"Page size at max", 1, MaxPageSize, 1, MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tt.page, tt.pageSize)
+			if p.Page != tt.wantPage || p.PageSize != tt.wantPageSize {
+				t.Errorf("New(%d, %d) = %+v, want page %d size %d", tt.page, tt.pageSize, p, tt.wantPage, tt.wantPageSize)
+			}
+		})
+	}
+}
+
+func TestTotalPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		pageSize int
+		want     int
+		wantErr  error
+	}{
+		{"No records", 0, 20, 0, nil},
+		{"Exactly one page", 20, 20, 1, nil},
+		{"Partial last page", 21, 20, 2, nil},
+		{"Page size of one", 5, 1, 5, nil},
+		{"Zero page size", 10, 0, 0, ErrInvalidPageSize},
+		{"Negative page size", 10, -1, 0, ErrInvalidPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TotalPages(tt.total, tt.pageSize)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TotalPages(%d, %d) error = %v, want %v", tt.total, tt.pageSize, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TotalPages(%d, %d) = %d, want %d", tt.total, tt.pageSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamsMetaAndOffset(t *testing.T) {
+	p := New(3, 0)
+	if got := p.Offset(); got != 2*DefaultPageSize {
+		t.Errorf("Offset() = %d, want %d", got, 2*DefaultPageSize)
+	}
+
+	meta := p.Meta(0)
+	want := Meta{Total: 0, Page: 3, PageSize: DefaultPageSize, TotalPages: 0}
+	if meta != want {
+		t.Errorf("Meta(0) = %+v, want %+v", meta, want)
+	}
+}
@@ -4,24 +4,74 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"regexp"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Tenoywil/CaribEx-backend/pkg/blockchain"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// SIWEMessage represents a parsed SIWE message
+var (
+	// ErrURINotAllowed is returned when a message's URI or one of its
+	// resources is not in the allow-list
+	ErrURINotAllowed = errors.New("SIWE URI is not allowed")
+	// ErrInvalidSignature is returned for a signature that is not 65 bytes
+	// of hex
+	ErrInvalidSignature = errors.New("invalid signature format")
+	// ErrMessageExpired is returned for a message past its Expiration Time
+	ErrMessageExpired = errors.New("SIWE message has expired")
+	// ErrMessageNotYetValid is returned for a message before its Not Before
+	// time, or issued in the future
+	ErrMessageNotYetValid = errors.New("SIWE message is not yet valid")
+	// ErrMessageStale is returned for a message issued longer ago than the
+	// maximum age
+	ErrMessageStale = errors.New("SIWE message was issued too long ago")
+	// ErrChainNotAllowed is returned for a message declaring a chain that is
+	// unsupported or not in the allow-list
+	ErrChainNotAllowed = errors.New("SIWE chain ID is not allowed")
+)
+
+// DefaultMaxAge is how long after its Issued At time a message is accepted
+// when no maximum age is configured. It matches the nonce lifetime.
+const DefaultMaxAge = 10 * time.Minute
+
+// clockSkew tolerates clients whose clocks run slightly ahead of ours
+const clockSkew = time.Minute
+
+// SIWEMessage represents a parsed SIWE message. ExpirationTime and NotBefore
+// are zero when the message does not set them.
 type SIWEMessage struct {
-	Domain    string
-	Address   string
-	Statement string
-	URI       string
-	Version   string
-	ChainID   string
-	Nonce     string
-	IssuedAt  time.Time
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	Resources      []string
+}
+
+// Options are the checks VerifySIWE applies beyond the signature
+type Options struct {
+	// Domains are the domains messages may be issued for
+	Domains []string
+	// URIs are the hosts or URI prefixes the message URI and resources may
+	// point to; empty allows the hosts in Domains
+	URIs []string
+	// MaxAge is how long after Issued At a message is accepted; zero means
+	// DefaultMaxAge
+	MaxAge time.Duration
+	// ChainIDs are the chains users may sign in on; empty allows every chain
+	// the blockchain package supports
+	ChainIDs []int64
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
 }
 
 // VerifySIWEMessage parses, normalizes, and verifies a signed SIWE message.
@@ -38,7 +88,10 @@ func VerifySIWEMessage(message, signature string) (bool, SIWEMessage, error) {
 	// Decode the signature (remove "0x")
 	sigBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
 	if err != nil {
-		return false, siwe, fmt.Errorf("invalid signature format")
+		return false, siwe, ErrInvalidSignature
+	}
+	if len(sigBytes) != crypto.SignatureLength {
+		return false, siwe, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidSignature, len(sigBytes), crypto.SignatureLength)
 	}
 
 	// Ethereum signatures have "v" as the last byte (27/28 or 0/1 offset)
@@ -54,17 +107,28 @@ func VerifySIWEMessage(message, signature string) (bool, SIWEMessage, error) {
 		return false, siwe, fmt.Errorf("failed to recover public key: %v", err)
 	}
 
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey).Hex()
+	recoveredAddr, err := blockchain.ParseAddress(crypto.PubkeyToAddress(*pubKey).Hex())
+	if err != nil {
+		return false, siwe, fmt.Errorf("failed to recover address: %v", err)
+	}
 
 	// Compare recovered address to SIWE message address
-	if !strings.EqualFold(recoveredAddr, siwe.Address) {
-		return false, siwe, fmt.Errorf("signature mismatch: recovered=%s, expected=%s", recoveredAddr, siwe.Address)
+	claimedAddr, err := blockchain.ParseAddress(siwe.Address)
+	if err != nil {
+		return false, siwe, fmt.Errorf("invalid address in SIWE message: %w", err)
+	}
+	if !recoveredAddr.Equal(claimedAddr) {
+		return false, siwe, fmt.Errorf("signature mismatch: recovered=%s, expected=%s", recoveredAddr, claimedAddr)
 	}
+	siwe.Address = claimedAddr.String()
 
 	return true, siwe, nil
 }
 
-// parseSiweMessage does minimal parsing of an EIP-4361 message.
+// parseSiweMessage parses an EIP-4361 message: the domain line, the address,
+// an optional statement, the "Key: value" fields and any resources. Fields
+// are matched by prefix at the start of a line, so text in the statement
+// cannot be mistaken for a field.
 func parseSiweMessage(message string) (SIWEMessage, error) {
 	var s SIWEMessage
 	lines := strings.Split(message, "\n")
@@ -73,56 +137,109 @@ func parseSiweMessage(message string) (SIWEMessage, error) {
 	}
 
 	// Line 1: domain + "wants you to sign in..."
-	parts := strings.SplitN(lines[0], " wants you to sign in", 2)
-	if len(parts) < 1 {
+	domain, ok := strings.CutSuffix(strings.TrimSpace(lines[0]), " wants you to sign in with your Ethereum account:")
+	if !ok || domain == "" {
 		return s, errors.New("invalid domain line")
 	}
-	s.Domain = strings.TrimSpace(parts[0])
+	s.Domain = domain
 
 	// Find the address line (skip empty lines after domain)
-	addressLineIndex := 1
-	for addressLineIndex < len(lines) && strings.TrimSpace(lines[addressLineIndex]) == "" {
-		addressLineIndex++
+	i := 1
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
 	}
-	if addressLineIndex >= len(lines) {
+	if i >= len(lines) {
 		return s, errors.New("no address found")
 	}
-	s.Address = strings.TrimSpace(lines[addressLineIndex])
-
-	// Extract remaining key-value lines
-	patterns := map[string]*regexp.Regexp{
-		"URI":      regexp.MustCompile(`URI:\s*(.+)`),
-		"Version":  regexp.MustCompile(`Version:\s*(.+)`),
-		"ChainID":  regexp.MustCompile(`Chain ID:\s*(.+)`),
-		"Nonce":    regexp.MustCompile(`Nonce:\s*(.+)`),
-		"IssuedAt": regexp.MustCompile(`Issued At:\s*(.+)`),
-	}
-
-	for _, line := range lines {
-		for key, re := range patterns {
-			if matches := re.FindStringSubmatch(line); len(matches) > 1 {
-				switch key {
-				case "URI":
-					s.URI = matches[1]
-				case "Version":
-					s.Version = matches[1]
-				case "ChainID":
-					s.ChainID = matches[1]
-				case "Nonce":
-					s.Nonce = matches[1]
-				case "IssuedAt":
-					t, _ := time.Parse(time.RFC3339, strings.TrimSpace(matches[1]))
-					s.IssuedAt = t
-				}
+	s.Address = strings.TrimSpace(lines[i])
+	i++
+
+	// The statement is the first non-empty line after the address, unless
+	// the message goes straight to its fields
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i < len(lines) && fieldKey(lines[i]) == "" {
+		s.Statement = strings.TrimSpace(lines[i])
+		i++
+	}
+
+	inResources := false
+	for _, line := range lines[i:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Resources are listed as "- <uri>" lines after a "Resources:" line
+		if line == "Resources:" {
+			inResources = true
+			continue
+		}
+		if inResources {
+			if resource, ok := strings.CutPrefix(line, "- "); ok {
+				s.Resources = append(s.Resources, strings.TrimSpace(resource))
+				continue
+			}
+			inResources = false
+		}
+
+		key := fieldKey(line)
+		if key == "" {
+			return s, fmt.Errorf("unexpected line %q", line)
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, key+":"))
+		switch key {
+		case "URI":
+			s.URI = value
+		case "Version":
+			s.Version = value
+		case "Chain ID":
+			s.ChainID = value
+		case "Nonce":
+			s.Nonce = value
+		case "Issued At", "Expiration Time", "Not Before":
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return s, fmt.Errorf("invalid %s: %q", key, value)
+			}
+			switch key {
+			case "Issued At":
+				s.IssuedAt = t
+			case "Expiration Time":
+				s.ExpirationTime = t
+			default:
+				s.NotBefore = t
 			}
 		}
 	}
 
+	if s.IssuedAt.IsZero() {
+		return s, errors.New("missing Issued At")
+	}
+
 	return s, nil
 }
 
-// VerifySIWE performs complete SIWE verification
-func VerifySIWE(message, signature, expectedDomain string) (*SIWEMessage, error) {
+// messageFields are the EIP-4361 fields parseSiweMessage reads. Request ID is
+// allowed but ignored.
+var messageFields = []string{"URI", "Version", "Chain ID", "Nonce", "Issued At", "Expiration Time", "Not Before", "Request ID"}
+
+// fieldKey returns the field line names, or "" when it is not a field
+func fieldKey(line string) string {
+	line = strings.TrimSpace(line)
+	for _, key := range messageFields {
+		if strings.HasPrefix(line, key+":") {
+			return key
+		}
+	}
+	return ""
+}
+
+// VerifySIWE performs complete SIWE verification, accepting messages issued
+// for any of the allowed domains. The message URI and any resources must
+// match the allowed URIs, the message must be within its validity period and
+// no older than the maximum age, and it must declare an allowed chain.
+func VerifySIWE(message, signature string, opts Options) (*SIWEMessage, error) {
 	// Use the comprehensive verification function
 	isValid, siweMsg, err := VerifySIWEMessage(message, signature)
 	if err != nil {
@@ -133,20 +250,123 @@ func VerifySIWE(message, signature, expectedDomain string) (*SIWEMessage, error)
 		return nil, fmt.Errorf("signature verification failed")
 	}
 
-	// Verify domain matches
-	if siweMsg.Domain != expectedDomain {
-		return nil, fmt.Errorf("domain mismatch: expected %s, got %s", expectedDomain, siweMsg.Domain)
+	if err := checkTimes(siweMsg, opts); err != nil {
+		return nil, err
+	}
+	// The signature of an externally owned account recovers to the same
+	// address on every chain, so only the declared chain needs checking
+	if err := checkChain(siweMsg.ChainID, opts.ChainIDs); err != nil {
+		return nil, err
+	}
+
+	// Verify domain is allowed
+	allowedDomains, allowedURIs := opts.Domains, opts.URIs
+	if !domainAllowed(siweMsg.Domain, allowedDomains) {
+		return nil, fmt.Errorf("domain mismatch: %s is not an allowed domain", siweMsg.Domain)
+	}
+
+	// Verify the URI and resources point back at us, so a message for our
+	// domain cannot send the user elsewhere after sign-in
+	if len(allowedURIs) == 0 {
+		allowedURIs = allowedDomains
 	}
+	for _, uri := range append([]string{siweMsg.URI}, siweMsg.Resources...) {
+		if !uriAllowed(uri, allowedURIs) {
+			return nil, fmt.Errorf("%w: %q", ErrURINotAllowed, uri)
+		}
+	}
+
+	return &siweMsg, nil
+}
+
+// checkTimes rejects a message that has expired, is not valid yet, or was
+// issued longer ago than the maximum age, so a captured message cannot be
+// replayed indefinitely
+func checkTimes(msg SIWEMessage, opts Options) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	if !msg.ExpirationTime.IsZero() && !now.Before(msg.ExpirationTime) {
+		return fmt.Errorf("%w: expired at %s", ErrMessageExpired, msg.ExpirationTime.Format(time.RFC3339))
+	}
+	if !msg.NotBefore.IsZero() && now.Add(clockSkew).Before(msg.NotBefore) {
+		return fmt.Errorf("%w: not before %s", ErrMessageNotYetValid, msg.NotBefore.Format(time.RFC3339))
+	}
+	if now.Add(clockSkew).Before(msg.IssuedAt) {
+		return fmt.Errorf("%w: issued at %s", ErrMessageNotYetValid, msg.IssuedAt.Format(time.RFC3339))
+	}
+	if now.Sub(msg.IssuedAt) > maxAge {
+		return fmt.Errorf("%w: issued at %s", ErrMessageStale, msg.IssuedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkChain rejects a chain ID that is malformed, unsupported, or missing
+// from allowed when allowed is not empty
+func checkChain(chainID string, allowed []int64) error {
+	id, err := strconv.ParseInt(strings.TrimSpace(chainID), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrChainNotAllowed, chainID)
+	}
+	if !blockchain.ValidateChainID(id) {
+		return fmt.Errorf("%w: chain %d is not supported", ErrChainNotAllowed, id)
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, a := range allowed {
+		if id == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: chain %d", ErrChainNotAllowed, id)
+}
 
-	// Convert to pointer and return
-	return &SIWEMessage{
-		Domain:    siweMsg.Domain,
-		Address:   siweMsg.Address,
-		Statement: siweMsg.Statement,
-		URI:       siweMsg.URI,
-		Version:   siweMsg.Version,
-		ChainID:   siweMsg.ChainID,
-		Nonce:     siweMsg.Nonce,
-		IssuedAt:  siweMsg.IssuedAt,
-	}, nil
+// domainAllowed reports whether domain is in the allow-list
+func domainAllowed(domain string, allowedDomains []string) bool {
+	for _, allowed := range allowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// uriAllowed reports whether uri matches an allow-list entry. An entry
+// without a scheme, such as "app.caribex.com" or "localhost:3000", allows any
+// URI on that host; an entry with one, such as "https://app.caribex.com/auth",
+// allows URIs with the same scheme and host under that path.
+func uriAllowed(uri string, allowed []string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" || u.User != nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if !strings.Contains(entry, "://") {
+			if strings.EqualFold(u.Host, entry) {
+				return true
+			}
+			continue
+		}
+
+		a, err := url.Parse(entry)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, a.Scheme) || !strings.EqualFold(u.Host, a.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(a.Path, "/")
+		if u.Path == prefix || strings.HasPrefix(u.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
 }
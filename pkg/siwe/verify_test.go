@@ -0,0 +1,208 @@
+package siwe
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testDomain = "localhost:3000"
+
+// signMessage builds an EIP-4361 message on chain 1 issued at issuedAt,
+// followed by any extra fields, and signs it with a fresh key
+func signMessage(t *testing.T, issuedAt time.Time, extra string) (string, string) {
+	t.Helper()
+	return signChainMessage(t, "1", issuedAt, extra)
+}
+
+// signChainMessage is signMessage declaring the given chain ID
+func signChainMessage(t *testing.T, chainID string, issuedAt time.Time, extra string) (string, string) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := fmt.Sprintf(`%s wants you to sign in with your Ethereum account:
+%s
+
+Sign in to CaribEX
+
+URI: http://%s
+Version: 1
+Chain ID: %s
+Nonce: abc123def456
+Issued At: %s%s`, testDomain, crypto.PubkeyToAddress(key.PublicKey).Hex(), testDomain, chainID,
+		issuedAt.UTC().Format(time.RFC3339), extra)
+
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27
+	return message, "0x" + hex.EncodeToString(sig)
+}
+
+func TestVerifySIWE_Times(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ts := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		extra    string
+		wantErr  error
+	}{
+		{"Valid", now.Add(-time.Minute), "", nil},
+		{"Valid until expiration", now.Add(-time.Minute), "\nExpiration Time: " + ts(time.Minute), nil},
+		{"Expired", now.Add(-2 * time.Minute), "\nExpiration Time: " + ts(-time.Second), ErrMessageExpired},
+		{"Not before in the future", now, "\nNot Before: " + ts(5*time.Minute), ErrMessageNotYetValid},
+		{"Not before within clock skew", now, "\nNot Before: " + ts(30*time.Second), nil},
+		{"Issued in the future", now.Add(5 * time.Minute), "", ErrMessageNotYetValid},
+		{"Older than the maximum age", now.Add(-DefaultMaxAge - time.Second), "", ErrMessageStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, signature := signMessage(t, tt.issuedAt, tt.extra)
+			_, err := VerifySIWE(message, signature, Options{
+				Domains: []string{testDomain},
+				Now:     func() time.Time { return now },
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifySIWE() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySIWE_MaxAge(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	message, signature := signMessage(t, now.Add(-30*time.Minute), "")
+
+	opts := Options{Domains: []string{testDomain}, Now: func() time.Time { return now }}
+	if _, err := VerifySIWE(message, signature, opts); !errors.Is(err, ErrMessageStale) {
+		t.Fatalf("VerifySIWE() with the default max age error = %v, want %v", err, ErrMessageStale)
+	}
+
+	opts.MaxAge = time.Hour
+	if _, err := VerifySIWE(message, signature, opts); err != nil {
+		t.Errorf("VerifySIWE() with a one hour max age error = %v", err)
+	}
+}
+
+func TestVerifySIWE_InvalidTimes(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	message, signature := signMessage(t, now, "\nExpiration Time: tomorrow")
+
+	if _, err := VerifySIWE(message, signature, Options{Domains: []string{testDomain}}); err == nil {
+		t.Error("VerifySIWE() accepted an unparsable Expiration Time")
+	}
+}
+
+func TestVerifySIWE_ChainID(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID string
+		allowed []int64
+		wantErr error
+	}{
+		{"Supported chain", "1", nil, nil},
+		{"Allowed chain", "8453", []int64{1, 8453}, nil},
+		{"Unsupported chain", "999999", nil, ErrChainNotAllowed},
+		{"Supported chain not allowed", "137", []int64{1, 8453}, ErrChainNotAllowed},
+		{"Malformed chain ID", "mainnet", nil, ErrChainNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, signature := signChainMessage(t, tt.chainID, time.Now(), "")
+			_, err := VerifySIWE(message, signature, Options{Domains: []string{testDomain}, ChainIDs: tt.allowed})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifySIWE() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySIWEMessage_SignatureLength(t *testing.T) {
+	message, signature := signMessage(t, time.Now(), "")
+
+	for name, sig := range map[string]string{
+		"Empty":    "",
+		"Prefix":   "0x",
+		"10 bytes": "0x" + strings.Repeat("ab", 10),
+		"66 bytes": signature + "00",
+		"Not hex":  "0xzz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := VerifySIWEMessage(message, sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySIWEMessage() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestParseSiweMessage(t *testing.T) {
+	message := `localhost:3000 wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+Sign in to CaribEX. URI: https://evil.example is not a field here.
+
+URI: http://localhost:3000/login
+Version: 1
+Chain ID: 137
+Nonce: abc123def456
+Issued At: 2025-01-01T12:00:00Z
+Expiration Time: 2025-01-01T12:10:00Z
+Not Before: 2025-01-01T11:59:00Z
+Request ID: req-1
+Resources:
+- http://localhost:3000/terms
+- http://localhost:3000/privacy`
+
+	got, err := parseSiweMessage(message)
+	if err != nil {
+		t.Fatalf("parseSiweMessage() error = %v", err)
+	}
+	at := func(s string) time.Time {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return ts
+	}
+	want := SIWEMessage{
+		Domain:         "localhost:3000",
+		Address:        "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+		Statement:      "Sign in to CaribEX. URI: https://evil.example is not a field here.",
+		URI:            "http://localhost:3000/login",
+		Version:        "1",
+		ChainID:        "137",
+		Nonce:          "abc123def456",
+		IssuedAt:       at("2025-01-01T12:00:00Z"),
+		ExpirationTime: at("2025-01-01T12:10:00Z"),
+		NotBefore:      at("2025-01-01T11:59:00Z"),
+		Resources:      []string{"http://localhost:3000/terms", "http://localhost:3000/privacy"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseSiweMessage() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseSiweMessage_Invalid(t *testing.T) {
+	for name, message := range map[string]string{
+		"Wrong domain line": "localhost:3000 says hello\n0xabc\n\nURI: http://localhost:3000\nVersion: 1\nChain ID: 1\nNonce: n\nIssued At: 2025-01-01T12:00:00Z",
+		"Missing Issued At": "localhost:3000 wants you to sign in with your Ethereum account:\n0xabc\n\nURI: http://localhost:3000\nVersion: 1\nChain ID: 1\nNonce: n",
+		"Unknown field":     "localhost:3000 wants you to sign in with your Ethereum account:\n0xabc\n\nURI: http://localhost:3000\nVersion: 1\nChain ID: 1\nNonce: n\nIssued At: 2025-01-01T12:00:00Z\nColour: blue",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseSiweMessage(message); err == nil {
+				t.Error("parseSiweMessage() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+// WebPContentType is the content type of converted images
+const WebPContentType = "image/webp"
+
+// decodeImage decodes a JPEG, PNG or WebP upload once so it can be converted
+// and thumbnailed. It returns nil for formats that are stored as uploaded,
+// such as GIF and SVG.
+func decodeImage(data []byte, contentType string) (image.Image, error) {
+	switch contentType {
+	case "image/jpeg", "image/jpg", "image/png", WebPContentType:
+	default:
+		return nil, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %w", contentType, err)
+	}
+	return img, nil
+}
+
+// ConvertToWebP re-encodes a decoded PNG image as lossless WebP. It reports
+// false, leaving the caller to store the original, for any other format and
+// when the WebP would not be smaller than the originalSize bytes uploaded.
+// JPEGs are kept as they are: a lossless encoding of a photograph is larger
+// than the JPEG it came from.
+func ConvertToWebP(img image.Image, contentType string, originalSize int) ([]byte, bool, error) {
+	if img == nil || contentType != "image/png" {
+		return nil, false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return nil, false, fmt.Errorf("failed to encode webp: %w", err)
+	}
+	if buf.Len() >= originalSize {
+		return nil, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testPNG draws a simple striped logo-like image
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 120))
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 200; x++ {
+			c := color.NRGBA{20, 110, 200, 255}
+			if (x/20+y/20)%2 == 0 {
+				c = color.NRGBA{250, 200, 40, 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// mustDecode decodes an image the way UploadImage does
+func mustDecode(t *testing.T, data []byte, contentType string) image.Image {
+	t.Helper()
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestConvertToWebP(t *testing.T) {
+	data := testPNG(t)
+
+	out, ok, err := ConvertToWebP(mustDecode(t, data, "image/png"), "image/png", len(data))
+	if err != nil {
+		t.Fatalf("ConvertToWebP() error = %v", err)
+	}
+	if !ok {
+		t.Fatal("ConvertToWebP() converted = false, want true")
+	}
+	if len(out) >= len(data) {
+		t.Errorf("webp size = %d, want less than png size %d", len(out), len(data))
+	}
+	if string(out[0:4]) != "RIFF" || string(out[8:12]) != "WEBP" {
+		t.Errorf("output is not a WebP file: % x", out[:16])
+	}
+}
+
+func TestConvertToWebP_SkipsOtherFormats(t *testing.T) {
+	// JPEGs are decoded for thumbnails but stay JPEG
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, mustDecode(t, testPNG(t), "image/png"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := ConvertToWebP(mustDecode(t, jpg.Bytes(), "image/jpeg"), "image/jpeg", jpg.Len()); err != nil || ok {
+		t.Errorf("ConvertToWebP(image/jpeg) = %v, %v; want skipped", ok, err)
+	}
+
+	for _, contentType := range []string{"image/webp", "image/svg+xml", "image/gif", "application/pdf"} {
+		_, ok, err := ConvertToWebP(nil, contentType, 100)
+		if err != nil || ok {
+			t.Errorf("ConvertToWebP(%q) = %v, %v; want skipped", contentType, ok, err)
+		}
+	}
+}
+
+func TestDecodeImage(t *testing.T) {
+	if _, err := decodeImage([]byte("not a png"), "image/png"); err == nil {
+		t.Error("decodeImage() error = nil, want decode error")
+	}
+	for _, contentType := range []string{"image/svg+xml", "image/gif"} {
+		if img, err := decodeImage([]byte("not decoded"), contentType); img != nil || err != nil {
+			t.Errorf("decodeImage(%q) = %v, %v; want skipped", contentType, img, err)
+		}
+	}
+}
+
+// fakeSupabase records the objects uploaded to it
+type fakeSupabase struct {
+	mu           sync.Mutex
+	contentTypes map[string]string
+}
+
+func (f *fakeSupabase) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	f.mu.Lock()
+	f.contentTypes[strings.TrimPrefix(r.URL.Path, "/object/")] = r.Header.Get("Content-Type")
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"Key":"ok"}`))
+}
+
+func TestUploadImage_ConvertsPNGToWebP(t *testing.T) {
+	tests := []struct {
+		name         string
+		keepOriginal bool
+	}{
+		{"replace original", false},
+		{"keep original", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSupabase{contentTypes: map[string]string{}}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			s, _ := NewSupabaseStorage(Config{
+				URL:           srv.URL,
+				Key:           "test-key",
+				Bucket:        "test-bucket",
+				ConvertToWebP: true,
+				KeepOriginal:  tt.keepOriginal,
+			})
+
+			data := testPNG(t)
+			file, header := createMockFile(t, "logo.png", "image/png", data)
+			defer file.Close()
+
+			result, err := s.UploadImage(context.TODO(), file, header, "products")
+			if err != nil {
+				t.Fatalf("UploadImage() error = %v", err)
+			}
+			if !result.Converted || result.ContentType != WebPContentType {
+				t.Errorf("result converted = %v, content type = %q; want converted %q", result.Converted, result.ContentType, WebPContentType)
+			}
+			if !strings.HasSuffix(result.URL, ".webp") {
+				t.Errorf("URL = %q, want .webp", result.URL)
+			}
+			if result.OriginalSize != int64(len(data)) || result.SavedBytes() <= 0 {
+				t.Errorf("sizes = %d -> %d, want a smaller webp", result.OriginalSize, result.Size)
+			}
+
+			var webpTypes, pngTypes int
+			for path, contentType := range fake.contentTypes {
+				switch {
+				case strings.HasSuffix(path, ".webp") && contentType == WebPContentType:
+					webpTypes++
+				case strings.HasSuffix(path, ".png") && contentType == "image/png":
+					pngTypes++
+				default:
+					t.Errorf("unexpected upload %s (%s)", path, contentType)
+				}
+			}
+			if webpTypes != 1 {
+				t.Errorf("webp uploads = %d, want 1", webpTypes)
+			}
+			wantOriginals := 0
+			if tt.keepOriginal {
+				wantOriginals = 1
+			}
+			if pngTypes != wantOriginals {
+				t.Errorf("original uploads = %d, want %d", pngTypes, wantOriginals)
+			}
+			if (result.OriginalURL != "") != tt.keepOriginal {
+				t.Errorf("OriginalURL = %q, keepOriginal %v", result.OriginalURL, tt.keepOriginal)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	// Register the decoders image.DecodeConfig needs for every accepted
+	// raster format
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// ErrImageDimensions is returned for an image outside the allowed size or
+// aspect ratio
+var ErrImageDimensions = errors.New("image dimensions are not allowed")
+
+// DefaultMaxImageDimension is the largest width or height accepted when no
+// limit is configured
+const DefaultMaxImageDimension = 4096
+
+// DimensionLimits bounds the pixel size of uploaded images. Zero leaves a
+// minimum or the aspect ratio unchecked.
+type DimensionLimits struct {
+	MaxWidth  int
+	MaxHeight int
+	MinWidth  int
+	MinHeight int
+	// MaxAspectRatio is the largest allowed ratio of the longer side to the
+	// shorter, e.g. 3 rejects banners wider than 3:1
+	MaxAspectRatio float64
+}
+
+// withDefaults fills in the default maximum for unset limits
+func (l DimensionLimits) withDefaults() DimensionLimits {
+	if l.MaxWidth <= 0 {
+		l.MaxWidth = DefaultMaxImageDimension
+	}
+	if l.MaxHeight <= 0 {
+		l.MaxHeight = DefaultMaxImageDimension
+	}
+	return l
+}
+
+// CheckDimensions reads the image header and rejects an image outside the
+// limits. SVGs have no fixed pixel size and are not checked.
+func CheckDimensions(data []byte, contentType string, limits DimensionLimits) error {
+	if contentType == "image/svg+xml" {
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to read image dimensions: %w", err)
+	}
+
+	limits = limits.withDefaults()
+	w, h := cfg.Width, cfg.Height
+	switch {
+	case w > limits.MaxWidth || h > limits.MaxHeight:
+		return fmt.Errorf("%w: %dx%d exceeds the maximum of %dx%d", ErrImageDimensions, w, h, limits.MaxWidth, limits.MaxHeight)
+	case w < limits.MinWidth || h < limits.MinHeight:
+		return fmt.Errorf("%w: %dx%d is below the minimum of %dx%d", ErrImageDimensions, w, h, limits.MinWidth, limits.MinHeight)
+	case w == 0 || h == 0:
+		return fmt.Errorf("%w: image is empty", ErrImageDimensions)
+	}
+
+	if limits.MaxAspectRatio > 0 {
+		ratio := float64(max(w, h)) / float64(min(w, h))
+		if ratio > limits.MaxAspectRatio {
+			return fmt.Errorf("%w: aspect ratio %.2f:1 exceeds the maximum of %.2f:1", ErrImageDimensions, ratio, limits.MaxAspectRatio)
+		}
+	}
+	return nil
+}
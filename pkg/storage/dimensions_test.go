@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	storagego "github.com/supabase-community/storage-go"
+)
+
+// sizedPNG encodes a blank PNG of the given size
+func sizedPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckDimensions(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h    int
+		limits  DimensionLimits
+		wantErr bool
+	}{
+		{"Within the defaults", 200, 120, DimensionLimits{}, false},
+		{"Wider than the maximum", 200, 120, DimensionLimits{MaxWidth: 100}, true},
+		{"Taller than the maximum", 200, 120, DimensionLimits{MaxHeight: 100}, true},
+		{"Larger than the default maximum", DefaultMaxImageDimension + 1, 10, DimensionLimits{}, true},
+		{"Below the minimum", 200, 120, DimensionLimits{MinWidth: 300}, true},
+		{"At the minimum", 200, 120, DimensionLimits{MinWidth: 200, MinHeight: 120}, false},
+		{"Within the aspect ratio", 200, 120, DimensionLimits{MaxAspectRatio: 2}, false},
+		{"Beyond the aspect ratio", 400, 100, DimensionLimits{MaxAspectRatio: 2}, true},
+		{"Tall beyond the aspect ratio", 100, 400, DimensionLimits{MaxAspectRatio: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDimensions(sizedPNG(t, tt.w, tt.h), "image/png", tt.limits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDimensions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrImageDimensions) {
+				t.Errorf("CheckDimensions() error = %v, want ErrImageDimensions", err)
+			}
+		})
+	}
+}
+
+func TestCheckDimensions_SkipsSVG(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="99999" height="1"></svg>`)
+	if err := CheckDimensions(svg, "image/svg+xml", DimensionLimits{MaxWidth: 10}); err != nil {
+		t.Errorf("CheckDimensions() error = %v, want SVG skipped", err)
+	}
+}
+
+func TestCheckDimensions_Undecodable(t *testing.T) {
+	err := CheckDimensions([]byte("not an image"), "image/png", DimensionLimits{})
+	if err == nil || errors.Is(err, ErrImageDimensions) {
+		t.Errorf("CheckDimensions() error = %v, want a decode error", err)
+	}
+}
+
+func TestUploadImage_RejectsOversizedImage(t *testing.T) {
+	s, _ := NewSupabaseStorage(Config{
+		URL:        "https://test.supabase.co",
+		Bucket:     "test-bucket",
+		Dimensions: DimensionLimits{MaxWidth: 100, MaxHeight: 100},
+	})
+	s.client = &mockObjectClient{resp: storagego.FileUploadResponse{Key: "test-bucket/products/photo.png"}}
+
+	file, header := createMockFile(t, "photo.png", "image/png", sizedPNG(t, 64, 64))
+	if _, err := s.UploadImage(context.TODO(), file, header, "products"); err != nil {
+		t.Errorf("UploadImage() of a 64x64 image error = %v", err)
+	}
+	file.Close()
+
+	file, header = createMockFile(t, "photo.png", "image/png", sizedPNG(t, 200, 120))
+	defer file.Close()
+	if _, err := s.UploadImage(context.TODO(), file, header, "products"); !errors.Is(err, ErrImageDimensions) {
+		t.Errorf("UploadImage() of a 200x120 image error = %v, want ErrImageDimensions", err)
+	}
+}
@@ -1,30 +1,95 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"path"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
+// maxDeleteBatch is the most keys S3 accepts in a single DeleteObjects call
+const maxDeleteBatch = 1000
+
+// Presigned URLs must live between one minute and seven days; S3 rejects
+// SigV4 signatures valid for longer than a week.
+const (
+	MinPresignExpiryMinutes = 1
+	MaxPresignExpiryMinutes = 7 * 24 * 60
+)
+
+// ErrInvalidExpiry is returned when a presigned URL lifetime is out of bounds
+var ErrInvalidExpiry = errors.New("presigned URL expiry out of range")
+
+// BatchDeleter removes many stored objects in as few round-trips as possible
+type BatchDeleter interface {
+	DeleteFiles(keys []string) error
+	KeyFromURL(rawURL string) (string, bool)
+}
+
+// Presigner issues time-limited URLs for private objects
+type Presigner interface {
+	GeneratePresignedURL(key string, expirationMinutes int) (string, error)
+	GeneratePresignedDownloadURL(key, filename string, expirationMinutes int) (string, error)
+	KeyFromURL(rawURL string) (string, bool)
+}
+
+// Uploader stores files privately in S3-compatible storage and issues
+// presigned URLs to read them back. S3Service implements it; depending on the
+// interface lets tests swap in a fake without network calls.
+type Uploader interface {
+	BatchDeleter
+	Presigner
+	UploadFile(fileHeader *multipart.FileHeader, prefix string) (UploadFileResult, error)
+	UploadFiles(files []*multipart.FileHeader, prefix string) ([]UploadFileResult, error)
+	DeleteFile(key string) error
+}
+
+var _ Uploader = (*S3Service)(nil)
+
+// BatchDeleteError reports the keys a batch delete could not remove while
+// the rest of the batch succeeded
+type BatchDeleteError struct {
+	// Failed maps each key that was not deleted to the reason S3 gave
+	Failed map[string]string
+}
+
+func (e *BatchDeleteError) Error() string {
+	keys := make([]string, 0, len(e.Failed))
+	for key := range e.Failed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	details := make([]string, 0, len(keys))
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("%s (%s)", key, e.Failed[key]))
+	}
+	return fmt.Sprintf("failed to delete %d file(s): %s", len(keys), strings.Join(details, ", "))
+}
+
 // S3Service handles file uploads to S3-compatible storage
 type S3Service struct {
 	uploader *s3manager.Uploader
-	s3Client *s3.S3
+	s3Client s3iface.S3API
 	bucket   string
 }
 
 // NewS3Service creates a new S3 service
-func NewS3Service(uploader *s3manager.Uploader, s3Client *s3.S3, bucket string) *S3Service {
+func NewS3Service(uploader *s3manager.Uploader, s3Client s3iface.S3API, bucket string) *S3Service {
 	return &S3Service{
 		uploader: uploader,
 		s3Client: s3Client,
@@ -128,15 +193,31 @@ func (s *S3Service) UploadFile(fileHeader *multipart.FileHeader, prefix string)
 	}, nil
 }
 
-// GeneratePresignedURL generates a presigned URL for downloading a file
+// GeneratePresignedURL generates a presigned URL for downloading a file.
+// expirationMinutes must be within [MinPresignExpiryMinutes, MaxPresignExpiryMinutes].
 func (s *S3Service) GeneratePresignedURL(key string, expirationMinutes int) (string, error) {
-	req, _ := s.s3Client.GetObjectRequest(&s3.GetObjectInput{
+	return s.GeneratePresignedDownloadURL(key, "", expirationMinutes)
+}
+
+// GeneratePresignedDownloadURL generates a presigned URL that makes browsers
+// save the file under the given human-readable name instead of its key.
+// An empty filename leaves the response disposition untouched.
+func (s *S3Service) GeneratePresignedDownloadURL(key, filename string, expirationMinutes int) (string, error) {
+	if expirationMinutes < MinPresignExpiryMinutes || expirationMinutes > MaxPresignExpiryMinutes {
+		return "", fmt.Errorf("%w: %d minutes, must be between %d and %d",
+			ErrInvalidExpiry, expirationMinutes, MinPresignExpiryMinutes, MaxPresignExpiryMinutes)
+	}
+
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
-	})
+	}
+	if filename != "" {
+		input.ResponseContentDisposition = aws.String(ContentDisposition(filename))
+	}
+	req, _ := s.s3Client.GetObjectRequest(input)
 
 	// Generate presigned URL
-	//convert to time
 	expirationDuration := time.Duration(expirationMinutes) * time.Minute
 	urlStr, err := req.Presign(expirationDuration)
 	if err != nil {
@@ -176,32 +257,53 @@ func (s *S3Service) DeleteFile(key string) error {
 	return nil
 }
 
-// DeleteFiles deletes multiple files from S3
+// DeleteFiles deletes multiple files from S3 in batches of up to 1000 keys.
+// Keys S3 reports as failed are collected into a *BatchDeleteError.
 func (s *S3Service) DeleteFiles(keys []string) error {
-	var objects []*s3.ObjectIdentifier
-	for _, key := range keys {
-		objects = append(objects, &s3.ObjectIdentifier{
-			Key: aws.String(key),
-		})
+	if len(keys) == 0 {
+		return nil
 	}
 
-	if len(objects) == 0 {
-		return nil
+	failed := make(map[string]string)
+
+	for start := 0; start < len(keys); start += maxDeleteBatch {
+		end := min(start+maxDeleteBatch, len(keys))
+
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, key := range keys[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{
+				Key: aws.String(key),
+			})
+		}
+
+		output, err := s.s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true), // only failures are listed in the response
+			},
+		})
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int("count", len(keys)).
+				Msg("failed to delete files from S3")
+			return fmt.Errorf("failed to delete files: %w", err)
+		}
+
+		for _, deleteErr := range output.Errors {
+			failed[aws.StringValue(deleteErr.Key)] = aws.StringValue(deleteErr.Message)
+		}
 	}
 
-	_, err := s.s3Client.DeleteObjects(&s3.DeleteObjectsInput{
-		Bucket: aws.String(s.bucket),
-		Delete: &s3.Delete{
-			Objects: objects,
-			Quiet:   aws.Bool(false),
-		},
-	})
-	if err != nil {
-		log.Error().
+	if len(failed) > 0 {
+		err := &BatchDeleteError{Failed: failed}
+		log.Warn().
 			Err(err).
 			Int("count", len(keys)).
-			Msg("failed to delete files from S3")
-		return fmt.Errorf("failed to delete files: %w", err)
+			Int("failed", len(failed)).
+			Msg("some files could not be deleted from S3")
+		return err
 	}
 
 	log.Info().
@@ -211,6 +313,33 @@ func (s *S3Service) DeleteFiles(keys []string) error {
 	return nil
 }
 
+// objectPathPrefixes are the paths that precede /<bucket>/<key> in the URLs
+// of stored objects: public object URLs, S3-compatible endpoints and plain
+// path-style S3
+var objectPathPrefixes = []string{"/storage/v1/object/public", "/storage/v1/object", "/storage/v1/s3", ""}
+
+// KeyFromURL translates a stored object URL into its key within the bucket.
+// It reports false for anything that is not an http(s) URL into the bucket,
+// bare keys included, so a caller can never be steered to an arbitrary key.
+func (s *S3Service) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+
+	for _, prefix := range objectPathPrefixes {
+		key, ok := strings.CutPrefix(u.Path, prefix+"/"+s.bucket+"/")
+		if !ok {
+			continue
+		}
+		if key == "" || slices.Contains(strings.Split(key, "/"), "..") {
+			return "", false
+		}
+		return key, true
+	}
+	return "", false
+}
+
 // detectContentType detects the content type of a file
 func detectContentType(file multipart.File) (string, error) {
 	// Read first 512 bytes for detection
@@ -240,8 +369,3 @@ func isAllowedContentType(contentType string) bool {
 
 	return allowedTypes[contentType]
 }
-
-// getDuration converts minutes to time.Duration
-func getDuration(minutes int) int64 {
-	return int64(minutes * 60) // seconds
-}
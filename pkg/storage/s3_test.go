@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+func newTestS3Service(t *testing.T) *S3Service {
+	t.Helper()
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String("http://localhost:9000"),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("test-key", "test-secret", ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewS3Service(nil, s3.New(sess), "products")
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"invoice.pdf", `attachment; filename="invoice.pdf"`},
+		{"my product image.jpg", `attachment; filename="my_product_image.jpg"`},
+		{`evil"; filename=x.exe`, `attachment; filename="evil___filename_x.exe"`},
+		{"", `attachment; filename="download"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := ContentDisposition(tt.filename); got != tt.want {
+				t.Errorf("ContentDisposition(%q) = %s, want %s", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePresignedDownloadURL(t *testing.T) {
+	svc := newTestS3Service(t)
+
+	t.Run("Includes disposition override", func(t *testing.T) {
+		urlStr, err := svc.GeneratePresignedDownloadURL("products/2f1c.pdf", "Order Invoice.pdf", 15)
+		if err != nil {
+			t.Fatalf("GeneratePresignedDownloadURL() error = %v", err)
+		}
+		u, err := url.Parse(urlStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `attachment; filename="Order_Invoice.pdf"`
+		if got := u.Query().Get("response-content-disposition"); got != want {
+			t.Errorf("response-content-disposition = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Plain presign has no override", func(t *testing.T) {
+		urlStr, err := svc.GeneratePresignedURL("products/2f1c.pdf", 15)
+		if err != nil {
+			t.Fatalf("GeneratePresignedURL() error = %v", err)
+		}
+		u, err := url.Parse(urlStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Query().Has("response-content-disposition") {
+			t.Error("GeneratePresignedURL() set a disposition override")
+		}
+	})
+}
+
+func TestGeneratePresignedURL_Expiry(t *testing.T) {
+	svc := newTestS3Service(t)
+
+	tests := []struct {
+		name    string
+		minutes int
+		wantErr bool
+	}{
+		{"Zero", 0, true},
+		{"Negative", -5, true},
+		{"Over max", MaxPresignExpiryMinutes + 1, true},
+		{"Min", MinPresignExpiryMinutes, false},
+		{"Max", MaxPresignExpiryMinutes, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlStr, err := svc.GeneratePresignedURL("products/2f1c.pdf", tt.minutes)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidExpiry) {
+					t.Fatalf("GeneratePresignedURL(%d) error = %v, want ErrInvalidExpiry", tt.minutes, err)
+				}
+				if urlStr != "" {
+					t.Errorf("GeneratePresignedURL(%d) returned URL %q alongside error", tt.minutes, urlStr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GeneratePresignedURL(%d) error = %v", tt.minutes, err)
+			}
+			u, err := url.Parse(urlStr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprint(tt.minutes * 60)
+			if got := u.Query().Get("X-Amz-Expires"); got != want {
+				t.Errorf("X-Amz-Expires = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// mockS3Client answers DeleteObjects with a canned per-key failure list
+type mockS3Client struct {
+	s3iface.S3API
+	failKeys  map[string]string
+	requested [][]string
+}
+
+func (m *mockS3Client) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	var keys []string
+	output := &s3.DeleteObjectsOutput{}
+	for _, obj := range input.Delete.Objects {
+		key := aws.StringValue(obj.Key)
+		keys = append(keys, key)
+		if reason, ok := m.failKeys[key]; ok {
+			output.Errors = append(output.Errors, &s3.Error{
+				Key:     aws.String(key),
+				Code:    aws.String("AccessDenied"),
+				Message: aws.String(reason),
+			})
+		}
+	}
+	m.requested = append(m.requested, keys)
+	return output, nil
+}
+
+func TestDeleteFiles_PartialFailure(t *testing.T) {
+	client := &mockS3Client{failKeys: map[string]string{"products/b.png": "Access Denied"}}
+	svc := NewS3Service(nil, client, "products")
+
+	err := svc.DeleteFiles([]string{"products/a.png", "products/b.png", "products/c.png"})
+
+	var batchErr *BatchDeleteError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("DeleteFiles() error = %v, want *BatchDeleteError", err)
+	}
+	if len(batchErr.Failed) != 1 || batchErr.Failed["products/b.png"] != "Access Denied" {
+		t.Errorf("Failed = %v, want only products/b.png", batchErr.Failed)
+	}
+	if len(client.requested) != 1 || len(client.requested[0]) != 3 {
+		t.Errorf("requested batches = %v, want one batch of 3 keys", client.requested)
+	}
+}
+
+func TestDeleteFiles_Batches(t *testing.T) {
+	client := &mockS3Client{}
+	svc := NewS3Service(nil, client, "products")
+
+	keys := make([]string, maxDeleteBatch+1)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("products/%d.png", i)
+	}
+	if err := svc.DeleteFiles(keys); err != nil {
+		t.Fatalf("DeleteFiles() error = %v", err)
+	}
+	if len(client.requested) != 2 {
+		t.Errorf("DeleteObjects calls = %d, want 2", len(client.requested))
+	}
+}
+
+func TestKeyFromURL(t *testing.T) {
+	svc := NewS3Service(nil, &mockS3Client{}, "products")
+
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"https://abc.supabase.co/storage/v1/object/public/products/products/a1.jpg", "products/a1.jpg", true},
+		{"https://abc.supabase.co/storage/v1/s3/products/products/a1.jpg", "products/a1.jpg", true},
+		// Bare keys, other buckets and other schemes are not ours to resolve
+		{"products/a1.jpg", "", false},
+		{"documents/user-1/id.pdf", "", false},
+		{"https://abc.supabase.co/storage/v1/object/public/other/products/a1.jpg", "", false},
+		{"ftp://abc.supabase.co/products/products/a1.jpg", "", false},
+		{"https://abc.supabase.co/storage/v1/object/public/products/", "", false},
+		{"https://abc.supabase.co/storage/v1/object/public/products/products/../documents/id.pdf", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := svc.KeyFromURL(tt.url)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("KeyFromURL(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,12 +24,75 @@ type Service interface {
 	GetPublicURL(path string) string
 }
 
+// ImageUploader is implemented by services that report how an uploaded image
+// was stored, including any format conversion
+type ImageUploader interface {
+	UploadImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (*ImageUploadResult, error)
+}
+
+// ImageUploadResult describes a stored image
+type ImageUploadResult struct {
+	// URL is the image to serve: the WebP when the upload was converted
+	URL         string
+	ContentType string
+	// OriginalURL is set when the original upload was kept alongside its
+	// conversion
+	OriginalURL  string
+	OriginalSize int64
+	Size         int64
+	// Converted reports whether the stored image was re-encoded
+	Converted bool
+	// Thumbnails are the resized copies stored alongside the image, smallest
+	// first
+	Thumbnails []Thumbnail
+}
+
+// SavedBytes is how much smaller the stored image is than the upload
+func (r *ImageUploadResult) SavedBytes() int64 {
+	return r.OriginalSize - r.Size
+}
+
+// ErrContentTypeMismatch is returned when an upload's bytes are not the type
+// its Content-Type header declares
+var ErrContentTypeMismatch = errors.New("file content does not match its declared type")
+
+// ErrProviderRejected is returned when the storage provider answers a request
+// with a failure
+var ErrProviderRejected = errors.New("storage provider rejected the request")
+
+// ProviderError carries the status and message of a failed provider response
+type ProviderError struct {
+	Status  string
+	Message string
+}
+
+func (e *ProviderError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("%s: %s", ErrProviderRejected, e.Message)
+	}
+	return fmt.Sprintf("%s (status %s): %s", ErrProviderRejected, e.Status, e.Message)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return ErrProviderRejected
+}
+
+// objectClient is the subset of the Supabase storage client the service uses
+type objectClient interface {
+	UploadFile(bucketID, relativePath string, data io.Reader, fileOptions ...storagego.FileOptions) (storagego.FileUploadResponse, error)
+	RemoveFile(bucketID string, paths []string) ([]storagego.FileUploadResponse, error)
+}
+
 // SupabaseStorage implements the Service interface using Supabase Storage
 type SupabaseStorage struct {
-	client     *storagego.Client
-	bucket     string
-	baseURL    string
-	maxFileSize int64
+	client        objectClient
+	bucket        string
+	baseURL       string
+	maxFileSize   int64
+	convertToWebP bool
+	keepOriginal  bool
+	dimensions    DimensionLimits
+	thumbnails    []int
 }
 
 // Config holds the configuration for Supabase Storage
@@ -34,6 +101,17 @@ type Config struct {
 	Key         string
 	Bucket      string
 	MaxFileSize int64
+	// ConvertToWebP re-encodes PNG uploads as lossless WebP when that makes
+	// them smaller
+	ConvertToWebP bool
+	// KeepOriginal also stores the original upload when it was converted
+	KeepOriginal bool
+	// Dimensions bounds the pixel size of uploaded images; unset maximums
+	// default to DefaultMaxImageDimension
+	Dimensions DimensionLimits
+	// ThumbnailWidths are the widths in pixels of the resized copies stored
+	// with each JPEG, PNG or WebP upload; empty stores none
+	ThumbnailWidths []int
 }
 
 // NewSupabaseStorage creates a new Supabase storage service
@@ -47,47 +125,185 @@ func NewSupabaseStorage(cfg Config) (*SupabaseStorage, error) {
 	}
 
 	return &SupabaseStorage{
-		client:      client,
-		bucket:      cfg.Bucket,
-		baseURL:     cfg.URL,
-		maxFileSize: maxFileSize,
+		client:        client,
+		bucket:        cfg.Bucket,
+		baseURL:       cfg.URL,
+		maxFileSize:   maxFileSize,
+		convertToWebP: cfg.ConvertToWebP,
+		keepOriginal:  cfg.KeepOriginal,
+		dimensions:    cfg.Dimensions,
+		thumbnails:    thumbnailWidths(cfg.ThumbnailWidths),
 	}, nil
 }
 
 // UploadFile uploads a file to Supabase Storage and returns the public URL
 func (s *SupabaseStorage) UploadFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (string, error) {
+	result, err := s.UploadImage(ctx, file, header, folder)
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// UploadImage uploads an image to Supabase Storage, converting it to WebP
+// first when configured and storing a thumbnail for each configured width.
+// Images outside the configured dimensions are rejected.
+func (s *SupabaseStorage) UploadImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (*ImageUploadResult, error) {
 	// Validate file size
 	if header.Size > s.maxFileSize {
-		return "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.maxFileSize)
+		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.maxFileSize)
 	}
 
 	// Validate file type (images only)
 	contentType := header.Header.Get("Content-Type")
 	if !isValidImageType(contentType) {
-		return "", fmt.Errorf("invalid file type: %s. Only images are allowed", contentType)
+		return nil, fmt.Errorf("invalid file type: %s. Only images are allowed", contentType)
 	}
 
+	// The declared type comes from the client, so check it against the bytes
+	detected, err := sniffImageType(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+	if !isValidImageType(detected) {
+		return nil, fmt.Errorf("invalid file type: content is %s. Only images are allowed", detected)
+	}
+	if detected != normalizeImageType(contentType) {
+		return nil, fmt.Errorf("%w: declared %s but content is %s", ErrContentTypeMismatch, contentType, detected)
+	}
+	contentType = detected
+
 	// Read file content
 	fileBytes, err := io.ReadAll(file)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if err := CheckDimensions(fileBytes, contentType, s.dimensions); err != nil {
+		return nil, err
 	}
 
 	// Generate unique filename
 	ext := filepath.Ext(header.Filename)
 	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("%s/%d_%s%s", folder, timestamp, sanitizeFilename(header.Filename), ext)
+	base := fmt.Sprintf("%s/%d_%s", folder, timestamp, sanitizeFilename(header.Filename))
+
+	result := &ImageUploadResult{
+		ContentType:  contentType,
+		OriginalSize: int64(len(fileBytes)),
+		Size:         int64(len(fileBytes)),
+	}
+
+	// Decode once for both the conversion and the thumbnails
+	var img image.Image
+	if (s.convertToWebP && contentType == "image/png") || len(s.thumbnails) > 0 {
+		if img, err = decodeImage(fileBytes, contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.convertToWebP {
+		converted, ok, err := ConvertToWebP(img, contentType, len(fileBytes))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if s.keepOriginal {
+				if err := s.put(base+ext, fileBytes, contentType); err != nil {
+					return nil, err
+				}
+				result.OriginalURL = s.GetPublicURL(base + ext)
+			}
+			if err := s.put(base+".webp", converted, WebPContentType); err != nil {
+				return nil, err
+			}
+			result.URL = s.GetPublicURL(base + ".webp")
+			result.ContentType = WebPContentType
+			result.Size = int64(len(converted))
+			result.Converted = true
+			ext = ".webp"
+		}
+	}
 
-	// Upload to Supabase Storage
-	_, err = s.client.UploadFile(s.bucket, filename, bytes.NewReader(fileBytes))
+	if !result.Converted {
+		if err := s.put(base+ext, fileBytes, contentType); err != nil {
+			return nil, err
+		}
+		result.URL = s.GetPublicURL(base + ext)
+	}
+
+	// Thumbnails follow the stored image's format
+	thumbs, err := makeThumbnails(img, result.ContentType, s.thumbnails)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload file to storage: %w", err)
+		return nil, err
 	}
+	for _, thumb := range thumbs {
+		filename := fmt.Sprintf("%s_w%d%s", base, thumb.width, ext)
+		if err := s.put(filename, thumb.data, result.ContentType); err != nil {
+			return nil, err
+		}
+		result.Thumbnails = append(result.Thumbnails, Thumbnail{
+			Width:  thumb.width,
+			Height: thumb.height,
+			URL:    s.GetPublicURL(filename),
+			Size:   int64(len(thumb.data)),
+		})
+	}
+	return result, nil
+}
 
-	// Get public URL
-	publicURL := s.GetPublicURL(filename)
+// thumbnailWidths sorts and de-duplicates the configured widths
+func thumbnailWidths(widths []int) []int {
+	sorted := slices.Clone(widths)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// put uploads data to the bucket under filename
+func (s *SupabaseStorage) put(filename string, data []byte, contentType string) error {
+	resp, err := s.client.UploadFile(s.bucket, filename, bytes.NewReader(data), storagego.FileOptions{ContentType: &contentType})
+	if err == nil {
+		err = checkResponse(resp)
+		if err == nil && resp.Key == "" {
+			// A stored object always comes back with its key
+			err = &ProviderError{Message: "upload was not acknowledged"}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload file to storage: %w", providerError(err))
+	}
+	return nil
+}
+
+// checkResponse reports a failure the provider put in a response body rather
+// than in the HTTP status
+func checkResponse(resp storagego.FileUploadResponse) error {
+	if resp.Error == "" && (resp.Code == "" || strings.HasPrefix(resp.Code, "2")) {
+		return nil
+	}
+	message := resp.Message
+	if message == "" {
+		message = resp.Error
+	}
+	return &ProviderError{Status: resp.Code, Message: message}
+}
 
-	return publicURL, nil
+// providerError turns the client's error for a non-2xx response into a
+// ProviderError, so it always carries a message and matches
+// ErrProviderRejected
+func providerError(err error) error {
+	var storageErr *storagego.StorageError
+	if !errors.As(err, &storageErr) {
+		return err
+	}
+	e := &ProviderError{Message: storageErr.Message}
+	if storageErr.Status != 0 {
+		e.Status = fmt.Sprint(storageErr.Status)
+	}
+	if e.Message == "" {
+		e.Message = "no error message"
+	}
+	return e
 }
 
 // DeleteFile deletes a file from Supabase Storage
@@ -95,9 +311,16 @@ func (s *SupabaseStorage) DeleteFile(ctx context.Context, path string) error {
 	// Extract the path from the full URL if needed
 	cleanPath := extractPathFromURL(path, s.baseURL, s.bucket)
 
-	_, err := s.client.RemoveFile(s.bucket, []string{cleanPath})
+	resp, err := s.client.RemoveFile(s.bucket, []string{cleanPath})
+	if err == nil {
+		for _, r := range resp {
+			if err = checkResponse(r); err != nil {
+				break
+			}
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+		return fmt.Errorf("failed to delete file: %w", providerError(err))
 	}
 
 	return nil
@@ -128,6 +351,67 @@ func isValidImageType(contentType string) bool {
 	return false
 }
 
+// normalizeImageType maps aliases to the type content sniffing reports
+func normalizeImageType(contentType string) string {
+	if contentType == "image/jpg" {
+		return "image/jpeg"
+	}
+	return contentType
+}
+
+// sniffImageType detects an upload's type from its first 512 bytes and
+// rewinds the file. http.DetectContentType does not know SVG, so XML or text
+// that opens with an <svg> element is reported as image/svg+xml.
+func sniffImageType(file multipart.File) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to reset file reader: %w", err)
+	}
+
+	head := buf[:n]
+	detected := http.DetectContentType(head)
+	if strings.HasPrefix(detected, "text/xml") || strings.HasPrefix(detected, "text/plain") {
+		if isSVG(head) {
+			return "image/svg+xml", nil
+		}
+	}
+	return detected, nil
+}
+
+// isSVG reports whether head opens an SVG document, allowing for an XML
+// declaration, comments and a doctype before the <svg> element
+func isSVG(head []byte) bool {
+	s := strings.TrimPrefix(string(head), "\ufeff")
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		switch {
+		case strings.HasPrefix(s, "<svg"):
+			return true
+		case strings.HasPrefix(s, "<?"):
+			s = skipPast(s, "?>")
+		case strings.HasPrefix(s, "<!--"):
+			s = skipPast(s, "-->")
+		case strings.HasPrefix(s, "<!"):
+			s = skipPast(s, ">")
+		default:
+			return false
+		}
+	}
+}
+
+// skipPast drops s up to and including the first end, or returns "" when
+// there is none
+func skipPast(s, end string) string {
+	if i := strings.Index(s, end); i >= 0 {
+		return s[i+len(end):]
+	}
+	return ""
+}
+
 // sanitizeFilename removes unsafe characters from filename
 func sanitizeFilename(filename string) string {
 	// Remove file extension for sanitization
@@ -151,6 +435,28 @@ func sanitizeFilename(filename string) string {
 	return name
 }
 
+// ContentDisposition builds an attachment Content-Disposition header value
+// with a sanitized filename, for presigned downloads and served files alike
+func ContentDisposition(filename string) string {
+	name := sanitizeFilename(filename)
+	if name == "" {
+		name = "download"
+	}
+
+	// Keep the extension so the browser can pick a handler, minus anything unsafe
+	ext := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.TrimPrefix(filepath.Ext(filename), "."))
+	if ext != "" {
+		name += "." + ext
+	}
+
+	return fmt.Sprintf("attachment; filename=%q", name)
+}
+
 // extractPathFromURL extracts the file path from a full URL
 func extractPathFromURL(url, baseURL, bucket string) string {
 	// If it's already a path, return as is
@@ -3,9 +3,16 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
 	"mime/multipart"
 	"net/textproto"
+	"strings"
 	"testing"
+
+	storagego "github.com/supabase-community/storage-go"
 )
 
 func TestIsValidImageType(t *testing.T) {
@@ -284,3 +291,161 @@ func TestUploadFile_ContentTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadImage_DetectsContentFromBytes(t *testing.T) {
+	var jpegData bytes.Buffer
+	if err := jpeg.Encode(&jpegData, image.NewGray(image.Rect(0, 0, 16, 16)), nil); err != nil {
+		t.Fatal(err)
+	}
+	svg := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- logo -->
+<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"></svg>`)
+	exe := append([]byte("MZ\x90\x00\x03\x00\x00\x00"), make([]byte, 600)...)
+
+	tests := []struct {
+		name         string
+		contentType  string
+		content      []byte
+		wantErr      bool
+		wantMismatch bool
+	}{
+		{"PNG declared as PNG", "image/png", testPNG(t), false, false},
+		{"JPEG declared as image/jpg", "image/jpg", jpegData.Bytes(), false, false},
+		{"SVG declared as SVG", "image/svg+xml", svg, false, false},
+		{"Executable declared as PNG", "image/png", exe, true, false},
+		{"HTML declared as SVG", "image/svg+xml", []byte("<html><script>alert(1)</script></html>"), true, false},
+		{"JPEG declared as PNG", "image/png", jpegData.Bytes(), true, true},
+		{"PNG declared as SVG", "image/svg+xml", testPNG(t), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &recordingObjectClient{}
+			s, _ := NewSupabaseStorage(Config{URL: "https://test.supabase.co", Bucket: "test-bucket"})
+			s.client = client
+
+			file, header := createMockFile(t, "upload.png", tt.contentType, tt.content)
+			defer file.Close()
+
+			_, err := s.UploadImage(context.TODO(), file, header, "products")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrContentTypeMismatch) != tt.wantMismatch {
+				t.Errorf("UploadImage() error = %v, want mismatch %v", err, tt.wantMismatch)
+			}
+			if err != nil {
+				if len(client.uploads) != 0 {
+					t.Error("rejected file was uploaded")
+				}
+				return
+			}
+			// Sniffing must not consume the start of the file
+			if !bytes.Equal(client.uploads[0], tt.content) {
+				t.Errorf("uploaded %d bytes, want the original %d", len(client.uploads[0]), len(tt.content))
+			}
+		})
+	}
+}
+
+// recordingObjectClient keeps the data of every upload
+type recordingObjectClient struct {
+	uploads [][]byte
+}
+
+func (c *recordingObjectClient) UploadFile(bucketID, relativePath string, data io.Reader, fileOptions ...storagego.FileOptions) (storagego.FileUploadResponse, error) {
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return storagego.FileUploadResponse{}, err
+	}
+	c.uploads = append(c.uploads, b)
+	return storagego.FileUploadResponse{Key: bucketID + "/" + relativePath}, nil
+}
+
+func (c *recordingObjectClient) RemoveFile(bucketID string, paths []string) ([]storagego.FileUploadResponse, error) {
+	return nil, nil
+}
+
+// mockObjectClient answers every call with a fixed response
+type mockObjectClient struct {
+	resp storagego.FileUploadResponse
+	err  error
+}
+
+func (m *mockObjectClient) UploadFile(bucketID, relativePath string, data io.Reader, fileOptions ...storagego.FileOptions) (storagego.FileUploadResponse, error) {
+	return m.resp, m.err
+}
+
+func (m *mockObjectClient) RemoveFile(bucketID string, paths []string) ([]storagego.FileUploadResponse, error) {
+	return []storagego.FileUploadResponse{m.resp}, m.err
+}
+
+func TestUploadFile_ProviderFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *mockObjectClient
+		wantMsg string
+	}{
+		{
+			name: "Failure in a success response",
+			client: &mockObjectClient{resp: storagego.FileUploadResponse{
+				Code: "404", Error: "Bucket not found", Message: "The resource was not found",
+			}},
+			wantMsg: "The resource was not found",
+		},
+		{
+			name:    "Error status without message",
+			client:  &mockObjectClient{err: &storagego.StorageError{Status: 413}},
+			wantMsg: "status 413",
+		},
+		{
+			name:    "Quota exceeded",
+			client:  &mockObjectClient{err: &storagego.StorageError{Status: 400, Message: "quota exceeded"}},
+			wantMsg: "quota exceeded",
+		},
+		{
+			name:    "Unacknowledged upload",
+			client:  &mockObjectClient{},
+			wantMsg: "not acknowledged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := NewSupabaseStorage(Config{URL: "https://test.supabase.co", Bucket: "test-bucket"})
+			s.client = tt.client
+
+			file, header := createMockFile(t, "photo.png", "image/png", testPNG(t))
+			defer file.Close()
+
+			url, err := s.UploadFile(context.TODO(), file, header, "products")
+			if !errors.Is(err, ErrProviderRejected) {
+				t.Fatalf("UploadFile() = %q, %v; want ErrProviderRejected", url, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("UploadFile() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestUploadFile_ProviderSuccess(t *testing.T) {
+	s, _ := NewSupabaseStorage(Config{URL: "https://test.supabase.co", Bucket: "test-bucket"})
+	s.client = &mockObjectClient{resp: storagego.FileUploadResponse{Key: "test-bucket/products/photo.jpg"}}
+
+	file, header := createMockFile(t, "photo.png", "image/png", testPNG(t))
+	defer file.Close()
+
+	if _, err := s.UploadFile(context.TODO(), file, header, "products"); err != nil {
+		t.Errorf("UploadFile() error = %v", err)
+	}
+}
+
+func TestDeleteFile_ProviderFailure(t *testing.T) {
+	s, _ := NewSupabaseStorage(Config{URL: "https://test.supabase.co", Bucket: "test-bucket"})
+	s.client = &mockObjectClient{resp: storagego.FileUploadResponse{Code: "403", Error: "Unauthorized"}}
+
+	if err := s.DeleteFile(context.TODO(), "products/photo.jpg"); !errors.Is(err, ErrProviderRejected) {
+		t.Errorf("DeleteFile() error = %v, want ErrProviderRejected", err)
+	}
+}
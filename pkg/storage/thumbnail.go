@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+// thumbnailJPEGQuality is the quality JPEG thumbnails are encoded at
+const thumbnailJPEGQuality = 85
+
+// Thumbnail is a resized copy of an uploaded image
+type Thumbnail struct {
+	Width  int
+	Height int
+	URL    string
+	Size   int64
+}
+
+// thumbnailData is an encoded thumbnail waiting to be stored
+type thumbnailData struct {
+	width, height int
+	data          []byte
+}
+
+// ParseThumbnailWidths reads a comma-separated list of thumbnail widths in
+// pixels, e.g. "200,800". An empty string yields no widths.
+func ParseThumbnailWidths(s string) ([]int, error) {
+	var widths []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		w, err := strconv.Atoi(item)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid thumbnail width %q", item)
+		}
+		widths = append(widths, w)
+	}
+	return widths, nil
+}
+
+// makeThumbnails scales an image decoded by decodeImage down to each width,
+// preserving its aspect ratio, and encodes the results as outputType. Widths
+// not smaller than the image are skipped. A nil image, from a format such as
+// GIF or SVG, gets no thumbnails.
+func makeThumbnails(img image.Image, outputType string, widths []int) ([]thumbnailData, error) {
+	if img == nil || len(widths) == 0 {
+		return nil, nil
+	}
+	bounds := img.Bounds()
+
+	var thumbs []thumbnailData
+	for _, width := range widths {
+		if width >= bounds.Dx() {
+			continue
+		}
+		height := max(1, bounds.Dy()*width/bounds.Dx())
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+		out, err := encodeImage(dst, outputType)
+		if err != nil {
+			return nil, err
+		}
+		thumbs = append(thumbs, thumbnailData{width: width, height: height, data: out})
+	}
+	return thumbs, nil
+}
+
+// encodeImage encodes img in the given format
+func encodeImage(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case WebPContentType:
+		err = nativewebp.Encode(&buf, img, nil)
+	case "image/jpeg", "image/jpg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailJPEGQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		return nil, fmt.Errorf("cannot encode %s images", contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s thumbnail: %w", contentType, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// gradientPNG draws a smooth gradient of the given size
+func gradientPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnails(t *testing.T) {
+	data := gradientPNG(t, 1000, 600)
+
+	thumbs, err := makeThumbnails(mustDecode(t, data, "image/png"), "image/png", []int{200, 800, 1000, 1200})
+	if err != nil {
+		t.Fatalf("makeThumbnails() error = %v", err)
+	}
+	// Widths at or above the original are not upscaled
+	if len(thumbs) != 2 {
+		t.Fatalf("got %d thumbnails, want 2", len(thumbs))
+	}
+	for i, want := range []struct{ w, h int }{{200, 120}, {800, 480}} {
+		thumb := thumbs[i]
+		if thumb.width != want.w || thumb.height != want.h {
+			t.Errorf("thumbnail %d is %dx%d, want %dx%d", i, thumb.width, thumb.height, want.w, want.h)
+		}
+		cfg, err := png.DecodeConfig(bytes.NewReader(thumb.data))
+		if err != nil || cfg.Width != want.w || cfg.Height != want.h {
+			t.Errorf("thumbnail %d decodes as %dx%d, %v", i, cfg.Width, cfg.Height, err)
+		}
+		if len(thumb.data) >= len(data) {
+			t.Errorf("thumbnail %d is %d bytes, want less than the original %d", i, len(thumb.data), len(data))
+		}
+	}
+}
+
+func TestMakeThumbnails_SkipsOtherFormats(t *testing.T) {
+	for _, contentType := range []string{"image/gif", "image/svg+xml"} {
+		thumbs, err := makeThumbnails(mustDecode(t, []byte("not decoded"), contentType), contentType, []int{200})
+		if err != nil || thumbs != nil {
+			t.Errorf("makeThumbnails(%q) = %v, %v; want skipped", contentType, thumbs, err)
+		}
+	}
+}
+
+func TestParseThumbnailWidths(t *testing.T) {
+	widths, err := ParseThumbnailWidths(" 200, 800,,")
+	if err != nil || len(widths) != 2 || widths[0] != 200 || widths[1] != 800 {
+		t.Errorf("ParseThumbnailWidths() = %v, %v; want [200 800]", widths, err)
+	}
+	for _, s := range []string{"200,wide", "0", "-5"} {
+		if _, err := ParseThumbnailWidths(s); err == nil {
+			t.Errorf("ParseThumbnailWidths(%q) error = nil, want error", s)
+		}
+	}
+}
+
+func TestUploadImage_StoresThumbnails(t *testing.T) {
+	fake := &fakeSupabase{contentTypes: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, _ := NewSupabaseStorage(Config{
+		URL:             srv.URL,
+		Key:             "test-key",
+		Bucket:          "test-bucket",
+		ThumbnailWidths: []int{800, 200, 200},
+	})
+
+	data := gradientPNG(t, 1000, 600)
+	file, header := createMockFile(t, "photo.png", "image/png", data)
+	defer file.Close()
+
+	result, err := s.UploadImage(context.TODO(), file, header, "products")
+	if err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+	if len(result.Thumbnails) != 2 {
+		t.Fatalf("got %d thumbnails, want 2", len(result.Thumbnails))
+	}
+	for i, want := range []int{200, 800} {
+		thumb := result.Thumbnails[i]
+		if thumb.Width != want || !strings.HasSuffix(thumb.URL, "_w"+strconv.Itoa(want)+".png") {
+			t.Errorf("thumbnail %d = %+v, want width %d", i, thumb, want)
+		}
+		if thumb.Size <= 0 || thumb.Size >= result.Size {
+			t.Errorf("thumbnail %d size = %d, want less than the original %d", i, thumb.Size, result.Size)
+		}
+	}
+	if len(fake.contentTypes) != 3 {
+		t.Errorf("uploaded %v, want the original and 2 thumbnails", fake.contentTypes)
+	}
+	for path, contentType := range fake.contentTypes {
+		if contentType != "image/png" {
+			t.Errorf("%s uploaded as %s, want image/png", path, contentType)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Headers sent with every delivery alongside the SignatureHeader
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Delivery defaults used when DispatcherConfig leaves them unset
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultTimeout        = 10 * time.Second
+)
+
+// maxResponseBytes is how much of an endpoint's response is read so the
+// connection can be reused
+const maxResponseBytes = 4 << 10
+
+// ErrDeliveryFailed is returned when an event could not be delivered within
+// the allowed attempts
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+// Event is the JSON body POSTed to subscribers
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Endpoint is a subscriber's URL and the secret its deliveries are signed
+// with
+type Endpoint struct {
+	ID     string
+	URL    string
+	Secret string
+}
+
+// Attempt describes a single delivery attempt. StatusCode is zero when no
+// response was received.
+type Attempt struct {
+	EndpointID string
+	EventID    string
+	EventType  string
+	Number     int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+	At         time.Time
+}
+
+// Succeeded reports whether the endpoint accepted the delivery
+func (a Attempt) Succeeded() bool {
+	return a.Err == nil
+}
+
+// Dispatcher delivers events to subscriber endpoints
+type Dispatcher interface {
+	// Dispatch delivers event to endpoint, retrying failed attempts, and
+	// returns ErrDeliveryFailed once the attempts are exhausted
+	Dispatch(ctx context.Context, endpoint Endpoint, event Event) error
+}
+
+// DispatcherConfig configures an HTTPDispatcher. Zero values use the
+// defaults.
+type DispatcherConfig struct {
+	// Client replaces the default client, which only connects to public
+	// addresses and does not follow redirects
+	Client *http.Client
+	// AllowPrivate lets the default client connect to private and loopback
+	// addresses, for local development
+	AllowPrivate   bool
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// OnAttempt, when not nil, is called after every attempt, e.g. to record
+	// it
+	OnAttempt func(Attempt)
+}
+
+// HTTPDispatcher POSTs signed events over HTTP. A 2xx response is a
+// successful delivery. Network errors, timeouts, 408, 429 and 5xx responses
+// are retried with exponential backoff; other responses, including
+// redirects, and refused private addresses fail immediately.
+type HTTPDispatcher struct {
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	onAttempt      func(Attempt)
+	sleep          func(ctx context.Context, d time.Duration) error
+}
+
+// NewHTTPDispatcher creates a dispatcher from cfg
+func NewHTTPDispatcher(cfg DispatcherConfig) *HTTPDispatcher {
+	d := &HTTPDispatcher{
+		client:         cfg.Client,
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		onAttempt:      cfg.OnAttempt,
+		sleep:          sleepContext,
+	}
+	if d.client == nil {
+		d.client = newClient(DefaultTimeout, cfg.AllowPrivate)
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = DefaultMaxAttempts
+	}
+	if d.initialBackoff <= 0 {
+		d.initialBackoff = DefaultInitialBackoff
+	}
+	if d.maxBackoff <= 0 {
+		d.maxBackoff = DefaultMaxBackoff
+	}
+	return d
+}
+
+// Dispatch implements Dispatcher
+func (d *HTTPDispatcher) Dispatch(ctx context.Context, endpoint Endpoint, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+	signature := Sign([]byte(endpoint.Secret), body)
+
+	backoff := d.initialBackoff
+	for number := 1; ; number++ {
+		start := time.Now()
+		status, err := d.post(ctx, endpoint.URL, event, body, signature)
+		if d.onAttempt != nil {
+			d.onAttempt(Attempt{
+				EndpointID: endpoint.ID,
+				EventID:    event.ID,
+				EventType:  event.Type,
+				Number:     number,
+				StatusCode: status,
+				Err:        err,
+				Duration:   time.Since(start),
+				At:         start,
+			})
+		}
+		if err == nil {
+			return nil
+		}
+		if number >= d.maxAttempts || !retryable(status) || errors.Is(err, ErrPrivateAddress) {
+			return fmt.Errorf("%w after %d attempts: %v", ErrDeliveryFailed, number, err)
+		}
+
+		if err := d.sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("%w after %d attempts: %v", ErrDeliveryFailed, number, err)
+		}
+		backoff = min(2*backoff, d.maxBackoff)
+	}
+}
+
+// post sends one delivery, returning the response status and an error unless
+// the endpoint answered with a 2xx
+func (d *HTTPDispatcher) post(ctx context.Context, url string, event Event, body []byte, signature string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a failed attempt with this response status may
+// succeed later. Zero means no response was received.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests || status >= 500
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testDispatcher returns a dispatcher that records attempts and backoff
+// delays instead of sleeping. It may connect to the loopback test servers.
+func testDispatcher(maxAttempts int) (*HTTPDispatcher, *[]Attempt, *[]time.Duration) {
+	var mu sync.Mutex
+	var attempts []Attempt
+	var delays []time.Duration
+	d := NewHTTPDispatcher(DispatcherConfig{
+		AllowPrivate:   true,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+		OnAttempt: func(a Attempt) {
+			mu.Lock()
+			attempts = append(attempts, a)
+			mu.Unlock()
+		},
+	})
+	d.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return ctx.Err()
+	}
+	return d, &attempts, &delays
+}
+
+var testEvent = Event{
+	ID:        "evt_1",
+	Type:      "order.status_changed",
+	CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	Data:      map[string]string{"order_id": "order-1", "status": "shipped"},
+}
+
+func TestDispatch_SignsPayload(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	t.Cleanup(server.Close)
+
+	d, _, _ := testDispatcher(1)
+	if err := d.Dispatch(context.Background(), Endpoint{ID: "wh-1", URL: server.URL, Secret: "whsec_seller"}, testEvent); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	if err := Verify([]byte("whsec_seller"), body, header.Get(SignatureHeader)); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	if got := header.Get(EventHeader); got != testEvent.Type {
+		t.Errorf("%s = %q, want %q", EventHeader, got, testEvent.Type)
+	}
+	if got := header.Get(DeliveryHeader); got != testEvent.ID {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, testEvent.ID)
+	}
+
+	var got struct {
+		ID   string            `json:"id"`
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if got.ID != "evt_1" || got.Type != testEvent.Type || got.Data["order_id"] != "order-1" {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestDispatch_RetriesUntilSuccess(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	d, attempts, delays := testDispatcher(5)
+	if err := d.Dispatch(context.Background(), Endpoint{ID: "wh-1", URL: server.URL, Secret: "s"}, testEvent); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("endpoint called %d times, want 3", calls)
+	}
+	if len(*attempts) != 3 {
+		t.Fatalf("recorded %d attempts, want 3", len(*attempts))
+	}
+	for i, a := range *attempts {
+		wantStatus, wantOK := http.StatusServiceUnavailable, false
+		if i == 2 {
+			wantStatus, wantOK = http.StatusOK, true
+		}
+		if a.Number != i+1 || a.StatusCode != wantStatus || a.Succeeded() != wantOK || a.EndpointID != "wh-1" || a.EventID != "evt_1" {
+			t.Errorf("attempt %d = %+v", i+1, a)
+		}
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(*delays) != 2 || (*delays)[0] != want[0] || (*delays)[1] != want[1] {
+		t.Errorf("backoff delays = %v, want %v", *delays, want)
+	}
+}
+
+func TestDispatch_GivesUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantAttempts int
+	}{
+		{"Retryable failure exhausts the attempts", http.StatusBadGateway, 4},
+		{"Rate limited is retried", http.StatusTooManyRequests, 4},
+		{"Client error is not retried", http.StatusGone, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			d, attempts, delays := testDispatcher(4)
+			err := d.Dispatch(context.Background(), Endpoint{URL: server.URL}, testEvent)
+			if !errors.Is(err, ErrDeliveryFailed) {
+				t.Fatalf("Dispatch() error = %v, want %v", err, ErrDeliveryFailed)
+			}
+			if len(*attempts) != tt.wantAttempts {
+				t.Errorf("recorded %d attempts, want %d", len(*attempts), tt.wantAttempts)
+			}
+			// The backoff doubles up to the maximum
+			for i, delay := range *delays {
+				if want := min(time.Second<<i, 3*time.Second); delay != want {
+					t.Errorf("delay %d = %v, want %v", i+1, delay, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatch_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	d, attempts, _ := testDispatcher(2)
+	if err := d.Dispatch(context.Background(), Endpoint{URL: server.URL}, testEvent); !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Dispatch() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if len(*attempts) != 2 || (*attempts)[0].StatusCode != 0 || (*attempts)[0].Succeeded() {
+		t.Errorf("attempts = %+v, want 2 without a response", *attempts)
+	}
+}
+
+func TestDispatch_StopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	d := NewHTTPDispatcher(DispatcherConfig{AllowPrivate: true, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- d.Dispatch(ctx, Endpoint{URL: server.URL}, testEvent) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDeliveryFailed) {
+			t.Errorf("Dispatch() error = %v, want %v", err, ErrDeliveryFailed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch() kept waiting after the context was cancelled")
+	}
+}
+
+func TestDispatch_RefusesPrivateAddresses(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	t.Cleanup(server.Close)
+
+	var attempts []Attempt
+	d := NewHTTPDispatcher(DispatcherConfig{
+		MaxAttempts: 3,
+		OnAttempt:   func(a Attempt) { attempts = append(attempts, a) },
+	})
+	err := d.Dispatch(context.Background(), Endpoint{URL: server.URL}, testEvent)
+	if !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Dispatch() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if calls != 0 {
+		t.Errorf("loopback endpoint was called %d times", calls)
+	}
+	// A refused address is not retried
+	if len(attempts) != 1 || !errors.Is(attempts[0].Err, ErrPrivateAddress) {
+		t.Errorf("attempts = %+v, want one refused with ErrPrivateAddress", attempts)
+	}
+}
+
+func TestDispatch_DoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	d, attempts, _ := testDispatcher(3)
+	if err := d.Dispatch(context.Background(), Endpoint{URL: server.URL + "/hook"}, testEvent); !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Dispatch() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if redirected {
+		t.Error("the redirect was followed")
+	}
+	if len(*attempts) != 1 || (*attempts)[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("attempts = %+v, want one answered with a redirect", *attempts)
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("PublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
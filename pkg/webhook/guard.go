@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a delivery would connect to an address
+// that is not publicly routable, such as a loopback, private or link-local
+// one
+var ErrPrivateAddress = errors.New("webhook address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// net.IP does not count as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP reports whether ip is a publicly routable unicast address
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// publicOnly is a net.Dialer Control function refusing connections to
+// addresses PublicIP rejects. It sees the address actually dialled, after DNS
+// resolution, so a host that re-resolves to a private address between
+// validation and delivery is still refused.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// newClient returns the HTTP client deliveries are made with. It never
+// follows redirects, since a redirect could point anywhere, and unless
+// allowPrivate is set only connects to public addresses. Proxies are not
+// used, as the proxy rather than the endpoint would be checked.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = publicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,46 @@
+// Package webhook signs, verifies and delivers webhook payloads. A signature
+// is the hex-encoded HMAC-SHA256 of the raw request body, prefixed with
+// "sha256=", and travels in the SignatureHeader.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// SignatureHeader carries the payload signature
+const SignatureHeader = "X-Signature"
+
+// signaturePrefix names the algorithm in front of the hex digest
+const signaturePrefix = "sha256="
+
+// ErrInvalidSignature is returned for a missing, malformed or mismatched
+// signature
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature of payload under secret
+func Sign(secret, payload []byte) string {
+	return signaturePrefix + hex.EncodeToString(digest(secret, payload))
+}
+
+// Verify checks signature against payload in constant time. The "sha256="
+// prefix is optional, since some providers send the bare digest.
+func Verify(secret, payload []byte, signature string) error {
+	got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), signaturePrefix))
+	if err != nil || len(got) != sha256.Size {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal(got, digest(secret, payload)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func digest(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
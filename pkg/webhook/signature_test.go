@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("whsec_test")
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	signature := Sign(secret, payload)
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("Sign() = %q, want a sha256= prefix", signature)
+	}
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		payload   []byte
+		signature string
+		wantErr   bool
+	}{
+		{"Valid", secret, payload, signature, false},
+		{"Bare digest", secret, payload, strings.TrimPrefix(signature, "sha256="), false},
+		{"Wrong secret", []byte("other"), payload, signature, true},
+		{"Tampered payload", secret, []byte(`{"id":"evt_1","type":"payment.failed"}`), signature, true},
+		{"Missing", secret, payload, "", true},
+		{"Not hex", secret, payload, "sha256=zz", true},
+		{"Truncated", secret, payload, signature[:20], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.payload, tt.signature)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}